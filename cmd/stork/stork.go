@@ -2,11 +2,17 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/libopenstorage/stork/drivers/volume"
+	_ "github.com/libopenstorage/stork/drivers/volume/csi"
+	"github.com/libopenstorage/stork/drivers/volume/plugin"
 	_ "github.com/libopenstorage/stork/drivers/volume/portworx"
 	"github.com/libopenstorage/stork/pkg/cluster"
 	"github.com/libopenstorage/stork/pkg/controller"
@@ -15,11 +21,14 @@ import (
 	"github.com/libopenstorage/stork/pkg/initializer"
 	"github.com/libopenstorage/stork/pkg/migration"
 	"github.com/libopenstorage/stork/pkg/monitor"
+	"github.com/libopenstorage/stork/pkg/nodewatcher"
+	"github.com/libopenstorage/stork/pkg/pvcexpander"
 	"github.com/libopenstorage/stork/pkg/pvcwatcher"
 	"github.com/libopenstorage/stork/pkg/rule"
 	"github.com/libopenstorage/stork/pkg/schedule"
 	"github.com/libopenstorage/stork/pkg/snapshot"
 	"github.com/libopenstorage/stork/pkg/version"
+	"github.com/portworx/sched-ops/k8s"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 	api_v1 "k8s.io/api/core/v1"
@@ -38,6 +47,8 @@ const (
 	defaultLockObjectName      = "stork"
 	defaultLockObjectNamespace = "kube-system"
 	eventComponentName         = "stork"
+
+	defaultDriverConfigMapNamespace = "kube-system"
 )
 
 var ext *extender.Extender
@@ -67,7 +78,7 @@ func main() {
 		},
 		cli.StringFlag{
 			Name:  "driver,d",
-			Usage: "Storage driver name",
+			Usage: "Storage driver name. Multiple drivers can be specified as a comma separated list",
 		},
 		cli.BoolTFlag{
 			Name:  "leader-elect",
@@ -91,6 +102,41 @@ func main() {
 			Name:  "extender",
 			Usage: "Enable scheduler extender for hyperconvergence (default: true)",
 		},
+		cli.StringFlag{
+			Name:  "extender-address",
+			Usage: "Address on which the scheduler extender should listen (default: :8099)",
+			Value: extender.DefaultListenAddress,
+		},
+		cli.StringFlag{
+			Name:  "extender-tls-cert",
+			Usage: "Certificate file used by the scheduler extender to serve HTTPS (default: none)",
+		},
+		cli.StringFlag{
+			Name:  "extender-tls-key",
+			Usage: "Private key file for the scheduler extender certificate (default: none)",
+		},
+		cli.StringFlag{
+			Name:  "extender-tls-client-ca",
+			Usage: "CA file used to verify client certificates from the scheduler. Requires --extender-tls-cert (default: none)",
+		},
+		cli.StringFlag{
+			Name:  "extender-scoring-configmap-name",
+			Usage: "Name of the ConfigMap with the weights used by the scheduler extender to score nodes (default: stork-scoring-policy)",
+			Value: extender.DefaultScoringConfigMapName,
+		},
+		cli.StringFlag{
+			Name:  "extender-scoring-configmap-namespace",
+			Usage: "Namespace of the ConfigMap with the weights used by the scheduler extender to score nodes (default: kube-system)",
+			Value: extender.DefaultScoringConfigMapNamespace,
+		},
+		cli.BoolFlag{
+			Name:  "extender-spread-replicas",
+			Usage: "Spread pods with the same owner across storage failure domains in the scheduler extender (default: false)",
+		},
+		cli.Int64Flag{
+			Name:  "extender-cache-ttl",
+			Usage: "The duration in seconds for which the scheduler extender caches nodes and volumes from the storage driver, 0 to disable (default: 0)",
+		},
 		cli.BoolTFlag{
 			Name:  "health-monitor",
 			Usage: "Enable health monitoring of the storage driver (default: true)",
@@ -99,6 +145,10 @@ func main() {
 			Name:  "health-monitor-interval",
 			Usage: "The interval in seconds to monitor the health of the storage driver (default: 120, min: 30)",
 		},
+		cli.Int64Flag{
+			Name:  "health-monitor-io-error-restart-interval",
+			Usage: "The interval in seconds after which pods are restarted again if IO to their volume is still failing (default: 600)",
+		},
 		cli.BoolTFlag{
 			Name:  "migration-controller",
 			Usage: "Start the migration controller (default: true)",
@@ -115,10 +165,52 @@ func main() {
 			Name:  "storage-cluster-controller",
 			Usage: "Start the storage cluster controller (default: false)",
 		},
+		cli.StringSliceFlag{
+			Name:  "driver-plugin",
+			Usage: "Volume driver plugin to load, specified as name=endpoint. The endpoint can be a unix socket (unix:///path) or host:port. The name can then be used with --driver (default: none)",
+		},
+		cli.StringSliceFlag{
+			Name:  "driver-option",
+			Usage: "Option passed to the storage drivers when they are initialized, specified as key=value. For example api=sdk to use the OpenStorage SDK with portworx (default: none)",
+		},
+		cli.StringFlag{
+			Name:  "driver-config-file",
+			Usage: "File with the configuration for the storage drivers. Can't be used with --driver-option (default: none)",
+		},
+		cli.StringFlag{
+			Name:  "driver-configmap-name",
+			Usage: "Name of the ConfigMap with the configuration for the storage drivers in the " + volume.DriverConfigKey + " key. Can't be used with --driver-option or --driver-config-file (default: none)",
+		},
+		cli.StringFlag{
+			Name:  "driver-configmap-namespace",
+			Usage: "Namespace of the ConfigMap with the configuration for the storage drivers (default: kube-system)",
+			Value: defaultDriverConfigMapNamespace,
+		},
+		cli.StringFlag{
+			Name:  "capabilities-configmap-namespace",
+			Usage: "Namespace of the ConfigMap in which the capabilities of the storage drivers are published (default: kube-system)",
+			Value: volume.DefaultCapabilitiesConfigMapNamespace,
+		},
 		cli.BoolTFlag{
 			Name:  "pvc-watcher",
 			Usage: "Start the controller to monitor PVC creation and deletions (default: true)",
 		},
+		cli.BoolFlag{
+			Name:  "pvc-expander",
+			Usage: "Start the controller to expand volumes based on the thresholds set in PVC and StorageClass annotations (default: false)",
+		},
+		cli.Int64Flag{
+			Name:  "pvc-expander-interval",
+			Usage: "The interval in seconds to check the usage of volumes for expansion (default: 60, min: 10)",
+		},
+		cli.BoolFlag{
+			Name:  "node-maintenance",
+			Usage: "Start the controller to put the driver into maintenance on nodes that are cordoned and drained (default: false)",
+		},
+		cli.IntFlag{
+			Name:  "node-maintenance-max-nodes",
+			Usage: "The maximum number of driver nodes that can be in maintenance at the same time (default: 1)",
+		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -139,12 +231,43 @@ func run(c *cli.Context) {
 		log.SetLevel(log.DebugLevel)
 	}
 
-	d, err := volume.Get(driverName)
+	for _, driverPlugin := range c.StringSlice("driver-plugin") {
+		pluginConfig := strings.SplitN(driverPlugin, "=", 2)
+		if len(pluginConfig) != 2 {
+			log.Fatalf("Invalid driver plugin %v, should be specified as name=endpoint", driverPlugin)
+		}
+		if err := plugin.Register(pluginConfig[0], pluginConfig[1]); err != nil {
+			log.Fatalf("Error registering driver plugin %v: %v", driverPlugin, err)
+		}
+	}
+
+	d, err := volume.GetMultiple(strings.Split(driverName, ","))
 	if err != nil {
 		log.Fatalf("Error getting Stork Driver %v: %v", driverName, err)
 	}
 
-	if err = d.Init(nil); err != nil {
+	// Drivers fall back to their defaults if no config or options are
+	// passed in
+	driverConfig, err := getDriverConfig(c, d, strings.Split(driverName, ","))
+	if err != nil {
+		log.Fatalf("Error getting config for Stork Driver %v: %v", driverName, err)
+	}
+	if driverOptions := c.StringSlice("driver-option"); len(driverOptions) > 0 {
+		if driverConfig != nil {
+			log.Fatalf("Driver options can't be used with a driver config")
+		}
+		options := make(map[string]string)
+		for _, driverOption := range driverOptions {
+			option := strings.SplitN(driverOption, "=", 2)
+			if len(option) != 2 {
+				log.Fatalf("Invalid driver option %v, should be specified as key=value", driverOption)
+			}
+			options[option[0]] = option[1]
+		}
+		driverConfig = options
+	}
+
+	if err = d.Init(driverConfig); err != nil {
 		log.Fatalf("Error initializing Stork Driver %v: %v", driverName, err)
 	}
 
@@ -164,7 +287,17 @@ func run(c *cli.Context) {
 
 	if c.Bool("extender") {
 		ext = &extender.Extender{
-			Driver: d,
+			Driver:                    d,
+			ListenAddress:             c.String("extender-address"),
+			TLSCertFile:               c.String("extender-tls-cert"),
+			TLSKeyFile:                c.String("extender-tls-key"),
+			TLSClientCAFile:           c.String("extender-tls-client-ca"),
+			KubeClient:                k8sClient,
+			Recorder:                  recorder,
+			ScoringConfigMapName:      c.String("extender-scoring-configmap-name"),
+			ScoringConfigMapNamespace: c.String("extender-scoring-configmap-namespace"),
+			SpreadReplicas:            c.Bool("extender-spread-replicas"),
+			CacheTTL:                  time.Duration(c.Int64("extender-cache-ttl")) * time.Second,
 		}
 
 		if err = ext.Start(); err != nil {
@@ -173,7 +306,7 @@ func run(c *cli.Context) {
 	}
 
 	runFunc := func(_ <-chan struct{}) {
-		runStork(d, recorder, c)
+		runStork(d, recorder, k8sClient, c)
 	}
 
 	if c.BoolT("leader-elect") {
@@ -228,7 +361,45 @@ func run(c *cli.Context) {
 	}
 }
 
-func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
+// getDriverConfig Returns the config for the driver parsed from the driver
+// config file or ConfigMap, or nil if neither was specified
+func getDriverConfig(c *cli.Context, d volume.Driver, driverNames []string) (interface{}, error) {
+	var data []byte
+	configFile := c.String("driver-config-file")
+	configMapName := c.String("driver-configmap-name")
+	if configFile != "" && configMapName != "" {
+		return nil, fmt.Errorf("Only one of the driver config file and ConfigMap can be specified")
+	}
+	if configFile != "" {
+		var err error
+		if data, err = ioutil.ReadFile(configFile); err != nil {
+			return nil, fmt.Errorf("Error reading driver config file: %v", err)
+		}
+	} else if configMapName != "" {
+		configMapNamespace := c.String("driver-configmap-namespace")
+		configMap, err := k8s.Instance().GetConfigMap(configMapName, configMapNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting driver config ConfigMap %v/%v: %v",
+				configMapNamespace, configMapName, err)
+		}
+		config, ok := configMap.Data[volume.DriverConfigKey]
+		if !ok {
+			return nil, fmt.Errorf("Driver config ConfigMap %v/%v doesn't have the %v key",
+				configMapNamespace, configMapName, volume.DriverConfigKey)
+		}
+		data = []byte(config)
+	} else {
+		return nil, nil
+	}
+
+	configs, err := volume.ParseConfigs(data, driverNames)
+	if err != nil {
+		return nil, err
+	}
+	return configs.ForDriver(d), nil
+}
+
+func runStork(d volume.Driver, recorder record.EventRecorder, k8sClient clientset.Interface, c *cli.Context) {
 	if err := controller.Init(); err != nil {
 		log.Fatalf("Error initializing controller: %v", err)
 	}
@@ -237,6 +408,11 @@ func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
 		log.Fatalf("Error initializing rule: %v", err)
 	}
 
+	capabilities := d.Capabilities()
+	if err := volume.PublishCapabilities(d, c.String("capabilities-configmap-namespace"), version.Version); err != nil {
+		log.Warnf("Error publishing driver capabilities: %v", err)
+	}
+
 	initializer := &initializer.Initializer{
 		Driver: d,
 	}
@@ -247,8 +423,15 @@ func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
 	}
 
 	monitor := &monitor.Monitor{
-		Driver:      d,
-		IntervalSec: c.Int64("health-monitor-interval"),
+		Driver:                    d,
+		IntervalSec:               c.Int64("health-monitor-interval"),
+		IOErrorRestartIntervalSec: c.Int64("health-monitor-io-error-restart-interval"),
+	}
+	if ext != nil {
+		monitor.NodeStatusChangeHandlers = append(monitor.NodeStatusChangeHandlers,
+			func(*volume.NodeInfo) {
+				ext.InvalidateCache()
+			})
 	}
 
 	if c.Bool("health-monitor") {
@@ -265,7 +448,12 @@ func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
 		Driver:   d,
 		Recorder: recorder,
 	}
-	if c.Bool("snapshotter") {
+	snapshotter := c.Bool("snapshotter")
+	if snapshotter && !capabilities.Snapshots {
+		log.Infof("Snapshots are not supported by driver %v, not starting snapshot controllers", d.String())
+		snapshotter = false
+	}
+	if snapshotter {
 		if err := snapshot.Start(); err != nil {
 			log.Fatalf("Error starting snapshot controller: %v", err)
 		}
@@ -288,6 +476,38 @@ func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
 		}
 	}
 
+	pvcExpander := &pvcexpander.PVCExpander{
+		Driver:      d,
+		Recorder:    recorder,
+		KubeClient:  k8sClient,
+		IntervalSec: c.Int64("pvc-expander-interval"),
+	}
+	expandPVCs := c.Bool("pvc-expander")
+	if expandPVCs && !capabilities.Resize {
+		log.Infof("Resizing volumes is not supported by driver %v, not starting PVC expander", d.String())
+		expandPVCs = false
+	}
+	if expandPVCs {
+		if err := pvcExpander.Start(); err != nil {
+			log.Fatalf("Error starting PVC expander: %v", err)
+		}
+	}
+
+	if c.Bool("node-maintenance") {
+		if !capabilities.Maintenance {
+			log.Infof("Node maintenance is not supported by driver %v, not starting node watcher", d.String())
+		} else {
+			nodeWatcher := nodewatcher.NodeWatcher{
+				Driver:                d,
+				Recorder:              recorder,
+				MaxNodesInMaintenance: c.Int("node-maintenance-max-nodes"),
+			}
+			if err := nodeWatcher.Start(); err != nil {
+				log.Fatalf("Error starting node watcher: %v", err)
+			}
+		}
+	}
+
 	if c.Bool("migration-controller") {
 		migrationAdminNamespace := c.String("migration-admin-namespace")
 		migration := migration.Migration{
@@ -329,11 +549,16 @@ func runStork(d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
 				log.Warnf("Error stopping monitor: %v", err)
 			}
 		}
-		if c.Bool("snapshotter") {
+		if snapshotter {
 			if err := snapshot.Stop(); err != nil {
 				log.Warnf("Error stopping snapshot controllers: %v", err)
 			}
 		}
+		if expandPVCs {
+			if err := pvcExpander.Stop(); err != nil {
+				log.Warnf("Error stopping PVC expander: %v", err)
+			}
+		}
 		if c.Bool("app-initializer") {
 			if err := initializer.Stop(); err != nil {
 				log.Warnf("Error stopping app-initializer: %v", err)
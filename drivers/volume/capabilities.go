@@ -0,0 +1,128 @@
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/portworx/sched-ops/k8s"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CapabilitiesConfigMapName Name of the ConfigMap in which stork publishes
+	// the capabilities of its volume drivers
+	CapabilitiesConfigMapName = "stork-capabilities"
+	// DefaultCapabilitiesConfigMapNamespace Default namespace for the
+	// capabilities ConfigMap
+	DefaultCapabilitiesConfigMapNamespace = "kube-system"
+	// capabilitiesVersionKey Key in the capabilities ConfigMap with the
+	// version of stork that published it. All other keys are driver names
+	capabilitiesVersionKey = "version"
+)
+
+// TopologyLevel Level of the topology that a driver reports for its nodes
+type TopologyLevel string
+
+const (
+	// TopologyLevelRack Driver reports the rack of nodes
+	TopologyLevelRack TopologyLevel = "rack"
+	// TopologyLevelZone Driver reports the zone of nodes
+	TopologyLevelZone TopologyLevel = "zone"
+	// TopologyLevelRegion Driver reports the region of nodes
+	TopologyLevelRegion TopologyLevel = "region"
+)
+
+// Capabilities Features supported by a volume driver. Used to disable
+// controllers and reject requests for features that a driver doesn't support
+type Capabilities struct {
+	// Snapshots Driver supports snapshots of volumes
+	Snapshots bool `json:"snapshots"`
+	// CloudSnapshots Driver supports snapshots stored in an objectstore
+	CloudSnapshots bool `json:"cloudSnapshots"`
+	// GroupSnapshots Driver supports snapshots of a group of volumes
+	GroupSnapshots bool `json:"groupSnapshots"`
+	// ClusterPair Driver supports pairing with a remote cluster
+	ClusterPair bool `json:"clusterPair"`
+	// Migration Driver supports migrating volumes to a paired cluster
+	Migration bool `json:"migration"`
+	// Resize Driver supports expanding volumes
+	Resize bool `json:"resize"`
+	// Maintenance Driver supports putting nodes into maintenance mode
+	Maintenance bool `json:"maintenance"`
+	// TopologyLevels Levels of the topology reported for nodes
+	TopologyLevels []TopologyLevel `json:"topologyLevels"`
+}
+
+// HasTopologyLevel Returns true if the driver reports the given level of
+// the topology for its nodes
+func (c *Capabilities) HasTopologyLevel(level TopologyLevel) bool {
+	for _, l := range c.TopologyLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishCapabilities Creates or updates the capabilities ConfigMap in the
+// given namespace with the capabilities of each driver used by stork
+func PublishCapabilities(d Driver, namespace string, version string) error {
+	drivers := []Driver{d}
+	if composite, ok := d.(*CompositeDriver); ok {
+		drivers = composite.GetDrivers()
+	}
+
+	data := map[string]string{
+		capabilitiesVersionKey: version,
+	}
+	for _, driver := range drivers {
+		encoded, err := json.Marshal(driver.Capabilities())
+		if err != nil {
+			return fmt.Errorf("Error encoding capabilities for driver %v: %v", driver.String(), err)
+		}
+		data[driver.String()] = string(encoded)
+	}
+
+	configMap, err := k8s.Instance().GetConfigMap(CapabilitiesConfigMapName, namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		_, err = k8s.Instance().CreateConfigMap(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      CapabilitiesConfigMapName,
+				Namespace: namespace,
+			},
+			Data: data,
+		})
+		return err
+	}
+	configMap.Data = data
+	_, err = k8s.Instance().UpdateConfigMap(configMap)
+	return err
+}
+
+// GetPublishedCapabilities Returns the version of stork and the capabilities
+// of each of its drivers, keyed by driver name, from the capabilities
+// ConfigMap in the given namespace
+func GetPublishedCapabilities(namespace string) (string, map[string]*Capabilities, error) {
+	configMap, err := k8s.Instance().GetConfigMap(CapabilitiesConfigMapName, namespace)
+	if err != nil {
+		return "", nil, err
+	}
+
+	capabilities := make(map[string]*Capabilities)
+	for key, value := range configMap.Data {
+		if key == capabilitiesVersionKey {
+			continue
+		}
+		driverCapabilities := &Capabilities{}
+		if err := json.Unmarshal([]byte(value), driverCapabilities); err != nil {
+			return "", nil, fmt.Errorf("Error parsing capabilities for driver %v: %v", key, err)
+		}
+		capabilities[key] = driverCapabilities
+	}
+	return configMap.Data[capabilitiesVersionKey], capabilities, nil
+}
@@ -0,0 +1,563 @@
+package volume
+
+import (
+	"fmt"
+	"strings"
+
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	snapshotVolume "github.com/kubernetes-incubator/external-storage/snapshot/pkg/volume"
+	stork_crd "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// CompositeDriver Driver that dispatches requests to multiple drivers based
+// on the driver that owns each PVC. The nodes and volumes returned by the
+// drivers are merged, with the name of the driver recorded in them
+type CompositeDriver struct {
+	drivers []Driver
+}
+
+// NewCompositeDriver Returns a driver that dispatches requests to the given
+// drivers
+func NewCompositeDriver(drivers []Driver) *CompositeDriver {
+	return &CompositeDriver{
+		drivers: drivers,
+	}
+}
+
+// GetMultiple Returns the driver to be used for the given driver names. A
+// CompositeDriver is returned if more than one name is given
+func GetMultiple(names []string) (Driver, error) {
+	if len(names) == 1 {
+		return Get(names[0])
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("No driver names specified")
+	}
+
+	drivers := make([]Driver, 0, len(names))
+	for _, name := range names {
+		d, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+		drivers = append(drivers, d)
+	}
+	return NewCompositeDriver(drivers), nil
+}
+
+// GetDrivers Returns the drivers to which requests are dispatched
+func (c *CompositeDriver) GetDrivers() []Driver {
+	return c.drivers
+}
+
+// GetDriverForPVC Returns the driver that owns the PVC, nil if none of the
+// drivers own it
+func (c *CompositeDriver) GetDriverForPVC(pvc *v1.PersistentVolumeClaim) Driver {
+	for _, d := range c.drivers {
+		if d.OwnsPVC(pvc) {
+			return d
+		}
+	}
+	return nil
+}
+
+// GetDriverForPV Returns the driver that owns the PVC bound to the PV, nil
+// if the PV isn't bound or none of the drivers own the PVC
+func (c *CompositeDriver) GetDriverForPV(pv *v1.PersistentVolume) Driver {
+	if pv.Spec.ClaimRef == nil {
+		return nil
+	}
+	pvc, err := k8s.Instance().GetPersistentVolumeClaim(pv.Spec.ClaimRef.Name, pv.Spec.ClaimRef.Namespace)
+	if err != nil {
+		logrus.Warnf("Error getting pvc %v for pv %v: %v", pv.Spec.ClaimRef.Name, pv.Name, err)
+		return nil
+	}
+	return c.GetDriverForPVC(pvc)
+}
+
+// GetDriverForSnapshot Returns the driver which took the snapshot, nil if
+// none of the drivers recognize it
+func (c *CompositeDriver) GetDriverForSnapshot(snap *snapv1.VolumeSnapshot) Driver {
+	for _, d := range c.drivers {
+		if _, err := d.GetSnapshotType(snap); err == nil {
+			return d
+		}
+	}
+	return nil
+}
+
+// getDriverForSelector Returns the driver that owns the PVCs matching the
+// selector. Returns an error if the PVCs are owned by different drivers
+func (c *CompositeDriver) getDriverForSelector(namespace string, selector map[string]string) (Driver, error) {
+	pvcs, err := k8s.Instance().GetPersistentVolumeClaims(namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	var owner Driver
+	for _, pvc := range pvcs.Items {
+		d := c.GetDriverForPVC(&pvc)
+		if d == nil {
+			continue
+		}
+		if owner != nil && owner.String() != d.String() {
+			return nil, &errors.ErrNotSupported{
+				Feature: "Group snapshots",
+				Reason:  fmt.Sprintf("PVCs are owned by different drivers: %v and %v", owner.String(), d.String()),
+			}
+		}
+		owner = d
+	}
+	if owner == nil {
+		return nil, fmt.Errorf("No PVCs owned by the drivers found for selector %v", selector)
+	}
+	return owner, nil
+}
+
+// getDriver Returns the driver with the given name, nil if not found
+func (c *CompositeDriver) getDriver(name string) Driver {
+	for _, d := range c.drivers {
+		if d.String() == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// String Returns the comma separated names of the drivers
+func (c *CompositeDriver) String() string {
+	names := make([]string, 0, len(c.drivers))
+	for _, d := range c.drivers {
+		names = append(names, d.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Init Initializes the drivers. If the config is a Configs each driver
+// gets its own config, otherwise all the drivers get the same config
+func (c *CompositeDriver) Init(config interface{}) error {
+	configs, perDriver := config.(Configs)
+	for _, d := range c.drivers {
+		driverConfig := config
+		if perDriver {
+			driverConfig = configs[d.String()]
+		}
+		if err := d.Init(driverConfig); err != nil {
+			return fmt.Errorf("Error initializing driver %v: %v", d.String(), err)
+		}
+	}
+	return nil
+}
+
+// Stop Stops all the drivers
+func (c *CompositeDriver) Stop() error {
+	var lastErr error
+	for _, d := range c.drivers {
+		if err := d.Stop(); err != nil {
+			logrus.Warnf("Error stopping driver %v: %v", d.String(), err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// InspectVolume Returns the info from the first driver that finds the volume
+func (c *CompositeDriver) InspectVolume(volumeID string) (*Info, error) {
+	for _, d := range c.drivers {
+		info, err := d.InspectVolume(volumeID)
+		if err == nil {
+			infoCopy := *info
+			infoCopy.Driver = d.String()
+			return &infoCopy, nil
+		}
+	}
+	return nil, &errors.ErrNotFound{
+		ID:   volumeID,
+		Type: "Volume",
+	}
+}
+
+// InspectVolumes Inspects the volumes using each driver in turn, passing
+// each driver only the volumes that weren't found by the previous ones
+func (c *CompositeDriver) InspectVolumes(volumeIDs []string) (map[string]*Info, error) {
+	volumes := make(map[string]*Info)
+	remaining := volumeIDs
+	for _, d := range c.drivers {
+		if len(remaining) == 0 {
+			break
+		}
+		driverVolumes, err := InspectVolumes(d, remaining)
+		if err != nil {
+			logrus.Warnf("Error inspecting volumes with driver %v: %v", d.String(), err)
+			continue
+		}
+		notFound := make([]string, 0, len(remaining))
+		for _, volumeID := range remaining {
+			info, ok := driverVolumes[volumeID]
+			if !ok {
+				notFound = append(notFound, volumeID)
+				continue
+			}
+			infoCopy := *info
+			infoCopy.Driver = d.String()
+			volumes[volumeID] = &infoCopy
+		}
+		remaining = notFound
+	}
+	return volumes, nil
+}
+
+// ResizeVolume Resizes the volume using the first driver that finds it
+func (c *CompositeDriver) ResizeVolume(volumeID string, sizeBytes uint64) (*Info, error) {
+	for _, d := range c.drivers {
+		if _, err := d.InspectVolume(volumeID); err != nil {
+			continue
+		}
+		info, err := d.ResizeVolume(volumeID, sizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		infoCopy := *info
+		infoCopy.Driver = d.String()
+		return &infoCopy, nil
+	}
+	return nil, &errors.ErrNotFound{
+		ID:   volumeID,
+		Type: "Volume",
+	}
+}
+
+// GetNodes Returns the nodes from all the drivers. A Kubernetes node will be
+// returned once for each driver running on it
+func (c *CompositeDriver) GetNodes() ([]*NodeInfo, error) {
+	var nodes []*NodeInfo
+	for _, d := range c.drivers {
+		driverNodes, err := d.GetNodes()
+		if err != nil {
+			return nil, fmt.Errorf("Error getting nodes for driver %v: %v", d.String(), err)
+		}
+		for _, node := range driverNodes {
+			nodeCopy := *node
+			nodeCopy.Driver = d.String()
+			nodes = append(nodes, &nodeCopy)
+		}
+	}
+	return nodes, nil
+}
+
+// GetPodVolumes Returns the volumes used by the pod from all the drivers
+func (c *CompositeDriver) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*Info, error) {
+	var volumes []*Info
+	for _, d := range c.drivers {
+		driverVolumes, err := d.GetPodVolumes(podSpec, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range driverVolumes {
+			volumeCopy := *volume
+			volumeCopy.Driver = d.String()
+			volumes = append(volumes, &volumeCopy)
+		}
+	}
+	return volumes, nil
+}
+
+// GetVolumeClaimTemplates Returns the templates owned by any of the drivers
+func (c *CompositeDriver) GetVolumeClaimTemplates(templates []v1.PersistentVolumeClaim) (
+	[]v1.PersistentVolumeClaim, error) {
+	var driverTemplates []v1.PersistentVolumeClaim
+	for _, t := range templates {
+		if c.OwnsPVC(&t) {
+			driverTemplates = append(driverTemplates, t)
+		}
+	}
+	return driverTemplates, nil
+}
+
+// OwnsPVC Returns true if any of the drivers own the PVC
+func (c *CompositeDriver) OwnsPVC(pvc *v1.PersistentVolumeClaim) bool {
+	return c.GetDriverForPVC(pvc) != nil
+}
+
+// GetSnapshotPlugin Returns the snapshot plugin of the first driver that has
+// one. GetSnapshotPlugins should be used to get the plugins for all the
+// drivers
+func (c *CompositeDriver) GetSnapshotPlugin() snapshotVolume.Plugin {
+	for _, d := range c.drivers {
+		if plugin := d.GetSnapshotPlugin(); plugin != nil {
+			return plugin
+		}
+	}
+	return nil
+}
+
+// GetSnapshotType Returns the snapshot type from the driver which took the
+// snapshot
+func (c *CompositeDriver) GetSnapshotType(snap *snapv1.VolumeSnapshot) (string, error) {
+	d := c.GetDriverForSnapshot(snap)
+	if d == nil {
+		return "", &errors.ErrNotSupported{}
+	}
+	return d.GetSnapshotType(snap)
+}
+
+// Capabilities Returns the features supported by any of the drivers.
+// Requests are still routed to the driver owning the volumes, which can fail
+// them if it doesn't support the feature
+func (c *CompositeDriver) Capabilities() *Capabilities {
+	capabilities := &Capabilities{}
+	for _, d := range c.drivers {
+		driverCapabilities := d.Capabilities()
+		capabilities.Snapshots = capabilities.Snapshots || driverCapabilities.Snapshots
+		capabilities.CloudSnapshots = capabilities.CloudSnapshots || driverCapabilities.CloudSnapshots
+		capabilities.GroupSnapshots = capabilities.GroupSnapshots || driverCapabilities.GroupSnapshots
+		capabilities.ClusterPair = capabilities.ClusterPair || driverCapabilities.ClusterPair
+		capabilities.Migration = capabilities.Migration || driverCapabilities.Migration
+		capabilities.Resize = capabilities.Resize || driverCapabilities.Resize
+		capabilities.Maintenance = capabilities.Maintenance || driverCapabilities.Maintenance
+		for _, level := range driverCapabilities.TopologyLevels {
+			if !capabilities.HasTopologyLevel(level) {
+				capabilities.TopologyLevels = append(capabilities.TopologyLevels, level)
+			}
+		}
+	}
+	return capabilities
+}
+
+// EnterMaintenance Puts the node into maintenance mode using the driver
+// which reported the node
+func (c *CompositeDriver) EnterMaintenance(node *NodeInfo) error {
+	d := c.getDriver(node.Driver)
+	if d == nil {
+		return fmt.Errorf("Driver %v not found for node %v", node.Driver, node.ID)
+	}
+	return d.EnterMaintenance(node)
+}
+
+// ExitMaintenance Takes the node out of maintenance mode using the driver
+// which reported the node
+func (c *CompositeDriver) ExitMaintenance(node *NodeInfo) error {
+	d := c.getDriver(node.Driver)
+	if d == nil {
+		return fmt.Errorf("Driver %v not found for node %v", node.Driver, node.ID)
+	}
+	return d.ExitMaintenance(node)
+}
+
+// PrepareVolumesOnNode Prepares the volumes on the node using the driver
+// which reported the node, if it supports it
+func (c *CompositeDriver) PrepareVolumesOnNode(volumes []*Info, node *NodeInfo) error {
+	d := c.getDriver(node.Driver)
+	if d == nil {
+		return fmt.Errorf("Driver %v not found for node %v", node.Driver, node.ID)
+	}
+	preparePlugin, ok := d.(NodePreparePluginInterface)
+	if !ok {
+		return nil
+	}
+	return preparePlugin.PrepareVolumesOnNode(volumes, node)
+}
+
+// GetProvisionCandidates Returns the provision candidates from the driver
+// that owns the PVC
+func (c *CompositeDriver) GetProvisionCandidates(
+	pvc *v1.PersistentVolumeClaim,
+	storageClass *storagev1.StorageClass,
+) ([]*ProvisionCandidate, error) {
+	d := c.GetDriverForPVC(pvc)
+	if d == nil {
+		return nil, fmt.Errorf("No driver found for PVC %v", pvc.Name)
+	}
+	plugin, ok := d.(ProvisionTopologyPluginInterface)
+	if !ok {
+		return nil, &errors.ErrNotSupported{}
+	}
+	return plugin.GetProvisionCandidates(pvc, storageClass)
+}
+
+// CreateGroupSnapshot Creates the group snapshot using the driver that owns
+// the PVCs in the group
+func (c *CompositeDriver) CreateGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) (*GroupSnapshotCreateResponse, error) {
+	d, err := c.getDriverForSelector(snap.Namespace, snap.Spec.PVCSelector.MatchLabels)
+	if err != nil {
+		return nil, err
+	}
+	return d.CreateGroupSnapshot(snap)
+}
+
+// GetGroupSnapshotStatus Returns the status from the driver that owns the
+// PVCs in the group
+func (c *CompositeDriver) GetGroupSnapshotStatus(snap *stork_crd.GroupVolumeSnapshot) (*GroupSnapshotCreateResponse, error) {
+	d, err := c.getDriverForSelector(snap.Namespace, snap.Spec.PVCSelector.MatchLabels)
+	if err != nil {
+		return nil, err
+	}
+	return d.GetGroupSnapshotStatus(snap)
+}
+
+// DeleteGroupSnapshot Deletes the group snapshot using the driver that owns
+// the PVCs in the group
+func (c *CompositeDriver) DeleteGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) error {
+	d, err := c.getDriverForSelector(snap.Namespace, snap.Spec.PVCSelector.MatchLabels)
+	if err != nil {
+		return err
+	}
+	return d.DeleteGroupSnapshot(snap)
+}
+
+// CreatePair Creates the pair using the first driver that supports pairing
+func (c *CompositeDriver) CreatePair(pair *stork_crd.ClusterPair) (string, error) {
+	for _, d := range c.drivers {
+		remoteID, err := d.CreatePair(pair)
+		if _, ok := err.(*errors.ErrNotSupported); ok {
+			continue
+		}
+		return remoteID, err
+	}
+	return "", &errors.ErrNotSupported{}
+}
+
+// DeletePair Deletes the pair using the first driver that supports pairing
+func (c *CompositeDriver) DeletePair(pair *stork_crd.ClusterPair) error {
+	for _, d := range c.drivers {
+		err := d.DeletePair(pair)
+		if _, ok := err.(*errors.ErrNotSupported); ok {
+			continue
+		}
+		return err
+	}
+	return &errors.ErrNotSupported{}
+}
+
+// StartMigration Starts the migration with all the drivers that support it.
+// Each driver only migrates the PVCs it owns
+func (c *CompositeDriver) StartMigration(migration *stork_crd.Migration) ([]*stork_crd.VolumeInfo, error) {
+	volumeInfos := make([]*stork_crd.VolumeInfo, 0)
+	supported := false
+	for _, d := range c.drivers {
+		driverVolumeInfos, err := d.StartMigration(migration)
+		if err != nil {
+			if _, ok := err.(*errors.ErrNotSupported); ok {
+				continue
+			}
+			return nil, err
+		}
+		supported = true
+		volumeInfos = append(volumeInfos, driverVolumeInfos...)
+	}
+	if !supported {
+		return nil, &errors.ErrNotSupported{}
+	}
+	return volumeInfos, nil
+}
+
+// GetMigrationStatus Returns the status of the volumes from the drivers
+// which own them
+func (c *CompositeDriver) GetMigrationStatus(migration *stork_crd.Migration) ([]*stork_crd.VolumeInfo, error) {
+	migrations, unowned := c.splitMigration(migration)
+	volumeInfos := make([]*stork_crd.VolumeInfo, 0)
+	for _, d := range c.drivers {
+		driverMigration, ok := migrations[d.String()]
+		if !ok {
+			continue
+		}
+		driverVolumeInfos, err := d.GetMigrationStatus(driverMigration)
+		if err != nil {
+			return nil, err
+		}
+		volumeInfos = append(volumeInfos, driverVolumeInfos...)
+	}
+	for _, volumeInfo := range unowned {
+		volumeInfo.Status = stork_crd.MigrationStatusFailed
+		volumeInfo.Reason = "Unable to find driver for volume"
+		volumeInfos = append(volumeInfos, volumeInfo)
+	}
+	return volumeInfos, nil
+}
+
+// CancelMigration Cancels the migration of the volumes with the drivers
+// which own them
+func (c *CompositeDriver) CancelMigration(migration *stork_crd.Migration) error {
+	migrations, _ := c.splitMigration(migration)
+	var lastErr error
+	for _, d := range c.drivers {
+		driverMigration, ok := migrations[d.String()]
+		if !ok {
+			continue
+		}
+		if err := d.CancelMigration(driverMigration); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// splitMigration Returns a copy of the migration for each driver, keyed by
+// the driver name, with only the volumes owned by that driver in the
+// status. Volumes for which the driver can't be found are also returned
+func (c *CompositeDriver) splitMigration(
+	migration *stork_crd.Migration,
+) (map[string]*stork_crd.Migration, []*stork_crd.VolumeInfo) {
+	migrations := make(map[string]*stork_crd.Migration)
+	var unowned []*stork_crd.VolumeInfo
+	for _, volumeInfo := range migration.Status.Volumes {
+		var d Driver
+		pvc, err := k8s.Instance().GetPersistentVolumeClaim(volumeInfo.PersistentVolumeClaim, volumeInfo.Namespace)
+		if err != nil {
+			logrus.Warnf("Error getting pvc %v/%v for migration %v: %v",
+				volumeInfo.Namespace, volumeInfo.PersistentVolumeClaim, migration.Name, err)
+		} else {
+			d = c.GetDriverForPVC(pvc)
+		}
+		if d == nil {
+			unowned = append(unowned, volumeInfo)
+			continue
+		}
+		driverMigration, ok := migrations[d.String()]
+		if !ok {
+			driverMigration = migration.DeepCopy()
+			driverMigration.Status.Volumes = nil
+			migrations[d.String()] = driverMigration
+		}
+		driverMigration.Status.Volumes = append(driverMigration.Status.Volumes, volumeInfo)
+	}
+	return migrations, unowned
+}
+
+// UpdateMigratedPersistentVolumeSpec Updates the PV spec using the driver
+// that owns the PVC bound to the PV
+func (c *CompositeDriver) UpdateMigratedPersistentVolumeSpec(
+	object runtime.Unstructured,
+) (runtime.Unstructured, error) {
+	var pv v1.PersistentVolume
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(), &pv); err != nil {
+		return nil, err
+	}
+	d := c.GetDriverForPV(&pv)
+	if d == nil {
+		return nil, fmt.Errorf("No driver found for PV %v", pv.Name)
+	}
+	return d.UpdateMigratedPersistentVolumeSpec(object)
+}
+
+// GetSnapshotPlugins Returns the snapshot plugins for the driver keyed by
+// the driver name. Returns the plugins for all the drivers of a
+// CompositeDriver. Drivers without a snapshot plugin are skipped
+func GetSnapshotPlugins(d Driver) map[string]snapshotVolume.Plugin {
+	plugins := make(map[string]snapshotVolume.Plugin)
+	drivers := []Driver{d}
+	if composite, ok := d.(*CompositeDriver); ok {
+		drivers = composite.GetDrivers()
+	}
+	for _, driver := range drivers {
+		if plugin := driver.GetSnapshotPlugin(); plugin != nil {
+			plugins[driver.String()] = plugin
+		}
+	}
+	return plugins
+}
@@ -0,0 +1,328 @@
+// +build unittest
+
+package volume
+
+import (
+	"fmt"
+	"testing"
+
+	stork_crd "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const compositeTestNamespace = "compositetest"
+
+// compositeTestDriver Driver that owns the PVCs using a storage class with
+// the same name as the driver. Calls to operations other than the ones
+// implemented panic since the embedded interface is nil
+type compositeTestDriver struct {
+	Driver
+	name           string
+	nodes          []*NodeInfo
+	nodesErr       error
+	volumes        []*Info
+	pairID         string
+	pairErr        error
+	groupSnapshots []string
+	migrations     []*stork_crd.Migration
+	inspected      []string
+}
+
+func (d *compositeTestDriver) String() string {
+	return d.name
+}
+
+func (d *compositeTestDriver) OwnsPVC(pvc *v1.PersistentVolumeClaim) bool {
+	return pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName == d.name
+}
+
+func (d *compositeTestDriver) GetNodes() ([]*NodeInfo, error) {
+	return d.nodes, d.nodesErr
+}
+
+func (d *compositeTestDriver) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*Info, error) {
+	return d.volumes, nil
+}
+
+func (d *compositeTestDriver) CreatePair(pair *stork_crd.ClusterPair) (string, error) {
+	return d.pairID, d.pairErr
+}
+
+func (d *compositeTestDriver) CreateGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) (*GroupSnapshotCreateResponse, error) {
+	d.groupSnapshots = append(d.groupSnapshots, snap.Name)
+	return &GroupSnapshotCreateResponse{}, nil
+}
+
+func (d *compositeTestDriver) GetMigrationStatus(migration *stork_crd.Migration) ([]*stork_crd.VolumeInfo, error) {
+	d.migrations = append(d.migrations, migration)
+	for _, volumeInfo := range migration.Status.Volumes {
+		volumeInfo.Status = stork_crd.MigrationStatusSuccessful
+	}
+	return migration.Status.Volumes, nil
+}
+
+func (d *compositeTestDriver) InspectVolume(volumeID string) (*Info, error) {
+	d.inspected = append(d.inspected, volumeID)
+	for _, info := range d.volumes {
+		if info.VolumeID == volumeID {
+			return info, nil
+		}
+	}
+	return nil, &errors.ErrNotFound{ID: volumeID, Type: "Volume"}
+}
+
+// bulkInspectTestDriver Test driver that also inspects volumes in bulk
+type bulkInspectTestDriver struct {
+	*compositeTestDriver
+	bulkInspected [][]string
+}
+
+func (d *bulkInspectTestDriver) InspectVolumes(volumeIDs []string) (map[string]*Info, error) {
+	d.bulkInspected = append(d.bulkInspected, volumeIDs)
+	volumes := make(map[string]*Info)
+	for _, info := range d.volumes {
+		for _, volumeID := range volumeIDs {
+			if info.VolumeID == volumeID {
+				volumes[volumeID] = info
+			}
+		}
+	}
+	return volumes, nil
+}
+
+func newCompositeTestPVC(name, driverName string, labels map[string]string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: compositeTestNamespace,
+			Labels:    labels,
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			StorageClassName: &driverName,
+		},
+	}
+}
+
+func newCompositeTestDrivers() (*compositeTestDriver, *compositeTestDriver, *CompositeDriver) {
+	driver1 := &compositeTestDriver{name: "driver1"}
+	driver2 := &compositeTestDriver{name: "driver2"}
+	return driver1, driver2, NewCompositeDriver([]Driver{driver1, driver2})
+}
+
+func createCompositeTestPVCs(t *testing.T, pvcs ...*v1.PersistentVolumeClaim) {
+	fakeKubeClient := fake.NewSimpleClientset()
+	k8s.Instance().SetClient(fakeKubeClient, nil, nil, nil, nil)
+	for _, pvc := range pvcs {
+		_, err := fakeKubeClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(pvc)
+		require.NoError(t, err, "Error creating PVC")
+	}
+}
+
+func TestComposite(t *testing.T) {
+	t.Run("pvcDispatchTest", pvcDispatchTest)
+	t.Run("getNodesTest", getNodesTest)
+	t.Run("splitMigrationTest", splitMigrationTest)
+	t.Run("createPairTest", createPairTest)
+	t.Run("groupSnapshotTest", groupSnapshotTest)
+	t.Run("inspectVolumesTest", inspectVolumesTest)
+}
+
+func pvcDispatchTest(t *testing.T) {
+	driver1, driver2, composite := newCompositeTestDrivers()
+	driver1.volumes = []*Info{{VolumeName: "vol1"}}
+	driver2.volumes = []*Info{{VolumeName: "vol2"}}
+
+	pvc1 := newCompositeTestPVC("pvc1", "driver1", nil)
+	pvc2 := newCompositeTestPVC("pvc2", "driver2", nil)
+	otherPVC := newCompositeTestPVC("other", "other", nil)
+	require.Equal(t, driver1, composite.GetDriverForPVC(pvc1), "Unexpected driver for PVC")
+	require.Equal(t, driver2, composite.GetDriverForPVC(pvc2), "Unexpected driver for PVC")
+	require.Nil(t, composite.GetDriverForPVC(otherPVC), "Expected no driver for PVC")
+	require.True(t, composite.OwnsPVC(pvc2), "Expected PVC to be owned")
+	require.False(t, composite.OwnsPVC(otherPVC), "Expected PVC not to be owned")
+
+	templates, err := composite.GetVolumeClaimTemplates([]v1.PersistentVolumeClaim{*pvc1, *otherPVC, *pvc2})
+	require.NoError(t, err, "Error getting volume claim templates")
+	require.Equal(t, []v1.PersistentVolumeClaim{*pvc1, *pvc2}, templates, "Unexpected volume claim templates")
+
+	// The volumes from all the drivers are returned with the name of the
+	// driver, without changing the volumes returned by the drivers
+	volumes, err := composite.GetPodVolumes(&v1.PodSpec{}, compositeTestNamespace)
+	require.NoError(t, err, "Error getting pod volumes")
+	require.Equal(t, []*Info{
+		{VolumeName: "vol1", Driver: "driver1"},
+		{VolumeName: "vol2", Driver: "driver2"},
+	}, volumes, "Unexpected pod volumes")
+	require.Empty(t, driver1.volumes[0].Driver, "Driver volume was modified")
+}
+
+func getNodesTest(t *testing.T) {
+	driver1, driver2, composite := newCompositeTestDrivers()
+	driver1.nodes = []*NodeInfo{{ID: "node1", Status: NodeOnline}}
+	driver2.nodes = []*NodeInfo{{ID: "node1", Status: NodeOffline}, {ID: "node2", Status: NodeOnline}}
+
+	// Nodes running both drivers are returned once for each driver
+	nodes, err := composite.GetNodes()
+	require.NoError(t, err, "Error getting nodes")
+	require.Equal(t, []*NodeInfo{
+		{ID: "node1", Status: NodeOnline, Driver: "driver1"},
+		{ID: "node1", Status: NodeOffline, Driver: "driver2"},
+		{ID: "node2", Status: NodeOnline, Driver: "driver2"},
+	}, nodes, "Unexpected nodes")
+	require.Empty(t, driver1.nodes[0].Driver, "Driver node was modified")
+
+	driver2.nodesErr = fmt.Errorf("Node error")
+	_, err = composite.GetNodes()
+	require.Error(t, err, "Expected error getting nodes")
+	require.Contains(t, err.Error(), "driver2", "Expected driver name in error")
+}
+
+func splitMigrationTest(t *testing.T) {
+	driver1, driver2, composite := newCompositeTestDrivers()
+	createCompositeTestPVCs(t,
+		newCompositeTestPVC("pvc1", "driver1", nil),
+		newCompositeTestPVC("pvc2", "driver2", nil),
+		newCompositeTestPVC("pvc3", "driver1", nil),
+		newCompositeTestPVC("other", "other", nil),
+	)
+	volumeInfos := make(map[string]*stork_crd.VolumeInfo)
+	migration := &stork_crd.Migration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: compositeTestNamespace},
+	}
+	for _, name := range []string{"pvc1", "pvc2", "pvc3", "other", "missing"} {
+		volumeInfo := &stork_crd.VolumeInfo{
+			PersistentVolumeClaim: name,
+			Namespace:             compositeTestNamespace,
+			Volume:                "vol-" + name,
+			Status:                stork_crd.MigrationStatusInProgress,
+		}
+		volumeInfos[name] = volumeInfo
+		migration.Status.Volumes = append(migration.Status.Volumes, volumeInfo)
+	}
+
+	migrations, unowned := composite.splitMigration(migration)
+	require.Len(t, migrations, 2, "Unexpected number of migrations")
+	require.Equal(t, []*stork_crd.VolumeInfo{volumeInfos["pvc1"], volumeInfos["pvc3"]},
+		migrations["driver1"].Status.Volumes, "Unexpected volumes for driver1")
+	require.Equal(t, []*stork_crd.VolumeInfo{volumeInfos["pvc2"]},
+		migrations["driver2"].Status.Volumes, "Unexpected volumes for driver2")
+	require.Equal(t, "migration", migrations["driver1"].Name, "Unexpected migration name")
+	require.Equal(t, []*stork_crd.VolumeInfo{volumeInfos["other"], volumeInfos["missing"]},
+		unowned, "Unexpected unowned volumes")
+	require.Len(t, migration.Status.Volumes, 5, "Original migration was modified")
+
+	// Each driver only gets the status of its own volumes, and volumes
+	// without a driver are failed
+	statuses, err := composite.GetMigrationStatus(migration)
+	require.NoError(t, err, "Error getting migration status")
+	require.Len(t, statuses, 5, "Unexpected number of volume statuses")
+	require.Len(t, driver1.migrations, 1, "Expected status from driver1")
+	require.Len(t, driver1.migrations[0].Status.Volumes, 2, "Unexpected volumes passed to driver1")
+	require.Len(t, driver2.migrations, 1, "Expected status from driver2")
+	require.Len(t, driver2.migrations[0].Status.Volumes, 1, "Unexpected volumes passed to driver2")
+	for _, name := range []string{"pvc1", "pvc2", "pvc3"} {
+		require.Equal(t, stork_crd.MigrationStatusSuccessful, volumeInfos[name].Status,
+			"Unexpected status for %v", name)
+	}
+	for _, name := range []string{"other", "missing"} {
+		require.Equal(t, stork_crd.MigrationStatusFailed, volumeInfos[name].Status,
+			"Unexpected status for %v", name)
+		require.Equal(t, "Unable to find driver for volume", volumeInfos[name].Reason,
+			"Unexpected reason for %v", name)
+	}
+}
+
+func createPairTest(t *testing.T) {
+	driver1, driver2, composite := newCompositeTestDrivers()
+	pair := &stork_crd.ClusterPair{}
+
+	// Drivers that don't support pairing are skipped
+	driver1.pairErr = &errors.ErrNotSupported{}
+	driver2.pairID = "remote-id"
+	remoteID, err := composite.CreatePair(pair)
+	require.NoError(t, err, "Error creating pair")
+	require.Equal(t, "remote-id", remoteID, "Unexpected remote ID")
+
+	// Other errors are returned
+	driver2.pairErr = fmt.Errorf("Pair error")
+	_, err = composite.CreatePair(pair)
+	require.Error(t, err, "Expected error creating pair")
+	require.Equal(t, "Pair error", err.Error(), "Unexpected error")
+
+	driver2.pairErr = &errors.ErrNotSupported{}
+	_, err = composite.CreatePair(pair)
+	require.Error(t, err, "Expected error creating pair without driver support")
+	_, ok := err.(*errors.ErrNotSupported)
+	require.True(t, ok, "Expected ErrNotSupported, got %v", err)
+}
+
+func groupSnapshotTest(t *testing.T) {
+	driver1, driver2, composite := newCompositeTestDrivers()
+	createCompositeTestPVCs(t,
+		newCompositeTestPVC("pvc1", "driver1", map[string]string{"app": "single"}),
+		newCompositeTestPVC("pvc2", "driver1", map[string]string{"app": "single"}),
+		newCompositeTestPVC("pvc3", "driver1", map[string]string{"app": "mixed"}),
+		newCompositeTestPVC("pvc4", "driver2", map[string]string{"app": "mixed"}),
+		newCompositeTestPVC("other", "other", map[string]string{"app": "other"}),
+	)
+	newGroupSnapshot := func(name, app string) *stork_crd.GroupVolumeSnapshot {
+		return &stork_crd.GroupVolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: compositeTestNamespace},
+			Spec: stork_crd.GroupVolumeSnapshotSpec{
+				PVCSelector: stork_crd.PVCSelectorSpec{
+					LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+				},
+			},
+		}
+	}
+
+	// Groups with PVCs from one driver are sent to that driver
+	_, err := composite.CreateGroupSnapshot(newGroupSnapshot("single", "single"))
+	require.NoError(t, err, "Error creating group snapshot")
+	require.Equal(t, []string{"single"}, driver1.groupSnapshots, "Expected group snapshot from driver1")
+	require.Empty(t, driver2.groupSnapshots, "Expected no group snapshot from driver2")
+
+	// Groups spanning drivers can't be snapshotted together
+	_, err = composite.CreateGroupSnapshot(newGroupSnapshot("mixed", "mixed"))
+	require.Error(t, err, "Expected error creating group snapshot across drivers")
+	_, ok := err.(*errors.ErrNotSupported)
+	require.True(t, ok, "Expected ErrNotSupported, got %v", err)
+	require.Equal(t, []string{"single"}, driver1.groupSnapshots, "Unexpected group snapshot from driver1")
+	require.Empty(t, driver2.groupSnapshots, "Expected no group snapshot from driver2")
+
+	_, err = composite.CreateGroupSnapshot(newGroupSnapshot("other", "other"))
+	require.Error(t, err, "Expected error creating group snapshot without owned PVCs")
+}
+
+func inspectVolumesTest(t *testing.T) {
+	driver1 := &compositeTestDriver{name: "driver1"}
+	driver2 := &bulkInspectTestDriver{compositeTestDriver: &compositeTestDriver{name: "driver2"}}
+	composite := NewCompositeDriver([]Driver{driver1, driver2})
+	driver1.volumes = []*Info{{VolumeID: "vol1"}}
+	driver2.volumes = []*Info{{VolumeID: "vol2"}}
+
+	// Drivers without bulk inspect should be called for each volume, and
+	// only the volumes they didn't find should be passed to the next driver
+	volumes, err := composite.InspectVolumes([]string{"vol1", "vol2", "missing"})
+	require.NoError(t, err, "Error inspecting volumes")
+	require.Equal(t, map[string]*Info{
+		"vol1": {VolumeID: "vol1", Driver: "driver1"},
+		"vol2": {VolumeID: "vol2", Driver: "driver2"},
+	}, volumes, "Unexpected volumes")
+	require.Equal(t, []string{"vol1", "vol2", "missing"}, driver1.inspected, "Unexpected volumes inspected")
+	require.Equal(t, [][]string{{"vol2", "missing"}}, driver2.bulkInspected, "Unexpected bulk inspects")
+	require.Empty(t, driver2.inspected, "Expected volumes to be inspected in bulk")
+	require.Empty(t, driver2.volumes[0].Driver, "Driver volume was modified")
+
+	// Drivers shouldn't be called once all the volumes have been found
+	driver2.bulkInspected = nil
+	volumes, err = composite.InspectVolumes([]string{"vol1"})
+	require.NoError(t, err, "Error inspecting volumes")
+	require.Len(t, volumes, 1, "Unexpected volumes")
+	require.Empty(t, driver2.bulkInspected, "Unexpected bulk inspects")
+}
@@ -0,0 +1,89 @@
+package volume
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// DriverConfigKey Key in the driver config ConfigMap with the config file
+const DriverConfigKey = "config.yaml"
+
+// Config Typed configuration for a volume driver
+type Config interface {
+	// Validate Returns an error if the configuration is invalid
+	Validate() error
+}
+
+// Configurable Implemented by drivers that take a typed configuration in
+// Init
+type Configurable interface {
+	// NewConfig Returns the default configuration for the driver. The
+	// section for the driver in the config file is decoded into it
+	NewConfig() Config
+}
+
+// Configs Configuration for each driver keyed by the driver name. When
+// passed to Init of the CompositeDriver each driver gets its own config
+type Configs map[string]interface{}
+
+// configFile Format of the driver config file
+type configFile struct {
+	Drivers map[string]json.RawMessage `json:"drivers"`
+}
+
+// ParseConfigs Parses the driver config file, in YAML or JSON, for the
+// given drivers. The sections for drivers that take a typed configuration
+// are decoded into it and validated, others are passed on as they are
+func ParseConfigs(data []byte, driverNames []string) (Configs, error) {
+	file := &configFile{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("Error parsing driver config: %v", err)
+	}
+
+	configs := make(Configs)
+	for _, name := range driverNames {
+		d, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+		raw, found := file.Drivers[name]
+		if c, ok := d.(Configurable); ok {
+			config := c.NewConfig()
+			if found {
+				decoder := json.NewDecoder(bytes.NewReader(raw))
+				decoder.DisallowUnknownFields()
+				if err := decoder.Decode(config); err != nil {
+					return nil, fmt.Errorf("Error parsing config for driver %v: %v", name, err)
+				}
+			}
+			if err := config.Validate(); err != nil {
+				return nil, fmt.Errorf("Invalid config for driver %v: %v", name, err)
+			}
+			configs[name] = config
+		} else if found {
+			var config interface{}
+			if err := json.Unmarshal(raw, &config); err != nil {
+				return nil, fmt.Errorf("Error parsing config for driver %v: %v", name, err)
+			}
+			configs[name] = config
+		}
+	}
+	for name := range file.Drivers {
+		if _, ok := configs[name]; !ok {
+			return nil, fmt.Errorf("Driver config has a section for %v, which isn't one of the drivers in use", name)
+		}
+	}
+	return configs, nil
+}
+
+// ForDriver Returns the config to pass to Init of the driver. A composite
+// driver gets all the configs
+func (c Configs) ForDriver(d Driver) interface{} {
+	if _, ok := d.(*CompositeDriver); ok {
+		return c
+	}
+	return c[d.String()]
+}
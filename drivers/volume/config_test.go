@@ -0,0 +1,189 @@
+// +build unittest
+
+package volume
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	configurableDriverName = "configurabletestdriver"
+	plainDriverName        = "plaintestdriver"
+)
+
+// testConfig Typed configuration for configurableTestDriver
+type testConfig struct {
+	Value int `json:"value"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.Value < 0 {
+		return fmt.Errorf("Value should not be negative")
+	}
+	return nil
+}
+
+// configurableTestDriver Driver that takes a typed configuration. Calls to
+// operations other than the ones implemented panic since the embedded
+// interface is nil
+type configurableTestDriver struct {
+	Driver
+	initConfig interface{}
+}
+
+func (d *configurableTestDriver) String() string {
+	return configurableDriverName
+}
+
+func (d *configurableTestDriver) NewConfig() Config {
+	return &testConfig{Value: 1}
+}
+
+func (d *configurableTestDriver) Init(config interface{}) error {
+	d.initConfig = config
+	return nil
+}
+
+// plainTestDriver Driver that takes its configuration as it is in the file
+type plainTestDriver struct {
+	Driver
+	initConfig interface{}
+}
+
+func (d *plainTestDriver) String() string {
+	return plainDriverName
+}
+
+func (d *plainTestDriver) Init(config interface{}) error {
+	d.initConfig = config
+	return nil
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("parseConfigsTest", parseConfigsTest)
+	t.Run("forDriverTest", forDriverTest)
+}
+
+func parseConfigsTest(t *testing.T) {
+	require.NoError(t, Register(configurableDriverName, &configurableTestDriver{}), "Error registering driver")
+	require.NoError(t, Register(plainDriverName, &plainTestDriver{}), "Error registering driver")
+
+	testCases := []struct {
+		name          string
+		data          string
+		driverNames   []string
+		expected      Configs
+		expectedError string
+	}{
+		{
+			name:        "default config without section",
+			data:        "drivers: {}",
+			driverNames: []string{configurableDriverName, plainDriverName},
+			expected: Configs{
+				configurableDriverName: &testConfig{Value: 1},
+			},
+		},
+		{
+			name: "yaml",
+			data: "drivers:\n" +
+				"  " + configurableDriverName + ":\n" +
+				"    value: 2\n" +
+				"  " + plainDriverName + ":\n" +
+				"    key: value\n",
+			driverNames: []string{configurableDriverName, plainDriverName},
+			expected: Configs{
+				configurableDriverName: &testConfig{Value: 2},
+				plainDriverName:        map[string]interface{}{"key": "value"},
+			},
+		},
+		{
+			name:        "json",
+			data:        `{"drivers": {"` + configurableDriverName + `": {"value": 3}}}`,
+			driverNames: []string{configurableDriverName},
+			expected: Configs{
+				configurableDriverName: &testConfig{Value: 3},
+			},
+		},
+		{
+			name:          "invalid file",
+			data:          "drivers: [",
+			driverNames:   []string{configurableDriverName},
+			expectedError: "Error parsing driver config",
+		},
+		{
+			name:          "unknown driver",
+			data:          "drivers: {}",
+			driverNames:   []string{"unknowndriver"},
+			expectedError: "unknowndriver",
+		},
+		{
+			name: "unknown field",
+			data: "drivers:\n" +
+				"  " + configurableDriverName + ":\n" +
+				"    unknown: 2\n",
+			driverNames:   []string{configurableDriverName},
+			expectedError: "Error parsing config for driver " + configurableDriverName,
+		},
+		{
+			name: "invalid field type",
+			data: "drivers:\n" +
+				"  " + configurableDriverName + ":\n" +
+				"    value: abc\n",
+			driverNames:   []string{configurableDriverName},
+			expectedError: "Error parsing config for driver " + configurableDriverName,
+		},
+		{
+			name: "invalid config",
+			data: "drivers:\n" +
+				"  " + configurableDriverName + ":\n" +
+				"    value: -1\n",
+			driverNames:   []string{configurableDriverName},
+			expectedError: "Invalid config for driver " + configurableDriverName,
+		},
+		{
+			name: "section for driver not in use",
+			data: "drivers:\n" +
+				"  " + plainDriverName + ":\n" +
+				"    key: value\n",
+			driverNames:   []string{configurableDriverName},
+			expectedError: "Driver config has a section for " + plainDriverName,
+		},
+	}
+	for _, tc := range testCases {
+		configs, err := ParseConfigs([]byte(tc.data), tc.driverNames)
+		if tc.expectedError != "" {
+			require.Error(t, err, "%v: Expected error parsing configs", tc.name)
+			require.Contains(t, err.Error(), tc.expectedError, "%v: Unexpected error", tc.name)
+			continue
+		}
+		require.NoError(t, err, "%v: Error parsing configs", tc.name)
+		require.Equal(t, tc.expected, configs, "%v: Unexpected configs", tc.name)
+	}
+}
+
+func forDriverTest(t *testing.T) {
+	configurableDriver := &configurableTestDriver{}
+	plainDriver := &plainTestDriver{}
+	configs := Configs{
+		configurableDriverName: &testConfig{Value: 2},
+		plainDriverName:        map[string]interface{}{"key": "value"},
+	}
+
+	// A single driver only gets its own config
+	require.Equal(t, configs[configurableDriverName], configs.ForDriver(configurableDriver),
+		"Unexpected config for single driver")
+	require.Nil(t, Configs{}.ForDriver(plainDriver), "Expected no config for driver without a section")
+
+	// A composite driver gets all the configs and passes each driver its own
+	compositeDriver := NewCompositeDriver([]Driver{configurableDriver, plainDriver})
+	compositeConfig := configs.ForDriver(compositeDriver)
+	require.Equal(t, configs, compositeConfig, "Unexpected config for composite driver")
+	require.NoError(t, compositeDriver.Init(compositeConfig), "Error initializing composite driver")
+	require.Equal(t, configs[configurableDriverName], configurableDriver.initConfig,
+		"Unexpected config passed to configurable driver")
+	require.Equal(t, configs[plainDriverName], plainDriver.initConfig,
+		"Unexpected config passed to plain driver")
+}
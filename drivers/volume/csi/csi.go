@@ -0,0 +1,477 @@
+package csi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	snapshotVolume "github.com/kubernetes-incubator/external-storage/snapshot/pkg/volume"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	k8shelper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+const (
+	// driverName is the name of the csi driver implementation
+	driverName = "csi"
+
+	// csiDriverNames is the environment variable with the comma separated
+	// names of the CSI drivers whose volumes should be managed
+	csiDriverNames = "CSI_DRIVER_NAMES"
+
+	// pvcProvisionerAnnotation is the annotation on PVC which has the provisioner name
+	pvcProvisionerAnnotation = "volume.beta.kubernetes.io/storage-provisioner"
+	// pvProvisionedByAnnotation is the annotation on PV which has the provisioner name
+	pvProvisionedByAnnotation = "pv.kubernetes.io/provisioned-by"
+)
+
+// csiNodeResources Versions of the CSINode API to look up the drivers
+// registered on nodes, in order of preference. The vendored client doesn't
+// have the CSINode types, so the objects are read with the dynamic client
+var csiNodeResources = []schema.GroupVersionResource{
+	{Group: "storage.k8s.io", Version: "v1", Resource: "csinodes"},
+	{Group: "storage.k8s.io", Version: "v1beta1", Resource: "csinodes"},
+}
+
+// csiNode The fields used from CSINode objects
+type csiNode struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		Drivers []csiNodeDriver `json:"drivers"`
+	} `json:"spec"`
+}
+
+// csiNodeDriver A CSI driver registered on a node
+type csiNodeDriver struct {
+	Name         string   `json:"name"`
+	NodeID       string   `json:"nodeID"`
+	TopologyKeys []string `json:"topologyKeys"`
+}
+
+// csi Driver for volumes provisioned by CSI drivers. Only uses objects from
+// the Kubernetes API so it doesn't know where the data for volumes is
+// located, but reports the topology of the volumes and nodes so that pods
+// can be scheduled on nodes from which their volumes are accessible.
+// Snapshots aren't supported: the stork snapshot controller is built on the
+// external-storage snapshot API, which picks the plugin from the volume
+// source of the PV and rejects CSI PVs before any plugin is called, and its
+// snapshot data can't refer to a CSI snapshot. Snapshots of CSI volumes need
+// to be taken with the snapshot.storage.k8s.io VolumeSnapshot API instead,
+// which is handled by the CSI external-snapshotter
+type csi struct {
+	storkvolume.ClusterPairNotSupported
+	storkvolume.MigrationNotSupported
+	storkvolume.GroupSnapshotNotSupported
+	driverNames      []string
+	dynamicInterface dynamic.Interface
+}
+
+func (c *csi) String() string {
+	return driverName
+}
+
+// Config Configuration for the csi driver
+type Config struct {
+	// DriverNames Names of the CSI drivers whose volumes should be
+	// managed. Defaults to the CSI_DRIVER_NAMES environment variable
+	DriverNames []string `json:"driverNames"`
+}
+
+// Validate Returns an error if any of the driver names is empty
+func (c *Config) Validate() error {
+	for _, name := range c.DriverNames {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("CSI driver names can't be empty")
+		}
+	}
+	return nil
+}
+
+// NewConfig Returns the default configuration for the driver
+func (c *csi) NewConfig() storkvolume.Config {
+	return &Config{}
+}
+
+// Init Initializes the driver with the names of the CSI drivers to manage.
+// The names can be passed in as a string slice or a *Config, otherwise they
+// are read from the CSI_DRIVER_NAMES environment variable
+func (c *csi) Init(config interface{}) error {
+	if err := c.initDriverNames(config); err != nil {
+		return err
+	}
+	if c.dynamicInterface != nil {
+		return nil
+	}
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return fmt.Errorf("Error getting cluster config: %v", err)
+	}
+	c.dynamicInterface, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("Error getting dynamic client: %v", err)
+	}
+	return nil
+}
+
+func (c *csi) initDriverNames(config interface{}) error {
+	if names, ok := config.([]string); ok && len(names) > 0 {
+		c.driverNames = names
+		return nil
+	}
+	if driverConfig, ok := config.(*Config); ok && len(driverConfig.DriverNames) > 0 {
+		c.driverNames = driverConfig.DriverNames
+		return nil
+	}
+
+	c.driverNames = nil
+	for _, name := range strings.Split(os.Getenv(csiDriverNames), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			c.driverNames = append(c.driverNames, name)
+		}
+	}
+	if len(c.driverNames) == 0 {
+		return fmt.Errorf("No CSI driver names configured, set %v", csiDriverNames)
+	}
+	return nil
+}
+
+func (c *csi) Stop() error {
+	return nil
+}
+
+func (c *csi) isDriverName(name string) bool {
+	for _, driverName := range c.driverNames {
+		if name == driverName {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *csi) InspectVolume(volumeID string) (*storkvolume.Info, error) {
+	pvs, err := k8s.Instance().GetPersistentVolumes()
+	if err != nil {
+		return nil, err
+	}
+	for _, pv := range pvs.Items {
+		if !storkvolume.IsCSIVolume(&pv, c.driverNames...) {
+			continue
+		}
+		if pv.Spec.CSI.VolumeHandle == volumeID || pv.Name == volumeID {
+			return c.getVolumeInfo(&pv), nil
+		}
+	}
+	return nil, &errors.ErrNotFound{
+		ID:   volumeID,
+		Type: "Volume",
+	}
+}
+
+// InspectVolumes Returns the info for the CSI PVs whose name or volume handle
+// is in the requested volume IDs, listing the PVs only once
+func (c *csi) InspectVolumes(volumeIDs []string) (map[string]*storkvolume.Info, error) {
+	requested := make(map[string]bool)
+	for _, volumeID := range volumeIDs {
+		requested[volumeID] = true
+	}
+	pvs, err := k8s.Instance().GetPersistentVolumes()
+	if err != nil {
+		return nil, err
+	}
+	volumes := make(map[string]*storkvolume.Info)
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if !storkvolume.IsCSIVolume(pv, c.driverNames...) {
+			continue
+		}
+		for _, volumeID := range []string{pv.Spec.CSI.VolumeHandle, pv.Name} {
+			if requested[volumeID] {
+				volumes[volumeID] = c.getVolumeInfo(pv)
+			}
+		}
+	}
+	return volumes, nil
+}
+
+// ResizeVolume Returns ErrNotSupported since CSI volumes are expanded by the
+// CSI external-resizer when the PVC is updated
+func (c *csi) ResizeVolume(volumeID string, sizeBytes uint64) (*storkvolume.Info, error) {
+	return nil, &errors.ErrNotSupported{}
+}
+
+// EnterMaintenance Returns ErrNotSupported since CSI doesn't have a
+// maintenance mode for nodes
+func (c *csi) EnterMaintenance(node *storkvolume.NodeInfo) error {
+	return &errors.ErrNotSupported{}
+}
+
+// ExitMaintenance Returns ErrNotSupported since CSI doesn't have a
+// maintenance mode for nodes
+func (c *csi) ExitMaintenance(node *storkvolume.NodeInfo) error {
+	return &errors.ErrNotSupported{}
+}
+
+// getVolumeInfo Returns the volume info for a CSI PV. The data nodes aren't
+// known, the volume attributes are returned as the labels
+func (c *csi) getVolumeInfo(pv *v1.PersistentVolume) *storkvolume.Info {
+	info := &storkvolume.Info{
+		VolumeID:        pv.Spec.CSI.VolumeHandle,
+		VolumeName:      pv.Name,
+		Labels:          make(map[string]string),
+		NodeAffinity:    pv.Spec.NodeAffinity,
+		VolumeSourceRef: pv,
+	}
+	if capacity, ok := pv.Spec.Capacity[v1.ResourceStorage]; ok {
+		info.Size = uint64(capacity.Value()) / (1024 * 1024 * 1024)
+	}
+	for k, v := range pv.Spec.CSI.VolumeAttributes {
+		info.Labels[k] = v
+	}
+	return info
+}
+
+// GetNodes Returns the Kubernetes nodes on which one of the CSI drivers has
+// been registered. The drivers and their topology are read from the CSINode
+// objects. On clusters that don't have the CSINode API, or for nodes without
+// a CSINode object, the node ID annotation added by the kubelet is used and
+// the topology is only taken from the node labels
+func (c *csi) GetNodes() ([]*storkvolume.NodeInfo, error) {
+	k8sNodes, err := k8s.Instance().GetNodes()
+	if err != nil {
+		return nil, err
+	}
+	csiNodeDrivers, err := c.getCSINodeDrivers()
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*storkvolume.NodeInfo
+	for _, k8sNode := range k8sNodes.Items {
+		var nodeDriver *csiNodeDriver
+		if driver, ok := csiNodeDrivers[k8sNode.Name]; ok {
+			nodeDriver = driver
+		} else {
+			for name, id := range storkvolume.GetCSINodeIDs(&k8sNode) {
+				if c.isDriverName(name) {
+					nodeDriver = &csiNodeDriver{Name: name, NodeID: id}
+					break
+				}
+			}
+		}
+		if nodeDriver == nil || nodeDriver.NodeID == "" {
+			continue
+		}
+
+		node := &storkvolume.NodeInfo{
+			ID:       nodeDriver.NodeID,
+			Hostname: strings.ToLower(k8sNode.Name),
+			Status:   storkvolume.NodeOffline,
+		}
+		for _, address := range k8sNode.Status.Addresses {
+			switch address.Type {
+			case v1.NodeHostName:
+				node.Hostname = strings.ToLower(address.Address)
+			case v1.NodeInternalIP, v1.NodeExternalIP:
+				node.IPs = append(node.IPs, address.Address)
+			}
+		}
+		for _, condition := range k8sNode.Status.Conditions {
+			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+				node.Status = storkvolume.NodeOnline
+				break
+			}
+		}
+		setTopologyFromKeys(&k8sNode, nodeDriver.TopologyKeys, node)
+		storkvolume.SetTopologyFromNode(&k8sNode, node)
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// getCSINodeDrivers Returns the first of the managed CSI drivers registered
+// in each CSINode object, keyed by the node name. Returns an empty map if
+// the cluster doesn't have the CSINode API
+func (c *csi) getCSINodeDrivers() (map[string]*csiNodeDriver, error) {
+	nodeDrivers := make(map[string]*csiNodeDriver)
+	for _, resource := range csiNodeResources {
+		list, err := c.dynamicInterface.Resource(resource).List(metav1.ListOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("Error listing CSINodes: %v", err)
+		}
+		for _, item := range list.Items {
+			data, err := item.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			node := &csiNode{}
+			if err := json.Unmarshal(data, node); err != nil {
+				return nil, fmt.Errorf("Error parsing CSINode %v: %v", item.GetName(), err)
+			}
+			for i, driver := range node.Spec.Drivers {
+				if c.isDriverName(driver.Name) {
+					nodeDrivers[node.Metadata.Name] = &node.Spec.Drivers[i]
+					break
+				}
+			}
+		}
+		return nodeDrivers, nil
+	}
+	return nodeDrivers, nil
+}
+
+// setTopologyFromKeys Sets the rack, zone and region of the node from the
+// node labels for the topology keys reported by the CSI driver. The keys are
+// matched by their name without the prefix, for example
+// topology.ebs.csi.aws.com/zone is used as the zone
+func setTopologyFromKeys(k8sNode *v1.Node, topologyKeys []string, node *storkvolume.NodeInfo) {
+	for _, key := range topologyKeys {
+		value, ok := k8sNode.Labels[key]
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key[strings.LastIndex(key, "/")+1:]) {
+		case "rack":
+			node.Rack = value
+		case "zone":
+			node.Zone = value
+		case "region":
+			node.Region = value
+		}
+	}
+}
+
+func (c *csi) OwnsPVC(pvc *v1.PersistentVolumeClaim) bool {
+	provisioner := ""
+	// Check for the provisioner in the PVC annotation. If not populated
+	// try getting the provisioner from the Storage class.
+	if val, ok := pvc.Annotations[pvcProvisionerAnnotation]; ok {
+		provisioner = val
+	} else {
+		storageClassName := k8shelper.GetPersistentVolumeClaimClass(pvc)
+		if storageClassName != "" {
+			storageClass, err := k8s.Instance().GetStorageClass(storageClassName)
+			if err == nil {
+				provisioner = storageClass.Provisioner
+			} else {
+				logrus.Warnf("Error getting storageclass %v for pvc %v: %v", storageClassName, pvc.Name, err)
+			}
+		}
+	}
+
+	if provisioner == "" {
+		if pvc.Spec.VolumeName == "" {
+			return false
+		}
+		// Try to get info from the PV since storage class could be deleted
+		pv, err := k8s.Instance().GetPersistentVolume(pvc.Spec.VolumeName)
+		if err != nil {
+			logrus.Warnf("Error getting pv %v for pvc %v: %v", pvc.Spec.VolumeName, pvc.Name, err)
+			return false
+		}
+		if val, ok := pv.Annotations[pvProvisionedByAnnotation]; ok {
+			provisioner = val
+		} else {
+			return storkvolume.IsCSIVolume(pv, c.driverNames...)
+		}
+	}
+
+	return c.isDriverName(provisioner)
+}
+
+func (c *csi) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*storkvolume.Info, error) {
+	var volumes []*storkvolume.Info
+	for _, volume := range podSpec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := k8s.Instance().GetPersistentVolumeClaim(
+			volume.PersistentVolumeClaim.ClaimName,
+			namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		if !c.OwnsPVC(pvc) {
+			continue
+		}
+
+		if pvc.Status.Phase == v1.ClaimPending {
+			return nil, &storkvolume.ErrPVCPending{
+				Name: volume.PersistentVolumeClaim.ClaimName,
+			}
+		}
+
+		pv, err := k8s.Instance().GetPersistentVolume(pvc.Spec.VolumeName)
+		if err != nil {
+			logrus.Warnf("Error getting pv %v for pvc %v: %v", pvc.Spec.VolumeName, pvc.Name, err)
+			volumes = append(volumes, &storkvolume.Info{
+				VolumeName: pvc.Spec.VolumeName,
+			})
+			continue
+		}
+		if pv.Spec.CSI == nil {
+			volumes = append(volumes, &storkvolume.Info{
+				VolumeName:   pv.Name,
+				NodeAffinity: pv.Spec.NodeAffinity,
+			})
+			continue
+		}
+		volumes = append(volumes, c.getVolumeInfo(pv))
+	}
+	return volumes, nil
+}
+
+func (c *csi) GetVolumeClaimTemplates(templates []v1.PersistentVolumeClaim) (
+	[]v1.PersistentVolumeClaim, error) {
+	var csiTemplates []v1.PersistentVolumeClaim
+	for _, t := range templates {
+		if c.OwnsPVC(&t) {
+			csiTemplates = append(csiTemplates, t)
+		}
+	}
+	return csiTemplates, nil
+}
+
+// GetSnapshotPlugin Returns nil since the stork snapshot controller can't
+// take snapshots of CSI volumes
+func (c *csi) GetSnapshotPlugin() snapshotVolume.Plugin {
+	return nil
+}
+
+// GetSnapshotType Returns ErrNotSupported since stork snapshots are never
+// taken by the csi driver
+func (c *csi) GetSnapshotType(snap *snapv1.VolumeSnapshot) (string, error) {
+	return "", &errors.ErrNotSupported{}
+}
+
+// Capabilities Returns the topology levels read from the node labels. Other
+// features aren't supported by the csi driver
+func (c *csi) Capabilities() *storkvolume.Capabilities {
+	return &storkvolume.Capabilities{
+		TopologyLevels: []storkvolume.TopologyLevel{
+			storkvolume.TopologyLevelRack,
+			storkvolume.TopologyLevelZone,
+			storkvolume.TopologyLevelRegion,
+		},
+	}
+}
+
+func init() {
+	if err := storkvolume.Register(driverName, &csi{}); err != nil {
+		logrus.Panicf("Error registering csi volume driver: %v", err)
+	}
+}
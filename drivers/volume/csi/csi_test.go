@@ -0,0 +1,534 @@
+// +build unittest
+
+package csi
+
+import (
+	"os"
+	"testing"
+
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testDriverName  = "test.csi.driver"
+	otherDriverName = "other.csi.driver"
+	testNamespace   = "csitest"
+)
+
+var fakeKubeClient *fake.Clientset
+
+// fakeDynamicClient Dynamic client that only lists CSINodes. The lists are
+// keyed by the API version, and versions without a list aren't served.
+// Calls to other operations panic since the embedded interface is nil
+type fakeDynamicClient struct {
+	dynamic.Interface
+	csiNodes map[string]*unstructured.UnstructuredList
+}
+
+func (f *fakeDynamicClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &fakeResourceClient{
+		resource: gvr,
+		list:     f.csiNodes[gvr.Version],
+	}
+}
+
+type fakeResourceClient struct {
+	dynamic.NamespaceableResourceInterface
+	resource schema.GroupVersionResource
+	list     *unstructured.UnstructuredList
+}
+
+func (f *fakeResourceClient) List(opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	if f.list == nil {
+		return nil, k8serrors.NewNotFound(f.resource.GroupResource(), "")
+	}
+	return f.list, nil
+}
+
+func resetTest() {
+	fakeKubeClient = fake.NewSimpleClientset()
+	k8s.Instance().SetClient(fakeKubeClient, nil, nil, nil, nil)
+}
+
+func newDriver(t *testing.T, csiNodes map[string]*unstructured.UnstructuredList) *csi {
+	c := &csi{
+		dynamicInterface: &fakeDynamicClient{csiNodes: csiNodes},
+	}
+	require.NoError(t, c.Init([]string{testDriverName}), "Error initializing driver")
+	return c
+}
+
+func newCSINode(name string, drivers ...map[string]interface{}) unstructured.Unstructured {
+	driverList := make([]interface{}, 0, len(drivers))
+	for _, driver := range drivers {
+		driverList = append(driverList, driver)
+	}
+	return unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "storage.k8s.io/v1",
+			"kind":       "CSINode",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"drivers": driverList,
+			},
+		},
+	}
+}
+
+func newNode(name, ip string, ready bool, labels, annotations map[string]string) *v1.Node {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeHostName, Address: name},
+				{Type: v1.NodeInternalIP, Address: ip},
+			},
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: status},
+			},
+		},
+	}
+}
+
+func newPV(name, driverName, handle string, affinity *v1.VolumeNodeAffinity) *v1.PersistentVolume {
+	return &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity: v1.ResourceList{
+				v1.ResourceStorage: resource.MustParse("2Gi"),
+			},
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{
+					Driver:           driverName,
+					VolumeHandle:     handle,
+					VolumeAttributes: map[string]string{"fsType": "ext4"},
+				},
+			},
+			NodeAffinity: affinity,
+		},
+	}
+}
+
+func newPVC(name, storageClass, volumeName string, annotations map[string]string) *v1.PersistentVolumeClaim {
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   testNamespace,
+			Annotations: annotations,
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			VolumeName: volumeName,
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Phase: v1.ClaimBound,
+		},
+	}
+	if storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+	if volumeName == "" {
+		pvc.Status.Phase = v1.ClaimPending
+	}
+	return pvc
+}
+
+func newZoneAffinity(zone string) *v1.VolumeNodeAffinity {
+	return &v1.VolumeNodeAffinity{
+		Required: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchExpressions: []v1.NodeSelectorRequirement{{
+					Key:      storkvolume.TopologyZoneLabel,
+					Operator: v1.NodeSelectorOpIn,
+					Values:   []string{zone},
+				}},
+			}},
+		},
+	}
+}
+
+func TestCSI(t *testing.T) {
+	t.Run("initTest", initTest)
+	t.Run("ownsPVCTest", ownsPVCTest)
+	t.Run("volumeInfoTest", volumeInfoTest)
+	t.Run("getNodesTest", getNodesTest)
+	t.Run("getNodesWithoutCSINodeTest", getNodesWithoutCSINodeTest)
+	t.Run("podVolumesTest", podVolumesTest)
+	t.Run("pendingPVCTest", pendingPVCTest)
+}
+
+func initTest(t *testing.T) {
+	defer os.Unsetenv(csiDriverNames)
+	testCases := []struct {
+		name          string
+		config        interface{}
+		env           string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name:     "string slice",
+			config:   []string{testDriverName, otherDriverName},
+			expected: []string{testDriverName, otherDriverName},
+		},
+		{
+			name:     "config",
+			config:   &Config{DriverNames: []string{otherDriverName}},
+			expected: []string{otherDriverName},
+		},
+		{
+			name:     "env var",
+			env:      " " + testDriverName + " ,," + otherDriverName,
+			expected: []string{testDriverName, otherDriverName},
+		},
+		{
+			name:     "empty config uses env var",
+			config:   &Config{},
+			env:      testDriverName,
+			expected: []string{testDriverName},
+		},
+		{
+			name:          "no driver names",
+			config:        &Config{},
+			expectedError: true,
+		},
+	}
+	for _, tc := range testCases {
+		require.NoError(t, os.Setenv(csiDriverNames, tc.env), "Error setting env var")
+		c := &csi{dynamicInterface: &fakeDynamicClient{}}
+		err := c.Init(tc.config)
+		if tc.expectedError {
+			require.Error(t, err, "%v: Expected error initializing driver", tc.name)
+			continue
+		}
+		require.NoError(t, err, "%v: Error initializing driver", tc.name)
+		require.Equal(t, tc.expected, c.driverNames, "%v: Unexpected driver names", tc.name)
+	}
+}
+
+func ownsPVCTest(t *testing.T) {
+	resetTest()
+	c := newDriver(t, nil)
+
+	_, err := fakeKubeClient.StorageV1().StorageClasses().Create(&storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "test-sc"},
+		Provisioner: testDriverName,
+	})
+	require.NoError(t, err, "Error creating storage class")
+	_, err = fakeKubeClient.StorageV1().StorageClasses().Create(&storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "other-sc"},
+		Provisioner: otherDriverName,
+	})
+	require.NoError(t, err, "Error creating storage class")
+	_, err = fakeKubeClient.CoreV1().PersistentVolumes().Create(newPV("test-pv", testDriverName, "vol1", nil))
+	require.NoError(t, err, "Error creating PV")
+	otherPV := newPV("other-pv", testDriverName, "vol2", nil)
+	otherPV.Annotations = map[string]string{pvProvisionedByAnnotation: otherDriverName}
+	_, err = fakeKubeClient.CoreV1().PersistentVolumes().Create(otherPV)
+	require.NoError(t, err, "Error creating PV")
+
+	testCases := []struct {
+		name     string
+		pvc      *v1.PersistentVolumeClaim
+		expected bool
+	}{
+		{
+			name:     "provisioner annotation",
+			pvc:      newPVC("pvc", "", "", map[string]string{pvcProvisionerAnnotation: testDriverName}),
+			expected: true,
+		},
+		{
+			name:     "other provisioner annotation",
+			pvc:      newPVC("pvc", "test-sc", "", map[string]string{pvcProvisionerAnnotation: otherDriverName}),
+			expected: false,
+		},
+		{
+			name:     "storage class",
+			pvc:      newPVC("pvc", "test-sc", "", nil),
+			expected: true,
+		},
+		{
+			name:     "other storage class",
+			pvc:      newPVC("pvc", "other-sc", "", nil),
+			expected: false,
+		},
+		{
+			name:     "deleted storage class with csi pv",
+			pvc:      newPVC("pvc", "deleted-sc", "test-pv", nil),
+			expected: true,
+		},
+		{
+			name:     "pv provisioned by other driver",
+			pvc:      newPVC("pvc", "", "other-pv", nil),
+			expected: false,
+		},
+		{
+			name:     "missing pv",
+			pvc:      newPVC("pvc", "", "missing-pv", nil),
+			expected: false,
+		},
+		{
+			name:     "no storage class or pv",
+			pvc:      newPVC("pvc", "", "", nil),
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, c.OwnsPVC(tc.pvc), "%v: Unexpected owner", tc.name)
+	}
+}
+
+func volumeInfoTest(t *testing.T) {
+	resetTest()
+	c := newDriver(t, nil)
+
+	affinity := newZoneAffinity("a")
+	_, err := fakeKubeClient.CoreV1().PersistentVolumes().Create(newPV("test-pv", testDriverName, "vol1", affinity))
+	require.NoError(t, err, "Error creating PV")
+	_, err = fakeKubeClient.CoreV1().PersistentVolumes().Create(newPV("other-pv", otherDriverName, "vol2", nil))
+	require.NoError(t, err, "Error creating PV")
+
+	for _, volumeID := range []string{"vol1", "test-pv"} {
+		info, err := c.InspectVolume(volumeID)
+		require.NoError(t, err, "Error inspecting volume %v", volumeID)
+		require.Equal(t, "vol1", info.VolumeID, "Unexpected volume ID")
+		require.Equal(t, "test-pv", info.VolumeName, "Unexpected volume name")
+		require.Equal(t, uint64(2), info.Size, "Unexpected volume size")
+		require.Equal(t, map[string]string{"fsType": "ext4"}, info.Labels, "Unexpected volume labels")
+		require.Equal(t, affinity, info.NodeAffinity, "Unexpected node affinity")
+		require.Empty(t, info.DataNodes, "Expected no data nodes")
+	}
+
+	// Volumes from other CSI drivers shouldn't be found
+	_, err = c.InspectVolume("vol2")
+	require.Error(t, err, "Expected error inspecting volume from other driver")
+
+	// Volumes inspected in bulk are keyed by the requested ID and volumes
+	// that aren't found are left out
+	volumes, err := c.InspectVolumes([]string{"vol1", "test-pv", "vol2", "missing"})
+	require.NoError(t, err, "Error inspecting volumes")
+	require.Len(t, volumes, 2, "Unexpected number of volumes")
+	for _, volumeID := range []string{"vol1", "test-pv"} {
+		require.Contains(t, volumes, volumeID, "Expected volume to be found")
+		require.Equal(t, "vol1", volumes[volumeID].VolumeID, "Unexpected volume ID")
+	}
+}
+
+func getNodesTest(t *testing.T) {
+	resetTest()
+	nodes := []*v1.Node{
+		// Registered in CSINode with topology keys
+		newNode("node1", "192.168.0.1", true, map[string]string{
+			"topology.test.csi.driver/zone":   "a",
+			"topology.test.csi.driver/region": "us-east",
+			"topology.test.csi.driver/rack":   "rack1",
+		}, nil),
+		// Registered in CSINode without topology keys, the topology is
+		// read from the node labels
+		newNode("node2", "192.168.0.2", false, map[string]string{
+			storkvolume.TopologyZoneLabel: "b",
+		}, nil),
+		// Only registered in the annotation
+		newNode("node3", "192.168.0.3", true, map[string]string{
+			storkvolume.TopologyZoneLabel: "c",
+		}, map[string]string{
+			storkvolume.CSINodeIDAnnotation: `{"` + testDriverName + `":"id3"}`,
+		}),
+		// Only has a different driver
+		newNode("node4", "192.168.0.4", true, nil, nil),
+		// No CSI drivers
+		newNode("node5", "192.168.0.5", true, nil, nil),
+	}
+	for _, node := range nodes {
+		_, err := fakeKubeClient.CoreV1().Nodes().Create(node)
+		require.NoError(t, err, "Error creating node")
+	}
+
+	csiNodes := &unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			newCSINode("node1",
+				map[string]interface{}{"name": otherDriverName, "nodeID": "other1"},
+				map[string]interface{}{
+					"name":   testDriverName,
+					"nodeID": "id1",
+					"topologyKeys": []interface{}{
+						"topology.test.csi.driver/zone",
+						"topology.test.csi.driver/region",
+						"topology.test.csi.driver/rack",
+					},
+				}),
+			newCSINode("node2", map[string]interface{}{"name": testDriverName, "nodeID": "id2"}),
+			newCSINode("node4", map[string]interface{}{"name": otherDriverName, "nodeID": "other4"}),
+		},
+	}
+	expected := []*storkvolume.NodeInfo{
+		{
+			ID:       "id1",
+			Hostname: "node1",
+			IPs:      []string{"192.168.0.1"},
+			Rack:     "rack1",
+			Zone:     "a",
+			Region:   "us-east",
+			Status:   storkvolume.NodeOnline,
+		},
+		{
+			ID:       "id2",
+			Hostname: "node2",
+			IPs:      []string{"192.168.0.2"},
+			Zone:     "b",
+			Status:   storkvolume.NodeOffline,
+		},
+		{
+			ID:       "id3",
+			Hostname: "node3",
+			IPs:      []string{"192.168.0.3"},
+			Zone:     "c",
+			Status:   storkvolume.NodeOnline,
+		},
+	}
+
+	// The v1 API should be preferred, and the v1beta1 API used if the
+	// cluster doesn't have the v1 API
+	for _, version := range []string{"v1", "v1beta1"} {
+		c := newDriver(t, map[string]*unstructured.UnstructuredList{version: csiNodes})
+		driverNodes, err := c.GetNodes()
+		require.NoError(t, err, "Error getting nodes with %v CSINodes", version)
+		require.Equal(t, expected, driverNodes, "Unexpected nodes with %v CSINodes", version)
+	}
+}
+
+func getNodesWithoutCSINodeTest(t *testing.T) {
+	resetTest()
+	_, err := fakeKubeClient.CoreV1().Nodes().Create(newNode("node1", "192.168.0.1", true, map[string]string{
+		storkvolume.TopologyZoneLabel:   "a",
+		storkvolume.TopologyRegionLabel: "us-east",
+		storkvolume.TopologyRackLabel:   "rack1",
+	}, map[string]string{
+		storkvolume.CSINodeIDAnnotation: `{"` + otherDriverName + `":"other1","` + testDriverName + `":"id1"}`,
+	}))
+	require.NoError(t, err, "Error creating node")
+	_, err = fakeKubeClient.CoreV1().Nodes().Create(newNode("node2", "192.168.0.2", true, nil, nil))
+	require.NoError(t, err, "Error creating node")
+
+	c := newDriver(t, nil)
+	driverNodes, err := c.GetNodes()
+	require.NoError(t, err, "Error getting nodes")
+	require.Equal(t, []*storkvolume.NodeInfo{{
+		ID:       "id1",
+		Hostname: "node1",
+		IPs:      []string{"192.168.0.1"},
+		Rack:     "rack1",
+		Zone:     "a",
+		Region:   "us-east",
+		Status:   storkvolume.NodeOnline,
+	}}, driverNodes, "Unexpected nodes")
+}
+
+func podVolumesTest(t *testing.T) {
+	resetTest()
+	c := newDriver(t, nil)
+
+	affinity := newZoneAffinity("a")
+	_, err := fakeKubeClient.CoreV1().PersistentVolumes().Create(newPV("test-pv", testDriverName, "vol1", affinity))
+	require.NoError(t, err, "Error creating PV")
+	pvcs := []*v1.PersistentVolumeClaim{
+		newPVC("csi-pvc", "", "test-pv", map[string]string{pvcProvisionerAnnotation: testDriverName}),
+		newPVC("other-pvc", "", "other-pv", map[string]string{pvcProvisionerAnnotation: otherDriverName}),
+		// The PV for a bound PVC can't be found
+		newPVC("missing-pv-pvc", "", "missing-pv", map[string]string{pvcProvisionerAnnotation: testDriverName}),
+	}
+	for _, pvc := range pvcs {
+		_, err := fakeKubeClient.CoreV1().PersistentVolumeClaims(testNamespace).Create(pvc)
+		require.NoError(t, err, "Error creating PVC")
+	}
+
+	podSpec := &v1.PodSpec{}
+	for _, pvc := range pvcs {
+		podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+			Name: pvc.Name,
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+			},
+		})
+	}
+	podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+		Name:         "emptydir",
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+	})
+
+	volumes, err := c.GetPodVolumes(podSpec, testNamespace)
+	require.NoError(t, err, "Error getting pod volumes")
+	require.Len(t, volumes, 2, "Unexpected number of volumes")
+	require.Equal(t, "vol1", volumes[0].VolumeID, "Unexpected volume ID")
+	require.Equal(t, affinity, volumes[0].NodeAffinity, "Unexpected node affinity")
+	require.Equal(t, &storkvolume.Info{VolumeName: "missing-pv"}, volumes[1], "Unexpected volume for missing PV")
+
+	// Pods using a PVC that doesn't exist should fail
+	podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+		Name: "missing",
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "missing"},
+		},
+	})
+	_, err = c.GetPodVolumes(podSpec, testNamespace)
+	require.Error(t, err, "Expected error getting volumes for missing PVC")
+}
+
+func pendingPVCTest(t *testing.T) {
+	resetTest()
+	c := newDriver(t, nil)
+
+	pvc := newPVC("pending-pvc", "", "", map[string]string{pvcProvisionerAnnotation: testDriverName})
+	_, err := fakeKubeClient.CoreV1().PersistentVolumeClaims(testNamespace).Create(pvc)
+	require.NoError(t, err, "Error creating PVC")
+	otherPVC := newPVC("other-pending-pvc", "", "", map[string]string{pvcProvisionerAnnotation: otherDriverName})
+	_, err = fakeKubeClient.CoreV1().PersistentVolumeClaims(testNamespace).Create(otherPVC)
+	require.NoError(t, err, "Error creating PVC")
+
+	podSpec := &v1.PodSpec{
+		Volumes: []v1.Volume{{
+			Name: "other",
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: otherPVC.Name},
+			},
+		}},
+	}
+	// Pending PVCs from other drivers are ignored
+	volumes, err := c.GetPodVolumes(podSpec, testNamespace)
+	require.NoError(t, err, "Error getting pod volumes")
+	require.Empty(t, volumes, "Expected no volumes")
+
+	podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+		Name: "pending",
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+		},
+	})
+	_, err = c.GetPodVolumes(podSpec, testNamespace)
+	require.Error(t, err, "Expected error for pending PVC")
+	pendingErr, ok := err.(*storkvolume.ErrPVCPending)
+	require.True(t, ok, "Expected ErrPVCPending, got %v", err)
+	require.Equal(t, pvc.Name, pendingErr.Name, "Unexpected pending PVC")
+}
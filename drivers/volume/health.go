@@ -0,0 +1,102 @@
+package volume
+
+import (
+	"strings"
+
+	"k8s.io/api/core/v1"
+)
+
+const (
+	healthAnnotationPrefix = "stork.libopenstorage.org/"
+	// ReplicationStatusAnnotation Annotation on a PVC with the replication
+	// status of its volume
+	ReplicationStatusAnnotation = healthAnnotationPrefix + "replication-status"
+	// DegradedNodesAnnotation Annotation on a PVC with the comma separated
+	// list of nodes whose replica of the volume isn't in sync
+	DegradedNodesAnnotation = healthAnnotationPrefix + "degraded-nodes"
+	// AttachedOnAnnotation Annotation on a PVC with the node on which its
+	// volume is attached
+	AttachedOnAnnotation = healthAnnotationPrefix + "attached-on"
+	// IOStateAnnotation Annotation on a PVC with the state of IO to its
+	// volume
+	IOStateAnnotation = healthAnnotationPrefix + "io-state"
+)
+
+// healthAnnotations Annotations used to publish the health of a volume on
+// its PVC
+var healthAnnotations = []string{
+	ReplicationStatusAnnotation,
+	DegradedNodesAnnotation,
+	AttachedOnAnnotation,
+	IOStateAnnotation,
+}
+
+// GetHealthAnnotations Returns the annotations with the health of the
+// volume. Fields that aren't reported by the driver aren't returned. The
+// usage isn't published since it changes too often
+func GetHealthAnnotations(info *Info) map[string]string {
+	annotations := make(map[string]string)
+	if info.ReplicationStatus != "" {
+		annotations[ReplicationStatusAnnotation] = string(info.ReplicationStatus)
+	}
+	if len(info.DegradedNodes) > 0 {
+		annotations[DegradedNodesAnnotation] = strings.Join(info.DegradedNodes, ",")
+	}
+	if info.AttachedOn != "" {
+		annotations[AttachedOnAnnotation] = info.AttachedOn
+	}
+	if info.IOState != "" {
+		annotations[IOStateAnnotation] = string(info.IOState)
+	}
+	return annotations
+}
+
+// UpdateHealthAnnotations Replaces the health annotations on the PVC with
+// the given ones. Returns true if any of them changed
+func UpdateHealthAnnotations(pvc *v1.PersistentVolumeClaim, annotations map[string]string) bool {
+	changed := false
+	for _, key := range healthAnnotations {
+		value, ok := annotations[key]
+		current, exists := pvc.Annotations[key]
+		if ok == exists && value == current {
+			continue
+		}
+		changed = true
+		if !ok {
+			delete(pvc.Annotations, key)
+			continue
+		}
+		if pvc.Annotations == nil {
+			pvc.Annotations = make(map[string]string)
+		}
+		pvc.Annotations[key] = value
+	}
+	return changed
+}
+
+// IsDegradedNode Returns true if the replica of the volume on the node
+// isn't in sync
+func (i *Info) IsDegradedNode(nodeID string) bool {
+	for _, degradedNode := range i.DegradedNodes {
+		if degradedNode == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAttachedOn Returns true if the volume is attached on the driver node
+func (i *Info) IsAttachedOn(node *NodeInfo) bool {
+	if i.AttachedOn == "" || node == nil || i.Driver != node.Driver {
+		return false
+	}
+	if i.AttachedOn == node.ID {
+		return true
+	}
+	for _, ip := range node.IPs {
+		if i.AttachedOn == ip {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package mock
+
+import (
+	"fmt"
+
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	stork_crd "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const defaultGroupSnapshotSteps = 1
+
+// snapshotTask Snapshot of a volume taken as part of a group snapshot
+type snapshotTask struct {
+	volumeID string
+	polls    int
+	err      string
+}
+
+// SetGroupSnapshotSteps Sets the number of times the status of a group
+// snapshot needs to be checked before the snapshots are ready. Defaults to 1
+func (m *Driver) SetGroupSnapshotSteps(steps int) {
+	m.groupSnapshotSteps = steps
+}
+
+// SetGroupSnapshotFailure Fails the snapshot of the volume in the given
+// number of group snapshot attempts. Used for negative testing
+func (m *Driver) SetGroupSnapshotFailure(volumeID string, attempts int) {
+	m.groupSnapshotFailures[volumeID] = attempts
+}
+
+// CreateGroupSnapshot Starts taking snapshots of the volumes bound to the
+// PVCs matching the selector of the group snapshot
+func (m *Driver) CreateGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) (*storkvolume.GroupSnapshotCreateResponse, error) {
+	if !m.Capabilities().GroupSnapshots {
+		return nil, &errors.ErrNotSupported{}
+	}
+	if m.interfaceError != nil {
+		return nil, m.interfaceError
+	}
+
+	pvcs, err := k8sutils.GetPVCsForGroupSnapshot(snap.Namespace, snap.Spec.PVCSelector.MatchLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(map[string]*snapshotTask)
+	response := &storkvolume.GroupSnapshotCreateResponse{
+		Snapshots: make([]*stork_crd.VolumeSnapshotStatus, 0),
+	}
+	for _, pvc := range pvcs {
+		if !m.OwnsPVC(&pvc) {
+			continue
+		}
+		task := &snapshotTask{
+			volumeID: pvc.Spec.VolumeName,
+		}
+		if attempts := m.groupSnapshotFailures[task.volumeID]; attempts > 0 {
+			m.groupSnapshotFailures[task.volumeID] = attempts - 1
+			task.err = "injected snapshot failure"
+		}
+		taskID := fmt.Sprintf("%v-%v", snap.Name, task.volumeID)
+		tasks[taskID] = task
+		response.Snapshots = append(response.Snapshots, &stork_crd.VolumeSnapshotStatus{
+			TaskID:         taskID,
+			ParentVolumeID: task.volumeID,
+			Conditions: []snapv1.VolumeSnapshotCondition{
+				{
+					Type:               snapv1.VolumeSnapshotConditionPending,
+					Status:             v1.ConditionTrue,
+					Message:            "Snapshot has been started",
+					LastTransitionTime: metav1.Now(),
+				},
+			},
+		})
+	}
+	m.groupSnapshots[objectKey(snap.Namespace, snap.Name)] = tasks
+	return response, nil
+}
+
+// GetGroupSnapshotStatus Advances the progress of the snapshots in the
+// group and returns their status
+func (m *Driver) GetGroupSnapshotStatus(snap *stork_crd.GroupVolumeSnapshot) (*storkvolume.GroupSnapshotCreateResponse, error) {
+	if !m.Capabilities().GroupSnapshots {
+		return nil, &errors.ErrNotSupported{}
+	}
+	if m.interfaceError != nil {
+		return nil, m.interfaceError
+	}
+
+	tasks, ok := m.groupSnapshots[objectKey(snap.Namespace, snap.Name)]
+	if !ok {
+		return nil, &errors.ErrNotFound{
+			ID:   snap.Name,
+			Type: "GroupSnapshot",
+		}
+	}
+	response := &storkvolume.GroupSnapshotCreateResponse{
+		Snapshots: make([]*stork_crd.VolumeSnapshotStatus, 0),
+	}
+	for _, snapshot := range snap.Status.VolumeSnapshots {
+		task, ok := tasks[snapshot.TaskID]
+		if !ok {
+			return nil, &errors.ErrNotFound{
+				ID:   snapshot.TaskID,
+				Type: "Snapshot",
+			}
+		}
+		status := &stork_crd.VolumeSnapshotStatus{
+			TaskID:         snapshot.TaskID,
+			ParentVolumeID: task.volumeID,
+		}
+		response.Snapshots = append(response.Snapshots, status)
+
+		if task.err != "" {
+			status.Conditions = getSnapshotConditions(snapv1.VolumeSnapshotConditionError,
+				fmt.Sprintf("snapshot failed due to err: %v", task.err))
+			continue
+		}
+		task.polls++
+		if task.polls >= m.groupSnapshotSteps {
+			status.DataSource = &snapv1.VolumeSnapshotDataSource{
+				HostPath: &snapv1.HostPathVolumeSnapshotSource{
+					Path: snapshot.TaskID,
+				},
+			}
+			status.Conditions = getSnapshotConditions(snapv1.VolumeSnapshotConditionReady,
+				"Snapshot created successfully and it is ready")
+		} else {
+			status.Conditions = getSnapshotConditions(snapv1.VolumeSnapshotConditionPending,
+				fmt.Sprintf("Snapshot in progress: %v%%", task.polls*100/m.groupSnapshotSteps))
+		}
+	}
+	return response, nil
+}
+
+// DeleteGroupSnapshot Deletes the snapshots taken for the group snapshot
+func (m *Driver) DeleteGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) error {
+	if !m.Capabilities().GroupSnapshots {
+		return &errors.ErrNotSupported{}
+	}
+	delete(m.groupSnapshots, objectKey(snap.Namespace, snap.Name))
+	return nil
+}
+
+// HasGroupSnapshot Returns true if the driver has snapshots for the group
+// snapshot
+func (m *Driver) HasGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) bool {
+	_, ok := m.groupSnapshots[objectKey(snap.Namespace, snap.Name)]
+	return ok
+}
+
+func getSnapshotConditions(
+	conditionType snapv1.VolumeSnapshotConditionType,
+	message string,
+) []snapv1.VolumeSnapshotCondition {
+	return []snapv1.VolumeSnapshotCondition{
+		{
+			Type:               conditionType,
+			Status:             v1.ConditionTrue,
+			Message:            message,
+			LastTransitionTime: metav1.Now(),
+		},
+	}
+}
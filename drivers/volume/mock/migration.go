@@ -0,0 +1,193 @@
+package mock
+
+import (
+	"fmt"
+
+	stork_crd "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/portworx/sched-ops/k8s"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	remoteStorageIDPrefix = "mock-remote-"
+	defaultMigrationSteps = 1
+)
+
+// SetClusterPairError Sets the error returned when creating cluster pairs.
+// Used for negative testing
+func (m *Driver) SetClusterPairError(err error) {
+	m.clusterPairError = err
+}
+
+// GetClusterPairs Returns the remote storage IDs of the cluster pairs that
+// have been created, keyed by namespace/name of the pair
+func (m *Driver) GetClusterPairs() map[string]string {
+	return m.clusterPairs
+}
+
+// SetMigrationSteps Sets the number of times the status of a volume
+// migration needs to be checked before it completes. Defaults to 1
+func (m *Driver) SetMigrationSteps(steps int) {
+	m.migrationSteps = steps
+}
+
+// SetMigrationFailure Fails the migration of the volume with the given
+// reason the next time its status is checked. Used for negative testing
+func (m *Driver) SetMigrationFailure(volumeID string, reason string) {
+	m.migrationFailures[volumeID] = reason
+}
+
+// IsMigrationCancelled Returns true if the migration was cancelled
+func (m *Driver) IsMigrationCancelled(migration *stork_crd.Migration) bool {
+	return m.cancelledMigrations[objectKey(migration.Namespace, migration.Name)]
+}
+
+// CreatePair Records a pair with a simulated remote cluster
+func (m *Driver) CreatePair(pair *stork_crd.ClusterPair) (string, error) {
+	if !m.Capabilities().ClusterPair {
+		return "", &errors.ErrNotSupported{}
+	}
+	if m.clusterPairError != nil {
+		return "", m.clusterPairError
+	}
+	remoteID := remoteStorageIDPrefix + pair.Name
+	m.clusterPairs[objectKey(pair.Namespace, pair.Name)] = remoteID
+	return remoteID, nil
+}
+
+// DeletePair Deletes a pair created with CreatePair
+func (m *Driver) DeletePair(pair *stork_crd.ClusterPair) error {
+	if !m.Capabilities().ClusterPair {
+		return &errors.ErrNotSupported{}
+	}
+	key := objectKey(pair.Namespace, pair.Name)
+	if _, ok := m.clusterPairs[key]; !ok {
+		return &errors.ErrNotFound{
+			ID:   key,
+			Type: "ClusterPair",
+		}
+	}
+	delete(m.clusterPairs, key)
+	return nil
+}
+
+// StartMigration Starts migrating the bound PVCs owned by the driver. The
+// migrations complete after their status has been checked the number of
+// times set with SetMigrationSteps
+func (m *Driver) StartMigration(migration *stork_crd.Migration) ([]*stork_crd.VolumeInfo, error) {
+	if !m.Capabilities().Migration {
+		return nil, &errors.ErrNotSupported{}
+	}
+	if m.interfaceError != nil {
+		return nil, m.interfaceError
+	}
+	if len(migration.Spec.Namespaces) == 0 {
+		return nil, fmt.Errorf("namespaces for migration cannot be empty")
+	}
+	if _, ok := m.clusterPairs[objectKey(migration.Namespace, migration.Spec.ClusterPair)]; !ok {
+		return nil, fmt.Errorf("Cluster pair %v hasn't been paired with the mock driver", migration.Spec.ClusterPair)
+	}
+
+	volumeInfos := make([]*stork_crd.VolumeInfo, 0)
+	for _, namespace := range migration.Spec.Namespaces {
+		pvcList, err := k8s.Instance().GetPersistentVolumeClaims(namespace, migration.Spec.Selectors)
+		if err != nil {
+			return nil, fmt.Errorf("error getting list of volumes to migrate: %v", err)
+		}
+		for _, pvc := range pvcList.Items {
+			if !m.OwnsPVC(&pvc) {
+				continue
+			}
+			volumeInfo := &stork_crd.VolumeInfo{
+				PersistentVolumeClaim: pvc.Name,
+				Namespace:             pvc.Namespace,
+				Volume:                pvc.Spec.VolumeName,
+			}
+			if pvc.Spec.VolumeName == "" {
+				volumeInfo.Status = stork_crd.MigrationStatusFailed
+				volumeInfo.Reason = "PVC isn't bound to a volume"
+			} else {
+				volumeInfo.Status = stork_crd.MigrationStatusInProgress
+				volumeInfo.Reason = "Volume migration has started"
+				m.migrationPolls[m.getMigrationTaskID(migration, volumeInfo)] = 0
+			}
+			volumeInfos = append(volumeInfos, volumeInfo)
+		}
+	}
+	return volumeInfos, nil
+}
+
+// GetMigrationStatus Advances the progress of the volume migrations and
+// returns their status
+func (m *Driver) GetMigrationStatus(migration *stork_crd.Migration) ([]*stork_crd.VolumeInfo, error) {
+	if !m.Capabilities().Migration {
+		return nil, &errors.ErrNotSupported{}
+	}
+	if m.interfaceError != nil {
+		return nil, m.interfaceError
+	}
+
+	cancelled := m.cancelledMigrations[objectKey(migration.Namespace, migration.Name)]
+	volumeInfos := make([]*stork_crd.VolumeInfo, 0, len(migration.Status.Volumes))
+	for _, vInfo := range migration.Status.Volumes {
+		volumeInfo := vInfo.DeepCopy()
+		volumeInfos = append(volumeInfos, volumeInfo)
+		if volumeInfo.Status != stork_crd.MigrationStatusInProgress {
+			continue
+		}
+
+		taskID := m.getMigrationTaskID(migration, volumeInfo)
+		polls, ok := m.migrationPolls[taskID]
+		if !ok {
+			volumeInfo.Status = stork_crd.MigrationStatusFailed
+			volumeInfo.Reason = "Migration not found for volume"
+			continue
+		}
+		if cancelled {
+			volumeInfo.Status = stork_crd.MigrationStatusFailed
+			volumeInfo.Reason = "Migration was cancelled"
+			continue
+		}
+		if reason, ok := m.migrationFailures[volumeInfo.Volume]; ok {
+			volumeInfo.Status = stork_crd.MigrationStatusFailed
+			volumeInfo.Reason = fmt.Sprintf("Migration failed for volume: %v", reason)
+			continue
+		}
+
+		polls++
+		m.migrationPolls[taskID] = polls
+		if polls >= m.migrationSteps {
+			volumeInfo.Status = stork_crd.MigrationStatusSuccessful
+			volumeInfo.Reason = "Migration successful for volume"
+		} else {
+			volumeInfo.Reason = fmt.Sprintf("Volume migration in progress: %v%%", polls*100/m.migrationSteps)
+		}
+	}
+	return volumeInfos, nil
+}
+
+// CancelMigration Cancels the volume migrations that are still in progress
+func (m *Driver) CancelMigration(migration *stork_crd.Migration) error {
+	if !m.Capabilities().Migration {
+		return &errors.ErrNotSupported{}
+	}
+	m.cancelledMigrations[objectKey(migration.Namespace, migration.Name)] = true
+	return nil
+}
+
+// UpdateMigratedPersistentVolumeSpec Returns the PV spec unchanged since the
+// volumes have the same name in the simulated remote cluster
+func (m *Driver) UpdateMigratedPersistentVolumeSpec(
+	object runtime.Unstructured,
+) (runtime.Unstructured, error) {
+	return object, nil
+}
+
+func (m *Driver) getMigrationTaskID(migration *stork_crd.Migration, volumeInfo *stork_crd.VolumeInfo) string {
+	return fmt.Sprintf("%v/%v/%v", migration.Namespace, migration.Name, volumeInfo.Volume)
+}
+
+func objectKey(namespace string, name string) string {
+	return namespace + "/" + name
+}
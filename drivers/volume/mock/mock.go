@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
 	snapshotVolume "github.com/kubernetes-incubator/external-storage/snapshot/pkg/volume"
@@ -11,6 +12,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8shelper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 )
 
@@ -24,17 +26,46 @@ const (
 	ZoneLabel = "mock/zone"
 	// RegionLabel Label used for the mock driver to set region information
 	RegionLabel = "mock/region"
+	// bytesPerGB Number of bytes in the unit used for the size of volumes
+	bytesPerGB = 1024 * 1024 * 1024
 )
 
 // Driver Mock driver for tests
 type Driver struct {
-	storkvolume.ClusterPairNotSupported
-	storkvolume.MigrationNotSupported
-	storkvolume.GroupSnapshotNotSupported
-	nodes          []*storkvolume.NodeInfo
-	volumes        map[string]*storkvolume.Info
-	pvcs           map[string]*v1.PersistentVolumeClaim
-	interfaceError error
+	nodes                 []*storkvolume.NodeInfo
+	volumes               map[string]*storkvolume.Info
+	pvcs                  map[string]*v1.PersistentVolumeClaim
+	preparedNodes         map[string]string
+	provisionCandidates   []*storkvolume.ProvisionCandidate
+	capabilities          *storkvolume.Capabilities
+	interfaceError        error
+	clusterPairs          map[string]string
+	clusterPairError      error
+	migrationSteps        int
+	migrationPolls        map[string]int
+	migrationFailures     map[string]string
+	cancelledMigrations   map[string]bool
+	groupSnapshotSteps    int
+	groupSnapshots        map[string]map[string]*snapshotTask
+	groupSnapshotFailures map[string]int
+	timeline              *nodeTimeline
+	clock                 func() time.Time
+}
+
+// NodeStatusChange Change in the status of a node, applied once the given
+// duration has passed since the node timeline was set
+type NodeStatusChange struct {
+	NodeIndex int
+	After     time.Duration
+	Status    storkvolume.NodeStatus
+}
+
+// nodeTimeline Changes in node status to be applied as time passes. Kept
+// behind a pointer since some of the driver methods have value receivers
+type nodeTimeline struct {
+	start   time.Time
+	changes []NodeStatusChange
+	applied int
 }
 
 // String Returns the name for the driver
@@ -92,7 +123,21 @@ func (m *Driver) CreateCluster(numNodes int, nodes *v1.NodeList) error {
 	}
 	m.volumes = make(map[string]*storkvolume.Info)
 	m.pvcs = make(map[string]*v1.PersistentVolumeClaim)
+	m.preparedNodes = make(map[string]string)
+	m.provisionCandidates = nil
+	m.capabilities = nil
 	m.interfaceError = nil
+	m.clusterPairs = make(map[string]string)
+	m.clusterPairError = nil
+	m.migrationSteps = defaultMigrationSteps
+	m.migrationPolls = make(map[string]int)
+	m.migrationFailures = make(map[string]string)
+	m.cancelledMigrations = make(map[string]bool)
+	m.groupSnapshotSteps = defaultGroupSnapshotSteps
+	m.groupSnapshots = make(map[string]map[string]*snapshotTask)
+	m.groupSnapshotFailures = make(map[string]int)
+	m.timeline = nil
+	m.clock = time.Now
 	return nil
 }
 
@@ -112,6 +157,32 @@ func (m *Driver) NewPVC(volumeName string) *v1.PersistentVolumeClaim {
 	return pvc
 }
 
+// NewPendingPVC Create a new reference to a PVC that hasn't been bound to a
+// volume yet
+func (m *Driver) NewPendingPVC(pvcName string) *v1.PersistentVolumeClaim {
+	pvc := &v1.PersistentVolumeClaim{}
+	pvc.Name = pvcName
+	storageClassName := m.GetStorageClassName()
+	pvc.Spec.StorageClassName = &storageClassName
+	pvc.Status.Phase = v1.ClaimPending
+	m.pvcs[pvcName] = pvc
+	return pvc
+}
+
+// SetProvisionCandidates Set the nodes on which volumes can be provisioned
+func (m *Driver) SetProvisionCandidates(nodeIndexes []int) error {
+	m.provisionCandidates = nil
+	for _, nodeIndex := range nodeIndexes {
+		if len(m.nodes) <= nodeIndex {
+			return fmt.Errorf("Node not found")
+		}
+		m.provisionCandidates = append(m.provisionCandidates, &storkvolume.ProvisionCandidate{
+			NodeID: m.nodes[nodeIndex].ID,
+		})
+	}
+	return nil
+}
+
 // ProvisionVolume Provision a volume in the mock driver
 func (m *Driver) ProvisionVolume(
 	volumeName string,
@@ -140,6 +211,72 @@ func (m *Driver) ProvisionVolume(
 	return nil
 }
 
+// UpdateVolumeNodeAffinity Update the node affinity for a volume
+func (m *Driver) UpdateVolumeNodeAffinity(
+	volumeName string,
+	affinity *v1.VolumeNodeAffinity,
+) error {
+	volume, ok := m.volumes[volumeName]
+	if !ok {
+		return fmt.Errorf("Volume %v not found", volumeName)
+	}
+	volume.NodeAffinity = affinity
+	return nil
+}
+
+// UpdateVolumeUsage Update the number of bytes used in a volume
+func (m *Driver) UpdateVolumeUsage(volumeName string, usedBytes uint64) error {
+	volume, ok := m.volumes[volumeName]
+	if !ok {
+		return fmt.Errorf("Volume %v not found", volumeName)
+	}
+	volume.UsedBytes = usedBytes
+	return nil
+}
+
+// UpdateVolumeHealth Update the replication status and IO state of a
+// volume, and the nodes on which its replicas aren't in sync
+func (m *Driver) UpdateVolumeHealth(
+	volumeName string,
+	replicationStatus storkvolume.ReplicationStatus,
+	degradedNodeIndexes []int,
+	ioState storkvolume.IOState,
+) error {
+	volume, ok := m.volumes[volumeName]
+	if !ok {
+		return fmt.Errorf("Volume %v not found", volumeName)
+	}
+	var degradedNodes []string
+	for _, nodeIndex := range degradedNodeIndexes {
+		if len(m.nodes) <= nodeIndex {
+			return fmt.Errorf("Node not found")
+		}
+		degradedNodes = append(degradedNodes, m.nodes[nodeIndex].ID)
+	}
+	volume.ReplicationStatus = replicationStatus
+	volume.DegradedNodes = degradedNodes
+	volume.IOState = ioState
+	return nil
+}
+
+// AttachVolume Set the node on which a volume is attached. A negative index
+// detaches the volume
+func (m *Driver) AttachVolume(volumeName string, nodeIndex int) error {
+	volume, ok := m.volumes[volumeName]
+	if !ok {
+		return fmt.Errorf("Volume %v not found", volumeName)
+	}
+	if nodeIndex < 0 {
+		volume.AttachedOn = ""
+		return nil
+	}
+	if len(m.nodes) <= nodeIndex {
+		return fmt.Errorf("Node not found")
+	}
+	volume.AttachedOn = m.nodes[nodeIndex].ID
+	return nil
+}
+
 // UpdateNodeStatus Update status for a node
 func (m *Driver) UpdateNodeStatus(
 	nodeIndex int,
@@ -152,6 +289,64 @@ func (m *Driver) UpdateNodeStatus(
 	return nil
 }
 
+// UpdateNodeUtilization Update the capacity and load information for a node
+func (m *Driver) UpdateNodeUtilization(
+	nodeIndex int,
+	utilization *storkvolume.NodeUtilization,
+) error {
+	if len(m.nodes) <= nodeIndex {
+		return fmt.Errorf("Node not found")
+	}
+	m.nodes[nodeIndex].Utilization = utilization
+	return nil
+}
+
+// SetNodeTimeline Sets the changes in node status to be applied as time
+// passes, to simulate nodes flapping. The changes are applied in order, when
+// the nodes are queried, once their duration has passed
+func (m *Driver) SetNodeTimeline(changes []NodeStatusChange) error {
+	for i, change := range changes {
+		if len(m.nodes) <= change.NodeIndex {
+			return fmt.Errorf("Node not found")
+		}
+		if i > 0 && change.After < changes[i-1].After {
+			return fmt.Errorf("Node status changes need to be ordered by time")
+		}
+	}
+	m.timeline = &nodeTimeline{
+		start:   m.clock(),
+		changes: changes,
+	}
+	return nil
+}
+
+// SetClock Sets the function used to get the current time for the node
+// timeline. Reset to time.Now when a cluster is created
+func (m *Driver) SetClock(clock func() time.Time) {
+	m.clock = clock
+}
+
+// applyNodeTimeline Applies the node status changes whose time has passed
+func (m Driver) applyNodeTimeline() {
+	if m.timeline == nil {
+		return
+	}
+	elapsed := m.clock().Sub(m.timeline.start)
+	for ; m.timeline.applied < len(m.timeline.changes); m.timeline.applied++ {
+		change := m.timeline.changes[m.timeline.applied]
+		if change.After > elapsed {
+			return
+		}
+		m.nodes[change.NodeIndex].Status = change.Status
+	}
+}
+
+// SetCapabilities Sets the capabilities returned by the driver. Reset when
+// a cluster is created
+func (m *Driver) SetCapabilities(capabilities *storkvolume.Capabilities) {
+	m.capabilities = capabilities
+}
+
 // SetInterfaceError to the specified error. Used for negative testing
 func (m *Driver) SetInterfaceError(err error) {
 	m.interfaceError = err
@@ -173,14 +368,68 @@ func (m Driver) InspectVolume(volumeID string) (*storkvolume.Info, error) {
 	return volume, nil
 }
 
+// ResizeVolume Resize a volume, rounding up the size to GB
+func (m *Driver) ResizeVolume(volumeID string, sizeBytes uint64) (*storkvolume.Info, error) {
+	if !m.Capabilities().Resize {
+		return nil, &errors.ErrNotSupported{}
+	}
+	if m.interfaceError != nil {
+		return nil, m.interfaceError
+	}
+
+	volume, ok := m.volumes[volumeID]
+	if !ok {
+		return nil, &errors.ErrNotFound{
+			ID:   volumeID,
+			Type: "volume",
+		}
+	}
+	size := (sizeBytes + bytesPerGB - 1) / bytesPerGB
+	if size < volume.Size {
+		return nil, fmt.Errorf("Volume %v can't be shrunk from %vGB to %vGB", volumeID, volume.Size, size)
+	}
+	volume.Size = size
+	return volume, nil
+}
+
 // GetNodes Get info about the nodes where the driver is running
 func (m Driver) GetNodes() ([]*storkvolume.NodeInfo, error) {
 	if m.interfaceError != nil {
 		return nil, m.interfaceError
 	}
+	m.applyNodeTimeline()
 	return m.nodes, nil
 }
 
+// EnterMaintenance Puts the node into maintenance mode
+func (m *Driver) EnterMaintenance(node *storkvolume.NodeInfo) error {
+	return m.setMaintenance(node, true)
+}
+
+// ExitMaintenance Takes the node out of maintenance mode
+func (m *Driver) ExitMaintenance(node *storkvolume.NodeInfo) error {
+	return m.setMaintenance(node, false)
+}
+
+func (m *Driver) setMaintenance(node *storkvolume.NodeInfo, maintenance bool) error {
+	if !m.Capabilities().Maintenance {
+		return &errors.ErrNotSupported{}
+	}
+	if m.interfaceError != nil {
+		return m.interfaceError
+	}
+	for _, n := range m.nodes {
+		if n.ID == node.ID {
+			n.Maintenance = maintenance
+			return nil
+		}
+	}
+	return &errors.ErrNotFound{
+		ID:   node.ID,
+		Type: "node",
+	}
+}
+
 // GetPodVolumes Get the Volumes in the Pod that use the mock driver
 func (m Driver) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*storkvolume.Info, error) {
 	if m.interfaceError != nil {
@@ -208,6 +457,12 @@ func (m Driver) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*storkvo
 				continue
 			}
 
+			if pvc.Status.Phase == v1.ClaimPending {
+				return nil, &storkvolume.ErrPVCPending{
+					Name: pvc.Name,
+				}
+			}
+
 			// Assume all mock volume have the same
 			// storageclass
 			if storageClassName != storageClassName {
@@ -225,9 +480,43 @@ func (m Driver) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*storkvo
 	return volumes, nil
 }
 
-// OwnsPVC returns false since mock driver doesn't own any PVCs
+// PrepareVolumesOnNode Records the node on which the volumes were prepared
+func (m Driver) PrepareVolumesOnNode(volumes []*storkvolume.Info, node *storkvolume.NodeInfo) error {
+	if m.interfaceError != nil {
+		return m.interfaceError
+	}
+	for _, volume := range volumes {
+		if _, ok := m.volumes[volume.VolumeID]; !ok {
+			return &errors.ErrNotFound{
+				ID:   volume.VolumeID,
+				Type: "volume",
+			}
+		}
+		m.preparedNodes[volume.VolumeID] = node.ID
+	}
+	return nil
+}
+
+// GetPreparedNode Returns the ID of the node on which the volume was last
+// prepared
+func (m *Driver) GetPreparedNode(volumeID string) string {
+	return m.preparedNodes[volumeID]
+}
+
+// OwnsPVC returns true if the PVC uses the mock storage class
 func (m *Driver) OwnsPVC(pvc *v1.PersistentVolumeClaim) bool {
-	return false
+	return k8shelper.GetPersistentVolumeClaimClass(pvc) == storageClassName
+}
+
+// GetProvisionCandidates Returns the nodes set with SetProvisionCandidates
+func (m Driver) GetProvisionCandidates(
+	pvc *v1.PersistentVolumeClaim,
+	storageClass *storagev1.StorageClass,
+) ([]*storkvolume.ProvisionCandidate, error) {
+	if m.interfaceError != nil {
+		return nil, m.interfaceError
+	}
+	return m.provisionCandidates, nil
 }
 
 // GetSnapshotPlugin Returns nil since snapshot is not supported in the mock driver
@@ -246,6 +535,23 @@ func (m *Driver) GetSnapshotType(snap *snapv1.VolumeSnapshot) (string, error) {
 	return "", &errors.ErrNotImplemented{}
 }
 
+// Capabilities Returns the capabilities set for the driver. Defaults to
+// the topology levels read from the node labels. Pairing, migration, group
+// snapshots, resizing and maintenance return ErrNotSupported unless enabled
+// with SetCapabilities
+func (m *Driver) Capabilities() *storkvolume.Capabilities {
+	if m.capabilities != nil {
+		return m.capabilities
+	}
+	return &storkvolume.Capabilities{
+		TopologyLevels: []storkvolume.TopologyLevel{
+			storkvolume.TopologyLevelRack,
+			storkvolume.TopologyLevelZone,
+			storkvolume.TopologyLevelRegion,
+		},
+	}
+}
+
 func init() {
 	if err := storkvolume.Register(driverName, &Driver{}); err != nil {
 		logrus.Panicf("Error registering mock volume driver: %v", err)
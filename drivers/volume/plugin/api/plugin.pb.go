@@ -0,0 +1,2530 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: plugin.proto
+
+package api
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// VolumeInfo Information about a volume
+type VolumeInfo struct {
+	VolumeId   string   `protobuf:"bytes,1,opt,name=volume_id,json=volumeId,proto3" json:"volume_id,omitempty"`
+	VolumeName string   `protobuf:"bytes,2,opt,name=volume_name,json=volumeName,proto3" json:"volume_name,omitempty"`
+	DataNodes  []string `protobuf:"bytes,3,rep,name=data_nodes,json=dataNodes,proto3" json:"data_nodes,omitempty"`
+	// Size of the volume in GB
+	Size     uint64            `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	ParentId string            `protobuf:"bytes,5,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Labels   map[string]string `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// JSON encoded v1.VolumeNodeAffinity, empty if not set
+	NodeAffinity []byte `protobuf:"bytes,7,opt,name=node_affinity,json=nodeAffinity,proto3" json:"node_affinity,omitempty"`
+	// Bytes used in the volume, 0 if not reported
+	UsedBytes uint64 `protobuf:"varint,8,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	// One of InSync, Resyncing or Degraded. Empty if not reported
+	ReplicationStatus string `protobuf:"bytes,9,opt,name=replication_status,json=replicationStatus,proto3" json:"replication_status,omitempty"`
+	// Data nodes whose replica of the volume isn't in sync
+	DegradedNodes []string `protobuf:"bytes,10,rep,name=degraded_nodes,json=degradedNodes,proto3" json:"degraded_nodes,omitempty"`
+	// ID or IP of the node on which the volume is attached
+	AttachedOn string `protobuf:"bytes,11,opt,name=attached_on,json=attachedOn,proto3" json:"attached_on,omitempty"`
+	// One of Online, Offline or Error. Empty if not reported
+	IoState              string   `protobuf:"bytes,12,opt,name=io_state,json=ioState,proto3" json:"io_state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VolumeInfo) Reset()         { *m = VolumeInfo{} }
+func (m *VolumeInfo) String() string { return proto.CompactTextString(m) }
+func (*VolumeInfo) ProtoMessage()    {}
+func (*VolumeInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{0}
+}
+func (m *VolumeInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VolumeInfo.Unmarshal(m, b)
+}
+func (m *VolumeInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VolumeInfo.Marshal(b, m, deterministic)
+}
+func (dst *VolumeInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VolumeInfo.Merge(dst, src)
+}
+func (m *VolumeInfo) XXX_Size() int {
+	return xxx_messageInfo_VolumeInfo.Size(m)
+}
+func (m *VolumeInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_VolumeInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VolumeInfo proto.InternalMessageInfo
+
+func (m *VolumeInfo) GetVolumeId() string {
+	if m != nil {
+		return m.VolumeId
+	}
+	return ""
+}
+
+func (m *VolumeInfo) GetVolumeName() string {
+	if m != nil {
+		return m.VolumeName
+	}
+	return ""
+}
+
+func (m *VolumeInfo) GetDataNodes() []string {
+	if m != nil {
+		return m.DataNodes
+	}
+	return nil
+}
+
+func (m *VolumeInfo) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *VolumeInfo) GetParentId() string {
+	if m != nil {
+		return m.ParentId
+	}
+	return ""
+}
+
+func (m *VolumeInfo) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *VolumeInfo) GetNodeAffinity() []byte {
+	if m != nil {
+		return m.NodeAffinity
+	}
+	return nil
+}
+
+func (m *VolumeInfo) GetUsedBytes() uint64 {
+	if m != nil {
+		return m.UsedBytes
+	}
+	return 0
+}
+
+func (m *VolumeInfo) GetReplicationStatus() string {
+	if m != nil {
+		return m.ReplicationStatus
+	}
+	return ""
+}
+
+func (m *VolumeInfo) GetDegradedNodes() []string {
+	if m != nil {
+		return m.DegradedNodes
+	}
+	return nil
+}
+
+func (m *VolumeInfo) GetAttachedOn() string {
+	if m != nil {
+		return m.AttachedOn
+	}
+	return ""
+}
+
+func (m *VolumeInfo) GetIoState() string {
+	if m != nil {
+		return m.IoState
+	}
+	return ""
+}
+
+// NodeUtilization Capacity and load information for a node
+type NodeUtilization struct {
+	TotalCapacity        uint64   `protobuf:"varint,1,opt,name=total_capacity,json=totalCapacity,proto3" json:"total_capacity,omitempty"`
+	UsedCapacity         uint64   `protobuf:"varint,2,opt,name=used_capacity,json=usedCapacity,proto3" json:"used_capacity,omitempty"`
+	AttachedVolumes      int64    `protobuf:"varint,4,opt,name=attached_volumes,json=attachedVolumes,proto3" json:"attached_volumes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NodeUtilization) Reset()         { *m = NodeUtilization{} }
+func (m *NodeUtilization) String() string { return proto.CompactTextString(m) }
+func (*NodeUtilization) ProtoMessage()    {}
+func (*NodeUtilization) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{1}
+}
+func (m *NodeUtilization) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodeUtilization.Unmarshal(m, b)
+}
+func (m *NodeUtilization) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodeUtilization.Marshal(b, m, deterministic)
+}
+func (dst *NodeUtilization) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeUtilization.Merge(dst, src)
+}
+func (m *NodeUtilization) XXX_Size() int {
+	return xxx_messageInfo_NodeUtilization.Size(m)
+}
+func (m *NodeUtilization) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeUtilization.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeUtilization proto.InternalMessageInfo
+
+func (m *NodeUtilization) GetTotalCapacity() uint64 {
+	if m != nil {
+		return m.TotalCapacity
+	}
+	return 0
+}
+
+func (m *NodeUtilization) GetUsedCapacity() uint64 {
+	if m != nil {
+		return m.UsedCapacity
+	}
+	return 0
+}
+
+func (m *NodeUtilization) GetAttachedVolumes() int64 {
+	if m != nil {
+		return m.AttachedVolumes
+	}
+	return 0
+}
+
+// NodeInfo Information about a node
+type NodeInfo struct {
+	Id       string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Hostname string   `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Ips      []string `protobuf:"bytes,3,rep,name=ips,proto3" json:"ips,omitempty"`
+	Rack     string   `protobuf:"bytes,4,opt,name=rack,proto3" json:"rack,omitempty"`
+	Zone     string   `protobuf:"bytes,5,opt,name=zone,proto3" json:"zone,omitempty"`
+	Region   string   `protobuf:"bytes,6,opt,name=region,proto3" json:"region,omitempty"`
+	// One of Online, Offline or Degraded
+	Status string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	// Not set if the driver doesn't report utilization
+	Utilization *NodeUtilization `protobuf:"bytes,8,opt,name=utilization,proto3" json:"utilization,omitempty"`
+	// True if the driver on the node is in maintenance mode
+	Maintenance          bool     `protobuf:"varint,9,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NodeInfo) Reset()         { *m = NodeInfo{} }
+func (m *NodeInfo) String() string { return proto.CompactTextString(m) }
+func (*NodeInfo) ProtoMessage()    {}
+func (*NodeInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{2}
+}
+func (m *NodeInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NodeInfo.Unmarshal(m, b)
+}
+func (m *NodeInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NodeInfo.Marshal(b, m, deterministic)
+}
+func (dst *NodeInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeInfo.Merge(dst, src)
+}
+func (m *NodeInfo) XXX_Size() int {
+	return xxx_messageInfo_NodeInfo.Size(m)
+}
+func (m *NodeInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeInfo proto.InternalMessageInfo
+
+func (m *NodeInfo) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetIps() []string {
+	if m != nil {
+		return m.Ips
+	}
+	return nil
+}
+
+func (m *NodeInfo) GetRack() string {
+	if m != nil {
+		return m.Rack
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetZone() string {
+	if m != nil {
+		return m.Zone
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetRegion() string {
+	if m != nil {
+		return m.Region
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *NodeInfo) GetUtilization() *NodeUtilization {
+	if m != nil {
+		return m.Utilization
+	}
+	return nil
+}
+
+func (m *NodeInfo) GetMaintenance() bool {
+	if m != nil {
+		return m.Maintenance
+	}
+	return false
+}
+
+type InitRequest struct {
+	// JSON encoded config passed in to the driver
+	Config               []byte   `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InitRequest) Reset()         { *m = InitRequest{} }
+func (m *InitRequest) String() string { return proto.CompactTextString(m) }
+func (*InitRequest) ProtoMessage()    {}
+func (*InitRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{3}
+}
+func (m *InitRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InitRequest.Unmarshal(m, b)
+}
+func (m *InitRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InitRequest.Marshal(b, m, deterministic)
+}
+func (dst *InitRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InitRequest.Merge(dst, src)
+}
+func (m *InitRequest) XXX_Size() int {
+	return xxx_messageInfo_InitRequest.Size(m)
+}
+func (m *InitRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_InitRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InitRequest proto.InternalMessageInfo
+
+func (m *InitRequest) GetConfig() []byte {
+	if m != nil {
+		return m.Config
+	}
+	return nil
+}
+
+type InitResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InitResponse) Reset()         { *m = InitResponse{} }
+func (m *InitResponse) String() string { return proto.CompactTextString(m) }
+func (*InitResponse) ProtoMessage()    {}
+func (*InitResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{4}
+}
+func (m *InitResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InitResponse.Unmarshal(m, b)
+}
+func (m *InitResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InitResponse.Marshal(b, m, deterministic)
+}
+func (dst *InitResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InitResponse.Merge(dst, src)
+}
+func (m *InitResponse) XXX_Size() int {
+	return xxx_messageInfo_InitResponse.Size(m)
+}
+func (m *InitResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_InitResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InitResponse proto.InternalMessageInfo
+
+type StopRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StopRequest) Reset()         { *m = StopRequest{} }
+func (m *StopRequest) String() string { return proto.CompactTextString(m) }
+func (*StopRequest) ProtoMessage()    {}
+func (*StopRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{5}
+}
+func (m *StopRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StopRequest.Unmarshal(m, b)
+}
+func (m *StopRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StopRequest.Marshal(b, m, deterministic)
+}
+func (dst *StopRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StopRequest.Merge(dst, src)
+}
+func (m *StopRequest) XXX_Size() int {
+	return xxx_messageInfo_StopRequest.Size(m)
+}
+func (m *StopRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StopRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StopRequest proto.InternalMessageInfo
+
+type StopResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StopResponse) Reset()         { *m = StopResponse{} }
+func (m *StopResponse) String() string { return proto.CompactTextString(m) }
+func (*StopResponse) ProtoMessage()    {}
+func (*StopResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{6}
+}
+func (m *StopResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StopResponse.Unmarshal(m, b)
+}
+func (m *StopResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StopResponse.Marshal(b, m, deterministic)
+}
+func (dst *StopResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StopResponse.Merge(dst, src)
+}
+func (m *StopResponse) XXX_Size() int {
+	return xxx_messageInfo_StopResponse.Size(m)
+}
+func (m *StopResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StopResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StopResponse proto.InternalMessageInfo
+
+type InspectVolumeRequest struct {
+	VolumeId             string   `protobuf:"bytes,1,opt,name=volume_id,json=volumeId,proto3" json:"volume_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InspectVolumeRequest) Reset()         { *m = InspectVolumeRequest{} }
+func (m *InspectVolumeRequest) String() string { return proto.CompactTextString(m) }
+func (*InspectVolumeRequest) ProtoMessage()    {}
+func (*InspectVolumeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{7}
+}
+func (m *InspectVolumeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InspectVolumeRequest.Unmarshal(m, b)
+}
+func (m *InspectVolumeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InspectVolumeRequest.Marshal(b, m, deterministic)
+}
+func (dst *InspectVolumeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InspectVolumeRequest.Merge(dst, src)
+}
+func (m *InspectVolumeRequest) XXX_Size() int {
+	return xxx_messageInfo_InspectVolumeRequest.Size(m)
+}
+func (m *InspectVolumeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_InspectVolumeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InspectVolumeRequest proto.InternalMessageInfo
+
+func (m *InspectVolumeRequest) GetVolumeId() string {
+	if m != nil {
+		return m.VolumeId
+	}
+	return ""
+}
+
+type InspectVolumeResponse struct {
+	Volume               *VolumeInfo `protobuf:"bytes,1,opt,name=volume,proto3" json:"volume,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *InspectVolumeResponse) Reset()         { *m = InspectVolumeResponse{} }
+func (m *InspectVolumeResponse) String() string { return proto.CompactTextString(m) }
+func (*InspectVolumeResponse) ProtoMessage()    {}
+func (*InspectVolumeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{8}
+}
+func (m *InspectVolumeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InspectVolumeResponse.Unmarshal(m, b)
+}
+func (m *InspectVolumeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InspectVolumeResponse.Marshal(b, m, deterministic)
+}
+func (dst *InspectVolumeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InspectVolumeResponse.Merge(dst, src)
+}
+func (m *InspectVolumeResponse) XXX_Size() int {
+	return xxx_messageInfo_InspectVolumeResponse.Size(m)
+}
+func (m *InspectVolumeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_InspectVolumeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InspectVolumeResponse proto.InternalMessageInfo
+
+func (m *InspectVolumeResponse) GetVolume() *VolumeInfo {
+	if m != nil {
+		return m.Volume
+	}
+	return nil
+}
+
+type ResizeVolumeRequest struct {
+	VolumeId string `protobuf:"bytes,1,opt,name=volume_id,json=volumeId,proto3" json:"volume_id,omitempty"`
+	// New size of the volume in bytes
+	SizeBytes            uint64   `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResizeVolumeRequest) Reset()         { *m = ResizeVolumeRequest{} }
+func (m *ResizeVolumeRequest) String() string { return proto.CompactTextString(m) }
+func (*ResizeVolumeRequest) ProtoMessage()    {}
+func (*ResizeVolumeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{9}
+}
+func (m *ResizeVolumeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResizeVolumeRequest.Unmarshal(m, b)
+}
+func (m *ResizeVolumeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResizeVolumeRequest.Marshal(b, m, deterministic)
+}
+func (dst *ResizeVolumeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResizeVolumeRequest.Merge(dst, src)
+}
+func (m *ResizeVolumeRequest) XXX_Size() int {
+	return xxx_messageInfo_ResizeVolumeRequest.Size(m)
+}
+func (m *ResizeVolumeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResizeVolumeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResizeVolumeRequest proto.InternalMessageInfo
+
+func (m *ResizeVolumeRequest) GetVolumeId() string {
+	if m != nil {
+		return m.VolumeId
+	}
+	return ""
+}
+
+func (m *ResizeVolumeRequest) GetSizeBytes() uint64 {
+	if m != nil {
+		return m.SizeBytes
+	}
+	return 0
+}
+
+type ResizeVolumeResponse struct {
+	Volume               *VolumeInfo `protobuf:"bytes,1,opt,name=volume,proto3" json:"volume,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ResizeVolumeResponse) Reset()         { *m = ResizeVolumeResponse{} }
+func (m *ResizeVolumeResponse) String() string { return proto.CompactTextString(m) }
+func (*ResizeVolumeResponse) ProtoMessage()    {}
+func (*ResizeVolumeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{10}
+}
+func (m *ResizeVolumeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResizeVolumeResponse.Unmarshal(m, b)
+}
+func (m *ResizeVolumeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResizeVolumeResponse.Marshal(b, m, deterministic)
+}
+func (dst *ResizeVolumeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResizeVolumeResponse.Merge(dst, src)
+}
+func (m *ResizeVolumeResponse) XXX_Size() int {
+	return xxx_messageInfo_ResizeVolumeResponse.Size(m)
+}
+func (m *ResizeVolumeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResizeVolumeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResizeVolumeResponse proto.InternalMessageInfo
+
+func (m *ResizeVolumeResponse) GetVolume() *VolumeInfo {
+	if m != nil {
+		return m.Volume
+	}
+	return nil
+}
+
+type GetNodesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetNodesRequest) Reset()         { *m = GetNodesRequest{} }
+func (m *GetNodesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetNodesRequest) ProtoMessage()    {}
+func (*GetNodesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{11}
+}
+func (m *GetNodesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetNodesRequest.Unmarshal(m, b)
+}
+func (m *GetNodesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetNodesRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetNodesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetNodesRequest.Merge(dst, src)
+}
+func (m *GetNodesRequest) XXX_Size() int {
+	return xxx_messageInfo_GetNodesRequest.Size(m)
+}
+func (m *GetNodesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetNodesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetNodesRequest proto.InternalMessageInfo
+
+type GetNodesResponse struct {
+	Nodes                []*NodeInfo `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetNodesResponse) Reset()         { *m = GetNodesResponse{} }
+func (m *GetNodesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetNodesResponse) ProtoMessage()    {}
+func (*GetNodesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{12}
+}
+func (m *GetNodesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetNodesResponse.Unmarshal(m, b)
+}
+func (m *GetNodesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetNodesResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetNodesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetNodesResponse.Merge(dst, src)
+}
+func (m *GetNodesResponse) XXX_Size() int {
+	return xxx_messageInfo_GetNodesResponse.Size(m)
+}
+func (m *GetNodesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetNodesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetNodesResponse proto.InternalMessageInfo
+
+func (m *GetNodesResponse) GetNodes() []*NodeInfo {
+	if m != nil {
+		return m.Nodes
+	}
+	return nil
+}
+
+type MaintenanceRequest struct {
+	Node                 *NodeInfo `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *MaintenanceRequest) Reset()         { *m = MaintenanceRequest{} }
+func (m *MaintenanceRequest) String() string { return proto.CompactTextString(m) }
+func (*MaintenanceRequest) ProtoMessage()    {}
+func (*MaintenanceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{13}
+}
+func (m *MaintenanceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MaintenanceRequest.Unmarshal(m, b)
+}
+func (m *MaintenanceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MaintenanceRequest.Marshal(b, m, deterministic)
+}
+func (dst *MaintenanceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MaintenanceRequest.Merge(dst, src)
+}
+func (m *MaintenanceRequest) XXX_Size() int {
+	return xxx_messageInfo_MaintenanceRequest.Size(m)
+}
+func (m *MaintenanceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MaintenanceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MaintenanceRequest proto.InternalMessageInfo
+
+func (m *MaintenanceRequest) GetNode() *NodeInfo {
+	if m != nil {
+		return m.Node
+	}
+	return nil
+}
+
+type MaintenanceResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MaintenanceResponse) Reset()         { *m = MaintenanceResponse{} }
+func (m *MaintenanceResponse) String() string { return proto.CompactTextString(m) }
+func (*MaintenanceResponse) ProtoMessage()    {}
+func (*MaintenanceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{14}
+}
+func (m *MaintenanceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MaintenanceResponse.Unmarshal(m, b)
+}
+func (m *MaintenanceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MaintenanceResponse.Marshal(b, m, deterministic)
+}
+func (dst *MaintenanceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MaintenanceResponse.Merge(dst, src)
+}
+func (m *MaintenanceResponse) XXX_Size() int {
+	return xxx_messageInfo_MaintenanceResponse.Size(m)
+}
+func (m *MaintenanceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MaintenanceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MaintenanceResponse proto.InternalMessageInfo
+
+type GetPodVolumesRequest struct {
+	// JSON encoded v1.PodSpec
+	PodSpec              []byte   `protobuf:"bytes,1,opt,name=pod_spec,json=podSpec,proto3" json:"pod_spec,omitempty"`
+	Namespace            string   `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPodVolumesRequest) Reset()         { *m = GetPodVolumesRequest{} }
+func (m *GetPodVolumesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetPodVolumesRequest) ProtoMessage()    {}
+func (*GetPodVolumesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{15}
+}
+func (m *GetPodVolumesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPodVolumesRequest.Unmarshal(m, b)
+}
+func (m *GetPodVolumesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPodVolumesRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetPodVolumesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPodVolumesRequest.Merge(dst, src)
+}
+func (m *GetPodVolumesRequest) XXX_Size() int {
+	return xxx_messageInfo_GetPodVolumesRequest.Size(m)
+}
+func (m *GetPodVolumesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPodVolumesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPodVolumesRequest proto.InternalMessageInfo
+
+func (m *GetPodVolumesRequest) GetPodSpec() []byte {
+	if m != nil {
+		return m.PodSpec
+	}
+	return nil
+}
+
+func (m *GetPodVolumesRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type GetPodVolumesResponse struct {
+	Volumes []*VolumeInfo `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	// Name of a PVC owned by the driver which is still pending. Volumes
+	// aren't returned if set
+	PendingPvc           string   `protobuf:"bytes,2,opt,name=pending_pvc,json=pendingPvc,proto3" json:"pending_pvc,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetPodVolumesResponse) Reset()         { *m = GetPodVolumesResponse{} }
+func (m *GetPodVolumesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetPodVolumesResponse) ProtoMessage()    {}
+func (*GetPodVolumesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{16}
+}
+func (m *GetPodVolumesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetPodVolumesResponse.Unmarshal(m, b)
+}
+func (m *GetPodVolumesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetPodVolumesResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetPodVolumesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetPodVolumesResponse.Merge(dst, src)
+}
+func (m *GetPodVolumesResponse) XXX_Size() int {
+	return xxx_messageInfo_GetPodVolumesResponse.Size(m)
+}
+func (m *GetPodVolumesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetPodVolumesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetPodVolumesResponse proto.InternalMessageInfo
+
+func (m *GetPodVolumesResponse) GetVolumes() []*VolumeInfo {
+	if m != nil {
+		return m.Volumes
+	}
+	return nil
+}
+
+func (m *GetPodVolumesResponse) GetPendingPvc() string {
+	if m != nil {
+		return m.PendingPvc
+	}
+	return ""
+}
+
+type GetVolumeClaimTemplatesRequest struct {
+	// JSON encoded v1.PersistentVolumeClaim for each template
+	Templates            [][]byte `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetVolumeClaimTemplatesRequest) Reset()         { *m = GetVolumeClaimTemplatesRequest{} }
+func (m *GetVolumeClaimTemplatesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetVolumeClaimTemplatesRequest) ProtoMessage()    {}
+func (*GetVolumeClaimTemplatesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{17}
+}
+func (m *GetVolumeClaimTemplatesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetVolumeClaimTemplatesRequest.Unmarshal(m, b)
+}
+func (m *GetVolumeClaimTemplatesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetVolumeClaimTemplatesRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetVolumeClaimTemplatesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetVolumeClaimTemplatesRequest.Merge(dst, src)
+}
+func (m *GetVolumeClaimTemplatesRequest) XXX_Size() int {
+	return xxx_messageInfo_GetVolumeClaimTemplatesRequest.Size(m)
+}
+func (m *GetVolumeClaimTemplatesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetVolumeClaimTemplatesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetVolumeClaimTemplatesRequest proto.InternalMessageInfo
+
+func (m *GetVolumeClaimTemplatesRequest) GetTemplates() [][]byte {
+	if m != nil {
+		return m.Templates
+	}
+	return nil
+}
+
+type GetVolumeClaimTemplatesResponse struct {
+	Templates            [][]byte `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetVolumeClaimTemplatesResponse) Reset()         { *m = GetVolumeClaimTemplatesResponse{} }
+func (m *GetVolumeClaimTemplatesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetVolumeClaimTemplatesResponse) ProtoMessage()    {}
+func (*GetVolumeClaimTemplatesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{18}
+}
+func (m *GetVolumeClaimTemplatesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetVolumeClaimTemplatesResponse.Unmarshal(m, b)
+}
+func (m *GetVolumeClaimTemplatesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetVolumeClaimTemplatesResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetVolumeClaimTemplatesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetVolumeClaimTemplatesResponse.Merge(dst, src)
+}
+func (m *GetVolumeClaimTemplatesResponse) XXX_Size() int {
+	return xxx_messageInfo_GetVolumeClaimTemplatesResponse.Size(m)
+}
+func (m *GetVolumeClaimTemplatesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetVolumeClaimTemplatesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetVolumeClaimTemplatesResponse proto.InternalMessageInfo
+
+func (m *GetVolumeClaimTemplatesResponse) GetTemplates() [][]byte {
+	if m != nil {
+		return m.Templates
+	}
+	return nil
+}
+
+type OwnsPVCRequest struct {
+	// JSON encoded v1.PersistentVolumeClaim
+	Pvc                  []byte   `protobuf:"bytes,1,opt,name=pvc,proto3" json:"pvc,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OwnsPVCRequest) Reset()         { *m = OwnsPVCRequest{} }
+func (m *OwnsPVCRequest) String() string { return proto.CompactTextString(m) }
+func (*OwnsPVCRequest) ProtoMessage()    {}
+func (*OwnsPVCRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{19}
+}
+func (m *OwnsPVCRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OwnsPVCRequest.Unmarshal(m, b)
+}
+func (m *OwnsPVCRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OwnsPVCRequest.Marshal(b, m, deterministic)
+}
+func (dst *OwnsPVCRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OwnsPVCRequest.Merge(dst, src)
+}
+func (m *OwnsPVCRequest) XXX_Size() int {
+	return xxx_messageInfo_OwnsPVCRequest.Size(m)
+}
+func (m *OwnsPVCRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_OwnsPVCRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OwnsPVCRequest proto.InternalMessageInfo
+
+func (m *OwnsPVCRequest) GetPvc() []byte {
+	if m != nil {
+		return m.Pvc
+	}
+	return nil
+}
+
+type OwnsPVCResponse struct {
+	Owns                 bool     `protobuf:"varint,1,opt,name=owns,proto3" json:"owns,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OwnsPVCResponse) Reset()         { *m = OwnsPVCResponse{} }
+func (m *OwnsPVCResponse) String() string { return proto.CompactTextString(m) }
+func (*OwnsPVCResponse) ProtoMessage()    {}
+func (*OwnsPVCResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{20}
+}
+func (m *OwnsPVCResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OwnsPVCResponse.Unmarshal(m, b)
+}
+func (m *OwnsPVCResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OwnsPVCResponse.Marshal(b, m, deterministic)
+}
+func (dst *OwnsPVCResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OwnsPVCResponse.Merge(dst, src)
+}
+func (m *OwnsPVCResponse) XXX_Size() int {
+	return xxx_messageInfo_OwnsPVCResponse.Size(m)
+}
+func (m *OwnsPVCResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OwnsPVCResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OwnsPVCResponse proto.InternalMessageInfo
+
+func (m *OwnsPVCResponse) GetOwns() bool {
+	if m != nil {
+		return m.Owns
+	}
+	return false
+}
+
+type GetSnapshotTypeRequest struct {
+	// JSON encoded VolumeSnapshot
+	Snapshot             []byte   `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSnapshotTypeRequest) Reset()         { *m = GetSnapshotTypeRequest{} }
+func (m *GetSnapshotTypeRequest) String() string { return proto.CompactTextString(m) }
+func (*GetSnapshotTypeRequest) ProtoMessage()    {}
+func (*GetSnapshotTypeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{21}
+}
+func (m *GetSnapshotTypeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSnapshotTypeRequest.Unmarshal(m, b)
+}
+func (m *GetSnapshotTypeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSnapshotTypeRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetSnapshotTypeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSnapshotTypeRequest.Merge(dst, src)
+}
+func (m *GetSnapshotTypeRequest) XXX_Size() int {
+	return xxx_messageInfo_GetSnapshotTypeRequest.Size(m)
+}
+func (m *GetSnapshotTypeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSnapshotTypeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSnapshotTypeRequest proto.InternalMessageInfo
+
+func (m *GetSnapshotTypeRequest) GetSnapshot() []byte {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
+type GetSnapshotTypeResponse struct {
+	Type                 string   `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSnapshotTypeResponse) Reset()         { *m = GetSnapshotTypeResponse{} }
+func (m *GetSnapshotTypeResponse) String() string { return proto.CompactTextString(m) }
+func (*GetSnapshotTypeResponse) ProtoMessage()    {}
+func (*GetSnapshotTypeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{22}
+}
+func (m *GetSnapshotTypeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSnapshotTypeResponse.Unmarshal(m, b)
+}
+func (m *GetSnapshotTypeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSnapshotTypeResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetSnapshotTypeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSnapshotTypeResponse.Merge(dst, src)
+}
+func (m *GetSnapshotTypeResponse) XXX_Size() int {
+	return xxx_messageInfo_GetSnapshotTypeResponse.Size(m)
+}
+func (m *GetSnapshotTypeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSnapshotTypeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSnapshotTypeResponse proto.InternalMessageInfo
+
+func (m *GetSnapshotTypeResponse) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+type GetCapabilitiesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCapabilitiesRequest) Reset()         { *m = GetCapabilitiesRequest{} }
+func (m *GetCapabilitiesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetCapabilitiesRequest) ProtoMessage()    {}
+func (*GetCapabilitiesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{23}
+}
+func (m *GetCapabilitiesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCapabilitiesRequest.Unmarshal(m, b)
+}
+func (m *GetCapabilitiesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCapabilitiesRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetCapabilitiesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCapabilitiesRequest.Merge(dst, src)
+}
+func (m *GetCapabilitiesRequest) XXX_Size() int {
+	return xxx_messageInfo_GetCapabilitiesRequest.Size(m)
+}
+func (m *GetCapabilitiesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCapabilitiesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCapabilitiesRequest proto.InternalMessageInfo
+
+type GetCapabilitiesResponse struct {
+	Snapshots      bool `protobuf:"varint,1,opt,name=snapshots,proto3" json:"snapshots,omitempty"`
+	CloudSnapshots bool `protobuf:"varint,2,opt,name=cloud_snapshots,json=cloudSnapshots,proto3" json:"cloud_snapshots,omitempty"`
+	GroupSnapshots bool `protobuf:"varint,3,opt,name=group_snapshots,json=groupSnapshots,proto3" json:"group_snapshots,omitempty"`
+	ClusterPair    bool `protobuf:"varint,4,opt,name=cluster_pair,json=clusterPair,proto3" json:"cluster_pair,omitempty"`
+	Migration      bool `protobuf:"varint,5,opt,name=migration,proto3" json:"migration,omitempty"`
+	// Levels of the topology reported for nodes: rack, zone or region
+	TopologyLevels       []string `protobuf:"bytes,6,rep,name=topology_levels,json=topologyLevels,proto3" json:"topology_levels,omitempty"`
+	Resize               bool     `protobuf:"varint,7,opt,name=resize,proto3" json:"resize,omitempty"`
+	Maintenance          bool     `protobuf:"varint,8,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCapabilitiesResponse) Reset()         { *m = GetCapabilitiesResponse{} }
+func (m *GetCapabilitiesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetCapabilitiesResponse) ProtoMessage()    {}
+func (*GetCapabilitiesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{24}
+}
+func (m *GetCapabilitiesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCapabilitiesResponse.Unmarshal(m, b)
+}
+func (m *GetCapabilitiesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCapabilitiesResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetCapabilitiesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCapabilitiesResponse.Merge(dst, src)
+}
+func (m *GetCapabilitiesResponse) XXX_Size() int {
+	return xxx_messageInfo_GetCapabilitiesResponse.Size(m)
+}
+func (m *GetCapabilitiesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCapabilitiesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCapabilitiesResponse proto.InternalMessageInfo
+
+func (m *GetCapabilitiesResponse) GetSnapshots() bool {
+	if m != nil {
+		return m.Snapshots
+	}
+	return false
+}
+
+func (m *GetCapabilitiesResponse) GetCloudSnapshots() bool {
+	if m != nil {
+		return m.CloudSnapshots
+	}
+	return false
+}
+
+func (m *GetCapabilitiesResponse) GetGroupSnapshots() bool {
+	if m != nil {
+		return m.GroupSnapshots
+	}
+	return false
+}
+
+func (m *GetCapabilitiesResponse) GetClusterPair() bool {
+	if m != nil {
+		return m.ClusterPair
+	}
+	return false
+}
+
+func (m *GetCapabilitiesResponse) GetMigration() bool {
+	if m != nil {
+		return m.Migration
+	}
+	return false
+}
+
+func (m *GetCapabilitiesResponse) GetTopologyLevels() []string {
+	if m != nil {
+		return m.TopologyLevels
+	}
+	return nil
+}
+
+func (m *GetCapabilitiesResponse) GetResize() bool {
+	if m != nil {
+		return m.Resize
+	}
+	return false
+}
+
+func (m *GetCapabilitiesResponse) GetMaintenance() bool {
+	if m != nil {
+		return m.Maintenance
+	}
+	return false
+}
+
+type GroupSnapshotRequest struct {
+	// JSON encoded GroupVolumeSnapshot
+	GroupSnapshot        []byte   `protobuf:"bytes,1,opt,name=group_snapshot,json=groupSnapshot,proto3" json:"group_snapshot,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GroupSnapshotRequest) Reset()         { *m = GroupSnapshotRequest{} }
+func (m *GroupSnapshotRequest) String() string { return proto.CompactTextString(m) }
+func (*GroupSnapshotRequest) ProtoMessage()    {}
+func (*GroupSnapshotRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{25}
+}
+func (m *GroupSnapshotRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupSnapshotRequest.Unmarshal(m, b)
+}
+func (m *GroupSnapshotRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupSnapshotRequest.Marshal(b, m, deterministic)
+}
+func (dst *GroupSnapshotRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupSnapshotRequest.Merge(dst, src)
+}
+func (m *GroupSnapshotRequest) XXX_Size() int {
+	return xxx_messageInfo_GroupSnapshotRequest.Size(m)
+}
+func (m *GroupSnapshotRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupSnapshotRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupSnapshotRequest proto.InternalMessageInfo
+
+func (m *GroupSnapshotRequest) GetGroupSnapshot() []byte {
+	if m != nil {
+		return m.GroupSnapshot
+	}
+	return nil
+}
+
+type GroupSnapshotResponse struct {
+	// JSON encoded VolumeSnapshotStatus for each snapshot in the group
+	Snapshots            [][]byte `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GroupSnapshotResponse) Reset()         { *m = GroupSnapshotResponse{} }
+func (m *GroupSnapshotResponse) String() string { return proto.CompactTextString(m) }
+func (*GroupSnapshotResponse) ProtoMessage()    {}
+func (*GroupSnapshotResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{26}
+}
+func (m *GroupSnapshotResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupSnapshotResponse.Unmarshal(m, b)
+}
+func (m *GroupSnapshotResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupSnapshotResponse.Marshal(b, m, deterministic)
+}
+func (dst *GroupSnapshotResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupSnapshotResponse.Merge(dst, src)
+}
+func (m *GroupSnapshotResponse) XXX_Size() int {
+	return xxx_messageInfo_GroupSnapshotResponse.Size(m)
+}
+func (m *GroupSnapshotResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupSnapshotResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupSnapshotResponse proto.InternalMessageInfo
+
+func (m *GroupSnapshotResponse) GetSnapshots() [][]byte {
+	if m != nil {
+		return m.Snapshots
+	}
+	return nil
+}
+
+type DeleteGroupSnapshotResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteGroupSnapshotResponse) Reset()         { *m = DeleteGroupSnapshotResponse{} }
+func (m *DeleteGroupSnapshotResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteGroupSnapshotResponse) ProtoMessage()    {}
+func (*DeleteGroupSnapshotResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{27}
+}
+func (m *DeleteGroupSnapshotResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteGroupSnapshotResponse.Unmarshal(m, b)
+}
+func (m *DeleteGroupSnapshotResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteGroupSnapshotResponse.Marshal(b, m, deterministic)
+}
+func (dst *DeleteGroupSnapshotResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteGroupSnapshotResponse.Merge(dst, src)
+}
+func (m *DeleteGroupSnapshotResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteGroupSnapshotResponse.Size(m)
+}
+func (m *DeleteGroupSnapshotResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteGroupSnapshotResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteGroupSnapshotResponse proto.InternalMessageInfo
+
+type ClusterPairRequest struct {
+	// JSON encoded ClusterPair
+	ClusterPair          []byte   `protobuf:"bytes,1,opt,name=cluster_pair,json=clusterPair,proto3" json:"cluster_pair,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterPairRequest) Reset()         { *m = ClusterPairRequest{} }
+func (m *ClusterPairRequest) String() string { return proto.CompactTextString(m) }
+func (*ClusterPairRequest) ProtoMessage()    {}
+func (*ClusterPairRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{28}
+}
+func (m *ClusterPairRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClusterPairRequest.Unmarshal(m, b)
+}
+func (m *ClusterPairRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClusterPairRequest.Marshal(b, m, deterministic)
+}
+func (dst *ClusterPairRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterPairRequest.Merge(dst, src)
+}
+func (m *ClusterPairRequest) XXX_Size() int {
+	return xxx_messageInfo_ClusterPairRequest.Size(m)
+}
+func (m *ClusterPairRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterPairRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterPairRequest proto.InternalMessageInfo
+
+func (m *ClusterPairRequest) GetClusterPair() []byte {
+	if m != nil {
+		return m.ClusterPair
+	}
+	return nil
+}
+
+type CreatePairResponse struct {
+	RemoteId             string   `protobuf:"bytes,1,opt,name=remote_id,json=remoteId,proto3" json:"remote_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreatePairResponse) Reset()         { *m = CreatePairResponse{} }
+func (m *CreatePairResponse) String() string { return proto.CompactTextString(m) }
+func (*CreatePairResponse) ProtoMessage()    {}
+func (*CreatePairResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{29}
+}
+func (m *CreatePairResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreatePairResponse.Unmarshal(m, b)
+}
+func (m *CreatePairResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreatePairResponse.Marshal(b, m, deterministic)
+}
+func (dst *CreatePairResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreatePairResponse.Merge(dst, src)
+}
+func (m *CreatePairResponse) XXX_Size() int {
+	return xxx_messageInfo_CreatePairResponse.Size(m)
+}
+func (m *CreatePairResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreatePairResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreatePairResponse proto.InternalMessageInfo
+
+func (m *CreatePairResponse) GetRemoteId() string {
+	if m != nil {
+		return m.RemoteId
+	}
+	return ""
+}
+
+type DeletePairResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeletePairResponse) Reset()         { *m = DeletePairResponse{} }
+func (m *DeletePairResponse) String() string { return proto.CompactTextString(m) }
+func (*DeletePairResponse) ProtoMessage()    {}
+func (*DeletePairResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{30}
+}
+func (m *DeletePairResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeletePairResponse.Unmarshal(m, b)
+}
+func (m *DeletePairResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeletePairResponse.Marshal(b, m, deterministic)
+}
+func (dst *DeletePairResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeletePairResponse.Merge(dst, src)
+}
+func (m *DeletePairResponse) XXX_Size() int {
+	return xxx_messageInfo_DeletePairResponse.Size(m)
+}
+func (m *DeletePairResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeletePairResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeletePairResponse proto.InternalMessageInfo
+
+type MigrationRequest struct {
+	// JSON encoded Migration
+	Migration            []byte   `protobuf:"bytes,1,opt,name=migration,proto3" json:"migration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MigrationRequest) Reset()         { *m = MigrationRequest{} }
+func (m *MigrationRequest) String() string { return proto.CompactTextString(m) }
+func (*MigrationRequest) ProtoMessage()    {}
+func (*MigrationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{31}
+}
+func (m *MigrationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MigrationRequest.Unmarshal(m, b)
+}
+func (m *MigrationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MigrationRequest.Marshal(b, m, deterministic)
+}
+func (dst *MigrationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MigrationRequest.Merge(dst, src)
+}
+func (m *MigrationRequest) XXX_Size() int {
+	return xxx_messageInfo_MigrationRequest.Size(m)
+}
+func (m *MigrationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MigrationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MigrationRequest proto.InternalMessageInfo
+
+func (m *MigrationRequest) GetMigration() []byte {
+	if m != nil {
+		return m.Migration
+	}
+	return nil
+}
+
+type MigrationResponse struct {
+	// JSON encoded VolumeInfo for each volume being migrated
+	Volumes              [][]byte `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MigrationResponse) Reset()         { *m = MigrationResponse{} }
+func (m *MigrationResponse) String() string { return proto.CompactTextString(m) }
+func (*MigrationResponse) ProtoMessage()    {}
+func (*MigrationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{32}
+}
+func (m *MigrationResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MigrationResponse.Unmarshal(m, b)
+}
+func (m *MigrationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MigrationResponse.Marshal(b, m, deterministic)
+}
+func (dst *MigrationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MigrationResponse.Merge(dst, src)
+}
+func (m *MigrationResponse) XXX_Size() int {
+	return xxx_messageInfo_MigrationResponse.Size(m)
+}
+func (m *MigrationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_MigrationResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MigrationResponse proto.InternalMessageInfo
+
+func (m *MigrationResponse) GetVolumes() [][]byte {
+	if m != nil {
+		return m.Volumes
+	}
+	return nil
+}
+
+type CancelMigrationResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelMigrationResponse) Reset()         { *m = CancelMigrationResponse{} }
+func (m *CancelMigrationResponse) String() string { return proto.CompactTextString(m) }
+func (*CancelMigrationResponse) ProtoMessage()    {}
+func (*CancelMigrationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{33}
+}
+func (m *CancelMigrationResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelMigrationResponse.Unmarshal(m, b)
+}
+func (m *CancelMigrationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelMigrationResponse.Marshal(b, m, deterministic)
+}
+func (dst *CancelMigrationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelMigrationResponse.Merge(dst, src)
+}
+func (m *CancelMigrationResponse) XXX_Size() int {
+	return xxx_messageInfo_CancelMigrationResponse.Size(m)
+}
+func (m *CancelMigrationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelMigrationResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelMigrationResponse proto.InternalMessageInfo
+
+type UpdateMigratedPersistentVolumeSpecRequest struct {
+	// JSON encoded PersistentVolume
+	Object               []byte   `protobuf:"bytes,1,opt,name=object,proto3" json:"object,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateMigratedPersistentVolumeSpecRequest) Reset() {
+	*m = UpdateMigratedPersistentVolumeSpecRequest{}
+}
+func (m *UpdateMigratedPersistentVolumeSpecRequest) String() string {
+	return proto.CompactTextString(m)
+}
+func (*UpdateMigratedPersistentVolumeSpecRequest) ProtoMessage() {}
+func (*UpdateMigratedPersistentVolumeSpecRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{34}
+}
+func (m *UpdateMigratedPersistentVolumeSpecRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateMigratedPersistentVolumeSpecRequest.Unmarshal(m, b)
+}
+func (m *UpdateMigratedPersistentVolumeSpecRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateMigratedPersistentVolumeSpecRequest.Marshal(b, m, deterministic)
+}
+func (dst *UpdateMigratedPersistentVolumeSpecRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateMigratedPersistentVolumeSpecRequest.Merge(dst, src)
+}
+func (m *UpdateMigratedPersistentVolumeSpecRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateMigratedPersistentVolumeSpecRequest.Size(m)
+}
+func (m *UpdateMigratedPersistentVolumeSpecRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateMigratedPersistentVolumeSpecRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateMigratedPersistentVolumeSpecRequest proto.InternalMessageInfo
+
+func (m *UpdateMigratedPersistentVolumeSpecRequest) GetObject() []byte {
+	if m != nil {
+		return m.Object
+	}
+	return nil
+}
+
+type UpdateMigratedPersistentVolumeSpecResponse struct {
+	Object               []byte   `protobuf:"bytes,1,opt,name=object,proto3" json:"object,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateMigratedPersistentVolumeSpecResponse) Reset() {
+	*m = UpdateMigratedPersistentVolumeSpecResponse{}
+}
+func (m *UpdateMigratedPersistentVolumeSpecResponse) String() string {
+	return proto.CompactTextString(m)
+}
+func (*UpdateMigratedPersistentVolumeSpecResponse) ProtoMessage() {}
+func (*UpdateMigratedPersistentVolumeSpecResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_plugin_f0d0db66ffed9513, []int{35}
+}
+func (m *UpdateMigratedPersistentVolumeSpecResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateMigratedPersistentVolumeSpecResponse.Unmarshal(m, b)
+}
+func (m *UpdateMigratedPersistentVolumeSpecResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateMigratedPersistentVolumeSpecResponse.Marshal(b, m, deterministic)
+}
+func (dst *UpdateMigratedPersistentVolumeSpecResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateMigratedPersistentVolumeSpecResponse.Merge(dst, src)
+}
+func (m *UpdateMigratedPersistentVolumeSpecResponse) XXX_Size() int {
+	return xxx_messageInfo_UpdateMigratedPersistentVolumeSpecResponse.Size(m)
+}
+func (m *UpdateMigratedPersistentVolumeSpecResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateMigratedPersistentVolumeSpecResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateMigratedPersistentVolumeSpecResponse proto.InternalMessageInfo
+
+func (m *UpdateMigratedPersistentVolumeSpecResponse) GetObject() []byte {
+	if m != nil {
+		return m.Object
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*VolumeInfo)(nil), "stork.plugin.VolumeInfo")
+	proto.RegisterMapType((map[string]string)(nil), "stork.plugin.VolumeInfo.LabelsEntry")
+	proto.RegisterType((*NodeUtilization)(nil), "stork.plugin.NodeUtilization")
+	proto.RegisterType((*NodeInfo)(nil), "stork.plugin.NodeInfo")
+	proto.RegisterType((*InitRequest)(nil), "stork.plugin.InitRequest")
+	proto.RegisterType((*InitResponse)(nil), "stork.plugin.InitResponse")
+	proto.RegisterType((*StopRequest)(nil), "stork.plugin.StopRequest")
+	proto.RegisterType((*StopResponse)(nil), "stork.plugin.StopResponse")
+	proto.RegisterType((*InspectVolumeRequest)(nil), "stork.plugin.InspectVolumeRequest")
+	proto.RegisterType((*InspectVolumeResponse)(nil), "stork.plugin.InspectVolumeResponse")
+	proto.RegisterType((*ResizeVolumeRequest)(nil), "stork.plugin.ResizeVolumeRequest")
+	proto.RegisterType((*ResizeVolumeResponse)(nil), "stork.plugin.ResizeVolumeResponse")
+	proto.RegisterType((*GetNodesRequest)(nil), "stork.plugin.GetNodesRequest")
+	proto.RegisterType((*GetNodesResponse)(nil), "stork.plugin.GetNodesResponse")
+	proto.RegisterType((*MaintenanceRequest)(nil), "stork.plugin.MaintenanceRequest")
+	proto.RegisterType((*MaintenanceResponse)(nil), "stork.plugin.MaintenanceResponse")
+	proto.RegisterType((*GetPodVolumesRequest)(nil), "stork.plugin.GetPodVolumesRequest")
+	proto.RegisterType((*GetPodVolumesResponse)(nil), "stork.plugin.GetPodVolumesResponse")
+	proto.RegisterType((*GetVolumeClaimTemplatesRequest)(nil), "stork.plugin.GetVolumeClaimTemplatesRequest")
+	proto.RegisterType((*GetVolumeClaimTemplatesResponse)(nil), "stork.plugin.GetVolumeClaimTemplatesResponse")
+	proto.RegisterType((*OwnsPVCRequest)(nil), "stork.plugin.OwnsPVCRequest")
+	proto.RegisterType((*OwnsPVCResponse)(nil), "stork.plugin.OwnsPVCResponse")
+	proto.RegisterType((*GetSnapshotTypeRequest)(nil), "stork.plugin.GetSnapshotTypeRequest")
+	proto.RegisterType((*GetSnapshotTypeResponse)(nil), "stork.plugin.GetSnapshotTypeResponse")
+	proto.RegisterType((*GetCapabilitiesRequest)(nil), "stork.plugin.GetCapabilitiesRequest")
+	proto.RegisterType((*GetCapabilitiesResponse)(nil), "stork.plugin.GetCapabilitiesResponse")
+	proto.RegisterType((*GroupSnapshotRequest)(nil), "stork.plugin.GroupSnapshotRequest")
+	proto.RegisterType((*GroupSnapshotResponse)(nil), "stork.plugin.GroupSnapshotResponse")
+	proto.RegisterType((*DeleteGroupSnapshotResponse)(nil), "stork.plugin.DeleteGroupSnapshotResponse")
+	proto.RegisterType((*ClusterPairRequest)(nil), "stork.plugin.ClusterPairRequest")
+	proto.RegisterType((*CreatePairResponse)(nil), "stork.plugin.CreatePairResponse")
+	proto.RegisterType((*DeletePairResponse)(nil), "stork.plugin.DeletePairResponse")
+	proto.RegisterType((*MigrationRequest)(nil), "stork.plugin.MigrationRequest")
+	proto.RegisterType((*MigrationResponse)(nil), "stork.plugin.MigrationResponse")
+	proto.RegisterType((*CancelMigrationResponse)(nil), "stork.plugin.CancelMigrationResponse")
+	proto.RegisterType((*UpdateMigratedPersistentVolumeSpecRequest)(nil), "stork.plugin.UpdateMigratedPersistentVolumeSpecRequest")
+	proto.RegisterType((*UpdateMigratedPersistentVolumeSpecResponse)(nil), "stork.plugin.UpdateMigratedPersistentVolumeSpecResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// VolumeDriverClient is the client API for VolumeDriver service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type VolumeDriverClient interface {
+	// Init Initializes the driver with the config passed in to stork
+	Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*InitResponse, error)
+	// Stop Stops the driver
+	Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StopResponse, error)
+	// InspectVolume Returns information about a volume. Should return the
+	// NOT_FOUND code if the volume doesn't exist
+	InspectVolume(ctx context.Context, in *InspectVolumeRequest, opts ...grpc.CallOption) (*InspectVolumeResponse, error)
+	// ResizeVolume Resizes a volume to the given size. Should return the
+	// NOT_FOUND code if the volume doesn't exist
+	ResizeVolume(ctx context.Context, in *ResizeVolumeRequest, opts ...grpc.CallOption) (*ResizeVolumeResponse, error)
+	// GetNodes Returns the nodes on which the driver is running
+	GetNodes(ctx context.Context, in *GetNodesRequest, opts ...grpc.CallOption) (*GetNodesResponse, error)
+	// EnterMaintenance Puts the driver on a node into maintenance mode
+	EnterMaintenance(ctx context.Context, in *MaintenanceRequest, opts ...grpc.CallOption) (*MaintenanceResponse, error)
+	// ExitMaintenance Takes the driver on a node out of maintenance mode
+	ExitMaintenance(ctx context.Context, in *MaintenanceRequest, opts ...grpc.CallOption) (*MaintenanceResponse, error)
+	// GetPodVolumes Returns the volumes from the driver used by a pod
+	GetPodVolumes(ctx context.Context, in *GetPodVolumesRequest, opts ...grpc.CallOption) (*GetPodVolumesResponse, error)
+	// GetVolumeClaimTemplates Returns the templates owned by the driver
+	GetVolumeClaimTemplates(ctx context.Context, in *GetVolumeClaimTemplatesRequest, opts ...grpc.CallOption) (*GetVolumeClaimTemplatesResponse, error)
+	// OwnsPVC Returns if the PVC is owned by the driver
+	OwnsPVC(ctx context.Context, in *OwnsPVCRequest, opts ...grpc.CallOption) (*OwnsPVCResponse, error)
+	// GetSnapshotType Returns the type of a snapshot. Should return the
+	// UNIMPLEMENTED code if the snapshot doesn't belong to the driver
+	GetSnapshotType(ctx context.Context, in *GetSnapshotTypeRequest, opts ...grpc.CallOption) (*GetSnapshotTypeResponse, error)
+	// GetCapabilities Returns the features supported by the driver
+	GetCapabilities(ctx context.Context, in *GetCapabilitiesRequest, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error)
+	// CreateGroupSnapshot Creates a group snapshot of the PVCs selected by a
+	// GroupVolumeSnapshot
+	CreateGroupSnapshot(ctx context.Context, in *GroupSnapshotRequest, opts ...grpc.CallOption) (*GroupSnapshotResponse, error)
+	// GetGroupSnapshotStatus Returns the status of a group snapshot
+	GetGroupSnapshotStatus(ctx context.Context, in *GroupSnapshotRequest, opts ...grpc.CallOption) (*GroupSnapshotResponse, error)
+	// DeleteGroupSnapshot Deletes a group snapshot
+	DeleteGroupSnapshot(ctx context.Context, in *GroupSnapshotRequest, opts ...grpc.CallOption) (*DeleteGroupSnapshotResponse, error)
+	// CreatePair Creates a pair with a remote cluster
+	CreatePair(ctx context.Context, in *ClusterPairRequest, opts ...grpc.CallOption) (*CreatePairResponse, error)
+	// DeletePair Deletes a pair with a remote cluster
+	DeletePair(ctx context.Context, in *ClusterPairRequest, opts ...grpc.CallOption) (*DeletePairResponse, error)
+	// StartMigration Starts migrating the volumes for a Migration
+	StartMigration(ctx context.Context, in *MigrationRequest, opts ...grpc.CallOption) (*MigrationResponse, error)
+	// GetMigrationStatus Returns the status of the volumes being migrated
+	GetMigrationStatus(ctx context.Context, in *MigrationRequest, opts ...grpc.CallOption) (*MigrationResponse, error)
+	// CancelMigration Cancels migrating the volumes for a Migration
+	CancelMigration(ctx context.Context, in *MigrationRequest, opts ...grpc.CallOption) (*CancelMigrationResponse, error)
+	// UpdateMigratedPersistentVolumeSpec Updates a PV spec to point to the
+	// migrated volume
+	UpdateMigratedPersistentVolumeSpec(ctx context.Context, in *UpdateMigratedPersistentVolumeSpecRequest, opts ...grpc.CallOption) (*UpdateMigratedPersistentVolumeSpecResponse, error)
+}
+
+type volumeDriverClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewVolumeDriverClient(cc *grpc.ClientConn) VolumeDriverClient {
+	return &volumeDriverClient{cc}
+}
+
+func (c *volumeDriverClient) Init(ctx context.Context, in *InitRequest, opts ...grpc.CallOption) (*InitResponse, error) {
+	out := new(InitResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/Init", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StopResponse, error) {
+	out := new(StopResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/Stop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) InspectVolume(ctx context.Context, in *InspectVolumeRequest, opts ...grpc.CallOption) (*InspectVolumeResponse, error) {
+	out := new(InspectVolumeResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/InspectVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) ResizeVolume(ctx context.Context, in *ResizeVolumeRequest, opts ...grpc.CallOption) (*ResizeVolumeResponse, error) {
+	out := new(ResizeVolumeResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/ResizeVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) GetNodes(ctx context.Context, in *GetNodesRequest, opts ...grpc.CallOption) (*GetNodesResponse, error) {
+	out := new(GetNodesResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/GetNodes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) EnterMaintenance(ctx context.Context, in *MaintenanceRequest, opts ...grpc.CallOption) (*MaintenanceResponse, error) {
+	out := new(MaintenanceResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/EnterMaintenance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) ExitMaintenance(ctx context.Context, in *MaintenanceRequest, opts ...grpc.CallOption) (*MaintenanceResponse, error) {
+	out := new(MaintenanceResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/ExitMaintenance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) GetPodVolumes(ctx context.Context, in *GetPodVolumesRequest, opts ...grpc.CallOption) (*GetPodVolumesResponse, error) {
+	out := new(GetPodVolumesResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/GetPodVolumes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) GetVolumeClaimTemplates(ctx context.Context, in *GetVolumeClaimTemplatesRequest, opts ...grpc.CallOption) (*GetVolumeClaimTemplatesResponse, error) {
+	out := new(GetVolumeClaimTemplatesResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/GetVolumeClaimTemplates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) OwnsPVC(ctx context.Context, in *OwnsPVCRequest, opts ...grpc.CallOption) (*OwnsPVCResponse, error) {
+	out := new(OwnsPVCResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/OwnsPVC", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) GetSnapshotType(ctx context.Context, in *GetSnapshotTypeRequest, opts ...grpc.CallOption) (*GetSnapshotTypeResponse, error) {
+	out := new(GetSnapshotTypeResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/GetSnapshotType", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) GetCapabilities(ctx context.Context, in *GetCapabilitiesRequest, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error) {
+	out := new(GetCapabilitiesResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/GetCapabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) CreateGroupSnapshot(ctx context.Context, in *GroupSnapshotRequest, opts ...grpc.CallOption) (*GroupSnapshotResponse, error) {
+	out := new(GroupSnapshotResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/CreateGroupSnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) GetGroupSnapshotStatus(ctx context.Context, in *GroupSnapshotRequest, opts ...grpc.CallOption) (*GroupSnapshotResponse, error) {
+	out := new(GroupSnapshotResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/GetGroupSnapshotStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) DeleteGroupSnapshot(ctx context.Context, in *GroupSnapshotRequest, opts ...grpc.CallOption) (*DeleteGroupSnapshotResponse, error) {
+	out := new(DeleteGroupSnapshotResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/DeleteGroupSnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) CreatePair(ctx context.Context, in *ClusterPairRequest, opts ...grpc.CallOption) (*CreatePairResponse, error) {
+	out := new(CreatePairResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/CreatePair", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) DeletePair(ctx context.Context, in *ClusterPairRequest, opts ...grpc.CallOption) (*DeletePairResponse, error) {
+	out := new(DeletePairResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/DeletePair", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) StartMigration(ctx context.Context, in *MigrationRequest, opts ...grpc.CallOption) (*MigrationResponse, error) {
+	out := new(MigrationResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/StartMigration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) GetMigrationStatus(ctx context.Context, in *MigrationRequest, opts ...grpc.CallOption) (*MigrationResponse, error) {
+	out := new(MigrationResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/GetMigrationStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) CancelMigration(ctx context.Context, in *MigrationRequest, opts ...grpc.CallOption) (*CancelMigrationResponse, error) {
+	out := new(CancelMigrationResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/CancelMigration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *volumeDriverClient) UpdateMigratedPersistentVolumeSpec(ctx context.Context, in *UpdateMigratedPersistentVolumeSpecRequest, opts ...grpc.CallOption) (*UpdateMigratedPersistentVolumeSpecResponse, error) {
+	out := new(UpdateMigratedPersistentVolumeSpecResponse)
+	err := c.cc.Invoke(ctx, "/stork.plugin.VolumeDriver/UpdateMigratedPersistentVolumeSpec", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VolumeDriverServer is the server API for VolumeDriver service.
+type VolumeDriverServer interface {
+	// Init Initializes the driver with the config passed in to stork
+	Init(context.Context, *InitRequest) (*InitResponse, error)
+	// Stop Stops the driver
+	Stop(context.Context, *StopRequest) (*StopResponse, error)
+	// InspectVolume Returns information about a volume. Should return the
+	// NOT_FOUND code if the volume doesn't exist
+	InspectVolume(context.Context, *InspectVolumeRequest) (*InspectVolumeResponse, error)
+	// ResizeVolume Resizes a volume to the given size. Should return the
+	// NOT_FOUND code if the volume doesn't exist
+	ResizeVolume(context.Context, *ResizeVolumeRequest) (*ResizeVolumeResponse, error)
+	// GetNodes Returns the nodes on which the driver is running
+	GetNodes(context.Context, *GetNodesRequest) (*GetNodesResponse, error)
+	// EnterMaintenance Puts the driver on a node into maintenance mode
+	EnterMaintenance(context.Context, *MaintenanceRequest) (*MaintenanceResponse, error)
+	// ExitMaintenance Takes the driver on a node out of maintenance mode
+	ExitMaintenance(context.Context, *MaintenanceRequest) (*MaintenanceResponse, error)
+	// GetPodVolumes Returns the volumes from the driver used by a pod
+	GetPodVolumes(context.Context, *GetPodVolumesRequest) (*GetPodVolumesResponse, error)
+	// GetVolumeClaimTemplates Returns the templates owned by the driver
+	GetVolumeClaimTemplates(context.Context, *GetVolumeClaimTemplatesRequest) (*GetVolumeClaimTemplatesResponse, error)
+	// OwnsPVC Returns if the PVC is owned by the driver
+	OwnsPVC(context.Context, *OwnsPVCRequest) (*OwnsPVCResponse, error)
+	// GetSnapshotType Returns the type of a snapshot. Should return the
+	// UNIMPLEMENTED code if the snapshot doesn't belong to the driver
+	GetSnapshotType(context.Context, *GetSnapshotTypeRequest) (*GetSnapshotTypeResponse, error)
+	// GetCapabilities Returns the features supported by the driver
+	GetCapabilities(context.Context, *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error)
+	// CreateGroupSnapshot Creates a group snapshot of the PVCs selected by a
+	// GroupVolumeSnapshot
+	CreateGroupSnapshot(context.Context, *GroupSnapshotRequest) (*GroupSnapshotResponse, error)
+	// GetGroupSnapshotStatus Returns the status of a group snapshot
+	GetGroupSnapshotStatus(context.Context, *GroupSnapshotRequest) (*GroupSnapshotResponse, error)
+	// DeleteGroupSnapshot Deletes a group snapshot
+	DeleteGroupSnapshot(context.Context, *GroupSnapshotRequest) (*DeleteGroupSnapshotResponse, error)
+	// CreatePair Creates a pair with a remote cluster
+	CreatePair(context.Context, *ClusterPairRequest) (*CreatePairResponse, error)
+	// DeletePair Deletes a pair with a remote cluster
+	DeletePair(context.Context, *ClusterPairRequest) (*DeletePairResponse, error)
+	// StartMigration Starts migrating the volumes for a Migration
+	StartMigration(context.Context, *MigrationRequest) (*MigrationResponse, error)
+	// GetMigrationStatus Returns the status of the volumes being migrated
+	GetMigrationStatus(context.Context, *MigrationRequest) (*MigrationResponse, error)
+	// CancelMigration Cancels migrating the volumes for a Migration
+	CancelMigration(context.Context, *MigrationRequest) (*CancelMigrationResponse, error)
+	// UpdateMigratedPersistentVolumeSpec Updates a PV spec to point to the
+	// migrated volume
+	UpdateMigratedPersistentVolumeSpec(context.Context, *UpdateMigratedPersistentVolumeSpecRequest) (*UpdateMigratedPersistentVolumeSpecResponse, error)
+}
+
+func RegisterVolumeDriverServer(s *grpc.Server, srv VolumeDriverServer) {
+	s.RegisterService(&_VolumeDriver_serviceDesc, srv)
+}
+
+func _VolumeDriver_Init_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).Init(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/Init",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).Init(ctx, req.(*InitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/Stop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).Stop(ctx, req.(*StopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_InspectVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).InspectVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/InspectVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).InspectVolume(ctx, req.(*InspectVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_ResizeVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResizeVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).ResizeVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/ResizeVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).ResizeVolume(ctx, req.(*ResizeVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_GetNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).GetNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/GetNodes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).GetNodes(ctx, req.(*GetNodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_EnterMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).EnterMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/EnterMaintenance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).EnterMaintenance(ctx, req.(*MaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_ExitMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).ExitMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/ExitMaintenance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).ExitMaintenance(ctx, req.(*MaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_GetPodVolumes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPodVolumesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).GetPodVolumes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/GetPodVolumes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).GetPodVolumes(ctx, req.(*GetPodVolumesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_GetVolumeClaimTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVolumeClaimTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).GetVolumeClaimTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/GetVolumeClaimTemplates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).GetVolumeClaimTemplates(ctx, req.(*GetVolumeClaimTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_OwnsPVC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OwnsPVCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).OwnsPVC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/OwnsPVC",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).OwnsPVC(ctx, req.(*OwnsPVCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_GetSnapshotType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSnapshotTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).GetSnapshotType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/GetSnapshotType",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).GetSnapshotType(ctx, req.(*GetSnapshotTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCapabilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).GetCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/GetCapabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).GetCapabilities(ctx, req.(*GetCapabilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_CreateGroupSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).CreateGroupSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/CreateGroupSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).CreateGroupSnapshot(ctx, req.(*GroupSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_GetGroupSnapshotStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).GetGroupSnapshotStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/GetGroupSnapshotStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).GetGroupSnapshotStatus(ctx, req.(*GroupSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_DeleteGroupSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).DeleteGroupSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/DeleteGroupSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).DeleteGroupSnapshot(ctx, req.(*GroupSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_CreatePair_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterPairRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).CreatePair(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/CreatePair",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).CreatePair(ctx, req.(*ClusterPairRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_DeletePair_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterPairRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).DeletePair(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/DeletePair",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).DeletePair(ctx, req.(*ClusterPairRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_StartMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).StartMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/StartMigration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).StartMigration(ctx, req.(*MigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_GetMigrationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).GetMigrationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/GetMigrationStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).GetMigrationStatus(ctx, req.(*MigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_CancelMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).CancelMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/CancelMigration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).CancelMigration(ctx, req.(*MigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VolumeDriver_UpdateMigratedPersistentVolumeSpec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMigratedPersistentVolumeSpecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VolumeDriverServer).UpdateMigratedPersistentVolumeSpec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/stork.plugin.VolumeDriver/UpdateMigratedPersistentVolumeSpec",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VolumeDriverServer).UpdateMigratedPersistentVolumeSpec(ctx, req.(*UpdateMigratedPersistentVolumeSpecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _VolumeDriver_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "stork.plugin.VolumeDriver",
+	HandlerType: (*VolumeDriverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Init",
+			Handler:    _VolumeDriver_Init_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _VolumeDriver_Stop_Handler,
+		},
+		{
+			MethodName: "InspectVolume",
+			Handler:    _VolumeDriver_InspectVolume_Handler,
+		},
+		{
+			MethodName: "ResizeVolume",
+			Handler:    _VolumeDriver_ResizeVolume_Handler,
+		},
+		{
+			MethodName: "GetNodes",
+			Handler:    _VolumeDriver_GetNodes_Handler,
+		},
+		{
+			MethodName: "EnterMaintenance",
+			Handler:    _VolumeDriver_EnterMaintenance_Handler,
+		},
+		{
+			MethodName: "ExitMaintenance",
+			Handler:    _VolumeDriver_ExitMaintenance_Handler,
+		},
+		{
+			MethodName: "GetPodVolumes",
+			Handler:    _VolumeDriver_GetPodVolumes_Handler,
+		},
+		{
+			MethodName: "GetVolumeClaimTemplates",
+			Handler:    _VolumeDriver_GetVolumeClaimTemplates_Handler,
+		},
+		{
+			MethodName: "OwnsPVC",
+			Handler:    _VolumeDriver_OwnsPVC_Handler,
+		},
+		{
+			MethodName: "GetSnapshotType",
+			Handler:    _VolumeDriver_GetSnapshotType_Handler,
+		},
+		{
+			MethodName: "GetCapabilities",
+			Handler:    _VolumeDriver_GetCapabilities_Handler,
+		},
+		{
+			MethodName: "CreateGroupSnapshot",
+			Handler:    _VolumeDriver_CreateGroupSnapshot_Handler,
+		},
+		{
+			MethodName: "GetGroupSnapshotStatus",
+			Handler:    _VolumeDriver_GetGroupSnapshotStatus_Handler,
+		},
+		{
+			MethodName: "DeleteGroupSnapshot",
+			Handler:    _VolumeDriver_DeleteGroupSnapshot_Handler,
+		},
+		{
+			MethodName: "CreatePair",
+			Handler:    _VolumeDriver_CreatePair_Handler,
+		},
+		{
+			MethodName: "DeletePair",
+			Handler:    _VolumeDriver_DeletePair_Handler,
+		},
+		{
+			MethodName: "StartMigration",
+			Handler:    _VolumeDriver_StartMigration_Handler,
+		},
+		{
+			MethodName: "GetMigrationStatus",
+			Handler:    _VolumeDriver_GetMigrationStatus_Handler,
+		},
+		{
+			MethodName: "CancelMigration",
+			Handler:    _VolumeDriver_CancelMigration_Handler,
+		},
+		{
+			MethodName: "UpdateMigratedPersistentVolumeSpec",
+			Handler:    _VolumeDriver_UpdateMigratedPersistentVolumeSpec_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "plugin.proto",
+}
+
+func init() { proto.RegisterFile("plugin.proto", fileDescriptor_plugin_f0d0db66ffed9513) }
+
+var fileDescriptor_plugin_f0d0db66ffed9513 = []byte{
+	// 1534 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0x5b, 0x73, 0x13, 0xc7,
+	0x12, 0x2e, 0x5d, 0x6c, 0x4b, 0x2d, 0x59, 0x36, 0x63, 0x1b, 0xe4, 0x05, 0x63, 0x33, 0xc6, 0x75,
+	0x0c, 0x85, 0x5d, 0x1c, 0x73, 0x4e, 0xc1, 0x39, 0x15, 0x02, 0xc1, 0x10, 0x47, 0x29, 0x2e, 0x66,
+	0x0d, 0x84, 0xca, 0x43, 0x54, 0xe3, 0xdd, 0xb1, 0x98, 0xb0, 0xda, 0xd9, 0xec, 0x8e, 0x44, 0xcc,
+	0x6b, 0xde, 0xf3, 0x9a, 0x5f, 0x91, 0x1f, 0x96, 0x3f, 0x91, 0x4a, 0xcd, 0x65, 0xaf, 0xba, 0xd8,
+	0x24, 0xbc, 0xed, 0x7c, 0xd3, 0xfd, 0x75, 0x4f, 0xf7, 0x4c, 0x7f, 0xb2, 0xa1, 0x19, 0x78, 0x83,
+	0x1e, 0xf3, 0x77, 0x83, 0x90, 0x0b, 0x8e, 0x9a, 0x91, 0xe0, 0xe1, 0xfb, 0x5d, 0x8d, 0xe1, 0x3f,
+	0x2a, 0x00, 0x6f, 0xb8, 0x37, 0xe8, 0xd3, 0x8e, 0x7f, 0xc2, 0xd1, 0x65, 0xa8, 0x0f, 0xd5, 0xaa,
+	0xcb, 0xdc, 0x76, 0x69, 0xa3, 0xb4, 0x5d, 0xb7, 0x6b, 0x1a, 0xe8, 0xb8, 0x68, 0x1d, 0x1a, 0x66,
+	0xd3, 0x27, 0x7d, 0xda, 0x2e, 0xab, 0x6d, 0xd0, 0xd0, 0x73, 0xd2, 0xa7, 0x68, 0x0d, 0xc0, 0x25,
+	0x82, 0x74, 0x7d, 0xee, 0xd2, 0xa8, 0x5d, 0xd9, 0xa8, 0x6c, 0xd7, 0xed, 0xba, 0x44, 0x9e, 0x4b,
+	0x00, 0x21, 0xa8, 0x46, 0xec, 0x23, 0x6d, 0x57, 0x37, 0x4a, 0xdb, 0x55, 0x5b, 0x7d, 0xcb, 0x80,
+	0x01, 0x09, 0xa9, 0x2f, 0x64, 0xc0, 0x19, 0x1d, 0x50, 0x03, 0x1d, 0x17, 0x7d, 0x01, 0xb3, 0x1e,
+	0x39, 0xa6, 0x5e, 0xd4, 0x9e, 0xdd, 0xa8, 0x6c, 0x37, 0xf6, 0xae, 0xef, 0x66, 0x73, 0xdf, 0x4d,
+	0xf3, 0xde, 0x7d, 0xaa, 0xcc, 0x9e, 0xf8, 0x22, 0x3c, 0xb5, 0x8d, 0x0f, 0xda, 0x84, 0x79, 0x99,
+	0x48, 0x97, 0x9c, 0x9c, 0x30, 0x9f, 0x89, 0xd3, 0xf6, 0xdc, 0x46, 0x69, 0xbb, 0x69, 0x37, 0x25,
+	0xf8, 0x95, 0xc1, 0x64, 0xca, 0x83, 0x88, 0xba, 0xdd, 0xe3, 0x53, 0x41, 0xa3, 0x76, 0x4d, 0x65,
+	0x56, 0x97, 0xc8, 0x23, 0x09, 0xa0, 0x1d, 0x40, 0x21, 0x0d, 0x3c, 0xe6, 0x10, 0xc1, 0xb8, 0xdf,
+	0x8d, 0x04, 0x11, 0x83, 0xa8, 0x5d, 0x57, 0x79, 0x5e, 0xc8, 0xec, 0x1c, 0xa9, 0x0d, 0xb4, 0x05,
+	0x2d, 0x97, 0xf6, 0x42, 0xe2, 0x52, 0xd7, 0x14, 0x01, 0x54, 0x11, 0xe6, 0x63, 0x54, 0x17, 0x62,
+	0x1d, 0x1a, 0x44, 0x08, 0xe2, 0xbc, 0xa3, 0x6e, 0x97, 0xfb, 0xed, 0x86, 0x2e, 0x64, 0x0c, 0xbd,
+	0xf0, 0xd1, 0x2a, 0xd4, 0x18, 0x57, 0xd1, 0x68, 0xbb, 0xa9, 0x76, 0xe7, 0x18, 0x97, 0x31, 0xa8,
+	0xf5, 0x3f, 0x68, 0x64, 0x0e, 0x8b, 0x16, 0xa1, 0xf2, 0x9e, 0x9e, 0x9a, 0x56, 0xc9, 0x4f, 0xb4,
+	0x0c, 0x33, 0x43, 0xe2, 0x0d, 0xe2, 0xfe, 0xe8, 0xc5, 0xff, 0xcb, 0xf7, 0x4a, 0xf8, 0xd7, 0x12,
+	0x2c, 0xc8, 0x04, 0x5e, 0x0b, 0xe6, 0xb1, 0x8f, 0x2a, 0x6f, 0x99, 0xb1, 0xe0, 0x82, 0x78, 0x5d,
+	0x87, 0x04, 0xc4, 0x61, 0x42, 0x53, 0x55, 0xed, 0x79, 0x85, 0xee, 0x1b, 0x50, 0xd6, 0x52, 0x95,
+	0x29, 0xb1, 0x2a, 0x2b, 0xab, 0xa6, 0x04, 0x13, 0xa3, 0x1b, 0xb0, 0x98, 0x1c, 0x4b, 0xdf, 0x8a,
+	0x48, 0xf5, 0xba, 0x62, 0x2f, 0xc4, 0xb8, 0x6e, 0x59, 0xf4, 0x6d, 0xb5, 0x56, 0x59, 0xac, 0xe2,
+	0x5f, 0xca, 0x50, 0x93, 0x09, 0xa9, 0xab, 0xd7, 0x82, 0x72, 0x72, 0xe7, 0xca, 0xcc, 0x45, 0x16,
+	0xd4, 0xde, 0xf1, 0x48, 0x64, 0xae, 0x5a, 0xb2, 0x96, 0xa7, 0x66, 0x41, 0x7c, 0xc3, 0xe4, 0xa7,
+	0xbc, 0x5b, 0x21, 0x71, 0xde, 0xab, 0x78, 0x75, 0x5b, 0x7d, 0x4b, 0xec, 0x23, 0xf7, 0xa9, 0xb9,
+	0x56, 0xea, 0x1b, 0x5d, 0x84, 0xd9, 0x90, 0xf6, 0x18, 0xf7, 0xdb, 0xb3, 0x0a, 0x35, 0x2b, 0x89,
+	0x9b, 0xe6, 0xce, 0x69, 0x5c, 0xaf, 0xd0, 0x03, 0x68, 0x0c, 0xd2, 0x72, 0xa9, 0x0b, 0xd2, 0xd8,
+	0x5b, 0xcb, 0xdf, 0xc3, 0x42, 0x4d, 0xed, 0xac, 0x07, 0xda, 0x80, 0x46, 0x9f, 0x30, 0x5f, 0x50,
+	0x9f, 0xf8, 0x0e, 0x55, 0x57, 0xa7, 0x66, 0x67, 0x21, 0xbc, 0x05, 0x8d, 0x8e, 0xcf, 0x84, 0x4d,
+	0x7f, 0x1a, 0xd0, 0x48, 0xc8, 0x4c, 0x1c, 0xee, 0x9f, 0xb0, 0x9e, 0xaa, 0x45, 0xd3, 0x36, 0x2b,
+	0xdc, 0x82, 0xa6, 0x36, 0x8b, 0x02, 0xee, 0x47, 0x14, 0xcf, 0x43, 0xe3, 0x48, 0xf0, 0xc0, 0xb8,
+	0xc9, 0x6d, 0xbd, 0x34, 0xdb, 0x77, 0x60, 0xb9, 0xe3, 0x47, 0x01, 0x75, 0x84, 0xae, 0x79, 0x4c,
+	0x3f, 0xed, 0x85, 0xe3, 0x0e, 0xac, 0x14, 0x9c, 0x34, 0x1b, 0xba, 0x0d, 0xb3, 0xda, 0x48, 0xb9,
+	0x34, 0xf6, 0xda, 0x93, 0x5e, 0xa2, 0x6d, 0xec, 0xf0, 0x4b, 0x58, 0xb2, 0xa9, 0x7c, 0xe2, 0xe7,
+	0x0f, 0x2f, 0x1f, 0xa3, 0xf4, 0x30, 0x8f, 0x51, 0x5f, 0xb1, 0xba, 0x44, 0xd4, 0x63, 0xc4, 0xdf,
+	0xc0, 0x72, 0x9e, 0xf2, 0x6f, 0x27, 0x77, 0x01, 0x16, 0x0e, 0xa8, 0x50, 0x8f, 0x31, 0xae, 0xdf,
+	0x43, 0x58, 0x4c, 0x21, 0x43, 0x7c, 0x0b, 0x66, 0xf4, 0x2b, 0x2e, 0xa9, 0xf1, 0x73, 0x71, 0xb4,
+	0xed, 0x8a, 0x55, 0x1b, 0xe1, 0x87, 0x80, 0x9e, 0xa5, 0x6d, 0x8d, 0x0f, 0x7c, 0x13, 0xaa, 0x72,
+	0xdb, 0xa4, 0x36, 0x89, 0x42, 0xd9, 0xe0, 0x15, 0x58, 0xca, 0x31, 0x98, 0x56, 0xbe, 0x80, 0xe5,
+	0x03, 0x2a, 0x0e, 0x79, 0xfc, 0x7a, 0x62, 0xea, 0x55, 0xa8, 0x05, 0xdc, 0xed, 0xca, 0x7e, 0x99,
+	0xbb, 0x32, 0x17, 0x70, 0xf7, 0x28, 0xa0, 0x0e, 0xba, 0x02, 0x75, 0xf9, 0x50, 0xa2, 0x80, 0x38,
+	0xf1, 0xeb, 0x49, 0x01, 0xec, 0xc1, 0x4a, 0x81, 0xd0, 0x1c, 0x78, 0x0f, 0xe6, 0xe2, 0x87, 0xab,
+	0x8f, 0x3c, 0xb9, 0x94, 0xb1, 0xa1, 0x1c, 0x66, 0x01, 0xf5, 0x5d, 0xe6, 0xf7, 0xba, 0xc1, 0xd0,
+	0x89, 0x55, 0xc1, 0x40, 0x87, 0x43, 0x07, 0x7f, 0x09, 0x57, 0x0f, 0xa8, 0xb9, 0x50, 0xfb, 0x1e,
+	0x61, 0xfd, 0x57, 0xb4, 0x1f, 0x78, 0x44, 0xa4, 0x07, 0xb9, 0x02, 0x75, 0x11, 0x63, 0x2a, 0x70,
+	0xd3, 0x4e, 0x01, 0xfc, 0x00, 0xd6, 0x27, 0xfa, 0x9b, 0xbc, 0xa7, 0x13, 0x60, 0x68, 0xbd, 0xf8,
+	0xe0, 0x47, 0x87, 0x6f, 0xf6, 0xe3, 0x80, 0x8b, 0x50, 0x09, 0x86, 0x71, 0xd1, 0xe4, 0x27, 0xde,
+	0x82, 0x85, 0xc4, 0xc6, 0x90, 0x22, 0xa8, 0xf2, 0x0f, 0x7e, 0xa4, 0xac, 0x6a, 0xb6, 0xfa, 0xc6,
+	0xff, 0x81, 0x8b, 0x07, 0x54, 0x1c, 0xf9, 0x24, 0x88, 0xde, 0x71, 0xf1, 0xea, 0x34, 0x48, 0xfa,
+	0x6c, 0x41, 0x2d, 0x32, 0xb0, 0xe1, 0x4d, 0xd6, 0x78, 0x07, 0x2e, 0x8d, 0x78, 0xa5, 0x41, 0xc4,
+	0x69, 0x40, 0xcd, 0x53, 0x50, 0xdf, 0xb8, 0xad, 0x82, 0xc8, 0xb1, 0x7a, 0xcc, 0x3c, 0x26, 0x58,
+	0x7a, 0x49, 0x7f, 0x2f, 0xc3, 0xa5, 0x91, 0xad, 0xb4, 0x06, 0x71, 0xc0, 0x38, 0xe7, 0x14, 0x40,
+	0xff, 0x82, 0x05, 0xc7, 0xe3, 0x03, 0xb7, 0x9b, 0xda, 0x94, 0x95, 0x4d, 0x4b, 0xc1, 0x47, 0x59,
+	0xc3, 0x5e, 0xc8, 0x07, 0x41, 0xc6, 0xb0, 0xa2, 0x0d, 0x15, 0x9c, 0x1a, 0x5e, 0x83, 0xa6, 0xe3,
+	0x0d, 0x22, 0x41, 0xc3, 0x6e, 0x40, 0x58, 0xa8, 0x26, 0x6f, 0xcd, 0x6e, 0x18, 0xec, 0x90, 0xb0,
+	0x50, 0xa6, 0xd4, 0x67, 0xbd, 0x50, 0x8f, 0xce, 0x19, 0x9d, 0x52, 0x02, 0xc8, 0x48, 0x82, 0x07,
+	0xdc, 0xe3, 0xbd, 0xd3, 0xae, 0x47, 0x87, 0xb1, 0xcc, 0xd7, 0xed, 0x56, 0x0c, 0x3f, 0x55, 0xa8,
+	0x9e, 0xd9, 0xea, 0x97, 0xc3, 0x9c, 0xe2, 0x30, 0xab, 0xe2, 0x68, 0xad, 0x8d, 0x8e, 0xd6, 0xfb,
+	0xb0, 0x7c, 0x90, 0xcd, 0x3a, 0x6e, 0xd6, 0x16, 0xb4, 0xf2, 0x87, 0x34, 0x2d, 0x9b, 0xcf, 0x9d,
+	0x11, 0xff, 0x17, 0x56, 0x0a, 0xee, 0xe3, 0x6b, 0xad, 0xee, 0x5b, 0x02, 0xe0, 0x35, 0xb8, 0xfc,
+	0x98, 0x7a, 0x54, 0xd0, 0xb1, 0xce, 0xf8, 0x2e, 0xa0, 0xfd, 0xb4, 0x48, 0x71, 0x4a, 0xc5, 0x72,
+	0xea, 0x84, 0xb2, 0xe5, 0xc4, 0xff, 0x06, 0xb4, 0x1f, 0x52, 0x22, 0xa8, 0xf6, 0x33, 0xb9, 0x5c,
+	0x86, 0x7a, 0x48, 0xfb, 0x5c, 0x64, 0x27, 0xaa, 0x06, 0x3a, 0x2e, 0x5e, 0x06, 0xa4, 0x53, 0xc9,
+	0xba, 0xe0, 0xdb, 0xb0, 0xf8, 0x2c, 0x6e, 0x43, 0xe6, 0x0d, 0xa6, 0xbd, 0xd2, 0xc1, 0x53, 0x00,
+	0xef, 0xc0, 0x85, 0x8c, 0x87, 0x89, 0xdc, 0xce, 0x4f, 0x8b, 0x66, 0x32, 0x13, 0xf0, 0x2a, 0x5c,
+	0xda, 0x97, 0x0d, 0xf0, 0x46, 0x9c, 0xf0, 0x3e, 0xdc, 0x78, 0x1d, 0xb8, 0x44, 0x50, 0xbd, 0x45,
+	0xdd, 0x43, 0x1a, 0x46, 0x2c, 0x12, 0xd4, 0x37, 0x4f, 0x5c, 0xce, 0xaf, 0x8c, 0x16, 0xf2, 0xe3,
+	0x1f, 0xa9, 0x13, 0xf7, 0xc7, 0xac, 0xf0, 0x63, 0xb8, 0x79, 0x1e, 0x12, 0x93, 0xe7, 0x04, 0x96,
+	0xbd, 0x3f, 0x5b, 0xd0, 0xd4, 0xe6, 0x8f, 0x43, 0x36, 0xa4, 0x21, 0xba, 0x0f, 0x55, 0x29, 0xb1,
+	0x68, 0x35, 0x3f, 0xf5, 0x32, 0xea, 0x6c, 0x59, 0xe3, 0xb6, 0x4c, 0x9c, 0xfb, 0x50, 0x95, 0x12,
+	0x5c, 0x74, 0xcf, 0xa8, 0xb4, 0x65, 0x8d, 0xdb, 0x32, 0xee, 0x6f, 0x61, 0x3e, 0x27, 0xbe, 0x08,
+	0x17, 0x63, 0x8d, 0xca, 0xb9, 0xb5, 0x39, 0xd5, 0xc6, 0x30, 0xbf, 0x86, 0x66, 0x56, 0x38, 0xd1,
+	0xb5, 0xbc, 0xd3, 0x18, 0x9d, 0xb6, 0xf0, 0x34, 0x13, 0x43, 0xdb, 0x81, 0x5a, 0x2c, 0x99, 0xa8,
+	0xf0, 0x93, 0xa8, 0xa0, 0xae, 0xd6, 0xd5, 0x49, 0xdb, 0x86, 0xea, 0x3b, 0x58, 0x7c, 0xe2, 0x0b,
+	0x1a, 0x66, 0xe4, 0x0f, 0x6d, 0xe4, 0x7d, 0x46, 0xb5, 0xd5, 0xba, 0x36, 0xc5, 0xc2, 0x10, 0xbf,
+	0x81, 0x85, 0x27, 0x3f, 0x33, 0xf1, 0xd9, 0x79, 0xdf, 0xc2, 0x7c, 0x4e, 0x42, 0x8b, 0xcd, 0x1a,
+	0x27, 0xd8, 0xd6, 0xe6, 0x54, 0x1b, 0xc3, 0x3c, 0x54, 0x23, 0x7e, 0x9c, 0xdc, 0xa1, 0x5b, 0x23,
+	0xfe, 0x53, 0x54, 0xd5, 0xda, 0x39, 0xa7, 0xb5, 0x89, 0xfb, 0x35, 0xcc, 0x19, 0x05, 0x44, 0x57,
+	0xf2, 0x9e, 0x79, 0xf1, 0xb4, 0xd6, 0x26, 0xec, 0x1a, 0x9e, 0x1f, 0xd4, 0x6f, 0xab, 0xac, 0xd8,
+	0xa1, 0xeb, 0x23, 0x99, 0x8c, 0x51, 0x50, 0x6b, 0xeb, 0x0c, 0xab, 0x1c, 0x7f, 0x56, 0x02, 0xc7,
+	0xf0, 0x8f, 0x11, 0x4f, 0x6b, 0xeb, 0x0c, 0xab, 0x84, 0x7f, 0x49, 0x4f, 0xd9, 0xdc, 0xf4, 0x1e,
+	0xe9, 0xef, 0x18, 0x59, 0xb1, 0x36, 0xa7, 0xda, 0x18, 0x7e, 0xa2, 0xd4, 0x3d, 0xb7, 0x67, 0xfe,
+	0x7a, 0xfc, 0x6c, 0x21, 0x5c, 0x58, 0x1a, 0x23, 0x40, 0xe7, 0xe2, 0xbf, 0x91, 0xb7, 0x99, 0xa2,
+	0x63, 0xe8, 0x10, 0x20, 0x95, 0xa3, 0xe2, 0xab, 0x1a, 0x55, 0x38, 0xab, 0x68, 0x31, 0x2a, 0x65,
+	0x87, 0x00, 0xa9, 0x5a, 0x7d, 0x3a, 0xe3, 0xa8, 0xd2, 0xa1, 0x97, 0xd0, 0x3a, 0x12, 0x24, 0x14,
+	0x89, 0x0e, 0xa1, 0xc2, 0x24, 0x2a, 0xea, 0xa0, 0xb5, 0x3e, 0x71, 0x3f, 0x19, 0xa6, 0xe8, 0x80,
+	0xa6, 0x84, 0xa6, 0x77, 0xff, 0x98, 0xf6, 0x2d, 0x2c, 0x14, 0x24, 0xf3, 0x4c, 0xce, 0xc2, 0x85,
+	0x9e, 0xa0, 0xb8, 0xe8, 0xb7, 0x12, 0xe0, 0xb3, 0xd5, 0x12, 0xdd, 0xcd, 0xb3, 0x9d, 0x5b, 0xa4,
+	0xad, 0x7b, 0x9f, 0xee, 0xa8, 0x33, 0x7b, 0x34, 0xf3, 0x7d, 0x85, 0x04, 0xec, 0x78, 0x56, 0xfd,
+	0x63, 0xea, 0xce, 0x5f, 0x03, 0x00, 0x9f, 0xca, 0x87, 0xa7, 0xa8, 0x12, 0x00, 0x00,
+}
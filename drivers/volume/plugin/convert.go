@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/plugin/api"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/api/core/v1"
+)
+
+func volumeInfoToAPI(info *storkvolume.Info) (*api.VolumeInfo, error) {
+	apiInfo := &api.VolumeInfo{
+		VolumeId:          info.VolumeID,
+		VolumeName:        info.VolumeName,
+		DataNodes:         info.DataNodes,
+		Size:              info.Size,
+		ParentId:          info.ParentID,
+		Labels:            info.Labels,
+		UsedBytes:         info.UsedBytes,
+		ReplicationStatus: string(info.ReplicationStatus),
+		DegradedNodes:     info.DegradedNodes,
+		AttachedOn:        info.AttachedOn,
+		IoState:           string(info.IOState),
+	}
+	if info.NodeAffinity != nil {
+		nodeAffinity, err := json.Marshal(info.NodeAffinity)
+		if err != nil {
+			return nil, err
+		}
+		apiInfo.NodeAffinity = nodeAffinity
+	}
+	return apiInfo, nil
+}
+
+func volumeInfoFromAPI(apiInfo *api.VolumeInfo) (*storkvolume.Info, error) {
+	info := &storkvolume.Info{
+		VolumeID:          apiInfo.VolumeId,
+		VolumeName:        apiInfo.VolumeName,
+		DataNodes:         apiInfo.DataNodes,
+		Size:              apiInfo.Size,
+		ParentID:          apiInfo.ParentId,
+		Labels:            apiInfo.Labels,
+		UsedBytes:         apiInfo.UsedBytes,
+		ReplicationStatus: storkvolume.ReplicationStatus(apiInfo.ReplicationStatus),
+		DegradedNodes:     apiInfo.DegradedNodes,
+		AttachedOn:        apiInfo.AttachedOn,
+		IOState:           storkvolume.IOState(apiInfo.IoState),
+	}
+	if info.Labels == nil {
+		info.Labels = make(map[string]string)
+	}
+	if len(apiInfo.NodeAffinity) != 0 {
+		info.NodeAffinity = &v1.VolumeNodeAffinity{}
+		if err := json.Unmarshal(apiInfo.NodeAffinity, info.NodeAffinity); err != nil {
+			return nil, fmt.Errorf("Error parsing node affinity for volume %v: %v", info.VolumeID, err)
+		}
+	}
+	return info, nil
+}
+
+func nodeInfoToAPI(node *storkvolume.NodeInfo) *api.NodeInfo {
+	apiNode := &api.NodeInfo{
+		Id:          node.ID,
+		Hostname:    node.Hostname,
+		Ips:         node.IPs,
+		Rack:        node.Rack,
+		Zone:        node.Zone,
+		Region:      node.Region,
+		Status:      string(node.Status),
+		Maintenance: node.Maintenance,
+	}
+	if node.Utilization != nil {
+		apiNode.Utilization = &api.NodeUtilization{
+			TotalCapacity:   node.Utilization.TotalCapacity,
+			UsedCapacity:    node.Utilization.UsedCapacity,
+			AttachedVolumes: int64(node.Utilization.AttachedVolumes),
+		}
+	}
+	return apiNode
+}
+
+func nodeInfoFromAPI(apiNode *api.NodeInfo) *storkvolume.NodeInfo {
+	node := &storkvolume.NodeInfo{
+		ID:          apiNode.Id,
+		Hostname:    apiNode.Hostname,
+		IPs:         apiNode.Ips,
+		Rack:        apiNode.Rack,
+		Zone:        apiNode.Zone,
+		Region:      apiNode.Region,
+		Status:      storkvolume.NodeStatus(apiNode.Status),
+		Maintenance: apiNode.Maintenance,
+	}
+	if apiNode.Utilization != nil {
+		node.Utilization = &storkvolume.NodeUtilization{
+			TotalCapacity:   apiNode.Utilization.TotalCapacity,
+			UsedCapacity:    apiNode.Utilization.UsedCapacity,
+			AttachedVolumes: int(apiNode.Utilization.AttachedVolumes),
+		}
+	}
+	return node
+}
+
+func capabilitiesToAPI(capabilities *storkvolume.Capabilities) *api.GetCapabilitiesResponse {
+	response := &api.GetCapabilitiesResponse{
+		Snapshots:      capabilities.Snapshots,
+		CloudSnapshots: capabilities.CloudSnapshots,
+		GroupSnapshots: capabilities.GroupSnapshots,
+		ClusterPair:    capabilities.ClusterPair,
+		Migration:      capabilities.Migration,
+		Resize:         capabilities.Resize,
+		Maintenance:    capabilities.Maintenance,
+	}
+	for _, level := range capabilities.TopologyLevels {
+		response.TopologyLevels = append(response.TopologyLevels, string(level))
+	}
+	return response
+}
+
+func capabilitiesFromAPI(response *api.GetCapabilitiesResponse) *storkvolume.Capabilities {
+	capabilities := &storkvolume.Capabilities{
+		Snapshots:      response.Snapshots,
+		CloudSnapshots: response.CloudSnapshots,
+		GroupSnapshots: response.GroupSnapshots,
+		ClusterPair:    response.ClusterPair,
+		Migration:      response.Migration,
+		Resize:         response.Resize,
+		Maintenance:    response.Maintenance,
+	}
+	for _, level := range response.TopologyLevels {
+		capabilities.TopologyLevels = append(capabilities.TopologyLevels, storkvolume.TopologyLevel(level))
+	}
+	return capabilities
+}
+
+// toStatus Converts errors returned by a driver to gRPC status errors so
+// that the type of error can be recovered by the client
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch err.(type) {
+	case *errors.ErrNotSupported, *errors.ErrNotImplemented:
+		return status.Error(codes.Unimplemented, err.Error())
+	case *errors.ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// fromStatus Converts gRPC status errors returned by a plugin to the errors
+// returned by drivers
+func fromStatus(err error, feature string) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch s.Code() {
+	case codes.Unimplemented:
+		return &errors.ErrNotSupported{
+			Feature: feature,
+			Reason:  s.Message(),
+		}
+	}
+	return fmt.Errorf("%v", s.Message())
+}
@@ -0,0 +1,444 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	snapshotVolume "github.com/kubernetes-incubator/external-storage/snapshot/pkg/volume"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/plugin/api"
+	stork_crd "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// unixPrefix Prefix for endpoints that are unix sockets
+	unixPrefix = "unix://"
+	// dialTimeout Timeout to connect to the plugin
+	dialTimeout = 30 * time.Second
+	// requestTimeout Timeout for each request to the plugin
+	requestTimeout = 1 * time.Minute
+)
+
+// driver Volume driver which forwards requests to a plugin running outside
+// of stork using the VolumeDriver gRPC service. Snapshots of volumes from
+// plugins can't be taken by the stork snapshot controller, so a snapshot
+// plugin isn't provided
+type driver struct {
+	name         string
+	endpoint     string
+	conn         *grpc.ClientConn
+	client       api.VolumeDriverClient
+	capabilities *storkvolume.Capabilities
+}
+
+// Register Registers a volume driver with the given name which forwards
+// requests to the plugin listening on the endpoint. The endpoint can either
+// be a unix socket specified as unix:///path or host:port
+func Register(name, endpoint string) error {
+	if name == "" || endpoint == "" {
+		return fmt.Errorf("Name and endpoint are required to register a plugin")
+	}
+	return storkvolume.Register(name, &driver{
+		name:     name,
+		endpoint: endpoint,
+	})
+}
+
+func (d *driver) String() string {
+	return d.name
+}
+
+// Init Connects to the plugin and initializes it with the JSON encoding of
+// the config
+func (d *driver) Init(config interface{}) error {
+	opts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(dialTimeout),
+	}
+	target := d.endpoint
+	if strings.HasPrefix(target, unixPrefix) {
+		target = strings.TrimPrefix(target, unixPrefix)
+		opts = append(opts, grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}))
+	}
+	conn, err := grpc.Dial(target, opts...)
+	if err != nil {
+		return fmt.Errorf("Error connecting to plugin %v at %v: %v", d.name, d.endpoint, err)
+	}
+	d.conn = conn
+	d.client = api.NewVolumeDriverClient(conn)
+
+	request := &api.InitRequest{}
+	if config != nil {
+		if request.Config, err = json.Marshal(config); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	if _, err = d.client.Init(ctx, request); err != nil {
+		return fromStatus(err, "Init")
+	}
+
+	response, err := d.client.GetCapabilities(ctx, &api.GetCapabilitiesRequest{})
+	if err != nil {
+		// Plugins that don't report capabilities are assumed to not support
+		// any of the optional features
+		logrus.Warnf("Error getting capabilities from plugin %v: %v", d.name, err)
+		d.capabilities = &storkvolume.Capabilities{}
+		return nil
+	}
+	d.capabilities = capabilitiesFromAPI(response)
+	return nil
+}
+
+func (d *driver) Stop() error {
+	if d.conn == nil {
+		return nil
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	if _, err := d.client.Stop(ctx, &api.StopRequest{}); err != nil {
+		logrus.Warnf("Error stopping plugin %v: %v", d.name, err)
+	}
+	return d.conn.Close()
+}
+
+func (d *driver) InspectVolume(volumeID string) (*storkvolume.Info, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.InspectVolume(ctx, &api.InspectVolumeRequest{VolumeId: volumeID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, &errors.ErrNotFound{
+				ID:   volumeID,
+				Type: "Volume",
+			}
+		}
+		return nil, fromStatus(err, "InspectVolume")
+	}
+	if response.Volume == nil {
+		return nil, fmt.Errorf("No volume info returned for %v", volumeID)
+	}
+	return volumeInfoFromAPI(response.Volume)
+}
+
+func (d *driver) ResizeVolume(volumeID string, sizeBytes uint64) (*storkvolume.Info, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.ResizeVolume(ctx, &api.ResizeVolumeRequest{
+		VolumeId:  volumeID,
+		SizeBytes: sizeBytes,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, &errors.ErrNotFound{
+				ID:   volumeID,
+				Type: "Volume",
+			}
+		}
+		return nil, fromStatus(err, "ResizeVolume")
+	}
+	if response.Volume == nil {
+		return nil, fmt.Errorf("No volume info returned for %v", volumeID)
+	}
+	return volumeInfoFromAPI(response.Volume)
+}
+
+func (d *driver) GetNodes() ([]*storkvolume.NodeInfo, error) {
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.GetNodes(ctx, &api.GetNodesRequest{})
+	if err != nil {
+		return nil, fromStatus(err, "GetNodes")
+	}
+	nodes := make([]*storkvolume.NodeInfo, 0, len(response.Nodes))
+	for _, node := range response.Nodes {
+		nodes = append(nodes, nodeInfoFromAPI(node))
+	}
+	return nodes, nil
+}
+
+func (d *driver) EnterMaintenance(node *storkvolume.NodeInfo) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	_, err := d.client.EnterMaintenance(ctx, &api.MaintenanceRequest{Node: nodeInfoToAPI(node)})
+	if err != nil {
+		return fromStatus(err, "EnterMaintenance")
+	}
+	return nil
+}
+
+func (d *driver) ExitMaintenance(node *storkvolume.NodeInfo) error {
+	ctx, cancel := newContext()
+	defer cancel()
+	_, err := d.client.ExitMaintenance(ctx, &api.MaintenanceRequest{Node: nodeInfoToAPI(node)})
+	if err != nil {
+		return fromStatus(err, "ExitMaintenance")
+	}
+	return nil
+}
+
+func (d *driver) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*storkvolume.Info, error) {
+	spec, err := json.Marshal(podSpec)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.GetPodVolumes(ctx, &api.GetPodVolumesRequest{
+		PodSpec:   spec,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, fromStatus(err, "GetPodVolumes")
+	}
+	if response.PendingPvc != "" {
+		return nil, &storkvolume.ErrPVCPending{
+			Name: response.PendingPvc,
+		}
+	}
+
+	var volumes []*storkvolume.Info
+	for _, apiInfo := range response.Volumes {
+		info, err := volumeInfoFromAPI(apiInfo)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, info)
+	}
+	return volumes, nil
+}
+
+func (d *driver) GetVolumeClaimTemplates(templates []v1.PersistentVolumeClaim) (
+	[]v1.PersistentVolumeClaim, error) {
+	request := &api.GetVolumeClaimTemplatesRequest{}
+	for _, template := range templates {
+		encoded, err := json.Marshal(&template)
+		if err != nil {
+			return nil, err
+		}
+		request.Templates = append(request.Templates, encoded)
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.GetVolumeClaimTemplates(ctx, request)
+	if err != nil {
+		return nil, fromStatus(err, "GetVolumeClaimTemplates")
+	}
+
+	var driverTemplates []v1.PersistentVolumeClaim
+	for _, encoded := range response.Templates {
+		var template v1.PersistentVolumeClaim
+		if err := json.Unmarshal(encoded, &template); err != nil {
+			return nil, err
+		}
+		driverTemplates = append(driverTemplates, template)
+	}
+	return driverTemplates, nil
+}
+
+func (d *driver) OwnsPVC(pvc *v1.PersistentVolumeClaim) bool {
+	encoded, err := json.Marshal(pvc)
+	if err != nil {
+		logrus.Warnf("Error encoding pvc %v: %v", pvc.Name, err)
+		return false
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.OwnsPVC(ctx, &api.OwnsPVCRequest{Pvc: encoded})
+	if err != nil {
+		logrus.Warnf("Error checking if plugin %v owns pvc %v: %v", d.name, pvc.Name, err)
+		return false
+	}
+	return response.Owns
+}
+
+// GetSnapshotPlugin Returns nil since snapshots can't be taken through
+// plugins
+func (d *driver) GetSnapshotPlugin() snapshotVolume.Plugin {
+	return nil
+}
+
+func (d *driver) GetSnapshotType(snap *snapv1.VolumeSnapshot) (string, error) {
+	encoded, err := json.Marshal(snap)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.GetSnapshotType(ctx, &api.GetSnapshotTypeRequest{Snapshot: encoded})
+	if err != nil {
+		return "", fromStatus(err, "GetSnapshotType")
+	}
+	return response.Type, nil
+}
+
+// Capabilities Returns the capabilities reported by the plugin when it was
+// initialized
+func (d *driver) Capabilities() *storkvolume.Capabilities {
+	if d.capabilities == nil {
+		return &storkvolume.Capabilities{}
+	}
+	return d.capabilities
+}
+
+func (d *driver) CreateGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) (
+	*storkvolume.GroupSnapshotCreateResponse, error) {
+	return d.groupSnapshotRequest(snap, d.client.CreateGroupSnapshot, "CreateGroupSnapshot")
+}
+
+func (d *driver) GetGroupSnapshotStatus(snap *stork_crd.GroupVolumeSnapshot) (
+	*storkvolume.GroupSnapshotCreateResponse, error) {
+	return d.groupSnapshotRequest(snap, d.client.GetGroupSnapshotStatus, "GetGroupSnapshotStatus")
+}
+
+func (d *driver) groupSnapshotRequest(
+	snap *stork_crd.GroupVolumeSnapshot,
+	call func(context.Context, *api.GroupSnapshotRequest, ...grpc.CallOption) (*api.GroupSnapshotResponse, error),
+	feature string,
+) (*storkvolume.GroupSnapshotCreateResponse, error) {
+	encoded, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := call(ctx, &api.GroupSnapshotRequest{GroupSnapshot: encoded})
+	if err != nil {
+		return nil, fromStatus(err, feature)
+	}
+
+	createResponse := &storkvolume.GroupSnapshotCreateResponse{}
+	for _, encoded := range response.Snapshots {
+		snapshot := &stork_crd.VolumeSnapshotStatus{}
+		if err := json.Unmarshal(encoded, snapshot); err != nil {
+			return nil, err
+		}
+		createResponse.Snapshots = append(createResponse.Snapshots, snapshot)
+	}
+	return createResponse, nil
+}
+
+func (d *driver) DeleteGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) error {
+	encoded, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	_, err = d.client.DeleteGroupSnapshot(ctx, &api.GroupSnapshotRequest{GroupSnapshot: encoded})
+	return fromStatus(err, "DeleteGroupSnapshot")
+}
+
+func (d *driver) CreatePair(pair *stork_crd.ClusterPair) (string, error) {
+	encoded, err := json.Marshal(pair)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.CreatePair(ctx, &api.ClusterPairRequest{ClusterPair: encoded})
+	if err != nil {
+		return "", fromStatus(err, "CreatePair")
+	}
+	return response.RemoteId, nil
+}
+
+func (d *driver) DeletePair(pair *stork_crd.ClusterPair) error {
+	encoded, err := json.Marshal(pair)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	_, err = d.client.DeletePair(ctx, &api.ClusterPairRequest{ClusterPair: encoded})
+	return fromStatus(err, "DeletePair")
+}
+
+func (d *driver) StartMigration(migration *stork_crd.Migration) ([]*stork_crd.VolumeInfo, error) {
+	return d.migrationRequest(migration, d.client.StartMigration, "StartMigration")
+}
+
+func (d *driver) GetMigrationStatus(migration *stork_crd.Migration) ([]*stork_crd.VolumeInfo, error) {
+	return d.migrationRequest(migration, d.client.GetMigrationStatus, "GetMigrationStatus")
+}
+
+func (d *driver) migrationRequest(
+	migration *stork_crd.Migration,
+	call func(context.Context, *api.MigrationRequest, ...grpc.CallOption) (*api.MigrationResponse, error),
+	feature string,
+) ([]*stork_crd.VolumeInfo, error) {
+	encoded, err := json.Marshal(migration)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := call(ctx, &api.MigrationRequest{Migration: encoded})
+	if err != nil {
+		return nil, fromStatus(err, feature)
+	}
+
+	volumeInfos := make([]*stork_crd.VolumeInfo, 0, len(response.Volumes))
+	for _, encoded := range response.Volumes {
+		volumeInfo := &stork_crd.VolumeInfo{}
+		if err := json.Unmarshal(encoded, volumeInfo); err != nil {
+			return nil, err
+		}
+		volumeInfos = append(volumeInfos, volumeInfo)
+	}
+	return volumeInfos, nil
+}
+
+func (d *driver) CancelMigration(migration *stork_crd.Migration) error {
+	encoded, err := json.Marshal(migration)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	_, err = d.client.CancelMigration(ctx, &api.MigrationRequest{Migration: encoded})
+	return fromStatus(err, "CancelMigration")
+}
+
+func (d *driver) UpdateMigratedPersistentVolumeSpec(
+	object runtime.Unstructured,
+) (runtime.Unstructured, error) {
+	encoded, err := json.Marshal(object.UnstructuredContent())
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := newContext()
+	defer cancel()
+	response, err := d.client.UpdateMigratedPersistentVolumeSpec(ctx,
+		&api.UpdateMigratedPersistentVolumeSpecRequest{Object: encoded})
+	if err != nil {
+		return nil, fromStatus(err, "UpdateMigratedPersistentVolumeSpec")
+	}
+
+	updated := &unstructured.Unstructured{}
+	if err := updated.UnmarshalJSON(response.Object); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func newContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), requestTimeout)
+}
@@ -0,0 +1,184 @@
+// +build unittest
+
+package plugin
+
+import (
+	"net"
+	"testing"
+
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	"github.com/libopenstorage/stork/drivers/volume/plugin/api"
+	stork_crd "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	mockDriverName = "MockDriver"
+	pluginName     = "mockplugin"
+)
+
+var mockDriver *mock.Driver
+var pluginDriver storkvolume.Driver
+var grpcServer *grpc.Server
+
+func TestPlugin(t *testing.T) {
+	t.Run("setup", setup)
+	t.Run("capabilitiesTest", capabilitiesTest)
+	t.Run("nodesTest", nodesTest)
+	t.Run("volumesTest", volumesTest)
+	t.Run("pendingPVCTest", pendingPVCTest)
+	t.Run("errorsTest", errorsTest)
+	t.Run("teardown", teardown)
+}
+
+// Start a plugin serving the mock driver and register a driver for it
+func setup(t *testing.T) {
+	d, err := storkvolume.Get(mockDriverName)
+	require.NoError(t, err, "Error getting mock driver")
+	var ok bool
+	mockDriver, ok = d.(*mock.Driver)
+	require.True(t, ok, "Error casting mock driver")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Error creating listener")
+	grpcServer = grpc.NewServer()
+	api.RegisterVolumeDriverServer(grpcServer, NewServer(mockDriver))
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+
+	err = Register(pluginName, listener.Addr().String())
+	require.NoError(t, err, "Error registering plugin")
+	pluginDriver, err = storkvolume.Get(pluginName)
+	require.NoError(t, err, "Error getting plugin driver")
+	require.Equal(t, pluginName, pluginDriver.String(), "Unexpected driver name")
+	err = pluginDriver.Init(nil)
+	require.NoError(t, err, "Error initializing plugin driver")
+}
+
+func teardown(t *testing.T) {
+	require.NoError(t, pluginDriver.Stop(), "Error stopping plugin driver")
+	grpcServer.Stop()
+}
+
+func capabilitiesTest(t *testing.T) {
+	require.Equal(t, mockDriver.Capabilities(), pluginDriver.Capabilities(), "Unexpected capabilities from plugin")
+}
+
+func nodesTest(t *testing.T) {
+	require.NoError(t, mockDriver.CreateCluster(3, &v1.NodeList{}), "Error creating cluster")
+	require.NoError(t, mockDriver.UpdateNodeStatus(2, storkvolume.NodeOffline), "Error updating node status")
+	require.NoError(t, mockDriver.UpdateNodeUtilization(1, &storkvolume.NodeUtilization{TotalCapacity: 100, UsedCapacity: 40, AttachedVolumes: 2}), "Error updating node utilization")
+
+	mockDriver.SetCapabilities(&storkvolume.Capabilities{Maintenance: true})
+	err := pluginDriver.EnterMaintenance(&storkvolume.NodeInfo{ID: "node1"})
+	mockDriver.SetCapabilities(nil)
+	require.NoError(t, err, "Error entering maintenance")
+
+	expectedNodes, err := mockDriver.GetNodes()
+	require.NoError(t, err, "Error getting nodes from mock driver")
+	nodes, err := pluginDriver.GetNodes()
+	require.NoError(t, err, "Error getting nodes from plugin")
+	require.Equal(t, expectedNodes, nodes, "Unexpected nodes from plugin")
+	require.True(t, nodes[0].Maintenance, "Node should be in maintenance")
+}
+
+func volumesTest(t *testing.T) {
+	require.NoError(t, mockDriver.ProvisionVolume("pluginVolume", []int{0, 1}, 2), "Error provisioning volume")
+	pvc := mockDriver.NewPVC("pluginVolume")
+	require.True(t, pluginDriver.OwnsPVC(pvc), "Plugin should own PVC")
+	require.False(t, pluginDriver.OwnsPVC(&v1.PersistentVolumeClaim{}), "Plugin shouldn't own PVC")
+
+	info, err := pluginDriver.InspectVolume("pluginVolume")
+	require.NoError(t, err, "Error inspecting volume")
+	require.Equal(t, "pluginVolume", info.VolumeID, "Unexpected volume ID")
+	require.Equal(t, []string{"node1", "node2"}, info.DataNodes, "Unexpected data nodes")
+	require.Equal(t, uint64(2), info.Size, "Unexpected size")
+
+	require.NoError(t, mockDriver.UpdateVolumeUsage("pluginVolume", 1024), "Error updating volume usage")
+	mockDriver.SetCapabilities(&storkvolume.Capabilities{Resize: true})
+	info, err = pluginDriver.ResizeVolume("pluginVolume", 3*1024*1024*1024)
+	mockDriver.SetCapabilities(nil)
+	require.NoError(t, err, "Error resizing volume")
+	require.Equal(t, uint64(3), info.Size, "Unexpected size after resize")
+	require.Equal(t, uint64(1024), info.UsedBytes, "Unexpected used bytes")
+
+	require.NoError(t, mockDriver.UpdateVolumeHealth("pluginVolume", storkvolume.ReplicationResyncing, []int{1}, storkvolume.IOStateOnline),
+		"Error updating volume health")
+	require.NoError(t, mockDriver.AttachVolume("pluginVolume", 0), "Error attaching volume")
+	info, err = pluginDriver.InspectVolume("pluginVolume")
+	require.NoError(t, err, "Error inspecting volume")
+	require.Equal(t, storkvolume.ReplicationResyncing, info.ReplicationStatus, "Unexpected replication status")
+	require.Equal(t, []string{"node2"}, info.DegradedNodes, "Unexpected degraded nodes")
+	require.Equal(t, "node1", info.AttachedOn, "Unexpected attached node")
+	require.Equal(t, storkvolume.IOStateOnline, info.IOState, "Unexpected IO state")
+
+	podSpec := &v1.PodSpec{
+		Volumes: []v1.Volume{
+			{
+				Name: "data",
+				VolumeSource: v1.VolumeSource{
+					PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+						ClaimName: pvc.Name,
+					},
+				},
+			},
+		},
+	}
+	volumes, err := pluginDriver.GetPodVolumes(podSpec, "default")
+	require.NoError(t, err, "Error getting pod volumes")
+	require.Len(t, volumes, 1, "Unexpected number of volumes")
+	require.Equal(t, "pluginVolume", volumes[0].VolumeID, "Unexpected volume")
+}
+
+func pendingPVCTest(t *testing.T) {
+	pvc := mockDriver.NewPendingPVC("pluginPendingPVC")
+	podSpec := &v1.PodSpec{
+		Volumes: []v1.Volume{
+			{
+				Name: "data",
+				VolumeSource: v1.VolumeSource{
+					PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+						ClaimName: pvc.Name,
+					},
+				},
+			},
+		},
+	}
+	_, err := pluginDriver.GetPodVolumes(podSpec, "default")
+	require.Error(t, err, "Expected error for pending PVC")
+	pendingErr, ok := err.(*storkvolume.ErrPVCPending)
+	require.True(t, ok, "Expected ErrPVCPending, got %v", err)
+	require.Equal(t, pvc.Name, pendingErr.Name, "Unexpected pending PVC")
+}
+
+func errorsTest(t *testing.T) {
+	_, err := pluginDriver.InspectVolume("missingVolume")
+	_, ok := err.(*errors.ErrNotFound)
+	require.True(t, ok, "Expected ErrNotFound, got %v", err)
+
+	_, err = pluginDriver.CreatePair(&stork_crd.ClusterPair{})
+	_, ok = err.(*errors.ErrNotSupported)
+	require.True(t, ok, "Expected ErrNotSupported, got %v", err)
+
+	_, err = pluginDriver.StartMigration(&stork_crd.Migration{})
+	_, ok = err.(*errors.ErrNotSupported)
+	require.True(t, ok, "Expected ErrNotSupported, got %v", err)
+
+	_, err = pluginDriver.ResizeVolume("pluginVolume", 0)
+	_, ok = err.(*errors.ErrNotSupported)
+	require.True(t, ok, "Expected ErrNotSupported, got %v", err)
+
+	err = pluginDriver.ExitMaintenance(&storkvolume.NodeInfo{ID: "node1"})
+	_, ok = err.(*errors.ErrNotSupported)
+	require.True(t, ok, "Expected ErrNotSupported, got %v", err)
+
+	mockDriver.SetInterfaceError(&errors.ErrNotImplemented{})
+	defer mockDriver.SetInterfaceError(nil)
+	_, err = pluginDriver.GetNodes()
+	require.Error(t, err, "Expected error getting nodes")
+}
@@ -0,0 +1,354 @@
+package plugin
+
+import (
+	"encoding/json"
+
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/plugin/api"
+	stork_crd "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// server Implementation of the VolumeDriver gRPC service which forwards
+// requests to a volume driver
+type server struct {
+	driver storkvolume.Driver
+}
+
+// NewServer Returns a VolumeDriver gRPC service backed by the given volume
+// driver. Can be used by plugins written in Go to serve an implementation
+// of volume.Driver, which should be registered with
+// api.RegisterVolumeDriverServer
+func NewServer(driver storkvolume.Driver) api.VolumeDriverServer {
+	return &server{
+		driver: driver,
+	}
+}
+
+func decode(encoded []byte, obj interface{}) error {
+	if err := json.Unmarshal(encoded, obj); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Error decoding request: %v", err)
+	}
+	return nil
+}
+
+func (s *server) Init(ctx context.Context, request *api.InitRequest) (*api.InitResponse, error) {
+	var config interface{}
+	if len(request.Config) != 0 {
+		if err := decode(request.Config, &config); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.driver.Init(config); err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.InitResponse{}, nil
+}
+
+func (s *server) Stop(ctx context.Context, request *api.StopRequest) (*api.StopResponse, error) {
+	if err := s.driver.Stop(); err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.StopResponse{}, nil
+}
+
+func (s *server) InspectVolume(
+	ctx context.Context,
+	request *api.InspectVolumeRequest,
+) (*api.InspectVolumeResponse, error) {
+	info, err := s.driver.InspectVolume(request.VolumeId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	apiInfo, err := volumeInfoToAPI(info)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.InspectVolumeResponse{Volume: apiInfo}, nil
+}
+
+func (s *server) ResizeVolume(
+	ctx context.Context,
+	request *api.ResizeVolumeRequest,
+) (*api.ResizeVolumeResponse, error) {
+	info, err := s.driver.ResizeVolume(request.VolumeId, request.SizeBytes)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	apiInfo, err := volumeInfoToAPI(info)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.ResizeVolumeResponse{Volume: apiInfo}, nil
+}
+
+func (s *server) GetNodes(ctx context.Context, request *api.GetNodesRequest) (*api.GetNodesResponse, error) {
+	nodes, err := s.driver.GetNodes()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	response := &api.GetNodesResponse{}
+	for _, node := range nodes {
+		response.Nodes = append(response.Nodes, nodeInfoToAPI(node))
+	}
+	return response, nil
+}
+
+func (s *server) EnterMaintenance(
+	ctx context.Context,
+	request *api.MaintenanceRequest,
+) (*api.MaintenanceResponse, error) {
+	if request.Node == nil {
+		return nil, status.Error(codes.InvalidArgument, "Node is required")
+	}
+	if err := s.driver.EnterMaintenance(nodeInfoFromAPI(request.Node)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.MaintenanceResponse{}, nil
+}
+
+func (s *server) ExitMaintenance(
+	ctx context.Context,
+	request *api.MaintenanceRequest,
+) (*api.MaintenanceResponse, error) {
+	if request.Node == nil {
+		return nil, status.Error(codes.InvalidArgument, "Node is required")
+	}
+	if err := s.driver.ExitMaintenance(nodeInfoFromAPI(request.Node)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.MaintenanceResponse{}, nil
+}
+
+func (s *server) GetPodVolumes(
+	ctx context.Context,
+	request *api.GetPodVolumesRequest,
+) (*api.GetPodVolumesResponse, error) {
+	var podSpec v1.PodSpec
+	if err := decode(request.PodSpec, &podSpec); err != nil {
+		return nil, err
+	}
+	volumes, err := s.driver.GetPodVolumes(&podSpec, request.Namespace)
+	if err != nil {
+		if pending, ok := err.(*storkvolume.ErrPVCPending); ok {
+			return &api.GetPodVolumesResponse{PendingPvc: pending.Name}, nil
+		}
+		return nil, toStatus(err)
+	}
+
+	response := &api.GetPodVolumesResponse{}
+	for _, info := range volumes {
+		apiInfo, err := volumeInfoToAPI(info)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		response.Volumes = append(response.Volumes, apiInfo)
+	}
+	return response, nil
+}
+
+func (s *server) GetVolumeClaimTemplates(
+	ctx context.Context,
+	request *api.GetVolumeClaimTemplatesRequest,
+) (*api.GetVolumeClaimTemplatesResponse, error) {
+	var templates []v1.PersistentVolumeClaim
+	for _, encoded := range request.Templates {
+		var template v1.PersistentVolumeClaim
+		if err := decode(encoded, &template); err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	driverTemplates, err := s.driver.GetVolumeClaimTemplates(templates)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &api.GetVolumeClaimTemplatesResponse{}
+	for _, template := range driverTemplates {
+		encoded, err := json.Marshal(&template)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		response.Templates = append(response.Templates, encoded)
+	}
+	return response, nil
+}
+
+func (s *server) OwnsPVC(ctx context.Context, request *api.OwnsPVCRequest) (*api.OwnsPVCResponse, error) {
+	var pvc v1.PersistentVolumeClaim
+	if err := decode(request.Pvc, &pvc); err != nil {
+		return nil, err
+	}
+	return &api.OwnsPVCResponse{Owns: s.driver.OwnsPVC(&pvc)}, nil
+}
+
+func (s *server) GetSnapshotType(
+	ctx context.Context,
+	request *api.GetSnapshotTypeRequest,
+) (*api.GetSnapshotTypeResponse, error) {
+	var snap snapv1.VolumeSnapshot
+	if err := decode(request.Snapshot, &snap); err != nil {
+		return nil, err
+	}
+	snapType, err := s.driver.GetSnapshotType(&snap)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.GetSnapshotTypeResponse{Type: snapType}, nil
+}
+
+func (s *server) GetCapabilities(
+	ctx context.Context,
+	request *api.GetCapabilitiesRequest,
+) (*api.GetCapabilitiesResponse, error) {
+	return capabilitiesToAPI(s.driver.Capabilities()), nil
+}
+
+func (s *server) CreateGroupSnapshot(
+	ctx context.Context,
+	request *api.GroupSnapshotRequest,
+) (*api.GroupSnapshotResponse, error) {
+	return s.groupSnapshotRequest(request, s.driver.CreateGroupSnapshot)
+}
+
+func (s *server) GetGroupSnapshotStatus(
+	ctx context.Context,
+	request *api.GroupSnapshotRequest,
+) (*api.GroupSnapshotResponse, error) {
+	return s.groupSnapshotRequest(request, s.driver.GetGroupSnapshotStatus)
+}
+
+func (s *server) groupSnapshotRequest(
+	request *api.GroupSnapshotRequest,
+	call func(*stork_crd.GroupVolumeSnapshot) (*storkvolume.GroupSnapshotCreateResponse, error),
+) (*api.GroupSnapshotResponse, error) {
+	var snap stork_crd.GroupVolumeSnapshot
+	if err := decode(request.GroupSnapshot, &snap); err != nil {
+		return nil, err
+	}
+	createResponse, err := call(&snap)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &api.GroupSnapshotResponse{}
+	if createResponse == nil {
+		return response, nil
+	}
+	for _, snapshot := range createResponse.Snapshots {
+		encoded, err := json.Marshal(snapshot)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		response.Snapshots = append(response.Snapshots, encoded)
+	}
+	return response, nil
+}
+
+func (s *server) DeleteGroupSnapshot(
+	ctx context.Context,
+	request *api.GroupSnapshotRequest,
+) (*api.DeleteGroupSnapshotResponse, error) {
+	var snap stork_crd.GroupVolumeSnapshot
+	if err := decode(request.GroupSnapshot, &snap); err != nil {
+		return nil, err
+	}
+	if err := s.driver.DeleteGroupSnapshot(&snap); err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.DeleteGroupSnapshotResponse{}, nil
+}
+
+func (s *server) CreatePair(ctx context.Context, request *api.ClusterPairRequest) (*api.CreatePairResponse, error) {
+	var pair stork_crd.ClusterPair
+	if err := decode(request.ClusterPair, &pair); err != nil {
+		return nil, err
+	}
+	remoteID, err := s.driver.CreatePair(&pair)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.CreatePairResponse{RemoteId: remoteID}, nil
+}
+
+func (s *server) DeletePair(ctx context.Context, request *api.ClusterPairRequest) (*api.DeletePairResponse, error) {
+	var pair stork_crd.ClusterPair
+	if err := decode(request.ClusterPair, &pair); err != nil {
+		return nil, err
+	}
+	if err := s.driver.DeletePair(&pair); err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.DeletePairResponse{}, nil
+}
+
+func (s *server) StartMigration(ctx context.Context, request *api.MigrationRequest) (*api.MigrationResponse, error) {
+	return s.migrationRequest(request, s.driver.StartMigration)
+}
+
+func (s *server) GetMigrationStatus(ctx context.Context, request *api.MigrationRequest) (*api.MigrationResponse, error) {
+	return s.migrationRequest(request, s.driver.GetMigrationStatus)
+}
+
+func (s *server) migrationRequest(
+	request *api.MigrationRequest,
+	call func(*stork_crd.Migration) ([]*stork_crd.VolumeInfo, error),
+) (*api.MigrationResponse, error) {
+	var migration stork_crd.Migration
+	if err := decode(request.Migration, &migration); err != nil {
+		return nil, err
+	}
+	volumeInfos, err := call(&migration)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &api.MigrationResponse{}
+	for _, volumeInfo := range volumeInfos {
+		encoded, err := json.Marshal(volumeInfo)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		response.Volumes = append(response.Volumes, encoded)
+	}
+	return response, nil
+}
+
+func (s *server) CancelMigration(
+	ctx context.Context,
+	request *api.MigrationRequest,
+) (*api.CancelMigrationResponse, error) {
+	var migration stork_crd.Migration
+	if err := decode(request.Migration, &migration); err != nil {
+		return nil, err
+	}
+	if err := s.driver.CancelMigration(&migration); err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.CancelMigrationResponse{}, nil
+}
+
+func (s *server) UpdateMigratedPersistentVolumeSpec(
+	ctx context.Context,
+	request *api.UpdateMigratedPersistentVolumeSpecRequest,
+) (*api.UpdateMigratedPersistentVolumeSpecResponse, error) {
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON(request.Object); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Error decoding request: %v", err)
+	}
+	updated, err := s.driver.UpdateMigratedPersistentVolumeSpec(object)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	encoded, err := json.Marshal(updated.UnstructuredContent())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &api.UpdateMigratedPersistentVolumeSpecResponse{Object: encoded}, nil
+}
@@ -0,0 +1,205 @@
+package portworx
+
+import (
+	"fmt"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultNodeCacheResyncPeriod  = 30 * time.Second
+	defaultAuthTokenRefreshPeriod = 1 * time.Minute
+
+	defaultMinMigrationVersion      = "2.0"
+	defaultMinCloudSnapshotsVersion = "2.0"
+	defaultMinGroupSnapshotsVersion = "2.0.2"
+)
+
+// SecretReference Reference to a key in a secret
+type SecretReference struct {
+	// Name Name of the secret
+	Name string `json:"name"`
+	// Namespace Namespace of the secret. Defaults to the namespace of the
+	// portworx service
+	Namespace string `json:"namespace"`
+	// Key Key in the secret. Defaults to auth-token for the auth secret and
+	// ca.crt for the CA secret
+	Key string `json:"key"`
+}
+
+// TLSConfig TLS configuration for the connection to the SDK API
+type TLSConfig struct {
+	// CASecret Secret with the CA certificate used to verify the SDK
+	// endpoint
+	CASecret *SecretReference `json:"caSecret"`
+	// CAFile Path of the CA certificate used to verify the SDK endpoint.
+	// The system roots are used if neither the CA secret nor the CA file are
+	// set
+	CAFile string `json:"caFile"`
+	// ServerName Name used to verify the certificate of the SDK endpoint.
+	// Defaults to the host of the endpoint
+	ServerName string `json:"serverName"`
+}
+
+// MinVersions Minimum versions of portworx needed on all nodes for
+// features to be used
+type MinVersions struct {
+	// Migration Version needed for cluster pairing and migration
+	Migration string `json:"migration"`
+	// CloudSnapshots Version needed for cloud snapshots
+	CloudSnapshots string `json:"cloudSnapshots"`
+	// GroupSnapshots Version needed for group snapshots
+	GroupSnapshots string `json:"groupSnapshots"`
+}
+
+// Config Configuration for the portworx driver
+type Config struct {
+	// API API used to talk to portworx, either rest (default) or sdk
+	API string `json:"api"`
+	// ServiceName Name of the portworx service. Defaults to the
+	// PX_SERVICE_NAME environment variable or portworx-service
+	ServiceName string `json:"serviceName"`
+	// ServiceNamespace Namespace of the portworx service. Defaults to the
+	// PX_NAMESPACE environment variable or kube-system
+	ServiceNamespace string `json:"serviceNamespace"`
+	// Endpoint Host used to reach portworx instead of the IP of the service
+	Endpoint string `json:"endpoint"`
+	// RestPort Port of the REST API. Defaults to the px-api port of the
+	// service
+	RestPort int `json:"restPort"`
+	// SDKPort Port of the SDK API. Defaults to the px-sdk port of the
+	// service
+	SDKPort int `json:"sdkPort"`
+	// TLS TLS configuration for the SDK API. The connection isn't encrypted
+	// if it isn't set
+	TLS *TLSConfig `json:"tls"`
+	// AuthSecret Secret with the token used to authenticate with the SDK
+	// API. Can only be used with TLS
+	AuthSecret *SecretReference `json:"authSecret"`
+	// AuthTokenRefreshPeriod Interval after which the token is read again
+	// from the auth secret, so that rotated tokens are used without
+	// restarting stork
+	AuthTokenRefreshPeriod metav1.Duration `json:"authTokenRefreshPeriod"`
+	// SDKTimeout Timeout for each call to the SDK API
+	SDKTimeout metav1.Duration `json:"sdkTimeout"`
+	// NodeCacheResyncPeriod Interval at which the cache of Kubernetes nodes
+	// and the number of volumes attached on each node is refreshed
+	NodeCacheResyncPeriod metav1.Duration `json:"nodeCacheResyncPeriod"`
+	// MinVersions Minimum versions of portworx needed for features
+	MinVersions MinVersions `json:"minVersions"`
+}
+
+// NewConfig Returns the default configuration for the driver
+func (p *portworx) NewConfig() storkvolume.Config {
+	return &Config{
+		API: APIRest,
+		SDKTimeout: metav1.Duration{
+			Duration: defaultSDKTimeout,
+		},
+		NodeCacheResyncPeriod: metav1.Duration{
+			Duration: defaultNodeCacheResyncPeriod,
+		},
+		AuthTokenRefreshPeriod: metav1.Duration{
+			Duration: defaultAuthTokenRefreshPeriod,
+		},
+		MinVersions: MinVersions{
+			Migration:      defaultMinMigrationVersion,
+			CloudSnapshots: defaultMinCloudSnapshotsVersion,
+			GroupSnapshots: defaultMinGroupSnapshotsVersion,
+		},
+	}
+}
+
+// newConfigFromOptions Returns the default configuration updated with the
+// options passed to Init
+func (p *portworx) newConfigFromOptions(options map[string]string) *Config {
+	config := p.NewConfig().(*Config)
+	if apiType, ok := options[APIOption]; ok {
+		config.API = apiType
+	}
+	if secretName := options[AuthSecretNameOption]; secretName != "" {
+		config.AuthSecret = &SecretReference{
+			Name:      secretName,
+			Namespace: options[AuthSecretNamespaceOption],
+			Key:       options[AuthSecretKeyOption],
+		}
+	}
+	if options[TLSOption] == "true" {
+		config.TLS = &TLSConfig{}
+	}
+	if secretName := options[TLSCASecretNameOption]; secretName != "" {
+		config.TLS = &TLSConfig{
+			CASecret: &SecretReference{
+				Name:      secretName,
+				Namespace: options[TLSCASecretNamespaceOption],
+				Key:       options[TLSCASecretKeyOption],
+			},
+		}
+	}
+	if caFile := options[TLSCAFileOption]; caFile != "" {
+		if config.TLS == nil {
+			config.TLS = &TLSConfig{}
+		}
+		config.TLS.CAFile = caFile
+	}
+	return config
+}
+
+// Validate Returns an error if the configuration is invalid
+func (c *Config) Validate() error {
+	if c.API != APIRest && c.API != APISDK {
+		return fmt.Errorf("Invalid api %v, should be %v or %v", c.API, APIRest, APISDK)
+	}
+	if c.RestPort < 0 || c.RestPort > 65535 {
+		return fmt.Errorf("Invalid REST port %v", c.RestPort)
+	}
+	if c.SDKPort < 0 || c.SDKPort > 65535 {
+		return fmt.Errorf("Invalid SDK port %v", c.SDKPort)
+	}
+	if c.TLS != nil {
+		if c.API != APISDK {
+			return fmt.Errorf("TLS can only be used with the %v api", APISDK)
+		}
+		if c.TLS.CASecret != nil {
+			if c.TLS.CAFile != "" {
+				return fmt.Errorf("Only one of the CA secret and the CA file can be set for TLS")
+			}
+			if c.TLS.CASecret.Name == "" {
+				return fmt.Errorf("TLS CA secret needs a name")
+			}
+		}
+	}
+	if c.AuthSecret != nil {
+		if c.API != APISDK {
+			return fmt.Errorf("Auth secret can only be used with the %v api", APISDK)
+		}
+		if c.AuthSecret.Name == "" {
+			return fmt.Errorf("Auth secret needs a name")
+		}
+		if c.TLS == nil {
+			return fmt.Errorf("Auth secret can only be used with TLS since the token would be sent unencrypted")
+		}
+	}
+	if c.SDKTimeout.Duration <= 0 {
+		return fmt.Errorf("SDK timeout should be greater than 0")
+	}
+	if c.NodeCacheResyncPeriod.Duration <= 0 {
+		return fmt.Errorf("Node cache resync period should be greater than 0")
+	}
+	if c.AuthTokenRefreshPeriod.Duration <= 0 {
+		return fmt.Errorf("Auth token refresh period should be greater than 0")
+	}
+	for feature, minVersion := range map[string]string{
+		"migration":       c.MinVersions.Migration,
+		"cloud snapshots": c.MinVersions.CloudSnapshots,
+		"group snapshots": c.MinVersions.GroupSnapshots,
+	} {
+		if _, err := version.NewVersion(minVersion); err != nil {
+			return fmt.Errorf("Invalid min version %v for %v: %v", minVersion, feature, err)
+		}
+	}
+	return nil
+}
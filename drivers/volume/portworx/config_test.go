@@ -0,0 +1,194 @@
+// +build unittest
+
+package portworx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	t.Run("validateTest", validateTest)
+	t.Run("configFromOptionsTest", configFromOptionsTest)
+}
+
+func validateTest(t *testing.T) {
+	p := &portworx{}
+	testCases := []struct {
+		name          string
+		update        func(c *Config)
+		expectedError string
+	}{
+		{
+			name:   "default",
+			update: func(c *Config) {},
+		},
+		{
+			name: "sdk with tls and auth",
+			update: func(c *Config) {
+				c.API = APISDK
+				c.TLS = &TLSConfig{CASecret: &SecretReference{Name: "px-ca"}}
+				c.AuthSecret = &SecretReference{Name: "px-auth"}
+			},
+		},
+		{
+			name:          "invalid api",
+			update:        func(c *Config) { c.API = "grpc" },
+			expectedError: "Invalid api grpc",
+		},
+		{
+			name:          "invalid rest port",
+			update:        func(c *Config) { c.RestPort = 65536 },
+			expectedError: "Invalid REST port",
+		},
+		{
+			name:          "invalid sdk port",
+			update:        func(c *Config) { c.SDKPort = -1 },
+			expectedError: "Invalid SDK port",
+		},
+		{
+			name:          "tls with rest api",
+			update:        func(c *Config) { c.TLS = &TLSConfig{} },
+			expectedError: "TLS can only be used with the sdk api",
+		},
+		{
+			name: "tls with ca secret and file",
+			update: func(c *Config) {
+				c.API = APISDK
+				c.TLS = &TLSConfig{CASecret: &SecretReference{Name: "px-ca"}, CAFile: "/etc/ca.crt"}
+			},
+			expectedError: "Only one of the CA secret and the CA file",
+		},
+		{
+			name: "tls ca secret without name",
+			update: func(c *Config) {
+				c.API = APISDK
+				c.TLS = &TLSConfig{CASecret: &SecretReference{}}
+			},
+			expectedError: "TLS CA secret needs a name",
+		},
+		{
+			name:          "auth secret with rest api",
+			update:        func(c *Config) { c.AuthSecret = &SecretReference{Name: "px-auth"} },
+			expectedError: "Auth secret can only be used with the sdk api",
+		},
+		{
+			name: "auth secret without name",
+			update: func(c *Config) {
+				c.API = APISDK
+				c.TLS = &TLSConfig{}
+				c.AuthSecret = &SecretReference{}
+			},
+			expectedError: "Auth secret needs a name",
+		},
+		{
+			name: "auth secret without tls",
+			update: func(c *Config) {
+				c.API = APISDK
+				c.AuthSecret = &SecretReference{Name: "px-auth"}
+			},
+			expectedError: "Auth secret can only be used with TLS",
+		},
+		{
+			name:          "invalid sdk timeout",
+			update:        func(c *Config) { c.SDKTimeout.Duration = 0 },
+			expectedError: "SDK timeout should be greater than 0",
+		},
+		{
+			name:          "invalid node cache resync period",
+			update:        func(c *Config) { c.NodeCacheResyncPeriod.Duration = -time.Second },
+			expectedError: "Node cache resync period should be greater than 0",
+		},
+		{
+			name:          "invalid auth token refresh period",
+			update:        func(c *Config) { c.AuthTokenRefreshPeriod.Duration = 0 },
+			expectedError: "Auth token refresh period should be greater than 0",
+		},
+		{
+			name:          "invalid migration version",
+			update:        func(c *Config) { c.MinVersions.Migration = "abc" },
+			expectedError: "Invalid min version abc for migration",
+		},
+		{
+			name:          "invalid cloud snapshots version",
+			update:        func(c *Config) { c.MinVersions.CloudSnapshots = "" },
+			expectedError: "Invalid min version  for cloud snapshots",
+		},
+		{
+			name:          "invalid group snapshots version",
+			update:        func(c *Config) { c.MinVersions.GroupSnapshots = "2.x" },
+			expectedError: "Invalid min version 2.x for group snapshots",
+		},
+	}
+	for _, tc := range testCases {
+		config := p.NewConfig().(*Config)
+		tc.update(config)
+		err := config.Validate()
+		if tc.expectedError == "" {
+			require.NoError(t, err, "%v: Unexpected error validating config", tc.name)
+			continue
+		}
+		require.Error(t, err, "%v: Expected error validating config", tc.name)
+		require.Contains(t, err.Error(), tc.expectedError, "%v: Unexpected error", tc.name)
+	}
+}
+
+func configFromOptionsTest(t *testing.T) {
+	p := &portworx{}
+	testCases := []struct {
+		name     string
+		options  map[string]string
+		expected func(c *Config)
+	}{
+		{
+			name:     "no options",
+			options:  map[string]string{},
+			expected: func(c *Config) {},
+		},
+		{
+			name: "auth secret",
+			options: map[string]string{
+				APIOption:                 APISDK,
+				AuthSecretNameOption:      "px-auth",
+				AuthSecretNamespaceOption: "portworx",
+				AuthSecretKeyOption:       "token",
+			},
+			expected: func(c *Config) {
+				c.API = APISDK
+				c.AuthSecret = &SecretReference{Name: "px-auth", Namespace: "portworx", Key: "token"}
+			},
+		},
+		{
+			name:    "tls with system roots",
+			options: map[string]string{TLSOption: "true"},
+			expected: func(c *Config) {
+				c.TLS = &TLSConfig{}
+			},
+		},
+		{
+			name: "tls with ca secret",
+			options: map[string]string{
+				TLSCASecretNameOption:      "px-ca",
+				TLSCASecretNamespaceOption: "portworx",
+				TLSCASecretKeyOption:       "ca.pem",
+			},
+			expected: func(c *Config) {
+				c.TLS = &TLSConfig{CASecret: &SecretReference{Name: "px-ca", Namespace: "portworx", Key: "ca.pem"}}
+			},
+		},
+		{
+			name:    "tls with ca file",
+			options: map[string]string{TLSOption: "false", TLSCAFileOption: "/etc/ca.crt"},
+			expected: func(c *Config) {
+				c.TLS = &TLSConfig{CAFile: "/etc/ca.crt"}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		expected := p.NewConfig().(*Config)
+		tc.expected(expected)
+		require.Equal(t, expected, p.newConfigFromOptions(tc.options), "%v: Unexpected config", tc.name)
+	}
+}
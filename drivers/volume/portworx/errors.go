@@ -26,3 +26,15 @@ type ErrFailedToGetNodes struct {
 func (e *ErrFailedToGetNodes) Error() string {
 	return fmt.Sprintf("Failed to get nodes for the driver: %v", e.Cause)
 }
+
+// ErrFailedToResizeVolume error type for failing to resize a volume
+type ErrFailedToResizeVolume struct {
+	// ID is the ID/name of the volume that failed to resize
+	ID string
+	// Cause is the underlying cause of the error
+	Cause string
+}
+
+func (e *ErrFailedToResizeVolume) Error() string {
+	return fmt.Sprintf("Failed to resize volume: %v due to err: %v", e.ID, e.Cause)
+}
@@ -1,13 +1,17 @@
 package portworx
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/csv"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	version "github.com/hashicorp/go-version"
@@ -17,11 +21,10 @@ import (
 	"github.com/kubernetes-incubator/external-storage/snapshot/pkg/controller/snapshotter"
 	snapshotVolume "github.com/kubernetes-incubator/external-storage/snapshot/pkg/volume"
 	"github.com/libopenstorage/openstorage/api"
+	"github.com/libopenstorage/openstorage/api/client"
 	clusterclient "github.com/libopenstorage/openstorage/api/client/cluster"
 	volumeclient "github.com/libopenstorage/openstorage/api/client/volume"
 	ost_errors "github.com/libopenstorage/openstorage/api/errors"
-	"github.com/libopenstorage/openstorage/cluster"
-	"github.com/libopenstorage/openstorage/volume"
 	storkvolume "github.com/libopenstorage/stork/drivers/volume"
 	stork_crd "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/errors"
@@ -32,6 +35,7 @@ import (
 	"github.com/portworx/sched-ops/k8s"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -63,6 +67,12 @@ const (
 	// default SDK port
 	defaultSDKPort = 9020
 
+	// enterMaintenanceResource and exitMaintenanceResource are the resources
+	// of the REST API on each node used to put it into and take it out of
+	// maintenance mode
+	enterMaintenanceResource = "entermaintenance"
+	exitMaintenanceResource  = "exitmaintenance"
+
 	// provisioner names for portworx volumes
 	provisionerName    = "kubernetes.io/portworx-volume"
 	csiProvisionerName = "com.openstorage.pxd"
@@ -82,6 +92,16 @@ const (
 	// and snapshots
 	namespaceLabel = "namespace"
 
+	// priorityIOParameter is the StorageClass parameter with the IO priority
+	// for the volume
+	priorityIOParameter = "priority_io"
+
+	// runtimeStateKey, resyncListKey Keys in the runtime state of a
+	// replica set with its state and with the indexes of the replicas being
+	// resynced
+	runtimeStateKey = "RuntimeState"
+	resyncListKey   = "ResyncList"
+
 	// pxRackLabelKey Label for rack information
 	pxRackLabelKey         = "px/rack"
 	snapshotDataNamePrefix = "k8s-volume-snapshot"
@@ -111,6 +131,42 @@ const (
 
 	pxRestPort = "px-api"
 	pxSdkPort  = "px-sdk"
+
+	// APIOption Init option to select the API used to talk to portworx,
+	// either APIRest (default) or APISDK
+	APIOption = "api"
+	// AuthSecretNameOption Init option with the name of the secret holding
+	// the token used to authenticate with the SDK API
+	AuthSecretNameOption = "auth-secret-name"
+	// AuthSecretNamespaceOption Init option with the namespace of the auth
+	// secret. Defaults to the namespace of the portworx service
+	AuthSecretNamespaceOption = "auth-secret-namespace"
+	// AuthSecretKeyOption Init option with the key in the auth secret that
+	// holds the token. Defaults to auth-token
+	AuthSecretKeyOption = "auth-secret-key"
+	// TLSOption Init option to use TLS for the SDK API when set to true.
+	// Setting the CA secret or file also enables TLS
+	TLSOption = "tls"
+	// TLSCASecretNameOption Init option with the name of the secret holding
+	// the CA certificate used to verify the SDK endpoint
+	TLSCASecretNameOption = "tls-ca-secret-name"
+	// TLSCASecretNamespaceOption Init option with the namespace of the CA
+	// secret. Defaults to the namespace of the portworx service
+	TLSCASecretNamespaceOption = "tls-ca-secret-namespace"
+	// TLSCASecretKeyOption Init option with the key in the CA secret that
+	// holds the certificate. Defaults to ca.crt
+	TLSCASecretKeyOption = "tls-ca-secret-key"
+	// TLSCAFileOption Init option with the path of the CA certificate used
+	// to verify the SDK endpoint
+	TLSCAFileOption = "tls-ca-file"
+
+	// APIRest Use the legacy REST API
+	APIRest = "rest"
+	// APISDK Use the OpenStorage SDK gRPC API
+	APISDK = "sdk"
+
+	defaultAuthSecretKey  = "auth-token"
+	defaultTLSCASecretKey = "ca.crt"
 )
 
 type cloudSnapStatus struct {
@@ -146,19 +202,37 @@ var cloudsnapBackoff = wait.Backoff{
 }
 
 type portworx struct {
-	clusterManager cluster.Cluster
-	volDriver      volume.VolumeDriver
+	config         *Config
+	clusterManager clusterClient
+	volDriver      volumeClient
 	store          cache.Store
 	stopChannel    chan struct{}
 	restPort       int
 	sdkPort        int
+
+	attachedVolumesLock sync.RWMutex
+	attachedVolumes     map[string]int
 }
 
 func (p *portworx) String() string {
 	return driverName
 }
 
-func (p *portworx) Init(_ interface{}) error {
+// Init Initializes the driver. The config can be passed in as a *Config,
+// or as a map[string]string with the options to select the API used to talk
+// to portworx and the secret with the token used to authenticate with it
+func (p *portworx) Init(config interface{}) error {
+	switch c := config.(type) {
+	case *Config:
+		p.config = c
+	case map[string]string:
+		p.config = p.newConfigFromOptions(c)
+	default:
+		p.config = p.NewConfig().(*Config)
+	}
+	if err := p.config.Validate(); err != nil {
+		return err
+	}
 
 	if err := p.initPortworxClients(); err != nil {
 		return err
@@ -174,43 +248,59 @@ func (p *portworx) Stop() error {
 }
 
 func (p *portworx) initPortworxClients() error {
-	var endpoint string
-
-	// Check if service name and namespace is provided
+	// Check if service name and namespace is provided in the config or
 	// as environment variables
-	serviceName := os.Getenv(pxServiceName)
+	serviceName := p.config.ServiceName
+	if len(serviceName) == 0 {
+		serviceName = os.Getenv(pxServiceName)
+	}
 	if len(serviceName) == 0 {
 		serviceName = defaultServiceName
 	}
-	namespace := os.Getenv(pxNamespace)
+	namespace := p.config.ServiceNamespace
 	if len(namespace) == 0 {
-		namespace = defaultNamespace
-	}
-
-	svc, err := k8s.Instance().GetService(serviceName, namespace)
-	if err == nil {
-		endpoint = svc.Spec.ClusterIP
-	} else {
-		return fmt.Errorf("Failed to get k8s service spec: %v", err)
+		namespace = os.Getenv(pxNamespace)
 	}
-
-	if len(endpoint) == 0 {
-		return fmt.Errorf("Failed to get endpoint for portworx volume driver")
+	if len(namespace) == 0 {
+		namespace = defaultNamespace
 	}
 
+	endpoint := p.config.Endpoint
 	p.restPort = defaultAPIPort
 	p.sdkPort = defaultSDKPort
+	if len(endpoint) == 0 {
+		svc, err := k8s.Instance().GetService(serviceName, namespace)
+		if err == nil {
+			endpoint = svc.Spec.ClusterIP
+		} else {
+			return fmt.Errorf("Failed to get k8s service spec: %v", err)
+		}
+
+		if len(endpoint) == 0 {
+			return fmt.Errorf("Failed to get endpoint for portworx volume driver")
+		}
 
-	// Get the ports from service
-	for _, svcPort := range svc.Spec.Ports {
-		if svcPort.Name == pxSdkPort &&
-			svcPort.Port != 0 {
-			p.sdkPort = int(svcPort.Port)
-		} else if svcPort.Name == pxRestPort &&
-			svcPort.Port != 0 {
-			p.restPort = int(svcPort.Port)
+		// Get the ports from service
+		for _, svcPort := range svc.Spec.Ports {
+			if svcPort.Name == pxSdkPort &&
+				svcPort.Port != 0 {
+				p.sdkPort = int(svcPort.Port)
+			} else if svcPort.Name == pxRestPort &&
+				svcPort.Port != 0 {
+				p.restPort = int(svcPort.Port)
+			}
 		}
 	}
+	if p.config.RestPort != 0 {
+		p.restPort = p.config.RestPort
+	}
+	if p.config.SDKPort != 0 {
+		p.sdkPort = p.config.SDKPort
+	}
+
+	if p.config.API == APISDK {
+		return p.initSDKClient(endpoint, namespace)
+	}
 
 	logrus.Infof("Using %v:%v as endpoint for portworx REST endpoint", endpoint, p.restPort)
 
@@ -232,8 +322,101 @@ func (p *portworx) initPortworxClients() error {
 	return nil
 }
 
+// initSDKClient Sets up the client for the SDK endpoint, authenticating
+// with the token from the auth secret if one was configured. The token is
+// read again from the secret every AuthTokenRefreshPeriod
+func (p *portworx) initSDKClient(endpoint string, namespace string) error {
+	var token *tokenCredentials
+	if authSecret := p.config.AuthSecret; authSecret != nil {
+		token = &tokenCredentials{
+			getToken: func() (string, error) {
+				value, err := getSecretValue(authSecret, namespace, defaultAuthSecretKey)
+				if err != nil {
+					return "", fmt.Errorf("Error getting token from auth secret: %v", err)
+				}
+				return strings.TrimSpace(string(value)), nil
+			},
+			refreshPeriod: p.config.AuthTokenRefreshPeriod.Duration,
+		}
+		// Fail right away if the token can't be read
+		if _, err := token.currentToken(); err != nil {
+			return err
+		}
+	}
+
+	tlsConfig, err := p.getSDKTLSConfig(namespace)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Using %v:%v as endpoint for portworx SDK endpoint", endpoint, p.sdkPort)
+
+	volumeClient, clusterClient, err := newSDKClients(
+		fmt.Sprintf("%v:%v", endpoint, p.sdkPort),
+		token,
+		tlsConfig,
+		p.config.SDKTimeout.Duration)
+	if err != nil {
+		return err
+	}
+	p.clusterManager = clusterClient
+	p.volDriver = volumeClient
+	return nil
+}
+
+// getSDKTLSConfig Returns the TLS config for the SDK endpoint, or nil if TLS
+// isn't enabled. The CA certificate is read from the CA secret or file if
+// one was configured, otherwise the system roots are used
+func (p *portworx) getSDKTLSConfig(namespace string) (*tls.Config, error) {
+	if p.config.TLS == nil {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		ServerName: p.config.TLS.ServerName,
+	}
+
+	var caCert []byte
+	var err error
+	if caSecret := p.config.TLS.CASecret; caSecret != nil {
+		if caCert, err = getSecretValue(caSecret, namespace, defaultTLSCASecretKey); err != nil {
+			return nil, fmt.Errorf("Error getting CA certificate from secret: %v", err)
+		}
+	} else if p.config.TLS.CAFile != "" {
+		if caCert, err = ioutil.ReadFile(p.config.TLS.CAFile); err != nil {
+			return nil, fmt.Errorf("Error reading CA file %v: %v", p.config.TLS.CAFile, err)
+		}
+	}
+	if caCert != nil {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("No valid CA certificates found for TLS")
+		}
+	}
+	return tlsConfig, nil
+}
+
+// getSecretValue Returns the value of the key in the referenced secret. The
+// namespace and key are used if they aren't set in the reference
+func getSecretValue(ref *SecretReference, namespace string, key string) ([]byte, error) {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	if ref.Key != "" {
+		key = ref.Key
+	}
+	secret, err := k8s.Instance().GetSecret(ref.Name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting secret %v/%v: %v", namespace, ref.Name, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("Secret %v/%v doesn't have a value in key %v", namespace, ref.Name, key)
+	}
+	return value, nil
+}
+
 func (p *portworx) startNodeCache() error {
-	resyncPeriod := 30 * time.Second
+	resyncPeriod := p.config.NodeCacheResyncPeriod.Duration
 
 	config, err := rest.InClusterConfig()
 	if err != nil {
@@ -262,6 +445,10 @@ func (p *portworx) startNodeCache() error {
 	p.store = store
 
 	go controller.Run(p.stopChannel)
+
+	// The number of volumes attached on each node is refreshed along with the
+	// nodes so that GetNodes doesn't have to enumerate all the volumes
+	go wait.Until(p.updateAttachedVolumeCounts, resyncPeriod, p.stopChannel)
 	return nil
 }
 
@@ -281,32 +468,178 @@ func (p *portworx) InspectVolume(volumeID string) (*storkvolume.Info, error) {
 		}
 	}
 
+	return p.getVolumeInfo(vols[0]), nil
+}
+
+// InspectVolumes Inspects the volumes with a single request to the volume
+// driver. The volumes are keyed by the requested ID, which can be either the
+// ID or the name of the volume
+func (p *portworx) InspectVolumes(volumeIDs []string) (map[string]*storkvolume.Info, error) {
+	vols, err := p.volDriver.Inspect(volumeIDs)
+	if err != nil {
+		return nil, &ErrFailedToInspectVolume{
+			ID:    strings.Join(volumeIDs, ","),
+			Cause: fmt.Sprintf("Volume inspect returned err: %v", err),
+		}
+	}
+
+	requested := make(map[string]bool)
+	for _, volumeID := range volumeIDs {
+		requested[volumeID] = true
+	}
+	volumes := make(map[string]*storkvolume.Info)
+	for _, vol := range vols {
+		info := p.getVolumeInfo(vol)
+		if requested[vol.Id] {
+			volumes[vol.Id] = info
+		}
+		if requested[vol.Locator.GetName()] {
+			volumes[vol.Locator.Name] = info
+		}
+	}
+	return volumes, nil
+}
+
+// getVolumeInfo Returns the stork volume info for a portworx volume
+func (p *portworx) getVolumeInfo(vol *api.Volume) *storkvolume.Info {
 	info := &storkvolume.Info{}
-	info.VolumeID = vols[0].Id
-	info.VolumeName = vols[0].Locator.Name
-	for _, rset := range vols[0].ReplicaSets {
+	info.VolumeID = vol.Id
+	info.VolumeName = vol.Locator.Name
+	for _, rset := range vol.ReplicaSets {
 		info.DataNodes = append(info.DataNodes, rset.Nodes...)
 	}
-	if vols[0].Source != nil {
-		info.ParentID = vols[0].Source.Parent
+	if vol.Source != nil {
+		info.ParentID = vol.Source.Parent
 	}
 
-	if len(vols[0].Locator.GetVolumeLabels()) > 0 {
-		info.Labels = vols[0].Locator.GetVolumeLabels()
+	if len(vol.Locator.GetVolumeLabels()) > 0 {
+		info.Labels = vol.Locator.GetVolumeLabels()
 	} else {
 		info.Labels = make(map[string]string)
 	}
 
-	for k, v := range vols[0].Spec.GetVolumeLabels() {
+	for k, v := range vol.Spec.GetVolumeLabels() {
 		info.Labels[k] = v
 	}
 
-	for k, v := range vols[0].Locator.GetVolumeLabels() {
+	for k, v := range vol.Locator.GetVolumeLabels() {
 		info.Labels[k] = v
 	}
 
-	info.VolumeSourceRef = vols[0]
-	return info, nil
+	info.UsedBytes = vol.Usage
+	info.ReplicationStatus, info.DegradedNodes = p.getReplicationStatus(vol)
+	info.AttachedOn = vol.AttachedOn
+	info.IOState = p.mapIOState(vol)
+	info.VolumeSourceRef = vol
+	return info
+}
+
+// getReplicationStatus Returns the replication status of the volume and the
+// nodes whose replicas are being resynced, read from the runtime state of
+// each replica set
+func (p *portworx) getReplicationStatus(vol *api.Volume) (storkvolume.ReplicationStatus, []string) {
+	var degradedNodes []string
+	resyncing := false
+	for i, runtimeState := range vol.RuntimeState {
+		if runtimeState == nil {
+			continue
+		}
+		if strings.Contains(strings.ToLower(runtimeState.RuntimeState[runtimeStateKey]), "resync") {
+			resyncing = true
+		}
+		if i >= len(vol.ReplicaSets) {
+			continue
+		}
+		replicaNodes := vol.ReplicaSets[i].Nodes
+		// The list of replicas is formatted like [0 2]
+		resyncList := strings.Trim(runtimeState.RuntimeState[resyncListKey], "[]")
+		for _, index := range strings.Fields(resyncList) {
+			replica, err := strconv.Atoi(index)
+			if err != nil || replica < 0 || replica >= len(replicaNodes) {
+				continue
+			}
+			resyncing = true
+			degradedNodes = append(degradedNodes, replicaNodes[replica])
+		}
+	}
+
+	switch {
+	case vol.Status == api.VolumeStatus_VOLUME_STATUS_DEGRADED:
+		return storkvolume.ReplicationDegraded, degradedNodes
+	case resyncing:
+		return storkvolume.ReplicationResyncing, degradedNodes
+	case vol.Status == api.VolumeStatus_VOLUME_STATUS_UP:
+		return storkvolume.ReplicationInSync, degradedNodes
+	}
+	return "", degradedNodes
+}
+
+func (p *portworx) mapIOState(vol *api.Volume) storkvolume.IOState {
+	switch {
+	case vol.State == api.VolumeState_VOLUME_STATE_ERROR:
+		return storkvolume.IOStateError
+	case vol.Status == api.VolumeStatus_VOLUME_STATUS_DOWN,
+		vol.Status == api.VolumeStatus_VOLUME_STATUS_NOT_PRESENT:
+		return storkvolume.IOStateOffline
+	case vol.Status == api.VolumeStatus_VOLUME_STATUS_NONE:
+		return ""
+	}
+	return storkvolume.IOStateOnline
+}
+
+func (p *portworx) ResizeVolume(volumeID string, sizeBytes uint64) (*storkvolume.Info, error) {
+	info, err := p.InspectVolume(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	vol, ok := info.VolumeSourceRef.(*api.Volume)
+	if !ok {
+		return nil, fmt.Errorf("Invalid volume returned for %v", volumeID)
+	}
+	if sizeBytes < vol.Spec.Size {
+		return nil, fmt.Errorf("Volume %v can't be shrunk from %v to %v bytes", volumeID, vol.Spec.Size, sizeBytes)
+	}
+	if sizeBytes == vol.Spec.Size {
+		return info, nil
+	}
+
+	if err := p.volDriver.Set(vol.Id, nil, &api.VolumeSpec{Size: sizeBytes}); err != nil {
+		return nil, &ErrFailedToResizeVolume{
+			ID:    volumeID,
+			Cause: err.Error(),
+		}
+	}
+	return p.InspectVolume(volumeID)
+}
+
+// EnterMaintenance Puts the node into maintenance mode. Maintenance mode
+// isn't exposed by the cluster API or the SDK, so the REST API on the node
+// itself is called like pxctl does, using the API used in the rest of the
+// driver
+func (p *portworx) EnterMaintenance(node *storkvolume.NodeInfo) error {
+	return p.maintenanceOp(node, enterMaintenanceResource)
+}
+
+// ExitMaintenance Takes the node out of maintenance mode using the REST API
+// on the node
+func (p *portworx) ExitMaintenance(node *storkvolume.NodeInfo) error {
+	return p.maintenanceOp(node, exitMaintenanceResource)
+}
+
+// maintenanceOp Calls the maintenance resource of the REST API on the
+// management IP of the node
+func (p *portworx) maintenanceOp(node *storkvolume.NodeInfo, resource string) error {
+	if len(node.IPs) == 0 || node.IPs[0] == "" {
+		return fmt.Errorf("No IP found for node %v", node.ID)
+	}
+	clnt, err := client.NewClient(fmt.Sprintf("http://%v:%v", node.IPs[0], p.restPort), "", "stork")
+	if err != nil {
+		return err
+	}
+	if err := clnt.Get().Resource(resource).Do().Error(); err != nil {
+		return fmt.Errorf("Error calling %v on node %v: %v", resource, node.ID, err)
+	}
+	return nil
 }
 
 func (p *portworx) mapNodeStatus(status api.Status) storkvolume.NodeStatus {
@@ -368,16 +701,40 @@ func (p *portworx) GetNodes() ([]*storkvolume.NodeInfo, error) {
 		}
 	}
 
+	// The attached volumes are optional, so they aren't reported until the
+	// volumes have been enumerated by the node cache
+	p.attachedVolumesLock.RLock()
+	attachedVolumes := p.attachedVolumes
+	p.attachedVolumesLock.RUnlock()
+
 	var nodes []*storkvolume.NodeInfo
 	for _, n := range cluster.Nodes {
 		nodeInfo := &storkvolume.NodeInfo{
 			ID:       n.Id,
 			Hostname: strings.ToLower(n.Hostname),
 			Status:   p.mapNodeStatus(n.Status),
+			// Nodes in maintenance are also reported as offline since the
+			// storage on them can't be used
+			Maintenance: n.Status == api.Status_STATUS_MAINTENANCE,
 		}
 		nodeInfo.IPs = append(nodeInfo.IPs, n.MgmtIp)
 		nodeInfo.IPs = append(nodeInfo.IPs, n.DataIp)
 
+		if len(n.Pools) > 0 {
+			nodeInfo.Utilization = &storkvolume.NodeUtilization{}
+			for _, pool := range n.Pools {
+				nodeInfo.Utilization.TotalCapacity += pool.TotalSize
+				nodeInfo.Utilization.UsedCapacity += pool.Used
+			}
+			if attachedVolumes != nil {
+				nodeInfo.Utilization.AttachedVolumes = attachedVolumes[n.Id] +
+					attachedVolumes[n.MgmtIp]
+				if n.DataIp != n.MgmtIp {
+					nodeInfo.Utilization.AttachedVolumes += attachedVolumes[n.DataIp]
+				}
+			}
+		}
+
 		labels, err := p.getNodeLabels(nodeInfo)
 		if err == nil {
 			if rack, ok := labels[pxRackLabelKey]; ok {
@@ -398,6 +755,85 @@ func (p *portworx) GetNodes() ([]*storkvolume.NodeInfo, error) {
 	return nodes, nil
 }
 
+// updateAttachedVolumeCounts Updates the cached number of volumes attached on
+// each node. The previous counts are kept if the volumes can't be enumerated
+func (p *portworx) updateAttachedVolumeCounts() {
+	attachedVolumes, err := p.getAttachedVolumeCounts()
+	if err != nil {
+		logrus.Warnf("Error getting attached volumes for nodes: %v", err)
+		return
+	}
+	p.attachedVolumesLock.Lock()
+	p.attachedVolumes = attachedVolumes
+	p.attachedVolumesLock.Unlock()
+}
+
+// getAttachedVolumeCounts Returns a map of the number of volumes attached
+// keyed by the identifier of the node they are attached on
+func (p *portworx) getAttachedVolumeCounts() (map[string]int, error) {
+	vols, err := p.volDriver.Enumerate(&api.VolumeLocator{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	attachedVolumes := make(map[string]int)
+	for _, vol := range vols {
+		if vol.AttachedOn != "" {
+			attachedVolumes[vol.AttachedOn]++
+		}
+	}
+	return attachedVolumes, nil
+}
+
+// GetProvisionCandidates Returns the online nodes with storage pools that
+// have enough free space for the PVC and match the IO priority requested in
+// the StorageClass
+func (p *portworx) GetProvisionCandidates(
+	pvc *v1.PersistentVolumeClaim,
+	storageClass *storagev1.StorageClass,
+) ([]*storkvolume.ProvisionCandidate, error) {
+	cos := api.CosType_NONE
+	if priority, ok := storageClass.Parameters[priorityIOParameter]; ok {
+		var err error
+		if cos, err = api.CosTypeSimpleValueOf(priority); err != nil {
+			return nil, fmt.Errorf("Invalid %v in StorageClass %v: %v", priorityIOParameter, storageClass.Name, err)
+		}
+	}
+	var size uint64
+	if request, ok := pvc.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+		size = uint64(request.Value())
+	}
+
+	cluster, err := p.clusterManager.Enumerate()
+	if err != nil {
+		return nil, &ErrFailedToGetNodes{
+			Cause: err.Error(),
+		}
+	}
+
+	var candidates []*storkvolume.ProvisionCandidate
+	for _, n := range cluster.Nodes {
+		if p.mapNodeStatus(n.Status) != storkvolume.NodeOnline {
+			continue
+		}
+		candidate := &storkvolume.ProvisionCandidate{
+			NodeID: n.Id,
+		}
+		for _, pool := range n.Pools {
+			if cos != api.CosType_NONE && pool.Cos != cos {
+				continue
+			}
+			if pool.Used > pool.TotalSize || pool.TotalSize-pool.Used < size {
+				continue
+			}
+			candidate.Pools = append(candidate.Pools, strconv.Itoa(int(pool.ID)))
+		}
+		if len(candidate.Pools) > 0 {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
 func (p *portworx) OwnsPVC(pvc *v1.PersistentVolumeClaim) bool {
 
 	provisioner := ""
@@ -429,7 +865,8 @@ func (p *portworx) OwnsPVC(pvc *v1.PersistentVolumeClaim) bool {
 			provisioner = val
 		} else {
 			// Finally check the volume reference in the spec
-			if pv.Spec.PortworxVolume != nil {
+			if pv.Spec.PortworxVolume != nil ||
+				storkvolume.IsCSIVolume(pv, csiProvisionerName) {
 				return true
 			}
 		}
@@ -447,6 +884,7 @@ func (p *portworx) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*stor
 	var volumes []*storkvolume.Info
 	for _, volume := range podSpec.Volumes {
 		volumeName := ""
+		var pv *v1.PersistentVolume
 		if volume.PersistentVolumeClaim != nil {
 			pvc, err := k8s.Instance().GetPersistentVolumeClaim(
 				volume.PersistentVolumeClaim.ClaimName,
@@ -465,6 +903,14 @@ func (p *portworx) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*stor
 				}
 			}
 			volumeName = pvc.Spec.VolumeName
+			pv, err = k8s.Instance().GetPersistentVolume(pvc.Spec.VolumeName)
+			if err != nil {
+				logrus.Warnf("Error getting pv %v for pvc %v: %v", pvc.Spec.VolumeName, pvc.Name, err)
+			} else if storkvolume.IsCSIVolume(pv, csiProvisionerName) {
+				// Volumes provisioned through CSI are referenced by their
+				// handle instead of the PV name
+				volumeName = pv.Spec.CSI.VolumeHandle
+			}
 		} else if volume.PortworxVolume != nil {
 			volumeName = volume.PortworxVolume.VolumeID
 		}
@@ -477,6 +923,9 @@ func (p *portworx) GetPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*stor
 					VolumeName: volumeName,
 				}
 			}
+			if pv != nil {
+				volumeInfo.NodeAffinity = pv.Spec.NodeAffinity
+			}
 			volumes = append(volumes, volumeInfo)
 		}
 	}
@@ -498,6 +947,24 @@ func (p *portworx) GetSnapshotPlugin() snapshotVolume.Plugin {
 	return p
 }
 
+func (p *portworx) Capabilities() *storkvolume.Capabilities {
+	return &storkvolume.Capabilities{
+		Snapshots:      true,
+		CloudSnapshots: true,
+		// Group snapshots aren't available in the SDK API
+		GroupSnapshots: p.config == nil || p.config.API != APISDK,
+		ClusterPair:    true,
+		Migration:      true,
+		Resize:         true,
+		Maintenance:    true,
+		TopologyLevels: []storkvolume.TopologyLevel{
+			storkvolume.TopologyLevelRack,
+			storkvolume.TopologyLevelZone,
+			storkvolume.TopologyLevelRegion,
+		},
+	}
+}
+
 func (p *portworx) getSnapshotName(tags *map[string]string) string {
 	return "snapshot-" + (*tags)[snapshotter.CloudSnapshotCreatedForVolumeSnapshotUIDTag]
 }
@@ -550,7 +1017,7 @@ func (p *portworx) SnapshotCreate(
 	switch snapType {
 	case crdv1.PortworxSnapshotTypeCloud:
 		log.SnapshotLog(snap).Debugf("Cloud SnapshotCreate for pv: %+v \n tags: %v", pv, tags)
-		ok, msg, err := p.ensureNodesHaveMinVersion("2.0")
+		ok, msg, err := p.ensureNodesHaveMinVersion(p.config.MinVersions.CloudSnapshots)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -558,7 +1025,7 @@ func (p *portworx) SnapshotCreate(
 		if !ok {
 			err = &errors.ErrNotSupported{
 				Feature: "Cloud snapshots",
-				Reason:  "API changes require PX version 2.0 onwards: " + msg,
+				Reason:  "API changes require PX version " + p.config.MinVersions.CloudSnapshots + " onwards: " + msg,
 			}
 
 			return nil, getErrorSnapshotConditions(err), err
@@ -1175,7 +1642,7 @@ func getCloudSnapStatusString(status *api.CloudBackupStatus) string {
 }
 
 func (p *portworx) CreatePair(pair *stork_crd.ClusterPair) (string, error) {
-	ok, msg, err := p.ensureNodesHaveMinVersion("2.0")
+	ok, msg, err := p.ensureNodesHaveMinVersion(p.config.MinVersions.Migration)
 	if err != nil {
 		return "", err
 	}
@@ -1183,7 +1650,7 @@ func (p *portworx) CreatePair(pair *stork_crd.ClusterPair) (string, error) {
 	if !ok {
 		err = &errors.ErrNotSupported{
 			Feature: "Cluster pair",
-			Reason:  "Only supported on PX version 2.0 onwards: " + msg,
+			Reason:  "Only supported on PX version " + p.config.MinVersions.Migration + " onwards: " + msg,
 		}
 		return "", err
 	}
@@ -1211,7 +1678,7 @@ func (p *portworx) DeletePair(pair *stork_crd.ClusterPair) error {
 }
 
 func (p *portworx) StartMigration(migration *stork_crd.Migration) ([]*stork_crd.VolumeInfo, error) {
-	ok, msg, err := p.ensureNodesHaveMinVersion("2.0")
+	ok, msg, err := p.ensureNodesHaveMinVersion(p.config.MinVersions.Migration)
 	if err != nil {
 		return nil, err
 	}
@@ -1219,7 +1686,7 @@ func (p *portworx) StartMigration(migration *stork_crd.Migration) ([]*stork_crd.
 	if !ok {
 		err = &errors.ErrNotSupported{
 			Feature: "Migration",
-			Reason:  "Only supported on PX version 2.0 onwards: " + msg,
+			Reason:  "Only supported on PX version " + p.config.MinVersions.Migration + " onwards: " + msg,
 		}
 		return nil, err
 	}
@@ -1368,7 +1835,7 @@ func (p *portworx) UpdateMigratedPersistentVolumeSpec(
 
 func (p *portworx) CreateGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) (
 	*storkvolume.GroupSnapshotCreateResponse, error) {
-	ok, msg, err := p.ensureNodesHaveMinVersion("2.0.2")
+	ok, msg, err := p.ensureNodesHaveMinVersion(p.config.MinVersions.GroupSnapshots)
 	if err != nil {
 		return nil, err
 	}
@@ -1376,7 +1843,7 @@ func (p *portworx) CreateGroupSnapshot(snap *stork_crd.GroupVolumeSnapshot) (
 	if !ok {
 		err = &errors.ErrNotSupported{
 			Feature: "Group snapshots using CRD",
-			Reason:  "Only supported on PX version 2.0.2 onwards: " + msg,
+			Reason:  "Only supported on PX version " + p.config.MinVersions.GroupSnapshots + " onwards: " + msg,
 		}
 
 		return nil, err
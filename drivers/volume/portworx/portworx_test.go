@@ -0,0 +1,47 @@
+// +build unittest
+
+package portworx
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenance(t *testing.T) {
+	var requests []string
+	failRequests := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if failRequests {
+			http.Error(w, "node is busy", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err, "Error parsing server address")
+	restPort, err := strconv.Atoi(port)
+	require.NoError(t, err, "Error parsing server port")
+
+	p := &portworx{restPort: restPort}
+	node := &storkvolume.NodeInfo{ID: "node1", IPs: []string{host, "192.168.0.1"}}
+	require.True(t, p.Capabilities().Maintenance, "Expected maintenance to be supported")
+
+	// The REST API on the management IP of the node should be called
+	require.NoError(t, p.EnterMaintenance(node), "Error entering maintenance")
+	require.NoError(t, p.ExitMaintenance(node), "Error exiting maintenance")
+	require.Equal(t, []string{"GET /entermaintenance", "GET /exitmaintenance"}, requests, "Unexpected requests")
+
+	failRequests = true
+	err = p.EnterMaintenance(node)
+	require.Error(t, err, "Expected error entering maintenance")
+	require.Contains(t, err.Error(), "node is busy", "Unexpected error")
+
+	err = p.EnterMaintenance(&storkvolume.NodeInfo{ID: "node2"})
+	require.Error(t, err, "Expected error for node without IP")
+}
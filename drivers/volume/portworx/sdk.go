@@ -0,0 +1,511 @@
+package portworx
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/libopenstorage/openstorage/api"
+	ost_errors "github.com/libopenstorage/openstorage/api/errors"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// defaultSDKTimeout Default timeout for each call made to the SDK endpoint
+const defaultSDKTimeout = 1 * time.Minute
+
+// volumeClient Operations on volumes used by the driver. Implemented by the
+// REST client and by sdkVolumeClient
+type volumeClient interface {
+	Inspect(volumeIDs []string) ([]*api.Volume, error)
+	Enumerate(locator *api.VolumeLocator, labels map[string]string) ([]*api.Volume, error)
+	Set(volumeID string, locator *api.VolumeLocator, spec *api.VolumeSpec) error
+	Delete(volumeID string) error
+	Snapshot(volumeID string, readonly bool, locator *api.VolumeLocator, noRetry bool) (string, error)
+	SnapshotGroup(groupID string, labels map[string]string, volumeIDs []string) (*api.GroupSnapCreateResponse, error)
+	CloudBackupCreate(input *api.CloudBackupCreateRequest) (*api.CloudBackupCreateResponse, error)
+	CloudBackupGroupCreate(input *api.CloudBackupGroupCreateRequest) (*api.CloudBackupGroupCreateResponse, error)
+	CloudBackupRestore(input *api.CloudBackupRestoreRequest) (*api.CloudBackupRestoreResponse, error)
+	CloudBackupDelete(input *api.CloudBackupDeleteRequest) error
+	CloudBackupStatus(input *api.CloudBackupStatusRequest) (*api.CloudBackupStatusResponse, error)
+	CloudMigrateStart(request *api.CloudMigrateStartRequest) (*api.CloudMigrateStartResponse, error)
+	CloudMigrateCancel(request *api.CloudMigrateCancelRequest) error
+	CloudMigrateStatus(request *api.CloudMigrateStatusRequest) (*api.CloudMigrateStatusResponse, error)
+}
+
+// clusterClient Operations on the cluster used by the driver. Implemented
+// by the REST client and by sdkClusterClient
+type clusterClient interface {
+	Enumerate() (api.Cluster, error)
+	CreatePair(request *api.ClusterPairCreateRequest) (*api.ClusterPairCreateResponse, error)
+	DeletePair(clusterID string) error
+}
+
+// tokenCredentials Adds the auth token as a bearer token to each call. The
+// token is only sent over connections using TLS. It is fetched again once it
+// is older than the refresh period so that rotated tokens get used
+type tokenCredentials struct {
+	getToken      func() (string, error)
+	refreshPeriod time.Duration
+	lock          sync.Mutex
+	token         string
+	fetchedAt     time.Time
+}
+
+func (t *tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := t.currentToken()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"authorization": "bearer " + token,
+	}, nil
+}
+
+// currentToken Returns the cached token, fetching it again if it is older
+// than the refresh period. If the token can't be fetched the cached one
+// keeps being used until the next refresh
+func (t *tokenCredentials) currentToken() (string, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.token != "" && time.Since(t.fetchedAt) < t.refreshPeriod {
+		return t.token, nil
+	}
+	token, err := t.getToken()
+	if err != nil {
+		if t.token == "" {
+			return "", fmt.Errorf("Error getting auth token: %v", err)
+		}
+		logrus.Warnf("Error refreshing auth token, using the previous token: %v", err)
+		t.fetchedAt = time.Now()
+		return t.token, nil
+	}
+	t.token = token
+	t.fetchedAt = time.Now()
+	return t.token, nil
+}
+
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// sdkVolumeClient Implements the volume operations using the OpenStorage
+// SDK gRPC API
+type sdkVolumeClient struct {
+	timeout     time.Duration
+	volumes     api.OpenStorageVolumeClient
+	cloudBackup api.OpenStorageCloudBackupClient
+	migrate     api.OpenStorageMigrateClient
+}
+
+// sdkClusterClient Implements the cluster operations using the OpenStorage
+// SDK gRPC API
+type sdkClusterClient struct {
+	timeout time.Duration
+	cluster api.OpenStorageClusterClient
+	nodes   api.OpenStorageNodeClient
+	pairs   api.OpenStorageClusterPairClient
+}
+
+// newSDKClients Connects to the SDK endpoint, using TLS if the TLS config
+// isn't nil. The token credentials, if not nil, add the token to every call,
+// which times out after the given duration. Tokens can only be sent when
+// using TLS
+func newSDKClients(
+	endpoint string,
+	token *tokenCredentials,
+	tlsConfig *tls.Config,
+	timeout time.Duration,
+) (*sdkVolumeClient, *sdkClusterClient, error) {
+	var opts []grpc.DialOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if token != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(token))
+	}
+	conn, err := grpc.Dial(endpoint, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error connecting to SDK endpoint %v: %v", endpoint, err)
+	}
+	volumes := &sdkVolumeClient{
+		timeout:     timeout,
+		volumes:     api.NewOpenStorageVolumeClient(conn),
+		cloudBackup: api.NewOpenStorageCloudBackupClient(conn),
+		migrate:     api.NewOpenStorageMigrateClient(conn),
+	}
+	cluster := &sdkClusterClient{
+		timeout: timeout,
+		cluster: api.NewOpenStorageClusterClient(conn),
+		nodes:   api.NewOpenStorageNodeClient(conn),
+		pairs:   api.NewOpenStorageClusterPairClient(conn),
+	}
+	return volumes, cluster, nil
+}
+
+func newSDKContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// fromSDKError Converts errors returned by the SDK to the errors returned
+// by the REST client, since callers check for them
+func fromSDKError(err error, id string, objectType string) error {
+	if status.Code(err) == codes.AlreadyExists {
+		return &ost_errors.ErrExists{
+			ID:   id,
+			Type: objectType,
+		}
+	}
+	return err
+}
+
+func (s *sdkVolumeClient) Inspect(volumeIDs []string) ([]*api.Volume, error) {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	var volumes []*api.Volume
+	for _, volumeID := range volumeIDs {
+		response, err := s.volumes.Inspect(ctx, &api.SdkVolumeInspectRequest{VolumeId: volumeID})
+		if err != nil {
+			// Missing volumes are left out like with the REST client
+			if status.Code(err) == codes.NotFound {
+				continue
+			}
+			return nil, err
+		}
+		volumes = append(volumes, response.Volume)
+	}
+	return volumes, nil
+}
+
+func (s *sdkVolumeClient) Enumerate(locator *api.VolumeLocator, labels map[string]string) ([]*api.Volume, error) {
+	request := &api.SdkVolumeEnumerateWithFiltersRequest{Locator: locator}
+	if len(labels) > 0 {
+		request.Locator = &api.VolumeLocator{
+			VolumeLabels: make(map[string]string),
+		}
+		if locator != nil {
+			request.Locator.Name = locator.Name
+			for k, v := range locator.VolumeLabels {
+				request.Locator.VolumeLabels[k] = v
+			}
+		}
+		for k, v := range labels {
+			request.Locator.VolumeLabels[k] = v
+		}
+	}
+
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	response, err := s.volumes.EnumerateWithFilters(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return s.Inspect(response.VolumeIds)
+}
+
+// Set Only updates the labels and the size of the volume, which are the
+// only fields set by the driver
+func (s *sdkVolumeClient) Set(volumeID string, locator *api.VolumeLocator, spec *api.VolumeSpec) error {
+	request := &api.SdkVolumeUpdateRequest{
+		VolumeId: volumeID,
+		Locator:  locator,
+	}
+	if spec != nil && spec.Size != 0 {
+		request.Spec = &api.VolumeSpecUpdate{
+			SizeOpt: &api.VolumeSpecUpdate_Size{Size: spec.Size},
+		}
+	}
+
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	_, err := s.volumes.Update(ctx, request)
+	return err
+}
+
+func (s *sdkVolumeClient) Delete(volumeID string) error {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	_, err := s.volumes.Delete(ctx, &api.SdkVolumeDeleteRequest{VolumeId: volumeID})
+	return err
+}
+
+// Snapshot Creates a snapshot of the volume if readonly is set. Otherwise
+// creates a writable clone of it, which is used to restore snapshots
+func (s *sdkVolumeClient) Snapshot(volumeID string, readonly bool, locator *api.VolumeLocator, noRetry bool) (string, error) {
+	if locator == nil {
+		locator = &api.VolumeLocator{}
+	}
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	if readonly {
+		response, err := s.volumes.SnapshotCreate(ctx, &api.SdkVolumeSnapshotCreateRequest{
+			VolumeId: volumeID,
+			Name:     locator.Name,
+			Labels:   locator.VolumeLabels,
+		})
+		if err != nil {
+			return "", fromSDKError(err, locator.Name, "Snapshot")
+		}
+		return response.SnapshotId, nil
+	}
+
+	response, err := s.volumes.Clone(ctx, &api.SdkVolumeCloneRequest{
+		Name:     locator.Name,
+		ParentId: volumeID,
+	})
+	if err != nil {
+		return "", fromSDKError(err, locator.Name, "Volume")
+	}
+	if len(locator.VolumeLabels) > 0 {
+		if err := s.Set(response.VolumeId, &api.VolumeLocator{VolumeLabels: locator.VolumeLabels}, nil); err != nil {
+			return "", err
+		}
+	}
+	return response.VolumeId, nil
+}
+
+// SnapshotGroup Group snapshots aren't available in the SDK API, so the
+// driver doesn't report the capability when using it
+func (s *sdkVolumeClient) SnapshotGroup(groupID string, labels map[string]string, volumeIDs []string) (*api.GroupSnapCreateResponse, error) {
+	return nil, &errors.ErrNotSupported{
+		Feature: "Group snapshots",
+		Reason:  "Not available in the SDK API",
+	}
+}
+
+func (s *sdkVolumeClient) CloudBackupCreate(input *api.CloudBackupCreateRequest) (*api.CloudBackupCreateResponse, error) {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	response, err := s.cloudBackup.Create(ctx, &api.SdkCloudBackupCreateRequest{
+		VolumeId:     input.VolumeID,
+		CredentialId: input.CredentialUUID,
+		Full:         input.Full,
+		TaskId:       input.Name,
+		Labels:       input.Labels,
+	})
+	if err != nil {
+		return nil, fromSDKError(err, input.Name, "Cloud backup")
+	}
+	return &api.CloudBackupCreateResponse{Name: response.TaskId}, nil
+}
+
+// CloudBackupGroupCreate Group cloud backups aren't available in the SDK
+// API, so the driver doesn't report the group snapshot capability when using
+// it
+func (s *sdkVolumeClient) CloudBackupGroupCreate(input *api.CloudBackupGroupCreateRequest) (*api.CloudBackupGroupCreateResponse, error) {
+	return nil, &errors.ErrNotSupported{
+		Feature: "Group cloud backups",
+		Reason:  "Not available in the SDK API",
+	}
+}
+
+func (s *sdkVolumeClient) CloudBackupRestore(input *api.CloudBackupRestoreRequest) (*api.CloudBackupRestoreResponse, error) {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	response, err := s.cloudBackup.Restore(ctx, &api.SdkCloudBackupRestoreRequest{
+		BackupId:          input.ID,
+		RestoreVolumeName: input.RestoreVolumeName,
+		CredentialId:      input.CredentialUUID,
+		NodeId:            input.NodeID,
+		TaskId:            input.Name,
+	})
+	if err != nil {
+		return nil, fromSDKError(err, input.Name, "Cloud restore")
+	}
+	return &api.CloudBackupRestoreResponse{
+		RestoreVolumeID: response.RestoreVolumeId,
+		Name:            response.TaskId,
+	}, nil
+}
+
+func (s *sdkVolumeClient) CloudBackupDelete(input *api.CloudBackupDeleteRequest) error {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	_, err := s.cloudBackup.Delete(ctx, &api.SdkCloudBackupDeleteRequest{
+		BackupId:     input.ID,
+		CredentialId: input.CredentialUUID,
+		Force:        input.Force,
+	})
+	return err
+}
+
+func (s *sdkVolumeClient) CloudBackupStatus(input *api.CloudBackupStatusRequest) (*api.CloudBackupStatusResponse, error) {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	response, err := s.cloudBackup.Status(ctx, &api.SdkCloudBackupStatusRequest{
+		VolumeId: input.SrcVolumeID,
+		Local:    input.Local,
+		TaskId:   input.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]api.CloudBackupStatus)
+	for name, s := range response.Statuses {
+		backupStatus := api.CloudBackupStatus{
+			ID:             s.BackupId,
+			OpType:         fromSDKCloudBackupOpType(s.Optype),
+			Status:         fromSDKCloudBackupStatusType(s.Status),
+			BytesDone:      s.BytesDone,
+			BytesTotal:     s.BytesTotal,
+			EtaSeconds:     s.EtaSeconds,
+			NodeID:         s.NodeId,
+			SrcVolumeID:    s.SrcVolumeId,
+			Info:           s.Info,
+			CredentialUUID: s.CredentialId,
+		}
+		if s.StartTime != nil {
+			backupStatus.StartTime, _ = ptypes.Timestamp(s.StartTime)
+		}
+		if s.CompletedTime != nil {
+			backupStatus.CompletedTime, _ = ptypes.Timestamp(s.CompletedTime)
+		}
+		statuses[name] = backupStatus
+	}
+	return &api.CloudBackupStatusResponse{Statuses: statuses}, nil
+}
+
+func fromSDKCloudBackupOpType(t api.SdkCloudBackupOpType) api.CloudBackupOpType {
+	if t == api.SdkCloudBackupOpType_SdkCloudBackupOpTypeRestoreOp {
+		return api.CloudRestoreOp
+	}
+	return api.CloudBackupOp
+}
+
+func fromSDKCloudBackupStatusType(t api.SdkCloudBackupStatusType) api.CloudBackupStatusType {
+	switch t {
+	case api.SdkCloudBackupStatusType_SdkCloudBackupStatusTypeDone:
+		return api.CloudBackupStatusDone
+	case api.SdkCloudBackupStatusType_SdkCloudBackupStatusTypeAborted:
+		return api.CloudBackupStatusAborted
+	case api.SdkCloudBackupStatusType_SdkCloudBackupStatusTypePaused:
+		return api.CloudBackupStatusPaused
+	case api.SdkCloudBackupStatusType_SdkCloudBackupStatusTypeStopped:
+		return api.CloudBackupStatusStopped
+	case api.SdkCloudBackupStatusType_SdkCloudBackupStatusTypeActive:
+		return api.CloudBackupStatusActive
+	case api.SdkCloudBackupStatusType_SdkCloudBackupStatusTypeFailed:
+		return api.CloudBackupStatusFailed
+	default:
+		return api.CloudBackupStatusNotStarted
+	}
+}
+
+func (s *sdkVolumeClient) CloudMigrateStart(request *api.CloudMigrateStartRequest) (*api.CloudMigrateStartResponse, error) {
+	sdkRequest := &api.SdkCloudMigrateStartRequest{
+		ClusterId: request.ClusterId,
+		TaskId:    request.TaskId,
+	}
+	switch request.Operation {
+	case api.CloudMigrate_MigrateCluster:
+		sdkRequest.Opt = &api.SdkCloudMigrateStartRequest_AllVolumes{
+			AllVolumes: &api.SdkCloudMigrateStartRequest_MigrateAllVolumes{},
+		}
+	case api.CloudMigrate_MigrateVolume:
+		sdkRequest.Opt = &api.SdkCloudMigrateStartRequest_Volume{
+			Volume: &api.SdkCloudMigrateStartRequest_MigrateVolume{VolumeId: request.TargetId},
+		}
+	case api.CloudMigrate_MigrateVolumeGroup:
+		sdkRequest.Opt = &api.SdkCloudMigrateStartRequest_VolumeGroup{
+			VolumeGroup: &api.SdkCloudMigrateStartRequest_MigrateVolumeGroup{GroupId: request.TargetId},
+		}
+	default:
+		return nil, fmt.Errorf("Invalid migration operation %v", request.Operation)
+	}
+
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	response, err := s.migrate.Start(ctx, sdkRequest)
+	if err != nil {
+		return nil, fromSDKError(err, request.TaskId, "Migration")
+	}
+	return response.Result, nil
+}
+
+func (s *sdkVolumeClient) CloudMigrateCancel(request *api.CloudMigrateCancelRequest) error {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	_, err := s.migrate.Cancel(ctx, &api.SdkCloudMigrateCancelRequest{Request: request})
+	return err
+}
+
+func (s *sdkVolumeClient) CloudMigrateStatus(request *api.CloudMigrateStatusRequest) (*api.CloudMigrateStatusResponse, error) {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	response, err := s.migrate.Status(ctx, &api.SdkCloudMigrateStatusRequest{Request: request})
+	if err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
+
+// Enumerate Returns the current cluster with all its nodes
+func (s *sdkClusterClient) Enumerate() (api.Cluster, error) {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	clusterResponse, err := s.cluster.InspectCurrent(ctx, &api.SdkClusterInspectCurrentRequest{})
+	if err != nil {
+		return api.Cluster{}, err
+	}
+	cluster := api.Cluster{
+		Id:     clusterResponse.Cluster.Id,
+		Status: clusterResponse.Cluster.Status,
+	}
+
+	nodesResponse, err := s.nodes.Enumerate(ctx, &api.SdkNodeEnumerateRequest{})
+	if err != nil {
+		return api.Cluster{}, err
+	}
+	for _, nodeID := range nodesResponse.NodeIds {
+		nodeResponse, err := s.nodes.Inspect(ctx, &api.SdkNodeInspectRequest{NodeId: nodeID})
+		if err != nil {
+			return api.Cluster{}, err
+		}
+		n := nodeResponse.Node
+		node := api.Node{
+			Id:                n.Id,
+			SchedulerNodeName: n.SchedulerNodeName,
+			Cpu:               n.Cpu,
+			MemTotal:          n.MemTotal,
+			MemUsed:           n.MemUsed,
+			MemFree:           n.MemFree,
+			Avgload:           int(n.AvgLoad),
+			Status:            n.Status,
+			MgmtIp:            n.MgmtIp,
+			DataIp:            n.DataIp,
+			Hostname:          n.Hostname,
+			NodeLabels:        n.NodeLabels,
+		}
+		for _, pool := range n.Pools {
+			node.Pools = append(node.Pools, *pool)
+		}
+		cluster.Nodes = append(cluster.Nodes, node)
+	}
+	return cluster, nil
+}
+
+func (s *sdkClusterClient) CreatePair(request *api.ClusterPairCreateRequest) (*api.ClusterPairCreateResponse, error) {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	response, err := s.pairs.Create(ctx, &api.SdkClusterPairCreateRequest{Request: request})
+	if err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
+
+func (s *sdkClusterClient) DeletePair(clusterID string) error {
+	ctx, cancel := newSDKContext(s.timeout)
+	defer cancel()
+	_, err := s.pairs.Delete(ctx, &api.SdkClusterPairDeleteRequest{ClusterId: clusterID})
+	return err
+}
@@ -0,0 +1,422 @@
+// +build unittest
+
+package portworx
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/libopenstorage/openstorage/api"
+	ost_errors "github.com/libopenstorage/openstorage/api/errors"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const sdkTestTimeout = 5 * time.Second
+
+// fakeVolumeServer Serves the volume operations of the SDK used by the
+// driver. Calls to other operations panic since the embedded interface is
+// nil
+type fakeVolumeServer struct {
+	api.OpenStorageVolumeServer
+	volumes           map[string]*api.Volume
+	enumerateRequest  *api.SdkVolumeEnumerateWithFiltersRequest
+	snapshotRequest   *api.SdkVolumeSnapshotCreateRequest
+	cloneRequest      *api.SdkVolumeCloneRequest
+	updateRequest     *api.SdkVolumeUpdateRequest
+	authorization     []string
+	snapshotCreateErr error
+}
+
+func newFakeVolumeServer() *fakeVolumeServer {
+	return &fakeVolumeServer{
+		volumes: map[string]*api.Volume{
+			"vol1": {Id: "vol1", Locator: &api.VolumeLocator{Name: "vol1"}},
+			"vol2": {Id: "vol2", Locator: &api.VolumeLocator{Name: "vol2"}},
+		},
+	}
+}
+
+func (f *fakeVolumeServer) Inspect(
+	ctx context.Context,
+	request *api.SdkVolumeInspectRequest,
+) (*api.SdkVolumeInspectResponse, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		f.authorization = md["authorization"]
+	}
+	volume, ok := f.volumes[request.VolumeId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Volume %v not found", request.VolumeId)
+	}
+	return &api.SdkVolumeInspectResponse{Volume: volume}, nil
+}
+
+func (f *fakeVolumeServer) EnumerateWithFilters(
+	ctx context.Context,
+	request *api.SdkVolumeEnumerateWithFiltersRequest,
+) (*api.SdkVolumeEnumerateWithFiltersResponse, error) {
+	f.enumerateRequest = request
+	// Return a missing volume too to check that it is left out
+	return &api.SdkVolumeEnumerateWithFiltersResponse{VolumeIds: []string{"vol1", "missing"}}, nil
+}
+
+func (f *fakeVolumeServer) SnapshotCreate(
+	ctx context.Context,
+	request *api.SdkVolumeSnapshotCreateRequest,
+) (*api.SdkVolumeSnapshotCreateResponse, error) {
+	f.snapshotRequest = request
+	if f.snapshotCreateErr != nil {
+		return nil, f.snapshotCreateErr
+	}
+	return &api.SdkVolumeSnapshotCreateResponse{SnapshotId: "snap1"}, nil
+}
+
+func (f *fakeVolumeServer) Clone(
+	ctx context.Context,
+	request *api.SdkVolumeCloneRequest,
+) (*api.SdkVolumeCloneResponse, error) {
+	f.cloneRequest = request
+	return &api.SdkVolumeCloneResponse{VolumeId: "clone1"}, nil
+}
+
+func (f *fakeVolumeServer) Update(
+	ctx context.Context,
+	request *api.SdkVolumeUpdateRequest,
+) (*api.SdkVolumeUpdateResponse, error) {
+	f.updateRequest = request
+	return &api.SdkVolumeUpdateResponse{}, nil
+}
+
+// startFakeSDKServer Starts a server for the fake volume service, using TLS
+// with the certificate if it isn't nil, and returns its endpoint
+func startFakeSDKServer(
+	t *testing.T,
+	volumeServer *fakeVolumeServer,
+	cert *tls.Certificate,
+) (string, *grpc.Server) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Error creating listener")
+	var opts []grpc.ServerOption
+	if cert != nil {
+		opts = append(opts, grpc.Creds(credentials.NewServerTLSFromCert(cert)))
+	}
+	server := grpc.NewServer(opts...)
+	api.RegisterOpenStorageVolumeServer(server, volumeServer)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return listener.Addr().String(), server
+}
+
+// newTestCertificate Returns a certificate for 127.0.0.1 signed by a new
+// CA, along with a pool with the CA certificate
+func newTestCertificate(t *testing.T) (*tls.Certificate, *x509.CertPool) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Error generating CA key")
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err, "Error creating CA certificate")
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err, "Error parsing CA certificate")
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Error generating key")
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	require.NoError(t, err, "Error creating certificate")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, pool
+}
+
+func TestSDK(t *testing.T) {
+	t.Run("inspectTest", inspectTest)
+	t.Run("inspectVolumesTest", inspectVolumesTest)
+	t.Run("enumerateLabelsTest", enumerateLabelsTest)
+	t.Run("fromSDKErrorTest", fromSDKErrorTest)
+	t.Run("snapshotTest", snapshotTest)
+	t.Run("cloneTest", cloneTest)
+	t.Run("tlsTest", tlsTest)
+	t.Run("tokenWithoutTLSTest", tokenWithoutTLSTest)
+	t.Run("tokenRefreshTest", tokenRefreshTest)
+}
+
+// newTestVolumeClient Starts a fake SDK server without TLS and returns a
+// client connected to it
+func newTestVolumeClient(t *testing.T, volumeServer *fakeVolumeServer) (*sdkVolumeClient, *grpc.Server) {
+	endpoint, server := startFakeSDKServer(t, volumeServer, nil)
+	volumeClient, _, err := newSDKClients(endpoint, nil, nil, sdkTestTimeout)
+	require.NoError(t, err, "Error creating SDK clients")
+	return volumeClient, server
+}
+
+func inspectTest(t *testing.T) {
+	volumeServer := newFakeVolumeServer()
+	volumeClient, server := newTestVolumeClient(t, volumeServer)
+	defer server.Stop()
+
+	// Missing volumes should be left out like with the REST client
+	volumes, err := volumeClient.Inspect([]string{"vol1", "missing", "vol2"})
+	require.NoError(t, err, "Error inspecting volumes")
+	require.Len(t, volumes, 2, "Unexpected number of volumes")
+	require.Equal(t, "vol1", volumes[0].Id, "Unexpected volume")
+	require.Equal(t, "vol2", volumes[1].Id, "Unexpected volume")
+}
+
+func inspectVolumesTest(t *testing.T) {
+	volumeServer := newFakeVolumeServer()
+	volumeServer.volumes["pvc-1"] = &api.Volume{
+		Id:         "123",
+		Locator:    &api.VolumeLocator{Name: "pvc-1"},
+		State:      api.VolumeState_VOLUME_STATE_ERROR,
+		AttachedOn: "node1",
+	}
+	volumeClient, server := newTestVolumeClient(t, volumeServer)
+	defer server.Stop()
+	p := &portworx{volDriver: volumeClient}
+
+	// Volumes should be keyed by the requested ID, which can also be the
+	// name of the volume, and missing volumes should be left out
+	volumes, err := p.InspectVolumes([]string{"vol1", "pvc-1", "missing"})
+	require.NoError(t, err, "Error inspecting volumes")
+	require.Len(t, volumes, 2, "Unexpected number of volumes")
+	require.Equal(t, "vol1", volumes["vol1"].VolumeID, "Unexpected volume")
+	require.Equal(t, "123", volumes["pvc-1"].VolumeID, "Unexpected volume")
+	require.Equal(t, "pvc-1", volumes["pvc-1"].VolumeName, "Unexpected volume name")
+	require.Equal(t, "node1", volumes["pvc-1"].AttachedOn, "Unexpected attached node")
+	require.Equal(t, storkvolume.IOStateError, volumes["pvc-1"].IOState, "Unexpected IO state")
+}
+
+func enumerateLabelsTest(t *testing.T) {
+	volumeServer := newFakeVolumeServer()
+	volumeClient, server := newTestVolumeClient(t, volumeServer)
+	defer server.Stop()
+
+	testCases := []struct {
+		name            string
+		locator         *api.VolumeLocator
+		labels          map[string]string
+		expectedLocator *api.VolumeLocator
+	}{
+		{
+			name:            "no labels",
+			locator:         &api.VolumeLocator{Name: "vol1", VolumeLabels: map[string]string{"a": "1"}},
+			expectedLocator: &api.VolumeLocator{Name: "vol1", VolumeLabels: map[string]string{"a": "1"}},
+		},
+		{
+			name:            "labels without locator",
+			labels:          map[string]string{"b": "2"},
+			expectedLocator: &api.VolumeLocator{VolumeLabels: map[string]string{"b": "2"}},
+		},
+		{
+			name:    "labels merged with locator",
+			locator: &api.VolumeLocator{Name: "vol1", VolumeLabels: map[string]string{"a": "1", "b": "1"}},
+			labels:  map[string]string{"b": "2", "c": "3"},
+			expectedLocator: &api.VolumeLocator{
+				Name:         "vol1",
+				VolumeLabels: map[string]string{"a": "1", "b": "2", "c": "3"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		var original *api.VolumeLocator
+		if tc.locator != nil {
+			original = &api.VolumeLocator{Name: tc.locator.Name, VolumeLabels: make(map[string]string)}
+			for k, v := range tc.locator.VolumeLabels {
+				original.VolumeLabels[k] = v
+			}
+		}
+
+		volumes, err := volumeClient.Enumerate(tc.locator, tc.labels)
+		require.NoError(t, err, "%v: Error enumerating volumes", tc.name)
+		require.Len(t, volumes, 1, "%v: Unexpected number of volumes", tc.name)
+		require.Equal(t, tc.expectedLocator.Name, volumeServer.enumerateRequest.Locator.GetName(),
+			"%v: Unexpected name in locator", tc.name)
+		require.Equal(t, tc.expectedLocator.VolumeLabels, volumeServer.enumerateRequest.Locator.GetVolumeLabels(),
+			"%v: Unexpected labels in locator", tc.name)
+		require.Equal(t, original.GetName(), tc.locator.GetName(), "%v: Locator passed in shouldn't be modified", tc.name)
+		require.Equal(t, original.GetVolumeLabels(), tc.locator.GetVolumeLabels(),
+			"%v: Locator passed in shouldn't be modified", tc.name)
+	}
+}
+
+func fromSDKErrorTest(t *testing.T) {
+	otherErr := fmt.Errorf("other error")
+	notFoundErr := status.Error(codes.NotFound, "not found")
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "already exists",
+			err:      status.Error(codes.AlreadyExists, "already exists"),
+			expected: &ost_errors.ErrExists{ID: "snap1", Type: "Snapshot"},
+		},
+		{
+			name:     "other status",
+			err:      notFoundErr,
+			expected: notFoundErr,
+		},
+		{
+			name:     "not a status",
+			err:      otherErr,
+			expected: otherErr,
+		},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, fromSDKError(tc.err, "snap1", "Snapshot"), "%v: Unexpected error", tc.name)
+	}
+}
+
+func snapshotTest(t *testing.T) {
+	volumeServer := newFakeVolumeServer()
+	volumeClient, server := newTestVolumeClient(t, volumeServer)
+	defer server.Stop()
+
+	labels := map[string]string{"a": "1"}
+	snapshotID, err := volumeClient.Snapshot("vol1", true, &api.VolumeLocator{Name: "snap1", VolumeLabels: labels}, true)
+	require.NoError(t, err, "Error creating snapshot")
+	require.Equal(t, "snap1", snapshotID, "Unexpected snapshot ID")
+	require.Equal(t, "vol1", volumeServer.snapshotRequest.VolumeId, "Unexpected volume for snapshot")
+	require.Equal(t, "snap1", volumeServer.snapshotRequest.Name, "Unexpected name for snapshot")
+	require.Equal(t, labels, volumeServer.snapshotRequest.Labels, "Unexpected labels for snapshot")
+	require.Nil(t, volumeServer.cloneRequest, "Readonly snapshots shouldn't be cloned")
+
+	// Snapshots that already exist should return the error from the REST
+	// client
+	volumeServer.snapshotCreateErr = status.Error(codes.AlreadyExists, "already exists")
+	_, err = volumeClient.Snapshot("vol1", true, &api.VolumeLocator{Name: "snap1"}, true)
+	require.Error(t, err, "Expected error for existing snapshot")
+	_, ok := err.(*ost_errors.ErrExists)
+	require.True(t, ok, "Expected ErrExists, got %T: %v", err, err)
+}
+
+func cloneTest(t *testing.T) {
+	volumeServer := newFakeVolumeServer()
+	volumeClient, server := newTestVolumeClient(t, volumeServer)
+	defer server.Stop()
+
+	cloneID, err := volumeClient.Snapshot("snap1", false, &api.VolumeLocator{Name: "restore1"}, true)
+	require.NoError(t, err, "Error cloning snapshot")
+	require.Equal(t, "clone1", cloneID, "Unexpected clone ID")
+	require.Equal(t, "snap1", volumeServer.cloneRequest.ParentId, "Unexpected parent for clone")
+	require.Equal(t, "restore1", volumeServer.cloneRequest.Name, "Unexpected name for clone")
+	require.Nil(t, volumeServer.snapshotRequest, "Writable clones shouldn't create snapshots")
+	require.Nil(t, volumeServer.updateRequest, "Clone without labels shouldn't be updated")
+
+	// Labels are set on the clone after it has been created since the clone
+	// request doesn't take them
+	labels := map[string]string{"a": "1"}
+	_, err = volumeClient.Snapshot("snap1", false, &api.VolumeLocator{Name: "restore2", VolumeLabels: labels}, true)
+	require.NoError(t, err, "Error cloning snapshot")
+	require.NotNil(t, volumeServer.updateRequest, "Clone with labels should be updated")
+	require.Equal(t, "clone1", volumeServer.updateRequest.VolumeId, "Unexpected volume updated")
+	require.Equal(t, labels, volumeServer.updateRequest.Locator.VolumeLabels, "Unexpected labels for clone")
+}
+
+func tlsTest(t *testing.T) {
+	cert, pool := newTestCertificate(t)
+	volumeServer := newFakeVolumeServer()
+	endpoint, server := startFakeSDKServer(t, volumeServer, cert)
+	defer server.Stop()
+
+	volumeClient, _, err := newSDKClients(endpoint, newTestToken("token1"), &tls.Config{RootCAs: pool}, sdkTestTimeout)
+	require.NoError(t, err, "Error creating SDK clients")
+	volumes, err := volumeClient.Inspect([]string{"vol1"})
+	require.NoError(t, err, "Error inspecting volume over TLS")
+	require.Len(t, volumes, 1, "Unexpected number of volumes")
+	require.Equal(t, []string{"bearer token1"}, volumeServer.authorization, "Unexpected authorization sent")
+
+	// Endpoints with certificates that aren't signed by the CA should be
+	// rejected
+	volumeClient, _, err = newSDKClients(endpoint, newTestToken("token1"), &tls.Config{RootCAs: x509.NewCertPool()}, sdkTestTimeout)
+	require.NoError(t, err, "Error creating SDK clients")
+	_, err = volumeClient.Inspect([]string{"vol1"})
+	require.Error(t, err, "Expected error for untrusted certificate")
+}
+
+func tokenWithoutTLSTest(t *testing.T) {
+	_, _, err := newSDKClients("127.0.0.1:9020", newTestToken("token1"), nil, sdkTestTimeout)
+	require.Error(t, err, "Expected error sending a token without TLS")
+}
+
+// newTestToken Returns credentials that always send the given token
+func newTestToken(token string) *tokenCredentials {
+	return &tokenCredentials{
+		getToken:      func() (string, error) { return token, nil },
+		refreshPeriod: time.Hour,
+	}
+}
+
+func tokenRefreshTest(t *testing.T) {
+	token := ""
+	var tokenErr error
+	fetches := 0
+	creds := &tokenCredentials{
+		getToken: func() (string, error) {
+			fetches++
+			return token, tokenErr
+		},
+		refreshPeriod: time.Hour,
+	}
+	checkToken := func(expectedToken string, expectedFetches int) {
+		metadata, err := creds.GetRequestMetadata(context.Background())
+		require.NoError(t, err, "Error getting request metadata")
+		require.Equal(t, "bearer "+expectedToken, metadata["authorization"], "Unexpected authorization")
+		require.Equal(t, expectedFetches, fetches, "Unexpected number of token fetches")
+	}
+
+	// Calls should fail if the token has never been fetched
+	tokenErr = fmt.Errorf("secret not found")
+	_, err := creds.GetRequestMetadata(context.Background())
+	require.Error(t, err, "Expected error without a token")
+
+	// The token should be cached until the refresh period has passed
+	token, tokenErr = "token1", nil
+	checkToken("token1", 2)
+	token = "token2"
+	checkToken("token1", 2)
+	creds.fetchedAt = time.Now().Add(-2 * time.Hour)
+	checkToken("token2", 3)
+
+	// The previous token should be used if it can't be fetched again
+	token, tokenErr = "", fmt.Errorf("secret not found")
+	creds.fetchedAt = time.Now().Add(-2 * time.Hour)
+	checkToken("token2", 4)
+	checkToken("token2", 4)
+}
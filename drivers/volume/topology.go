@@ -0,0 +1,93 @@
+package volume
+
+import (
+	"encoding/json"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	k8shelper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	kubeletapis "k8s.io/kubernetes/pkg/kubelet/apis"
+)
+
+const (
+	// CSINodeIDAnnotation Annotation added to nodes by the kubelet with the
+	// ID of the node for each registered CSI driver
+	CSINodeIDAnnotation = "csi.volume.kubernetes.io/nodeid"
+	// TopologyRackLabel Label on nodes used as the rack for drivers that don't
+	// report one
+	TopologyRackLabel = "topology.stork.libopenstorage.org/rack"
+	// TopologyZoneLabel Label on nodes used as the zone for drivers that don't
+	// report one. The failure domain zone label is used if it isn't present
+	TopologyZoneLabel = "topology.kubernetes.io/zone"
+	// TopologyRegionLabel Label on nodes used as the region for drivers that
+	// don't report one. The failure domain region label is used if it isn't
+	// present
+	TopologyRegionLabel = "topology.kubernetes.io/region"
+)
+
+// IsCSIVolume Returns true if the PV was provisioned by one of the given CSI
+// drivers
+func IsCSIVolume(pv *v1.PersistentVolume, driverNames ...string) bool {
+	if pv == nil || pv.Spec.CSI == nil {
+		return false
+	}
+	for _, name := range driverNames {
+		if pv.Spec.CSI.Driver == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCSINodeIDs Returns the IDs of the node for each CSI driver, keyed by
+// the driver name
+func GetCSINodeIDs(k8sNode *v1.Node) map[string]string {
+	annotation, ok := k8sNode.Annotations[CSINodeIDAnnotation]
+	if !ok {
+		return nil
+	}
+	nodeIDs := make(map[string]string)
+	if err := json.Unmarshal([]byte(annotation), &nodeIDs); err != nil {
+		return nil
+	}
+	return nodeIDs
+}
+
+// SetTopologyFromNode Sets the rack, zone and region for the driver node from
+// the topology labels on the kubernetes node if the driver didn't report them
+func SetTopologyFromNode(k8sNode *v1.Node, driverNode *NodeInfo) {
+	if driverNode.Rack == "" {
+		driverNode.Rack = k8sNode.Labels[TopologyRackLabel]
+	}
+	if driverNode.Zone == "" {
+		driverNode.Zone = getLabel(k8sNode, TopologyZoneLabel, kubeletapis.LabelZoneFailureDomain)
+	}
+	if driverNode.Region == "" {
+		driverNode.Region = getLabel(k8sNode, TopologyRegionLabel, kubeletapis.LabelZoneRegion)
+	}
+}
+
+// IsNodeAffinityMatch Returns true if the volume with the given node affinity
+// can be accessed from the node. Volumes without node affinity can be
+// accessed from all nodes
+func IsNodeAffinityMatch(k8sNode *v1.Node, affinity *v1.VolumeNodeAffinity) bool {
+	if affinity == nil || affinity.Required == nil {
+		return true
+	}
+	return k8shelper.MatchNodeSelectorTerms(
+		affinity.Required.NodeSelectorTerms,
+		labels.Set(k8sNode.Labels),
+		fields.Set{"metadata.name": k8sNode.Name},
+	)
+}
+
+// getLabel Returns the value of the first label present on the node
+func getLabel(k8sNode *v1.Node, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := k8sNode.Labels[key]; ok {
+			return value
+		}
+	}
+	return ""
+}
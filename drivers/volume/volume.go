@@ -10,6 +10,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -32,9 +33,20 @@ type Driver interface {
 	// InspectVolume returns information about a volume.
 	InspectVolume(volumeID string) (*Info, error)
 
+	// ResizeVolume Resize a volume to the given size in bytes. Volumes can
+	// only be expanded. Returns the information about the resized volume
+	ResizeVolume(volumeID string, sizeBytes uint64) (*Info, error)
+
 	// GetNodes Get the list of nodes where the driver is available
 	GetNodes() ([]*NodeInfo, error)
 
+	// EnterMaintenance Put the driver on the node into maintenance mode so
+	// that it can be taken down without affecting volumes
+	EnterMaintenance(node *NodeInfo) error
+
+	// ExitMaintenance Take the driver on the node out of maintenance mode
+	ExitMaintenance(node *NodeInfo) error
+
 	// GetPodVolumes Get all the volumes used by a pod backed by the driver
 	GetPodVolumes(*v1.PodSpec, string) ([]*Info, error)
 
@@ -52,6 +64,9 @@ type Driver interface {
 	// doesn't belong to driver
 	GetSnapshotType(snap *snapv1.VolumeSnapshot) (string, error)
 
+	// Capabilities Get the features supported by the driver
+	Capabilities() *Capabilities
+
 	// Stop the driver
 	Stop() error
 
@@ -97,10 +112,49 @@ type MigratePluginInterface interface {
 	// Cancel the migration of volumes specified in the status
 	CancelMigration(*stork_crd.Migration) error
 	// Update the PVC spec to point to the migrated volume on the destination
-	// cluster
+	// cluster. The claimRef in the spec still refers to the PVC on the source
+	// cluster, and is updated for the namespace mapping of the migration
+	// after the driver is called
 	UpdateMigratedPersistentVolumeSpec(object runtime.Unstructured) (runtime.Unstructured, error)
 }
 
+// NodePreparePluginInterface Optional interface for drivers that can prepare
+// volumes on a node before a pod using them is bound to that node
+type NodePreparePluginInterface interface {
+	// PrepareVolumesOnNode Prepare the volumes to be used on the given node,
+	// for example by attaching them or moving the data local to the node
+	PrepareVolumesOnNode(volumes []*Info, node *NodeInfo) error
+}
+
+// ProvisionTopologyPluginInterface Optional interface for drivers that can
+// report where volumes for a StorageClass could be provisioned. Used to
+// schedule pods using PVCs that are only provisioned after the pod has been
+// scheduled
+type ProvisionTopologyPluginInterface interface {
+	// GetProvisionCandidates Get the nodes, and the storage pools on them, on
+	// which a volume for the PVC can be provisioned using the StorageClass
+	GetProvisionCandidates(
+		pvc *v1.PersistentVolumeClaim,
+		storageClass *storagev1.StorageClass,
+	) ([]*ProvisionCandidate, error)
+}
+
+// BulkInspectPluginInterface Optional interface for drivers that can inspect
+// multiple volumes with a single request to the storage provider
+type BulkInspectPluginInterface interface {
+	// InspectVolumes Returns information about the volumes, keyed by the
+	// requested volume ID. Volumes that aren't found are left out
+	InspectVolumes(volumeIDs []string) (map[string]*Info, error)
+}
+
+// ProvisionCandidate Node on which a volume can be provisioned
+type ProvisionCandidate struct {
+	// NodeID ID of the driver node
+	NodeID string
+	// Pools IDs of the storage pools on the node which can be used
+	Pools []string
+}
+
 // Info Information about a volume
 type Info struct {
 	// VolumeID is a unique identifier for the volume
@@ -111,14 +165,61 @@ type Info struct {
 	DataNodes []string
 	// Size is the size of the volume in GB
 	Size uint64
+	// UsedBytes is the number of bytes used in the volume. 0 if the driver
+	// doesn't report usage
+	UsedBytes uint64
+	// ReplicationStatus is the status of the replicas of the volume. Empty
+	// if the driver doesn't report it
+	ReplicationStatus ReplicationStatus
+	// DegradedNodes is the list of data nodes whose replica of the volume
+	// isn't in sync
+	DegradedNodes []string
+	// AttachedOn is the ID or IP of the driver node on which the volume is
+	// attached. Empty if the volume isn't attached
+	AttachedOn string
+	// IOState is the state of IO to the volume. Empty if the driver doesn't
+	// report it
+	IOState IOState
 	// ParentID points to the ID of the parent volume for snapshots
 	ParentID string
 	// Labels are user applied labels on the volume
 	Labels map[string]string
 	// VolumeSourceRef is a optional reference to the source of the volume
 	VolumeSourceRef interface{}
+	// NodeAffinity is an optional constraint on the nodes from which the
+	// volume can be accessed, for example the topology of a CSI volume
+	NodeAffinity *v1.VolumeNodeAffinity
+	// Driver is the name of the driver that owns the volume. Only set when
+	// multiple drivers are used
+	Driver string
 }
 
+// ReplicationStatus Status of the replicas of a volume
+type ReplicationStatus string
+
+const (
+	// ReplicationInSync All the replicas of the volume are in sync
+	ReplicationInSync ReplicationStatus = "InSync"
+	// ReplicationResyncing Some replicas of the volume are being resynced
+	ReplicationResyncing ReplicationStatus = "Resyncing"
+	// ReplicationDegraded The volume has fewer replicas in sync than
+	// required
+	ReplicationDegraded ReplicationStatus = "Degraded"
+)
+
+// IOState State of IO to a volume
+type IOState string
+
+const (
+	// IOStateOnline IO to the volume is possible
+	IOStateOnline IOState = "Online"
+	// IOStateOffline The volume is down and IO to it isn't possible
+	IOStateOffline IOState = "Offline"
+	// IOStateError IO to the volume is failing on the node where it is
+	// attached
+	IOStateError IOState = "Error"
+)
+
 // NodeStatus Status of driver on a node
 type NodeStatus string
 
@@ -148,6 +249,34 @@ type NodeInfo struct {
 	Region string
 	// Status of the node
 	Status NodeStatus
+	// Maintenance is true if the driver on the node is in maintenance mode.
+	// Nodes in maintenance shouldn't be preferred for pods even if the
+	// driver is still online on them
+	Maintenance bool
+	// Utilization Optional capacity and load information for the node. nil
+	// if the driver doesn't report it
+	Utilization *NodeUtilization
+	// Driver Name of the driver running on the node. Only set when multiple
+	// drivers are used
+	Driver string
+}
+
+// NodeUtilization Capacity and load information for a node
+type NodeUtilization struct {
+	// TotalCapacity Total storage capacity on the node in bytes
+	TotalCapacity uint64
+	// UsedCapacity Storage capacity in use on the node in bytes
+	UsedCapacity uint64
+	// AttachedVolumes Number of volumes attached on the node
+	AttachedVolumes int
+}
+
+// FreeCapacity Returns the free storage capacity on the node in bytes
+func (u *NodeUtilization) FreeCapacity() uint64 {
+	if u.UsedCapacity > u.TotalCapacity {
+		return 0
+	}
+	return u.TotalCapacity - u.UsedCapacity
 }
 
 var (
@@ -241,6 +370,12 @@ func IsNodeMatch(k8sNode *v1.Node, driverNode *NodeInfo) bool {
 	if isHostnameMatch(driverNode.ID, k8sNode.Name) {
 		return true
 	}
+	// CSI drivers register their ID for the node with the kubelet
+	for _, nodeID := range GetCSINodeIDs(k8sNode) {
+		if nodeID == driverNode.ID {
+			return true
+		}
+	}
 	for _, address := range k8sNode.Status.Addresses {
 		switch address.Type {
 		case v1.NodeHostName:
@@ -303,3 +438,25 @@ func GetSnapshotType(snap *snapv1.VolumeSnapshot) string {
 
 	return defaultSnapType
 }
+
+// InspectVolumes Returns information about the volumes, keyed by the
+// requested volume ID, using a single request if the driver supports it.
+// Otherwise the volumes are inspected one at a time and the ones that can't
+// be inspected are left out
+func InspectVolumes(d Driver, volumeIDs []string) (map[string]*Info, error) {
+	if plugin, ok := d.(BulkInspectPluginInterface); ok {
+		return plugin.InspectVolumes(volumeIDs)
+	}
+	volumes := make(map[string]*Info)
+	for _, volumeID := range volumeIDs {
+		info, err := d.InspectVolume(volumeID)
+		if err != nil {
+			if _, ok := err.(*errors.ErrNotFound); !ok {
+				logrus.Warnf("Error inspecting volume %v: %v", volumeID, err)
+			}
+			continue
+		}
+		volumes[volumeID] = info
+	}
+	return volumes, nil
+}
@@ -21,6 +21,26 @@ type MigrationSpec struct {
 	Selectors         map[string]string `json:"selectors"`
 	PreExecRule       string            `json:"preExecRule"`
 	PostExecRule      string            `json:"postExecRule"`
+	// IncludeResourceTypes Types of resources to migrate. If empty all
+	// namespaced types are migrated except for the ones that are created
+	// by controllers or are specific to a cluster, like Pods and Events.
+	// Types are the kind or plural name of the resource, optionally
+	// followed by the group, for example Deployment or deployments.apps
+	IncludeResourceTypes []string `json:"includeResourceTypes"`
+	// ExcludeResourceTypes Types of resources that shouldn't be migrated.
+	// Takes precedence over IncludeResourceTypes
+	ExcludeResourceTypes []string `json:"excludeResourceTypes"`
+	// ApplyStrategy How resources that already exist on the destination
+	// cluster are applied. Defaults to Recreate
+	ApplyStrategy MigrationApplyStrategyType `json:"applyStrategy"`
+	// ResourceTransformation Name of the ResourceTransformation, in the
+	// namespace of the migration, used to transform the resources before
+	// they are applied on the destination cluster
+	ResourceTransformation string `json:"resourceTransformation"`
+	// NamespaceMapping Namespaces on the destination cluster to which the
+	// namespaces being migrated are mapped. Namespaces that aren't in the
+	// mapping are migrated to namespaces with the same name
+	NamespaceMapping map[string]string `json:"namespaceMapping"`
 }
 
 // MigrationStatus is the status of a migration operation
@@ -29,6 +49,13 @@ type MigrationStatus struct {
 	Status    MigrationStatusType `json:"status"`
 	Resources []*ResourceInfo     `json:"resources"`
 	Volumes   []*VolumeInfo       `json:"volumes"`
+	// ResourceStage Stage in which resources are being applied on the
+	// destination cluster. The resources in the stages before it have been
+	// applied
+	ResourceStage MigrationResourceStageType `json:"resourceStage"`
+	// ResourceStageTimestamp Time at which the resources in the resource
+	// stage started being applied
+	ResourceStageTimestamp meta.Time `json:"resourceStageTimestamp"`
 }
 
 // ResourceInfo is the info for the migration of a resource
@@ -38,6 +65,9 @@ type ResourceInfo struct {
 	meta.GroupVersionKind `json:",inline"`
 	Status                MigrationStatusType `json:"status"`
 	Reason                string              `json:"reason"`
+	// Stage Stage in which the resource is applied on the destination
+	// cluster
+	Stage MigrationResourceStageType `json:"stage"`
 }
 
 // VolumeInfo is the info for the migration of a volume
@@ -49,6 +79,49 @@ type VolumeInfo struct {
 	Reason                string              `json:"reason"`
 }
 
+// MigrationApplyStrategyType is how resources that already exist on the
+// destination cluster are applied
+type MigrationApplyStrategyType string
+
+const (
+	// MigrationApplyStrategyRecreate deletes the existing resource and
+	// creates it again
+	MigrationApplyStrategyRecreate MigrationApplyStrategyType = "Recreate"
+	// MigrationApplyStrategyMerge patches the existing resource with the
+	// changes made to the resource since it was last migrated. Fields set on
+	// the destination cluster are kept and resources with fields that were
+	// changed on both clusters aren't applied
+	MigrationApplyStrategyMerge MigrationApplyStrategyType = "Merge"
+	// MigrationApplyStrategySkipIfExists leaves the existing resource as it
+	// is
+	MigrationApplyStrategySkipIfExists MigrationApplyStrategyType = "SkipIfExists"
+)
+
+// MigrationResourceStageType is the stage in which a resource is applied on
+// the destination cluster. The stages are applied in the order below
+type MigrationResourceStageType string
+
+const (
+	// MigrationResourceStageAccessControl for service accounts and RBAC
+	// resources
+	MigrationResourceStageAccessControl MigrationResourceStageType = "AccessControl"
+	// MigrationResourceStageConfiguration for secrets, config maps and
+	// quotas
+	MigrationResourceStageConfiguration MigrationResourceStageType = "Configuration"
+	// MigrationResourceStagePersistentVolumes for persistent volumes
+	MigrationResourceStagePersistentVolumes MigrationResourceStageType = "PersistentVolumes"
+	// MigrationResourceStagePersistentVolumeClaims for persistent volume
+	// claims. They need to be bound before the workloads are applied
+	MigrationResourceStagePersistentVolumeClaims MigrationResourceStageType = "PersistentVolumeClaims"
+	// MigrationResourceStageServices for services
+	MigrationResourceStageServices MigrationResourceStageType = "Services"
+	// MigrationResourceStageWorkloads for resources that run pods
+	MigrationResourceStageWorkloads MigrationResourceStageType = "Workloads"
+	// MigrationResourceStageOther for all other resources, including
+	// custom resources
+	MigrationResourceStageOther MigrationResourceStageType = "Other"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
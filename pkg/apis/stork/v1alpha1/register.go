@@ -51,6 +51,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ClusterDomainsStatusList{},
 		&ClusterDomainUpdate{},
 		&ClusterDomainUpdateList{},
+		&ResourceTransformation{},
+		&ResourceTransformationList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
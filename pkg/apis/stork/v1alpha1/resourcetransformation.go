@@ -0,0 +1,116 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ResourceTransformationResourceName is name for "resourcetransformation" resource
+	ResourceTransformationResourceName = "resourcetransformation"
+	// ResourceTransformationResourcePlural is plural for "resourcetransformation" resource
+	ResourceTransformationResourcePlural = "resourcetransformations"
+)
+
+// FieldOperationType is the operation performed on a field of a resource
+type FieldOperationType string
+
+const (
+	// FieldOperationSet sets the field to the value, creating the fields
+	// above it if they don't exist
+	FieldOperationSet FieldOperationType = "Set"
+	// FieldOperationDelete deletes the field
+	FieldOperationDelete FieldOperationType = "Delete"
+	// FieldOperationReplace sets the field to the value if it is equal to
+	// from
+	FieldOperationReplace FieldOperationType = "Replace"
+	// FieldOperationReplacePrefix replaces from at the start of the field
+	// with the value
+	FieldOperationReplacePrefix FieldOperationType = "ReplacePrefix"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ResourceTransformation is a set of rules used to transform resources when
+// they are migrated
+type ResourceTransformation struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+	Spec            ResourceTransformationSpec `json:"spec"`
+}
+
+// ResourceTransformationSpec is the spec of a resource transformation
+type ResourceTransformationSpec struct {
+	// Rules are applied to the resources in order
+	Rules []TransformationRule `json:"rules"`
+}
+
+// TransformationRule is a set of changes made to the resources matched by
+// the rule. The JSON patch is applied before the field operations
+type TransformationRule struct {
+	// Resources selects the resources the rule is applied to
+	Resources ResourceMatcher `json:"resources"`
+	// JSONPatch is a JSON patch (RFC 6902) applied to the resources
+	// +optional
+	JSONPatch []JSONPatchOperation `json:"jsonPatch,omitempty"`
+	// Fields are operations on fields of the resources
+	// +optional
+	Fields []FieldOperation `json:"fields,omitempty"`
+}
+
+// ResourceMatcher selects resources by their type and labels
+type ResourceMatcher struct {
+	// Types of the resources. A type is the kind or plural name of the
+	// resource, optionally followed by the group, for example Deployment or
+	// deployments.apps. Matches all types if empty
+	// +optional
+	Types []string `json:"types,omitempty"`
+	// Selector is a label selector for the resources. Matches all resources
+	// if empty
+	// +optional
+	Selector *meta.LabelSelector `json:"selector,omitempty"`
+}
+
+// JSONPatchOperation is an operation in a JSON patch
+type JSONPatchOperation struct {
+	// Op is the operation, one of add, remove, replace, move, copy or test
+	Op string `json:"op"`
+	// Path is the JSON pointer to the field
+	Path string `json:"path"`
+	// From is the JSON pointer to the source field for move and copy
+	// +optional
+	From string `json:"from,omitempty"`
+	// Value is the value used by add, replace and test
+	// +optional
+	Value *runtime.RawExtension `json:"value,omitempty"`
+}
+
+// FieldOperation is an operation on the fields at a path in a resource
+type FieldOperation struct {
+	// Path is the dot separated path to the field, for example
+	// spec.storageClassName. Keys with dots are put in brackets, for example
+	// metadata.annotations[example.com/key]. A key ending with * matches all
+	// keys with that prefix. The operation is applied to all the items of
+	// lists in the path
+	Path string `json:"path"`
+	// Operation is the operation performed on the field
+	Operation FieldOperationType `json:"operation"`
+	// From is the value that is replaced for Replace and ReplacePrefix
+	// +optional
+	From string `json:"from,omitempty"`
+	// Value is the value set for Set, Replace and ReplacePrefix
+	// +optional
+	Value string `json:"value,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ResourceTransformationList is a list of resource transformations
+type ResourceTransformationList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+
+	Items []ResourceTransformation `json:"items"`
+}
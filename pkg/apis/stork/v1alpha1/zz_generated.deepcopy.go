@@ -398,6 +398,22 @@ func (in *DailyPolicy) DeepCopy() *DailyPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldOperation) DeepCopyInto(out *FieldOperation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldOperation.
+func (in *FieldOperation) DeepCopy() *FieldOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Geography) DeepCopyInto(out *Geography) {
 	*out = *in
@@ -547,6 +563,27 @@ func (in *IntervalPolicy) DeepCopy() *IntervalPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JSONPatchOperation) DeepCopyInto(out *JSONPatchOperation) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JSONPatchOperation.
+func (in *JSONPatchOperation) DeepCopy() *JSONPatchOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(JSONPatchOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KvdbSpec) DeepCopyInto(out *KvdbSpec) {
 	*out = *in
@@ -779,6 +816,23 @@ func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.IncludeResourceTypes != nil {
+		in, out := &in.IncludeResourceTypes, &out.IncludeResourceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeResourceTypes != nil {
+		in, out := &in.ExcludeResourceTypes, &out.ExcludeResourceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceMapping != nil {
+		in, out := &in.NamespaceMapping, &out.NamespaceMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -817,6 +871,7 @@ func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
 			}
 		}
 	}
+	in.ResourceStageTimestamp.DeepCopyInto(&out.ResourceStageTimestamp)
 	return
 }
 
@@ -1043,6 +1098,115 @@ func (in *ResourceInfo) DeepCopy() *ResourceInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceMatcher) DeepCopyInto(out *ResourceMatcher) {
+	*out = *in
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceMatcher.
+func (in *ResourceMatcher) DeepCopy() *ResourceMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceMatcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTransformation) DeepCopyInto(out *ResourceTransformation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTransformation.
+func (in *ResourceTransformation) DeepCopy() *ResourceTransformation {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTransformation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceTransformation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTransformationList) DeepCopyInto(out *ResourceTransformationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceTransformation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTransformationList.
+func (in *ResourceTransformationList) DeepCopy() *ResourceTransformationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTransformationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceTransformationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTransformationSpec) DeepCopyInto(out *ResourceTransformationSpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]TransformationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTransformationSpec.
+func (in *ResourceTransformationSpec) DeepCopy() *ResourceTransformationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTransformationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
@@ -1467,6 +1631,35 @@ func (in *StorageSpec) DeepCopy() *StorageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationRule) DeepCopyInto(out *TransformationRule) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.JSONPatch != nil {
+		in, out := &in.JSONPatch, &out.JSONPatch
+		*out = make([]JSONPatchOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]FieldOperation, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationRule.
+func (in *TransformationRule) DeepCopy() *TransformationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeInfo) DeepCopyInto(out *VolumeInfo) {
 	*out = *in
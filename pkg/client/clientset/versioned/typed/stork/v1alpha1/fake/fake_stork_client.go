@@ -52,6 +52,10 @@ func (c *FakeStorkV1alpha1) MigrationSchedules(namespace string) v1alpha1.Migrat
 	return &FakeMigrationSchedules{c, namespace}
 }
 
+func (c *FakeStorkV1alpha1) ResourceTransformations(namespace string) v1alpha1.ResourceTransformationInterface {
+	return &FakeResourceTransformations{c, namespace}
+}
+
 func (c *FakeStorkV1alpha1) Rules(namespace string) v1alpha1.RuleInterface {
 	return &FakeRules{c, namespace}
 }
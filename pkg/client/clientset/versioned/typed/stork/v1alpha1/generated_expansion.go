@@ -30,6 +30,8 @@ type MigrationExpansion interface{}
 
 type MigrationScheduleExpansion interface{}
 
+type ResourceTransformationExpansion interface{}
+
 type RuleExpansion interface{}
 
 type SchedulePolicyExpansion interface{}
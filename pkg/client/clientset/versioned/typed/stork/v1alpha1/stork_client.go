@@ -33,6 +33,7 @@ type StorkV1alpha1Interface interface {
 	GroupVolumeSnapshotsGetter
 	MigrationsGetter
 	MigrationSchedulesGetter
+	ResourceTransformationsGetter
 	RulesGetter
 	SchedulePoliciesGetter
 	StorageClustersGetter
@@ -68,6 +69,10 @@ func (c *StorkV1alpha1Client) MigrationSchedules(namespace string) MigrationSche
 	return newMigrationSchedules(c, namespace)
 }
 
+func (c *StorkV1alpha1Client) ResourceTransformations(namespace string) ResourceTransformationInterface {
+	return newResourceTransformations(c, namespace)
+}
+
 func (c *StorkV1alpha1Client) Rules(namespace string) RuleInterface {
 	return newRules(c, namespace)
 }
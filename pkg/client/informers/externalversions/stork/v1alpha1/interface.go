@@ -36,6 +36,8 @@ type Interface interface {
 	Migrations() MigrationInformer
 	// MigrationSchedules returns a MigrationScheduleInformer.
 	MigrationSchedules() MigrationScheduleInformer
+	// ResourceTransformations returns a ResourceTransformationInformer.
+	ResourceTransformations() ResourceTransformationInformer
 	// Rules returns a RuleInformer.
 	Rules() RuleInformer
 	// SchedulePolicies returns a SchedulePolicyInformer.
@@ -87,6 +89,11 @@ func (v *version) MigrationSchedules() MigrationScheduleInformer {
 	return &migrationScheduleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ResourceTransformations returns a ResourceTransformationInformer.
+func (v *version) ResourceTransformations() ResourceTransformationInformer {
+	return &resourceTransformationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Rules returns a RuleInformer.
 func (v *version) Rules() RuleInformer {
 	return &ruleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
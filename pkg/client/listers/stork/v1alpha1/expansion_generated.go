@@ -66,6 +66,14 @@ type MigrationScheduleListerExpansion interface{}
 // MigrationScheduleNamespaceLister.
 type MigrationScheduleNamespaceListerExpansion interface{}
 
+// ResourceTransformationListerExpansion allows custom methods to be added to
+// ResourceTransformationLister.
+type ResourceTransformationListerExpansion interface{}
+
+// ResourceTransformationNamespaceListerExpansion allows custom methods to be added to
+// ResourceTransformationNamespaceLister.
+type ResourceTransformationNamespaceListerExpansion interface{}
+
 // RuleListerExpansion allows custom methods to be added to
 // RuleLister.
 type RuleListerExpansion interface{}
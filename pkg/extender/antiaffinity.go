@@ -0,0 +1,191 @@
+package extender
+
+import (
+	"strconv"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// SpreadReplicasAnnotation Annotation on a pod to enable or disable
+	// spreading it across storage failure domains from other pods with the
+	// same owner. Overrides the SpreadReplicas setting on the extender
+	SpreadReplicasAnnotation = "stork.libopenstorage.org/spread-replicas"
+
+	// minSpreadScore Lowest score to which nodes are penalized for running
+	// pods with the same owner. Nodes with a score of 0 are given the default
+	// score, so it needs to be above 0
+	minSpreadScore = 1
+)
+
+// isSpreadEnabled Checks if pods with the same owner as the given pod should
+// be spread across failure domains
+func (e *Extender) isSpreadEnabled(pod *v1.Pod) bool {
+	if value, ok := pod.Annotations[SpreadReplicasAnnotation]; ok {
+		enabled, err := strconv.ParseBool(value)
+		if err == nil {
+			return enabled
+		}
+		storklog.PodLog(pod).Warnf("Invalid value %v for %v annotation, ignoring", value, SpreadReplicasAnnotation)
+	}
+	return e.SpreadReplicas
+}
+
+// getOwnerUID Returns the UID of the StatefulSet, Deployment or other
+// controller that owns the pod. For pods owned by a ReplicaSet, the owner of
+// the ReplicaSet is returned if it has one so that pods from different
+// revisions of a Deployment are treated as siblings
+func getOwnerUID(pod *v1.Pod, replicaSetOwners map[types.UID]types.UID) types.UID {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return ""
+	}
+	if owner.Kind == "ReplicaSet" {
+		if deploymentUID, ok := replicaSetOwners[owner.UID]; ok {
+			return deploymentUID
+		}
+	}
+	return owner.UID
+}
+
+// getSiblingNodes Returns the names of the nodes where pods with the same
+// owner as the given pod have been scheduled. A node is returned once for
+// every sibling scheduled on it. The pods and ReplicaSets are read from the
+// listers
+func (e *Extender) getSiblingNodes(pod *v1.Pod) ([]string, error) {
+	if metav1.GetControllerOf(pod) == nil {
+		return nil, nil
+	}
+
+	replicaSetOwners := make(map[types.UID]types.UID)
+	replicaSets, err := e.replicaSetLister.ReplicaSets(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, replicaSet := range replicaSets {
+		if owner := metav1.GetControllerOf(replicaSet); owner != nil {
+			replicaSetOwners[replicaSet.UID] = owner.UID
+		}
+	}
+
+	ownerUID := getOwnerUID(pod, replicaSetOwners)
+	pods, err := e.podLister.Pods(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var siblingNodes []string
+	for _, sibling := range pods {
+		if sibling.Name == pod.Name ||
+			sibling.Spec.NodeName == "" ||
+			sibling.DeletionTimestamp != nil ||
+			sibling.Status.Phase == v1.PodSucceeded ||
+			sibling.Status.Phase == v1.PodFailed {
+			continue
+		}
+		if getOwnerUID(sibling, replicaSetOwners) == ownerUID {
+			siblingNodes = append(siblingNodes, sibling.Spec.NodeName)
+		}
+	}
+	return siblingNodes, nil
+}
+
+// findDriverNode Returns the driver node running on the kubernetes node with
+// the given name
+func (e *Extender) findDriverNode(
+	nodeName string,
+	requestNodes []v1.Node,
+	driverNodes []*volume.NodeInfo,
+) *volume.NodeInfo {
+	var k8sNode *v1.Node
+	for i := range requestNodes {
+		if requestNodes[i].Name == nodeName {
+			k8sNode = &requestNodes[i]
+			break
+		}
+	}
+	if k8sNode == nil {
+		node, err := e.nodeLister.Get(nodeName)
+		if err != nil {
+			return nil
+		}
+		k8sNode = node
+	}
+	for _, dnode := range driverNodes {
+		if volume.IsNodeMatch(k8sNode, dnode) {
+			return dnode
+		}
+	}
+	return nil
+}
+
+// spreadSiblings Reduces the score of nodes in the same node, rack or zone as
+// nodes that are already running pods with the same owner. The score is
+// reduced by the weight for the closest locality for every such pod. Nodes
+// with a score of 0 will be assigned the default score later, so the penalty
+// is applied to the default score for them. Penalized scores don't go below
+// minSpreadScore so that they aren't raised back to the default score
+func (e *Extender) spreadSiblings(
+	pod *v1.Pod,
+	requestNodes []v1.Node,
+	driverNodes []*volume.NodeInfo,
+	rackInfo *localityInfo,
+	zoneInfo *localityInfo,
+	priorityMap map[string]int,
+	weights *scoringWeights,
+) {
+	siblingNodes, err := e.getSiblingNodes(pod)
+	if err != nil {
+		storklog.PodLog(pod).Warnf("Error getting sibling pods, not spreading: %v", err)
+		return
+	}
+
+	var siblingDriverNodes []*volume.NodeInfo
+	for _, nodeName := range siblingNodes {
+		if dnode := e.findDriverNode(nodeName, requestNodes, driverNodes); dnode != nil {
+			siblingDriverNodes = append(siblingDriverNodes, dnode)
+		} else {
+			storklog.PodLog(pod).Debugf("Driver not found on node %v with sibling pod", nodeName)
+		}
+	}
+	if len(siblingDriverNodes) == 0 {
+		return
+	}
+
+	for _, node := range requestNodes {
+		hostname := e.getHostname(&node)
+		nodeRack := rackInfo.HostnameMap[hostname]
+		nodeZone := zoneInfo.HostnameMap[hostname]
+		penalty := 0
+		for _, sibling := range siblingDriverNodes {
+			if volume.IsNodeMatch(&node, sibling) {
+				penalty += weights.node
+			} else if nodeRack != "" && nodeRack == rackInfo.HostnameMap[sibling.Hostname] {
+				penalty += weights.rack
+			} else if nodeZone != "" && nodeZone == zoneInfo.HostnameMap[sibling.Hostname] {
+				penalty += weights.zone
+			}
+		}
+		if penalty == 0 {
+			continue
+		}
+		score := priorityMap[node.Name]
+		if score == 0 {
+			score = weights.defaultVal
+		}
+		penalizedScore := score - penalty
+		if penalizedScore < minSpreadScore {
+			penalizedScore = minSpreadScore
+		}
+		if penalizedScore < score {
+			storklog.PodLog(pod).Debugf("Reducing score for node %v from %v to %v because of sibling pods",
+				node.Name, score, penalizedScore)
+			priorityMap[node.Name] = penalizedScore
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package extender
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	nodesCacheType      = "nodes"
+	podVolumesCacheType = "podvolumes"
+)
+
+var (
+	cacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stork_extender_cache_hits_total",
+			Help: "Number of extender requests served from the driver topology cache",
+		},
+		[]string{"type"},
+	)
+	cacheMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stork_extender_cache_misses_total",
+			Help: "Number of extender requests that had to query the driver",
+		},
+		[]string{"type"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(cacheHits, cacheMisses)
+}
+
+type nodesCacheEntry struct {
+	nodes  []*volume.NodeInfo
+	expiry time.Time
+}
+
+type podVolumesCacheEntry struct {
+	volumes []*volume.Info
+	expiry  time.Time
+}
+
+// topologyCache Caches the nodes and pod volumes returned by the driver for
+// a short duration so that bursts of scheduling requests don't query the
+// driver for the same information. Caching is disabled if the TTL is 0
+type topologyCache struct {
+	driver     volume.Driver
+	ttl        time.Duration
+	lock       sync.Mutex
+	nodes      *nodesCacheEntry
+	podVolumes map[string]*podVolumesCacheEntry
+}
+
+func newTopologyCache(driver volume.Driver, ttl time.Duration) *topologyCache {
+	return &topologyCache{
+		driver:     driver,
+		ttl:        ttl,
+		podVolumes: make(map[string]*podVolumesCacheEntry),
+	}
+}
+
+// getNodes Returns a copy of the driver nodes since callers modify them
+func (c *topologyCache) getNodes() ([]*volume.NodeInfo, error) {
+	if c.ttl == 0 {
+		return c.driver.GetNodes()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.nodes != nil && time.Now().Before(c.nodes.expiry) {
+		cacheHits.WithLabelValues(nodesCacheType).Inc()
+		return copyNodes(c.nodes.nodes), nil
+	}
+
+	cacheMisses.WithLabelValues(nodesCacheType).Inc()
+	nodes, err := c.driver.GetNodes()
+	if err != nil {
+		return nil, err
+	}
+	c.nodes = &nodesCacheEntry{
+		nodes:  copyNodes(nodes),
+		expiry: time.Now().Add(c.ttl),
+	}
+	return nodes, nil
+}
+
+// getPodVolumes Returns a copy of the pod volumes since callers modify them
+func (c *topologyCache) getPodVolumes(podSpec *v1.PodSpec, namespace string) ([]*volume.Info, error) {
+	if c.ttl == 0 {
+		return c.driver.GetPodVolumes(podSpec, namespace)
+	}
+
+	// Pods with the same volumes in the same namespace, like replicas of
+	// a Deployment, share the cache entry
+	specVolumes, err := json.Marshal(podSpec.Volumes)
+	if err != nil {
+		return c.driver.GetPodVolumes(podSpec, namespace)
+	}
+	key := namespace + "/" + string(specVolumes)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if entry, ok := c.podVolumes[key]; ok && time.Now().Before(entry.expiry) {
+		cacheHits.WithLabelValues(podVolumesCacheType).Inc()
+		return copyVolumes(entry.volumes), nil
+	}
+
+	cacheMisses.WithLabelValues(podVolumesCacheType).Inc()
+	volumes, err := c.driver.GetPodVolumes(podSpec, namespace)
+	if err != nil {
+		// Don't cache errors like pending PVCs since they are expected to
+		// be resolved soon
+		delete(c.podVolumes, key)
+		return nil, err
+	}
+	c.podVolumes[key] = &podVolumesCacheEntry{
+		volumes: copyVolumes(volumes),
+		expiry:  time.Now().Add(c.ttl),
+	}
+	c.removeExpired()
+	return volumes, nil
+}
+
+// invalidate Clears all the cached entries
+func (c *topologyCache) invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.nodes = nil
+	c.podVolumes = make(map[string]*podVolumesCacheEntry)
+}
+
+// removeExpired Removes expired pod volume entries so that the cache doesn't
+// grow unbounded. Should be called with the lock held
+func (c *topologyCache) removeExpired() {
+	now := time.Now()
+	for key, entry := range c.podVolumes {
+		if now.After(entry.expiry) {
+			delete(c.podVolumes, key)
+		}
+	}
+}
+
+func copyNodes(nodes []*volume.NodeInfo) []*volume.NodeInfo {
+	copied := make([]*volume.NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		nodeCopy := *node
+		nodeCopy.IPs = append([]string(nil), node.IPs...)
+		if node.Utilization != nil {
+			utilization := *node.Utilization
+			nodeCopy.Utilization = &utilization
+		}
+		copied = append(copied, &nodeCopy)
+	}
+	return copied
+}
+
+func copyVolumes(volumes []*volume.Info) []*volume.Info {
+	copied := make([]*volume.Info, 0, len(volumes))
+	for _, vol := range volumes {
+		volCopy := *vol
+		volCopy.DataNodes = append([]string(nil), vol.DataNodes...)
+		volCopy.DegradedNodes = append([]string(nil), vol.DegradedNodes...)
+		if vol.Labels != nil {
+			volCopy.Labels = make(map[string]string, len(vol.Labels))
+			for k, v := range vol.Labels {
+				volCopy.Labels[k] = v
+			}
+		}
+		if vol.NodeAffinity != nil {
+			volCopy.NodeAffinity = vol.NodeAffinity.DeepCopy()
+		}
+		copied = append(copied, &volCopy)
+	}
+	return copied
+}
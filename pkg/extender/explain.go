@@ -0,0 +1,148 @@
+package extender
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+)
+
+const (
+	reasonDriverNotInstalled = "Storage driver not installed on node"
+	reasonDriverOffline      = "Storage driver on node is %v"
+	reasonNoReplica          = "No online replica found for volume %v"
+	reasonNodeAffinity       = "Node doesn't match the node affinity of volume %v"
+	reasonCannotProvision    = "Storage driver can't provision a volume for PVC %v on node"
+)
+
+// nodeScore Breakdown of the score assigned to a node
+type nodeScore struct {
+	Node string `json:"node"`
+	// VolumeScores Score for the locality of the node to each volume
+	VolumeScores map[string]int `json:"volumeScores,omitempty"`
+	// SpreadAdjustment Change in score because of pods with the same owner
+	SpreadAdjustment int `json:"spreadAdjustment,omitempty"`
+	// TieBreakerAdjustment Change in score because of the utilization of
+	// the node
+	TieBreakerAdjustment int `json:"tieBreakerAdjustment,omitempty"`
+	// DefaultScore Set if the node was assigned the default score
+	DefaultScore bool `json:"defaultScore,omitempty"`
+	// Maintenance Set if the node is cordoned or the driver on it is in
+	// maintenance, in which case it gets the lowest score
+	Maintenance bool `json:"maintenance,omitempty"`
+	// Score Final score for the node
+	Score int `json:"score"`
+}
+
+// explanation Result of the filter and prioritize steps for a pod
+type explanation struct {
+	Pod           string                      `json:"pod"`
+	Weights       map[string]int              `json:"weights"`
+	Volumes       map[string][]string         `json:"volumes,omitempty"`
+	PendingClaims []string                    `json:"pendingClaims,omitempty"`
+	FilteredNodes []string                    `json:"filteredNodes"`
+	FailedNodes   schedulerapi.FailedNodesMap `json:"failedNodes,omitempty"`
+	Scores        []*nodeScore                `json:"scores,omitempty"`
+	Error         string                      `json:"error,omitempty"`
+}
+
+// processExplainRequest Returns the result of filtering and scoring all the
+// nodes in the cluster for the pod specified as "?pod=namespace/name"
+func (e *Extender) processExplainRequest(w http.ResponseWriter, req *http.Request) {
+	if e.KubeClient == nil {
+		http.Error(w, "Kubernetes client not configured", http.StatusInternalServerError)
+		return
+	}
+
+	podName := strings.SplitN(req.URL.Query().Get("pod"), "/", 2)
+	if len(podName) != 2 || podName[0] == "" || podName[1] == "" {
+		http.Error(w, "Pod should be specified as namespace/name", http.StatusBadRequest)
+		return
+	}
+
+	pod, err := e.KubeClient.CoreV1().Pods(podName[0]).Get(podName[1], metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	nodes, err := e.KubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(e.explain(pod, nodes.Items)); err != nil {
+		log.Errorf("Error encoding explain response: %v", err)
+	}
+}
+
+// explain Runs the filter and prioritize steps for the pod and records the
+// reasons for the decisions
+func (e *Extender) explain(pod *v1.Pod, nodes []v1.Node) *explanation {
+	weights := e.getScoringWeights(pod)
+	result := &explanation{
+		Pod:           pod.Namespace + "/" + pod.Name,
+		Weights:       weights.values(),
+		Volumes:       make(map[string][]string),
+		FilteredNodes: []string{},
+	}
+
+	driverVolumes, pendingClaims, err := e.getPodVolumes(pod)
+	if err != nil {
+		storklog.PodLog(pod).Debugf("Error getting volumes to explain: %v", err)
+	}
+	for _, volumeInfo := range driverVolumes {
+		result.Volumes[volumeInfo.VolumeName] = volumeInfo.DataNodes
+	}
+	for _, claim := range pendingClaims {
+		result.PendingClaims = append(result.PendingClaims, claim.pvc.Name)
+	}
+
+	filteredNodes, failedNodes, err := e.filterNodes(pod, nodes)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.FailedNodes = failedNodes
+	for _, node := range filteredNodes {
+		result.FilteredNodes = append(result.FilteredNodes, node.Name)
+	}
+
+	breakdown := make(map[string]*nodeScore)
+	if _, err := e.scoreNodes(pod, filteredNodes, &weights, breakdown); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for _, node := range filteredNodes {
+		result.Scores = append(result.Scores, breakdown[node.Name])
+	}
+	sort.SliceStable(result.Scores, func(i, j int) bool {
+		return result.Scores[i].Score > result.Scores[j].Score
+	})
+	return result
+}
+
+// copyScores Returns a copy of the scores so that changes made by each
+// scoring step can be recorded
+func copyScores(priorityMap map[string]int) map[string]int {
+	scores := make(map[string]int, len(priorityMap))
+	for nodeName, score := range priorityMap {
+		scores[nodeName] = score
+	}
+	return scores
+}
@@ -2,8 +2,12 @@ package extender
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -11,14 +15,23 @@ import (
 
 	"github.com/libopenstorage/stork/drivers/volume"
 	storklog "github.com/libopenstorage/stork/pkg/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/record"
 	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
 )
 
 const (
 	filter     = "filter"
 	prioritize = "prioritize"
+	bind       = "bind"
+	explain    = "explain"
+	preempt    = "preemption"
 	// nodePriorityScore Score by which each node is bumped if it has data for a volume
 	nodePriorityScore = 100
 	// rackPriorityScore Score by which each node is bumped if it is in the same
@@ -32,14 +45,54 @@ const (
 	regionPriorityScore = 10
 	// defaultScore Score assigned to a node which doesn't have data for any volume
 	defaultScore = 5
+	// DefaultListenAddress Address on which the extender listens if one
+	// isn't specified
+	DefaultListenAddress = ":8099"
 )
 
 // Extender Scheduler extender
 type Extender struct {
-	Driver  volume.Driver
-	server  *http.Server
-	lock    sync.Mutex
-	started bool
+	Driver volume.Driver
+	// ListenAddress Address on which the extender should listen for
+	// requests from the scheduler. Defaults to DefaultListenAddress
+	ListenAddress string
+	// TLSCertFile Certificate to be used to serve requests over HTTPS. If
+	// empty requests are served over HTTP
+	TLSCertFile string
+	// TLSKeyFile Private key for TLSCertFile
+	TLSKeyFile string
+	// TLSClientCAFile CA bundle used to verify client certificates. If set
+	// the scheduler is required to present a valid client certificate
+	TLSClientCAFile string
+	// KubeClient Client used to bind pods to nodes and load the scoring
+	// policy. Required for the bind verb
+	KubeClient kubernetes.Interface
+	// Recorder Used to record events for pods bound by the extender
+	Recorder record.EventRecorder
+	// ScoringConfigMapName Name of the ConfigMap with the weights used to
+	// score nodes. Defaults to DefaultScoringConfigMapName
+	ScoringConfigMapName string
+	// ScoringConfigMapNamespace Namespace of the ConfigMap with the weights
+	// used to score nodes. Defaults to DefaultScoringConfigMapNamespace
+	ScoringConfigMapNamespace string
+	// SpreadReplicas Spread pods with the same owner across storage failure
+	// domains. Can be overridden per pod with SpreadReplicasAnnotation
+	SpreadReplicas bool
+	// CacheTTL Duration for which the nodes and pod volumes returned by the
+	// driver are cached. Caching is disabled if 0
+	CacheTTL    time.Duration
+	server      *http.Server
+	lock        sync.Mutex
+	started     bool
+	stopChannel chan struct{}
+	weights     scoringWeights
+	weightsLock sync.RWMutex
+	cache       *topologyCache
+
+	namespaceLister  corelisters.NamespaceLister
+	nodeLister       corelisters.NodeLister
+	replicaSetLister appslisters.ReplicaSetLister
+	podLister        corelisters.PodLister
 }
 
 // Start Starts the extender
@@ -51,12 +104,54 @@ func (e *Extender) Start() error {
 		return fmt.Errorf("Extender has already been started")
 	}
 
-	// TODO: Make the listen port configurable
-	e.server = &http.Server{Addr: ":8099"}
+	if e.ListenAddress == "" {
+		e.ListenAddress = DefaultListenAddress
+	}
+	if e.ScoringConfigMapName == "" {
+		e.ScoringConfigMapName = DefaultScoringConfigMapName
+	}
+	if e.ScoringConfigMapNamespace == "" {
+		e.ScoringConfigMapNamespace = DefaultScoringConfigMapNamespace
+	}
+
+	tlsConfig, err := e.getTLSConfig()
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", e.serveHTTP)
+	mux.Handle("/metrics", promhttp.Handler())
+	e.server = &http.Server{
+		Addr:      e.ListenAddress,
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
+
+	// Create the listener here so that errors binding to the address are
+	// returned to the caller instead of failing in the background
+	listener, err := net.Listen("tcp", e.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("Error listening on %v: %v", e.ListenAddress, err)
+	}
+
+	e.stopChannel = make(chan struct{})
+	e.cache = newTopologyCache(e.Driver, e.CacheTTL)
+	e.weights = defaultScoringWeights()
+	if e.KubeClient != nil {
+		e.startListers()
+		e.startScoringPolicyRefresh()
+	}
 
-	http.HandleFunc("/", e.serveHTTP)
 	go func() {
-		if err := e.server.ListenAndServe(); err != http.ErrServerClosed {
+		var err error
+		if tlsConfig != nil {
+			// The certificates have already been loaded in the TLS config
+			err = e.server.ServeTLS(listener, "", "")
+		} else {
+			err = e.server.Serve(listener)
+		}
+		if err != http.ErrServerClosed {
 			log.Panicf("Error starting extender server: %v", err)
 		}
 	}()
@@ -79,15 +174,70 @@ func (e *Extender) Stop() error {
 	if err := e.server.Shutdown(ctx); err != nil {
 		return err
 	}
+	close(e.stopChannel)
 	e.started = false
 	return nil
 }
 
+// InvalidateCache Clears the cached nodes and pod volumes so that the next
+// request queries the driver. Should be called when the status of a driver
+// node changes
+func (e *Extender) InvalidateCache() {
+	e.lock.Lock()
+	cache := e.cache
+	e.lock.Unlock()
+	if cache != nil {
+		log.Debugf("Invalidating extender topology cache")
+		cache.invalidate()
+	}
+}
+
+func (e *Extender) getTLSConfig() (*tls.Config, error) {
+	if e.TLSCertFile == "" && e.TLSKeyFile == "" {
+		if e.TLSClientCAFile != "" {
+			return nil, fmt.Errorf("Certificate and key are required to verify client certificates")
+		}
+		return nil, nil
+	}
+	if e.TLSCertFile == "" || e.TLSKeyFile == "" {
+		return nil, fmt.Errorf("Both certificate and key need to be specified to enable TLS")
+	}
+
+	cert, err := tls.LoadX509KeyPair(e.TLSCertFile, e.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading certificate and key: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if e.TLSClientCAFile != "" {
+		caCert, err := ioutil.ReadFile(e.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading client CA file: %v", err)
+		}
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("No valid certificates found in client CA file %v", e.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = caPool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
 func (e *Extender) serveHTTP(w http.ResponseWriter, req *http.Request) {
 	if strings.Contains(req.URL.Path, filter) {
 		e.processFilterRequest(w, req)
 	} else if strings.Contains(req.URL.Path, prioritize) {
 		e.processPrioritizeRequest(w, req)
+	} else if strings.Contains(req.URL.Path, bind) {
+		e.processBindRequest(w, req)
+	} else if strings.Contains(req.URL.Path, preempt) {
+		e.processPreemptionRequest(w, req)
+	} else if strings.Contains(req.URL.Path, explain) {
+		e.processExplainRequest(w, req)
 	} else {
 		http.Error(w, "Unsupported request", http.StatusNotFound)
 	}
@@ -124,73 +274,178 @@ func (e *Extender) processFilterRequest(w http.ResponseWriter, req *http.Request
 		storklog.PodLog(pod).Debugf("%v %+v", node.Name, node.Status.Addresses)
 	}
 
-	filteredNodes := []v1.Node{}
-	driverVolumes, err := e.Driver.GetPodVolumes(&pod.Spec, pod.Namespace)
+	filteredNodes, failedNodes, err := e.filterNodes(pod, args.Nodes.Items)
+	if err != nil {
+		http.Error(w, "Waiting for PVC to be bound", http.StatusBadRequest)
+		return
+	}
+
+	storklog.PodLog(pod).Debugf("Nodes in filter response:")
+	for _, node := range filteredNodes {
+		log.Debugf("%v %+v", node.Name, node.Status.Addresses)
+	}
+	response := &schedulerapi.ExtenderFilterResult{
+		Nodes: &v1.NodeList{
+			Items: filteredNodes,
+		},
+		FailedNodes: failedNodes,
+	}
+	if err := encoder.Encode(response); err != nil {
+		storklog.PodLog(pod).Errorf("Error encoding filter response: %+v : %v", response, err)
+	}
+}
+
+// filterNodes Returns the nodes on which the pod can be scheduled along with
+// the reason each of the other nodes was rejected. Only returns an error if
+// the PVCs used by the pod haven't been bound yet
+func (e *Extender) filterNodes(
+	pod *v1.Pod,
+	nodes []v1.Node,
+) ([]v1.Node, schedulerapi.FailedNodesMap, error) {
+	failedNodes := make(schedulerapi.FailedNodesMap)
+	driverVolumes, pendingClaims, err := e.getPodVolumes(pod)
 	if err != nil {
 		storklog.PodLog(pod).Warnf("Error getting volumes for Pod for driver: %v", err)
 		if _, ok := err.(*volume.ErrPVCPending); ok {
-			http.Error(w, "Waiting for PVC to be bound", http.StatusBadRequest)
-			return
+			return nil, nil, err
 		}
-	} else if len(driverVolumes) > 0 {
-		driverNodes, err := e.Driver.GetNodes()
-		if err != nil {
-			storklog.PodLog(pod).Errorf("Error getting list of driver nodes, returning all nodes")
-		} else {
-			for _, volumeInfo := range driverVolumes {
-				onlineNodeFound := false
-				for _, volumeNode := range volumeInfo.DataNodes {
-					for _, driverNode := range driverNodes {
-						if volumeNode == driverNode.ID && driverNode.Status == volume.NodeOnline {
-							onlineNodeFound = true
-						}
-					}
-				}
-				if !onlineNodeFound {
-					storklog.PodLog(pod).Errorf("No nodes in filter request have replica for volume, returning error")
-					http.Error(w, "No node found with volume", http.StatusBadRequest)
-					return
+		return nodes, failedNodes, nil
+	}
+	// If we didn't find a PVC that interested us, return all the nodes from
+	// the request
+	if len(driverVolumes) == 0 && len(pendingClaims) == 0 {
+		return nodes, failedNodes, nil
+	}
+
+	driverNodes, err := e.cache.getNodes()
+	if err != nil {
+		storklog.PodLog(pod).Errorf("Error getting list of driver nodes, returning all nodes")
+		return nodes, failedNodes, nil
+	}
+
+	for _, volumeInfo := range driverVolumes {
+		// Drivers that don't report where the data for the volume is
+		// located, like CSI drivers, rely on the node affinity of the volume
+		// instead, which is checked for each node below. Volumes without
+		// node affinity can be accessed from any node
+		if len(volumeInfo.DataNodes) == 0 {
+			continue
+		}
+		onlineNodeFound := false
+		for _, volumeNode := range volumeInfo.DataNodes {
+			for _, driverNode := range driverNodes {
+				if volumeNode == driverNode.ID &&
+					volumeInfo.Driver == driverNode.Driver &&
+					driverNode.Status == volume.NodeOnline {
+					onlineNodeFound = true
 				}
 			}
+		}
+		if !onlineNodeFound {
+			storklog.PodLog(pod).Errorf("No online replica found for volume %v, rejecting all nodes", volumeInfo.VolumeName)
+			for _, node := range nodes {
+				failedNodes[node.Name] = fmt.Sprintf(reasonNoReplica, volumeInfo.VolumeName)
+			}
+			return []v1.Node{}, failedNodes, nil
+		}
+	}
 
-			for _, node := range args.Nodes.Items {
-				for _, driverNode := range driverNodes {
-					storklog.PodLog(pod).Debugf("nodeInfo: %v", driverNode)
-					if driverNode.Status == volume.NodeOnline &&
-						volume.IsNodeMatch(&node, driverNode) {
-						filteredNodes = append(filteredNodes, node)
-						break
-					}
+	provisionCandidates := e.getProvisionCandidates(pod, pendingClaims)
+	// The driver for each volume needs to be online on the node. Pending
+	// claims can be provisioned by any of the drivers
+	volumeDrivers := make(map[string]bool)
+	for _, volumeInfo := range driverVolumes {
+		volumeDrivers[volumeInfo.Driver] = true
+	}
+	if len(volumeDrivers) == 0 {
+		volumeDrivers[""] = true
+	}
+	filteredNodes := []v1.Node{}
+	for _, node := range nodes {
+		onlineNodes, reason := getOnlineDriverNodes(pod, &node, driverNodes, volumeDrivers)
+		if reason == "" {
+			for _, volumeInfo := range driverVolumes {
+				if !volume.IsNodeAffinityMatch(&node, volumeInfo.NodeAffinity) {
+					reason = fmt.Sprintf(reasonNodeAffinity, volumeInfo.VolumeName)
+					break
 				}
 			}
-			// If we filtered out all the nodes, the driver isn't running on any
-			// of them, so return an error to avoid scheduling a pod on a
-			// non-driver node
-			if len(filteredNodes) == 0 {
-				storklog.PodLog(pod).Errorf("No nodes in filter request have driver, returning error")
-				http.Error(w, "No node found with driver", http.StatusBadRequest)
-				return
+		}
+		if reason == "" {
+			for pvcName, nodeIDs := range provisionCandidates {
+				if !isProvisionCandidate(onlineNodes, nodeIDs) {
+					reason = fmt.Sprintf(reasonCannotProvision, pvcName)
+					break
+				}
 			}
 		}
+		if reason != "" {
+			failedNodes[node.Name] = reason
+			continue
+		}
+		filteredNodes = append(filteredNodes, node)
 	}
-
-	// If we didn't find a PVC that interested us, return all the nodes from the request
+	// If we filtered out all the nodes, the driver isn't running on any of
+	// them. The reasons are returned to the scheduler so that the pod isn't
+	// scheduled on a non-driver node
 	if len(filteredNodes) == 0 {
-		filteredNodes = args.Nodes.Items
+		storklog.PodLog(pod).Errorf("No nodes in filter request have driver online")
 	}
+	return filteredNodes, failedNodes, nil
+}
 
-	storklog.PodLog(pod).Debugf("Nodes in filter response:")
-	for _, node := range filteredNodes {
-		log.Debugf("%v %+v", node.Name, node.Status.Addresses)
+// getOnlineDriverNodes Returns the online driver nodes matching the node, or
+// the reason the node can't be used if any of the given drivers isn't online
+// on it. An empty driver name matches nodes from any driver
+func getOnlineDriverNodes(
+	pod *v1.Pod,
+	node *v1.Node,
+	driverNodes []*volume.NodeInfo,
+	drivers map[string]bool,
+) ([]*volume.NodeInfo, string) {
+	var onlineNodes []*volume.NodeInfo
+	offlineStatus := make(map[string]volume.NodeStatus)
+	for _, driverNode := range driverNodes {
+		storklog.PodLog(pod).Debugf("nodeInfo: %v", driverNode)
+		if !volume.IsNodeMatch(node, driverNode) {
+			continue
+		}
+		if driverNode.Status == volume.NodeOnline {
+			onlineNodes = append(onlineNodes, driverNode)
+			continue
+		}
+		offlineStatus[driverNode.Driver] = driverNode.Status
+		offlineStatus[""] = driverNode.Status
 	}
-	response := &schedulerapi.ExtenderFilterResult{
-		Nodes: &v1.NodeList{
-			Items: filteredNodes,
-		},
+
+	for driverName := range drivers {
+		found := false
+		for _, onlineNode := range onlineNodes {
+			if driverName == "" || onlineNode.Driver == driverName {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if status, ok := offlineStatus[driverName]; ok {
+			return nil, fmt.Sprintf(reasonDriverOffline, status)
+		}
+		return nil, reasonDriverNotInstalled
 	}
-	if err := encoder.Encode(response); err != nil {
-		storklog.PodLog(pod).Errorf("Error encoding filter response: %+v : %v", response, err)
+	return onlineNodes, ""
+}
+
+// isProvisionCandidate Returns true if any of the driver nodes is a
+// candidate for provisioning
+func isProvisionCandidate(driverNodes []*volume.NodeInfo, nodeIDs map[string]bool) bool {
+	for _, driverNode := range driverNodes {
+		if nodeIDs[driverNode.ID] {
+			return true
+		}
 	}
+	return false
 }
 
 func (e *Extender) getNodeScore(
@@ -200,6 +455,7 @@ func (e *Extender) getNodeScore(
 	zoneInfo *localityInfo,
 	regionInfo *localityInfo,
 	idMap map[string]*volume.NodeInfo,
+	weights *scoringWeights,
 ) int {
 	for _, address := range node.Status.Addresses {
 		if address.Type != v1.NodeHostName {
@@ -215,23 +471,27 @@ func (e *Extender) getNodeScore(
 					if zone == nodeZone || nodeZone == "" {
 						for _, rack := range rackInfo.PreferredLocality {
 							if rack == nodeRack || nodeRack == "" {
+								// Replicas that aren't in sync don't make the
+								// node local to the data, but still count for
+								// the locality of the volume
 								for _, datanode := range volumeInfo.DataNodes {
-									if volume.IsNodeMatch(&node, idMap[datanode]) {
-										return nodePriorityScore
+									if !volumeInfo.IsDegradedNode(datanode) &&
+										volume.IsNodeMatch(&node, idMap[datanode]) {
+										return weights.node
 									}
 								}
 								if nodeRack != "" {
-									return rackPriorityScore
+									return weights.rack
 								}
 							}
 						}
 						if nodeZone != "" {
-							return zonePriorityScore
+							return weights.zone
 						}
 					}
 				}
 				if nodeRegion != "" {
-					return regionPriorityScore
+					return weights.region
 				}
 			}
 		}
@@ -265,33 +525,68 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 	for _, node := range args.Nodes.Items {
 		storklog.PodLog(pod).Debugf("%+v", node.Status.Addresses)
 	}
+	weights := e.getScoringWeights(pod)
+	storklog.PodLog(pod).Debugf("Scoring weights: %+v", weights)
+
+	respList, err := e.scoreNodes(pod, args.Nodes.Items, &weights, nil)
+	if err != nil {
+		http.Error(w, "Waiting for PVC to be bound", http.StatusBadRequest)
+		return
+	}
+
+	storklog.PodLog(pod).Debugf("Nodes in response:")
+	for _, node := range respList {
+		storklog.PodLog(pod).Debugf("%+v", node)
+	}
+
+	if err := encoder.Encode(respList); err != nil {
+		storklog.PodLog(pod).Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// scoreNodes Returns the score for each of the nodes. If breakdown isn't nil
+// the components of the score for each node are added to it. Only returns an
+// error if the PVCs used by the pod haven't been bound yet
+func (e *Extender) scoreNodes(
+	pod *v1.Pod,
+	nodes []v1.Node,
+	weights *scoringWeights,
+	breakdown map[string]*nodeScore,
+) (schedulerapi.HostPriorityList, error) {
 	respList := schedulerapi.HostPriorityList{}
 
 	// Intialize scores to 0
 	priorityMap := make(map[string]int)
-	for _, node := range args.Nodes.Items {
+	// Nodes that are cordoned or on which the driver is in maintenance. They
+	// aren't preferred even if they have data for the volumes
+	maintenanceNodes := make(map[string]bool)
+	for _, node := range nodes {
 		for _, address := range node.Status.Addresses {
 			if address.Type == v1.NodeHostName {
 				priorityMap[address.Address] = 0
 			}
 		}
+		if breakdown != nil {
+			breakdown[node.Name] = &nodeScore{
+				Node:         node.Name,
+				VolumeScores: make(map[string]int),
+			}
+		}
 	}
 
-	driverVolumes, err := e.Driver.GetPodVolumes(&pod.Spec, pod.Namespace)
+	driverVolumes, pendingClaims, err := e.getPodVolumes(pod)
 	if err != nil {
 		storklog.PodLog(pod).Warnf("Error getting volumes for Pod for driver: %v", err)
 		if _, ok := err.(*volume.ErrPVCPending); ok {
-			http.Error(w, "Waiting for PVC to be bound", http.StatusBadRequest)
-			return
+			return nil, err
 		}
 		goto sendResponse
-	} else if len(driverVolumes) > 0 {
-		driverNodes, err := e.Driver.GetNodes()
+	} else if len(driverVolumes) > 0 || len(pendingClaims) > 0 {
+		driverNodes, err := e.cache.getNodes()
 		if err != nil {
 			storklog.PodLog(pod).Errorf("Error getting nodes for driver: %v", err)
 			goto sendResponse
 		}
-
 		// Create a map for ID->Node and Hostname->Rack/Zone/Region
 		idMap := make(map[string]*volume.NodeInfo)
 		var rackInfo, zoneInfo, regionInfo localityInfo
@@ -301,26 +596,34 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 		for _, dnode := range driverNodes {
 			// Replace driver's hostname with the kubernetes hostname to make it
 			// easier to match nodes when calculating scores
-			for _, knode := range args.Nodes.Items {
+			for _, knode := range nodes {
 				if volume.IsNodeMatch(&knode, dnode) {
+					if dnode.Maintenance {
+						maintenanceNodes[knode.Name] = true
+					}
 					dnode.Hostname = e.getHostname(&knode)
+					// Use the topology labels on the node if the driver
+					// doesn't report the locality itself
+					volume.SetTopologyFromNode(&knode, dnode)
 					break
 				}
 			}
 			idMap[dnode.ID] = dnode
 			storklog.PodLog(pod).Debugf("nodeInfo: %v", dnode)
-			// For any node that is offline remove the locality info so that we
-			// don't prioritize nodes close to it
-			if dnode.Status == volume.NodeOnline {
+			// For any node that is offline or in maintenance remove the
+			// locality info so that we don't prioritize nodes close to it
+			if dnode.Status == volume.NodeOnline && !dnode.Maintenance {
 				// Add region info into zone and zone info into rack so that we can
-				// differentiate same names in different localities
+				// differentiate same names in different localities. Nodes
+				// without a rack or zone shouldn't inherit the locality above
+				// them
 				regionInfo.HostnameMap[dnode.Hostname] = dnode.Region
-				if regionInfo.HostnameMap[dnode.Hostname] != "" {
+				if regionInfo.HostnameMap[dnode.Hostname] != "" && dnode.Zone != "" {
 					zoneInfo.HostnameMap[dnode.Hostname] = regionInfo.HostnameMap[dnode.Hostname] + "-" + dnode.Zone
 				} else {
 					zoneInfo.HostnameMap[dnode.Hostname] = dnode.Zone
 				}
-				if zoneInfo.HostnameMap[dnode.Hostname] != "" {
+				if zoneInfo.HostnameMap[dnode.Hostname] != "" && dnode.Rack != "" {
 					rackInfo.HostnameMap[dnode.Hostname] = zoneInfo.HostnameMap[dnode.Hostname] + "-" + dnode.Rack
 				} else {
 					rackInfo.HostnameMap[dnode.Hostname] = dnode.Rack
@@ -356,8 +659,74 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 			storklog.PodLog(pod).Debugf("Volume %v allocated in zones: %v", volume.VolumeName, zoneInfo.PreferredLocality)
 			storklog.PodLog(pod).Debugf("Volume %v allocated in regions: %v", volume.VolumeName, regionInfo.PreferredLocality)
 
-			for _, node := range args.Nodes.Items {
-				priorityMap[node.Name] += e.getNodeScore(node, volume, &rackInfo, &zoneInfo, &regionInfo, idMap)
+			for _, node := range nodes {
+				score := e.getNodeScore(node, volume, &rackInfo, &zoneInfo, &regionInfo, idMap, weights)
+				priorityMap[node.Name] += score
+				if breakdown != nil {
+					breakdown[node.Name].VolumeScores[volume.VolumeName] = score
+				}
+			}
+		}
+
+		// Prefer nodes where the pending PVCs can be provisioned locally
+		for pvcName, nodeIDs := range e.getProvisionCandidates(pod, pendingClaims) {
+			for _, node := range nodes {
+				score := 0
+				for nodeID := range nodeIDs {
+					if volume.IsNodeMatch(&node, idMap[nodeID]) {
+						score = weights.node
+						break
+					}
+				}
+				priorityMap[node.Name] += score
+				if breakdown != nil {
+					breakdown[node.Name].VolumeScores[pvcName] = score
+				}
+			}
+		}
+
+		if e.KubeClient != nil && e.isSpreadEnabled(pod) {
+			scores := copyScores(priorityMap)
+			e.spreadSiblings(pod, nodes, driverNodes, &rackInfo, &zoneInfo, priorityMap, weights)
+			for nodeName, nodeBreakdown := range breakdown {
+				previousScore := scores[nodeName]
+				if previousScore == 0 && priorityMap[nodeName] != 0 {
+					// The node would have been given the default score
+					previousScore = weights.defaultVal
+				}
+				nodeBreakdown.SpreadAdjustment = priorityMap[nodeName] - previousScore
+			}
+		}
+
+		// Use the utilization of the online driver nodes to break ties
+		// between nodes with the same locality
+		utilizationMap := make(map[string]*volume.NodeUtilization)
+		for _, node := range nodes {
+			for _, dnode := range driverNodes {
+				if dnode.Status == volume.NodeOnline && dnode.Utilization != nil &&
+					volume.IsNodeMatch(&node, dnode) {
+					utilizationMap[node.Name] = dnode.Utilization
+					break
+				}
+			}
+		}
+		scores := copyScores(priorityMap)
+		breakTies(priorityMap, utilizationMap, weights)
+		for nodeName, nodeBreakdown := range breakdown {
+			nodeBreakdown.TieBreakerAdjustment = priorityMap[nodeName] - scores[nodeName]
+		}
+
+		// Nodes being drained for maintenance get the lowest score so that
+		// pods are moved off them instead of following their replicas
+		for _, node := range nodes {
+			if node.Spec.Unschedulable {
+				maintenanceNodes[node.Name] = true
+			}
+			if maintenanceNodes[node.Name] {
+				priorityMap[node.Name] = 0
+				if breakdown != nil {
+					breakdown[node.Name].Maintenance = true
+				}
 			}
 		}
 	}
@@ -366,21 +735,141 @@ sendResponse:
 	// For any nodes that didn't have any volumes, assign it a
 	// default score so that it doesn't get completely ignored
 	// by the scheduler
-	for _, node := range args.Nodes.Items {
+	for _, node := range nodes {
 		score, ok := priorityMap[node.Name]
-		if !ok || score == 0 {
-			score = defaultScore
+		if (!ok || score == 0) && !maintenanceNodes[node.Name] {
+			score = weights.defaultVal
+			if breakdown != nil {
+				breakdown[node.Name].DefaultScore = true
+			}
+		}
+		if breakdown != nil {
+			breakdown[node.Name].Score = score
 		}
 		hostPriority := schedulerapi.HostPriority{Host: node.Name, Score: score}
 		respList = append(respList, hostPriority)
 	}
+	return respList, nil
+}
 
-	storklog.PodLog(pod).Debugf("Nodes in response:")
-	for _, node := range respList {
-		storklog.PodLog(pod).Debugf("%+v", node)
+// Prepare the driver volumes used by the pod on the node selected by the
+// scheduler, if the driver supports it
+func (e *Extender) prepareVolumesOnNode(pod *v1.Pod, nodeName string) error {
+	preparePlugin, ok := e.Driver.(volume.NodePreparePluginInterface)
+	if !ok {
+		return nil
 	}
 
-	if err := encoder.Encode(respList); err != nil {
-		storklog.PodLog(pod).Errorf("Failed to encode response: %v", err)
+	driverVolumes, err := e.Driver.GetPodVolumes(&pod.Spec, pod.Namespace)
+	if err != nil {
+		return fmt.Errorf("Error getting volumes for pod: %v", err)
+	}
+	if len(driverVolumes) == 0 {
+		return nil
+	}
+
+	node, err := e.KubeClient.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("Error getting node %v: %v", nodeName, err)
+	}
+	driverNodes, err := e.Driver.GetNodes()
+	if err != nil {
+		return fmt.Errorf("Error getting nodes for driver: %v", err)
+	}
+	// Each driver running on the node prepares the volumes it owns
+	prepared := make(map[string]bool)
+	for _, driverNode := range driverNodes {
+		if prepared[driverNode.Driver] || !volume.IsNodeMatch(node, driverNode) {
+			continue
+		}
+		prepared[driverNode.Driver] = true
+		var nodeVolumes []*volume.Info
+		for _, volumeInfo := range driverVolumes {
+			if volumeInfo.Driver == driverNode.Driver {
+				nodeVolumes = append(nodeVolumes, volumeInfo)
+			}
+		}
+		if len(nodeVolumes) == 0 {
+			continue
+		}
+		storklog.PodLog(pod).Debugf("Preparing volumes on node %v", driverNode.ID)
+		if err := preparePlugin.PrepareVolumesOnNode(nodeVolumes, driverNode); err != nil {
+			return err
+		}
+	}
+	if len(prepared) == 0 {
+		return fmt.Errorf("Driver not running on node %v", nodeName)
+	}
+	return nil
+}
+
+func (e *Extender) processBindRequest(w http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(req.Body)
+	defer func() {
+		if err := req.Body.Close(); err != nil {
+			log.Warnf("Error closing decoder")
+		}
+	}()
+	encoder := json.NewEncoder(w)
+
+	var args schedulerapi.ExtenderBindingArgs
+	if err := decoder.Decode(&args); err != nil {
+		log.Errorf("Error decoding bind request: %v", err)
+		http.Error(w, "Decode error", http.StatusBadRequest)
+		return
+	}
+
+	response := &schedulerapi.ExtenderBindingResult{}
+	if e.KubeClient == nil {
+		log.Errorf("No client configured to bind pod %v/%v", args.PodNamespace, args.PodName)
+		response.Error = "Extender not configured to bind pods"
+	} else if err := e.bindPod(&args); err != nil {
+		response.Error = err.Error()
+	}
+
+	if err := encoder.Encode(response); err != nil {
+		log.Errorf("Error encoding bind response: %+v : %v", response, err)
+	}
+}
+
+func (e *Extender) bindPod(args *schedulerapi.ExtenderBindingArgs) error {
+	pod, err := e.KubeClient.CoreV1().Pods(args.PodNamespace).Get(args.PodName, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("Error getting pod %v/%v for bind request: %v", args.PodNamespace, args.PodName, err)
+		return err
+	}
+
+	// Failing to prepare the volumes shouldn't prevent the pod from being
+	// scheduled, the volumes will be prepared when the pod starts
+	if err := e.prepareVolumesOnNode(pod, args.Node); err != nil {
+		storklog.PodLog(pod).Warnf("Error preparing volumes on node %v: %v", args.Node, err)
+		e.recordEvent(pod, v1.EventTypeWarning, "FailedPrepareVolumes",
+			fmt.Sprintf("Error preparing volumes on node %v: %v", args.Node, err))
+	}
+
+	binding := &v1.Binding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: args.PodNamespace,
+			Name:      args.PodName,
+			UID:       args.PodUID,
+		},
+		Target: v1.ObjectReference{
+			Kind: "Node",
+			Name: args.Node,
+		},
+	}
+	if err := e.KubeClient.CoreV1().Pods(args.PodNamespace).Bind(binding); err != nil {
+		storklog.PodLog(pod).Errorf("Error binding pod to node %v: %v", args.Node, err)
+		return err
+	}
+	storklog.PodLog(pod).Infof("Bound pod to node %v", args.Node)
+	e.recordEvent(pod, v1.EventTypeNormal, "StorkBound",
+		fmt.Sprintf("Pod bound to node %v by stork", args.Node))
+	return nil
+}
+
+func (e *Extender) recordEvent(pod *v1.Pod, eventType, reason, message string) {
+	if e.Recorder != nil {
+		e.Recorder.Event(pod, eventType, reason, message)
 	}
 }
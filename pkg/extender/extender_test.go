@@ -3,20 +3,40 @@
 package extender
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/libopenstorage/stork/drivers/volume"
 	"github.com/libopenstorage/stork/drivers/volume/mock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
 )
 
@@ -27,6 +47,7 @@ const (
 
 var driver *mock.Driver
 var extender *Extender
+var fakeKubeClient *fake.Clientset
 
 func setup(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
@@ -44,8 +65,16 @@ func setup(t *testing.T) {
 		t.Fatalf("Error initializing mock volume driver: %v", err)
 	}
 
+	fakeKubeClient = fake.NewSimpleClientset()
+	// The fake clientset doesn't set the namespace for bindings, so handle
+	// them here instead of in the object tracker
+	fakeKubeClient.PrependReactor("create", "pods",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return action.GetSubresource() == "bindings", nil, nil
+		})
 	extender = &Extender{
-		Driver: storkdriver,
+		Driver:     storkdriver,
+		KubeClient: fakeKubeClient,
 	}
 
 	if err = extender.Start(); err != nil {
@@ -59,6 +88,43 @@ func teardown(t *testing.T) {
 	}
 }
 
+// waitForLister Waits for an object to be added to or removed from a lister
+// used by the extender. get should return an error if the object isn't found
+func waitForLister(t *testing.T, name string, exists bool, get func() error) {
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return (get() == nil) == exists, nil
+	})
+	require.NoError(t, err, "Timed out waiting for %v in lister", name)
+}
+
+func waitForNamespace(t *testing.T, name string, exists bool) {
+	waitForLister(t, name, exists, func() error {
+		_, err := extender.namespaceLister.Get(name)
+		return err
+	})
+}
+
+func waitForNode(t *testing.T, name string, exists bool) {
+	waitForLister(t, name, exists, func() error {
+		_, err := extender.nodeLister.Get(name)
+		return err
+	})
+}
+
+func waitForPod(t *testing.T, namespace string, name string, exists bool) {
+	waitForLister(t, name, exists, func() error {
+		_, err := extender.podLister.Pods(namespace).Get(name)
+		return err
+	})
+}
+
+func waitForReplicaSet(t *testing.T, namespace string, name string, exists bool) {
+	waitForLister(t, name, exists, func() error {
+		_, err := extender.replicaSetLister.ReplicaSets(namespace).Get(name)
+		return err
+	})
+}
+
 func newPod(podName string, volumes []string) *v1.Pod {
 	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{Name: podName},
@@ -121,6 +187,92 @@ func sendRequest(
 	return resp, nil
 }
 
+func sendBindRequest(
+	pod *v1.Pod,
+	nodeName string,
+) (*schedulerapi.ExtenderBindingResult, error) {
+	args := &schedulerapi.ExtenderBindingArgs{
+		PodName:      pod.Name,
+		PodNamespace: pod.Namespace,
+		PodUID:       pod.UID,
+		Node:         nodeName,
+	}
+
+	b, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Post("http://localhost:8099/bind",
+		"application/json",
+		strings.NewReader(string(b)))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logrus.Warnf("Error closing decoder: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		contents, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New(strings.TrimSpace(string(contents)))
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	var bindResult schedulerapi.ExtenderBindingResult
+	if err := decoder.Decode(&bindResult); err != nil {
+		logrus.Errorf("Error decoding bind response: %v", err)
+		return nil, err
+	}
+	return &bindResult, nil
+}
+
+func sendPreemptionRequest(
+	pod *v1.Pod,
+	victims map[string]*schedulerapi.Victims,
+) (*schedulerapi.ExtenderPreemptionResult, error) {
+	args := &schedulerapi.ExtenderPreemptionArgs{
+		Pod:               pod,
+		NodeNameToVictims: victims,
+	}
+
+	b, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Post("http://localhost:8099/preemption",
+		"application/json",
+		strings.NewReader(string(b)))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logrus.Warnf("Error closing decoder: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		contents, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New(strings.TrimSpace(string(contents)))
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	var preemptionResult schedulerapi.ExtenderPreemptionResult
+	if err := decoder.Decode(&preemptionResult); err != nil {
+		logrus.Errorf("Error decoding preemption response: %v", err)
+		return nil, err
+	}
+	return &preemptionResult, nil
+}
+
 func sendFilterRequest(
 	pod *v1.Pod,
 	nodeList *v1.NodeList,
@@ -267,6 +419,23 @@ func TestExtender(t *testing.T) {
 	t.Run("ipTest", ipTest)
 	t.Run("invalidRequestsTest", invalidRequestsTest)
 	t.Run("noReplicasTest", noReplicasTest)
+	t.Run("invalidConfigTest", invalidConfigTest)
+	t.Run("tlsTest", tlsTest)
+	t.Run("mutualTLSTest", mutualTLSTest)
+	t.Run("bindTest", bindTest)
+	t.Run("bindNoPodTest", bindNoPodTest)
+	t.Run("scoringPolicyTest", scoringPolicyTest)
+	t.Run("utilizationTest", utilizationTest)
+	t.Run("degradedReplicaTest", degradedReplicaTest)
+	t.Run("maintenanceTest", maintenanceTest)
+	t.Run("spreadReplicasTest", spreadReplicasTest)
+	t.Run("cacheTest", cacheTest)
+	t.Run("explainTest", explainTest)
+	t.Run("csiTopologyTest", csiTopologyTest)
+	t.Run("csiNoTopologyTest", csiNoTopologyTest)
+	t.Run("waitForFirstConsumerTest", waitForFirstConsumerTest)
+	t.Run("preemptionTest", preemptionTest)
+	t.Run("multipleDriversTest", multipleDriversTest)
 	t.Run("teardown", teardown)
 }
 
@@ -375,7 +544,8 @@ func noVolumeNodeTest(t *testing.T) {
 // Create a pod with a PVC using the mock storage class.
 // Create a storage cluster with 3 nodes n1,n2,n3.
 // Send filter request with node n4, n5
-// The filter response should return an error
+// The filter response should reject all the nodes since the driver isn't
+// installed on them
 func noDriverNodeTest(t *testing.T) {
 	nodes := &v1.NodeList{}
 	requestNodes := &v1.NodeList{}
@@ -398,8 +568,12 @@ func noDriverNodeTest(t *testing.T) {
 		t.Fatalf("Error provisioning volume: %v", err)
 	}
 	filterResponse, err := sendFilterRequest(pod, requestNodes)
-	if err == nil {
-		t.Fatalf("Expected error for filter request, got nil: %v", filterResponse)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, requestNodes, []int{}, filterResponse)
+	require.Len(t, filterResponse.FailedNodes, 2, "Expected all nodes to be rejected")
+	for _, node := range requestNodes.Items {
+		require.Equal(t, reasonDriverNotInstalled, filterResponse.FailedNodes[node.Name],
+			"Unexpected reason for node %v", node.Name)
 	}
 }
 
@@ -575,6 +749,8 @@ func driverNodeErrorStateTest(t *testing.T) {
 		t.Fatalf("Error sending filter request: %v", err)
 	}
 	verifyFilterResponse(t, nodes, []int{1, 2, 3, 4}, filterResponse)
+	require.Equal(t, fmt.Sprintf(reasonDriverOffline, volume.NodeOffline), filterResponse.FailedNodes["node1.domain"],
+		"Unexpected reason for offline node")
 
 	nodes = filterResponse.Nodes
 	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
@@ -792,7 +968,7 @@ func invalidRequestsTest(t *testing.T) {
 // Create a pod with a PVC using the mock storage class.
 // Place the data on nodes n1. Mark n1 as offline Send requests with node n1,
 // n2, n3
-// The filter response should return an error since no replicas for
+// The filter response should reject all the nodes since no replicas for
 // the volume are online
 func noReplicasTest(t *testing.T) {
 	nodes := &v1.NodeList{}
@@ -813,6 +989,1084 @@ func noReplicasTest(t *testing.T) {
 	if err := driver.UpdateNodeStatus(0, volume.NodeOffline); err != nil {
 		t.Fatalf("Error setting node status to Offline: %v", err)
 	}
-	_, err := sendFilterRequest(pod, requestNodes)
-	require.Error(t, err, "Expected error since no replicas are online")
+	requestNodes.Items = nodes.Items
+	filterResponse, err := sendFilterRequest(pod, requestNodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, requestNodes, []int{}, filterResponse)
+	require.Len(t, filterResponse.FailedNodes, 3, "Expected all nodes to be rejected")
+	for _, node := range requestNodes.Items {
+		require.Equal(t, fmt.Sprintf(reasonNoReplica, "noReplicasTest"), filterResponse.FailedNodes[node.Name],
+			"Unexpected reason for node %v", node.Name)
+	}
+}
+
+// Try to start extenders with invalid listen addresses and TLS configs. All
+// of them should fail to start without affecting the running extender
+func invalidConfigTest(t *testing.T) {
+	ext := &Extender{
+		Driver: driver,
+	}
+	err := ext.Start()
+	require.Error(t, err, "Expected error when starting extender on address in use")
+
+	ext = &Extender{
+		Driver:        driver,
+		ListenAddress: "localhost:0",
+		TLSCertFile:   "/nonexistent/cert.pem",
+	}
+	err = ext.Start()
+	require.Error(t, err, "Expected error when starting extender without TLS key")
+
+	ext = &Extender{
+		Driver:          driver,
+		ListenAddress:   "localhost:0",
+		TLSClientCAFile: "/nonexistent/ca.pem",
+	}
+	err = ext.Start()
+	require.Error(t, err, "Expected error when starting extender with client CA but no certificate")
+
+	ext = &Extender{
+		Driver:        driver,
+		ListenAddress: "localhost:0",
+		TLSCertFile:   "/nonexistent/cert.pem",
+		TLSKeyFile:    "/nonexistent/key.pem",
+	}
+	err = ext.Start()
+	require.Error(t, err, "Expected error when starting extender with invalid certificate")
+
+	resp, err := http.Post("http://localhost:8099/invalidPath",
+		"application/json", nil)
+	require.NoError(t, err, "Expected no error from running extender")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "Excected HTTP NotFound for invalid path")
+}
+
+// newTestCA Returns a new self-signed CA certificate and its key
+func newTestCA(t *testing.T, name string) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Error generating CA key")
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err, "Error creating CA certificate")
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, "Error parsing CA certificate")
+	return cert, key
+}
+
+// newTestCertificate Returns a PEM encoded certificate for 127.0.0.1 signed
+// by the CA, along with its PEM encoded key
+func newTestCertificate(
+	t *testing.T,
+	ca *x509.Certificate,
+	caKey *rsa.PrivateKey,
+	usage x509.ExtKeyUsage,
+) ([]byte, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "Error generating key")
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err, "Error creating certificate")
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func writeTestFile(t *testing.T, dir string, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, data, 0600), "Error writing %v", name)
+	return path
+}
+
+func sendTLSFilterRequest(
+	client *http.Client,
+	address string,
+	pod *v1.Pod,
+	nodeList *v1.NodeList,
+) (*schedulerapi.ExtenderFilterResult, error) {
+	b, err := json.Marshal(&schedulerapi.ExtenderArgs{
+		Pod:   pod,
+		Nodes: nodeList,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post("https://"+address+"/filter",
+		"application/json",
+		strings.NewReader(string(b)))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logrus.Warnf("Error closing decoder: %v", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected status code %v", resp.StatusCode)
+	}
+	var filterResult schedulerapi.ExtenderFilterResult
+	if err := json.NewDecoder(resp.Body).Decode(&filterResult); err != nil {
+		return nil, err
+	}
+	return &filterResult, nil
+}
+
+// Start an extender with a generated certificate and key and make sure a
+// filter request completes over HTTPS
+func tlsTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extender-tls")
+	require.NoError(t, err, "Error creating temp dir")
+	defer os.RemoveAll(dir)
+
+	ca, caKey := newTestCA(t, "test-ca")
+	certPEM, keyPEM := newTestCertificate(t, ca, caKey, x509.ExtKeyUsageServerAuth)
+	ext := &Extender{
+		Driver:        driver,
+		ListenAddress: "127.0.0.1:8100",
+		TLSCertFile:   writeTestFile(t, dir, "tls.crt", certPEM),
+		TLSKeyFile:    writeTestFile(t, dir, "tls.key", keyPEM),
+	}
+	require.NoError(t, ext.Start(), "Error starting extender with TLS")
+	defer func() {
+		require.NoError(t, ext.Stop(), "Error stopping extender")
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+	pod := newPod("tlsPod", nil)
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack1", "", ""))
+	filterResponse, err := sendTLSFilterRequest(client, ext.ListenAddress, pod, nodes)
+	require.NoError(t, err, "Error sending filter request over HTTPS")
+	verifyFilterResponse(t, nodes, []int{0, 1}, filterResponse)
+
+	// Plain HTTP requests shouldn't be served
+	resp, err := http.Post("http://"+ext.ListenAddress+"/filter", "application/json", nil)
+	if err == nil {
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected plain HTTP request to be rejected")
+		require.NoError(t, resp.Body.Close(), "Error closing response body")
+	}
+}
+
+// Start an extender that requires client certificates and make sure only
+// clients with a certificate signed by the configured CA are allowed
+func mutualTLSTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extender-mtls")
+	require.NoError(t, err, "Error creating temp dir")
+	defer os.RemoveAll(dir)
+
+	ca, caKey := newTestCA(t, "test-ca")
+	certPEM, keyPEM := newTestCertificate(t, ca, caKey, x509.ExtKeyUsageServerAuth)
+	ext := &Extender{
+		Driver:          driver,
+		ListenAddress:   "127.0.0.1:8101",
+		TLSCertFile:     writeTestFile(t, dir, "tls.crt", certPEM),
+		TLSKeyFile:      writeTestFile(t, dir, "tls.key", keyPEM),
+		TLSClientCAFile: writeTestFile(t, dir, "ca.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})),
+	}
+	require.NoError(t, ext.Start(), "Error starting extender with mutual TLS")
+	defer func() {
+		require.NoError(t, ext.Stop(), "Error stopping extender")
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	newClient := func(certificates []tls.Certificate) *http.Client {
+		return &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      pool,
+					Certificates: certificates,
+				},
+			},
+		}
+	}
+	pod := newPod("mutualTLSPod", nil)
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+
+	// Clients signed by the configured CA should be allowed
+	clientCertPEM, clientKeyPEM := newTestCertificate(t, ca, caKey, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	require.NoError(t, err, "Error loading client certificate")
+	filterResponse, err := sendTLSFilterRequest(newClient([]tls.Certificate{clientCert}), ext.ListenAddress, pod, nodes)
+	require.NoError(t, err, "Error sending filter request with client certificate")
+	verifyFilterResponse(t, nodes, []int{0}, filterResponse)
+
+	// Clients without a certificate should be rejected
+	_, err = sendTLSFilterRequest(newClient(nil), ext.ListenAddress, pod, nodes)
+	require.Error(t, err, "Expected error sending filter request without client certificate")
+
+	// Clients with a certificate signed by another CA should be rejected
+	otherCA, otherCAKey := newTestCA(t, "other-ca")
+	otherCertPEM, otherKeyPEM := newTestCertificate(t, otherCA, otherCAKey, x509.ExtKeyUsageClientAuth)
+	otherCert, err := tls.X509KeyPair(otherCertPEM, otherKeyPEM)
+	require.NoError(t, err, "Error loading client certificate")
+	_, err = sendTLSFilterRequest(newClient([]tls.Certificate{otherCert}), ext.ListenAddress, pod, nodes)
+	require.Error(t, err, "Expected error sending filter request with certificate from another CA")
+}
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1, n2. Send a bind request for node n2
+// The volume should be prepared on n2 and the pod should be bound to n2
+func bindTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+
+	if err := driver.CreateCluster(3, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	for _, node := range nodes.Items {
+		_, err := fakeKubeClient.CoreV1().Nodes().Create(node.DeepCopy())
+		require.NoError(t, err, "Error creating node")
+	}
+	defer func() {
+		for _, node := range nodes.Items {
+			err := fakeKubeClient.CoreV1().Nodes().Delete(node.Name, &metav1.DeleteOptions{})
+			require.NoError(t, err, "Error deleting node")
+		}
+	}()
+	pod := newPod("bindTest", []string{"bindTest"})
+	pod.Namespace = defaultNamespace
+	_, err := fakeKubeClient.CoreV1().Pods(defaultNamespace).Create(pod)
+	require.NoError(t, err, "Error creating pod")
+
+	provNodes := []int{0, 1}
+	if err := driver.ProvisionVolume("bindTest", provNodes, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+
+	fakeKubeClient.ClearActions()
+	bindResponse, err := sendBindRequest(pod, "node2")
+	require.NoError(t, err, "Error sending bind request")
+	require.Empty(t, bindResponse.Error, "Unexpected error in bind response")
+	require.Equal(t, "node2", driver.GetPreparedNode("bindTest"), "Volume not prepared on node2")
+
+	bound := false
+	for _, action := range fakeKubeClient.Actions() {
+		if action.GetVerb() == "create" && action.GetSubresource() == "bindings" {
+			binding := action.(k8stesting.CreateAction).GetObject().(*v1.Binding)
+			require.Equal(t, "node2", binding.Target.Name, "Pod bound to wrong node")
+			require.Equal(t, pod.Name, binding.Name, "Wrong pod bound")
+			bound = true
+		}
+	}
+	require.True(t, bound, "Pod wasn't bound")
+}
+
+// Send a bind request for a pod that doesn't exist
+// The bind response should return an error
+func bindNoPodTest(t *testing.T) {
+	pod := newPod("bindNoPodTest", nil)
+	pod.Namespace = defaultNamespace
+	bindResponse, err := sendBindRequest(pod, "node1")
+	require.NoError(t, err, "Error sending bind request")
+	require.NotEmpty(t, bindResponse.Error, "Expected error in bind response")
+}
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1, n2. Send prioritize requests with node n1, n2,
+// n3, n4, n5 after updating the scoring policy and annotations
+// The prioritize response should use the weights from the policy, then the
+// namespace annotation and then the pod annotation
+func scoringPolicyTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack3", "", ""))
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	pod := newPod("scoringPolicyTest", []string{"scoringPolicyTest"})
+	pod.Namespace = defaultNamespace
+	if err := driver.ProvisionVolume("scoringPolicyTest", []int{0, 1}, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultScoringConfigMapName,
+			Namespace: DefaultScoringConfigMapNamespace,
+		},
+		Data: map[string]string{
+			"node":    "200",
+			"rack":    "20",
+			"default": "1",
+		},
+	}
+	_, err := fakeKubeClient.CoreV1().ConfigMaps(DefaultScoringConfigMapNamespace).Create(configMap)
+	require.NoError(t, err, "Error creating scoring policy")
+	extender.loadScoringPolicy()
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(t, nodes, []int{200, 200, 20, 20, 1}, prioritizeResponse)
+
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: defaultNamespace,
+			Annotations: map[string]string{
+				ScoringWeightsAnnotation: "rack=0",
+			},
+		},
+	}
+	_, err = fakeKubeClient.CoreV1().Namespaces().Create(namespace)
+	require.NoError(t, err, "Error creating namespace")
+	waitForNamespace(t, defaultNamespace, true)
+
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(t, nodes, []int{200, 200, 1, 1, 1}, prioritizeResponse)
+
+	pod.Annotations = map[string]string{
+		ScoringWeightsAnnotation: "node=10, default=10",
+	}
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(t, nodes, []int{10, 10, 10, 10, 10}, prioritizeResponse)
+
+	// Invalid annotations should be ignored
+	pod.Annotations[ScoringWeightsAnnotation] = "node=abc"
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(t, nodes, []int{200, 200, 1, 1, 1}, prioritizeResponse)
+
+	err = fakeKubeClient.CoreV1().Namespaces().Delete(defaultNamespace, &metav1.DeleteOptions{})
+	require.NoError(t, err, "Error deleting namespace")
+	waitForNamespace(t, defaultNamespace, false)
+	err = fakeKubeClient.CoreV1().ConfigMaps(DefaultScoringConfigMapNamespace).Delete(DefaultScoringConfigMapName, &metav1.DeleteOptions{})
+	require.NoError(t, err, "Error deleting scoring policy")
+	extender.loadScoringPolicy()
+
+	pod.Annotations = nil
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{nodePriorityScore, nodePriorityScore, rackPriorityScore, rackPriorityScore, defaultScore},
+		prioritizeResponse)
+}
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1, n2, n3 and n4. Set utilization for n1, n2 and
+// n4. Send prioritize request with node n1, n2, n3, n4, n5
+// The prioritize response should bump the node with the most free capacity
+// and then the one with fewer attached volumes, without changing the order
+// of nodes with different locality
+func utilizationTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack3", "", ""))
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	pod := newPod("utilizationTest", []string{"utilizationTest"})
+	if err := driver.ProvisionVolume("utilizationTest", []int{0, 1, 2, 3}, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+
+	err := driver.UpdateNodeUtilization(0, &volume.NodeUtilization{
+		TotalCapacity:   100,
+		UsedCapacity:    90,
+		AttachedVolumes: 1,
+	})
+	require.NoError(t, err, "Error updating node utilization")
+	err = driver.UpdateNodeUtilization(1, &volume.NodeUtilization{
+		TotalCapacity:   100,
+		UsedCapacity:    10,
+		AttachedVolumes: 5,
+	})
+	require.NoError(t, err, "Error updating node utilization")
+	err = driver.UpdateNodeUtilization(3, &volume.NodeUtilization{
+		TotalCapacity:   100,
+		UsedCapacity:    10,
+		AttachedVolumes: 2,
+	})
+	require.NoError(t, err, "Error updating node utilization")
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{nodePriorityScore,
+			nodePriorityScore + tieBreakerMaxScore/2,
+			nodePriorityScore,
+			nodePriorityScore + tieBreakerMaxScore,
+			defaultScore},
+		prioritizeResponse)
+
+	// Nodes with the same utilization should get the same score
+	err = driver.UpdateNodeUtilization(1, &volume.NodeUtilization{
+		TotalCapacity:   100,
+		UsedCapacity:    10,
+		AttachedVolumes: 2,
+	})
+	require.NoError(t, err, "Error updating node utilization")
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{nodePriorityScore,
+			nodePriorityScore + tieBreakerMaxScore/2,
+			nodePriorityScore,
+			nodePriorityScore + tieBreakerMaxScore/2,
+			defaultScore},
+		prioritizeResponse)
+}
+
+// Create a pod with a PVC using the mock storage class. Place the data on
+// nodes n1, n2 with the replica on n2 being resynced. Send prioritize request
+// with node n1, n2, n3, n4, n5. n2 should only get the rack score since its
+// replica isn't in sync
+func degradedReplicaTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack3", "", ""))
+
+	require.NoError(t, driver.CreateCluster(5, nodes), "Error creating cluster")
+	pod := newPod("degradedReplicaTest", []string{"degradedReplicaTest"})
+	require.NoError(t, driver.ProvisionVolume("degradedReplicaTest", []int{0, 1}, 1), "Error provisioning volume")
+	err := driver.UpdateVolumeHealth("degradedReplicaTest", volume.ReplicationResyncing, []int{1}, volume.IOStateOnline)
+	require.NoError(t, err, "Error updating volume health")
+
+	filterResponse, err := sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{0, 1, 2, 3, 4}, filterResponse)
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{nodePriorityScore,
+			rackPriorityScore,
+			rackPriorityScore,
+			rackPriorityScore,
+			defaultScore},
+		prioritizeResponse)
+}
+
+// Create a pod with a PVC using the mock storage class. Place the data on
+// nodes n1, n2 and put the driver on n1 into maintenance. Cordon n4. Send
+// prioritize request with node n1, n2, n3, n4, n5
+// Nodes n1 and n4 should get the lowest score and n3 shouldn't be preferred
+// because of the replica on n1
+func maintenanceTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack3", "", ""))
+	nodes.Items[3].Spec.Unschedulable = true
+
+	require.NoError(t, driver.CreateCluster(5, nodes), "Error creating cluster")
+	driver.SetCapabilities(&volume.Capabilities{Maintenance: true})
+	driverNodes, err := driver.GetNodes()
+	require.NoError(t, err, "Error getting driver nodes")
+	require.NoError(t, driver.EnterMaintenance(driverNodes[0]), "Error entering maintenance")
+	pod := newPod("maintenanceTest", []string{"maintenanceTest"})
+	require.NoError(t, driver.ProvisionVolume("maintenanceTest", []int{0, 1}, 1), "Error provisioning volume")
+
+	filterResponse, err := sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{0, 1, 2, 3, 4}, filterResponse)
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{0,
+			nodePriorityScore,
+			defaultScore,
+			0,
+			defaultScore},
+		prioritizeResponse)
+}
+
+// Create a pod owned by a StatefulSet with a PVC using the mock storage class.
+// Place the data on nodes n1, n2. Another pod from the same StatefulSet is
+// running on n1. Send prioritize request with node n1, n2, n3, n4, n5
+// Without spreading the prioritize response should prefer n1 and n2.
+// With spreading n1 and n3 should be penalized since they are on the same
+// node and rack as the other pod
+func spreadReplicasTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack3", "", ""))
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("spreadReplicasTest", []int{0, 1}, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+
+	isController := true
+	owner := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+		Name:       "spreadReplicasTest",
+		UID:        "spreadReplicasTestUID",
+		Controller: &isController,
+	}
+	sibling := newPod("spreadReplicasTest-0", nil)
+	sibling.Namespace = defaultNamespace
+	sibling.OwnerReferences = []metav1.OwnerReference{owner}
+	sibling.Spec.NodeName = "node1"
+	_, err := fakeKubeClient.CoreV1().Pods(defaultNamespace).Create(sibling)
+	require.NoError(t, err, "Error creating sibling pod")
+	waitForPod(t, defaultNamespace, sibling.Name, true)
+
+	pod := newPod("spreadReplicasTest-1", []string{"spreadReplicasTest"})
+	pod.Namespace = defaultNamespace
+	pod.OwnerReferences = []metav1.OwnerReference{owner}
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{nodePriorityScore, nodePriorityScore, rackPriorityScore, rackPriorityScore, defaultScore},
+		prioritizeResponse)
+
+	pod.Annotations = map[string]string{SpreadReplicasAnnotation: "true"}
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{minSpreadScore, nodePriorityScore, minSpreadScore, rackPriorityScore, defaultScore},
+		prioritizeResponse)
+
+	err = fakeKubeClient.CoreV1().Pods(defaultNamespace).Delete(sibling.Name, &metav1.DeleteOptions{})
+	require.NoError(t, err, "Error deleting sibling pod")
+	waitForPod(t, defaultNamespace, sibling.Name, false)
+
+	// Pods from different ReplicaSets of the same Deployment should be
+	// treated as siblings
+	deploymentOwner := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       "spreadReplicasTest",
+		UID:        "spreadReplicasTestDeploymentUID",
+		Controller: &isController,
+	}
+	var replicaSetOwners []metav1.OwnerReference
+	for _, name := range []string{"spreadReplicasTest-1", "spreadReplicasTest-2"} {
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       defaultNamespace,
+				UID:             types.UID(name + "UID"),
+				OwnerReferences: []metav1.OwnerReference{deploymentOwner},
+			},
+		}
+		_, err = fakeKubeClient.AppsV1().ReplicaSets(defaultNamespace).Create(replicaSet)
+		require.NoError(t, err, "Error creating ReplicaSet")
+		waitForReplicaSet(t, defaultNamespace, name, true)
+		replicaSetOwners = append(replicaSetOwners, metav1.OwnerReference{
+			APIVersion: "apps/v1",
+			Kind:       "ReplicaSet",
+			Name:       name,
+			UID:        replicaSet.UID,
+			Controller: &isController,
+		})
+	}
+	sibling.OwnerReferences = []metav1.OwnerReference{replicaSetOwners[0]}
+	_, err = fakeKubeClient.CoreV1().Pods(defaultNamespace).Create(sibling)
+	require.NoError(t, err, "Error creating sibling pod")
+	waitForPod(t, defaultNamespace, sibling.Name, true)
+
+	pod.OwnerReferences = []metav1.OwnerReference{replicaSetOwners[1]}
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{minSpreadScore, nodePriorityScore, minSpreadScore, rackPriorityScore, defaultScore},
+		prioritizeResponse)
+
+	err = fakeKubeClient.CoreV1().Pods(defaultNamespace).Delete(sibling.Name, &metav1.DeleteOptions{})
+	require.NoError(t, err, "Error deleting sibling pod")
+	waitForPod(t, defaultNamespace, sibling.Name, false)
+	for _, owner := range replicaSetOwners {
+		err = fakeKubeClient.AppsV1().ReplicaSets(defaultNamespace).Delete(owner.Name, &metav1.DeleteOptions{})
+		require.NoError(t, err, "Error deleting ReplicaSet")
+	}
+}
+
+func getCacheCount(t *testing.T, counter *prometheus.CounterVec, cacheType string) float64 {
+	metric := &dto.Metric{}
+	err := counter.WithLabelValues(cacheType).Write(metric)
+	require.NoError(t, err, "Error reading cache metric")
+	return metric.GetCounter().GetValue()
+}
+
+// Enable the cache and check that a node going offline is only seen after
+// the cache is invalidated
+func cacheTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+
+	if err := driver.CreateCluster(3, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("cacheTest", []int{0, 1}, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+
+	extender.cache = newTopologyCache(driver, time.Minute)
+	defer func() {
+		extender.cache = newTopologyCache(driver, 0)
+	}()
+
+	pod := newPod("cacheTestPod", []string{"cacheTest"})
+	hits := getCacheCount(t, cacheHits, nodesCacheType)
+	misses := getCacheCount(t, cacheMisses, nodesCacheType)
+
+	filterResponse, err := sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{0, 1, 2}, filterResponse)
+	require.Equal(t, misses+1, getCacheCount(t, cacheMisses, nodesCacheType), "Expected cache miss")
+
+	err = driver.UpdateNodeStatus(0, volume.NodeOffline)
+	require.NoError(t, err, "Error setting node status to Offline")
+
+	// Should still be served from the cache
+	filterResponse, err = sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{0, 1, 2}, filterResponse)
+	require.Equal(t, hits+1, getCacheCount(t, cacheHits, nodesCacheType), "Expected cache hit")
+
+	extender.InvalidateCache()
+	filterResponse, err = sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{1, 2}, filterResponse)
+	require.Equal(t, misses+2, getCacheCount(t, cacheMisses, nodesCacheType), "Expected cache miss")
+
+	// Changes made by callers to the returned volumes shouldn't change the
+	// cached volumes
+	volumes, err := extender.cache.getPodVolumes(&pod.Spec, pod.Namespace)
+	require.NoError(t, err, "Error getting pod volumes")
+	require.Len(t, volumes, 1, "Unexpected number of volumes")
+	dataNodes := append([]string(nil), volumes[0].DataNodes...)
+	volumes[0].DataNodes[0] = "modified"
+	volumes[0].VolumeName = "modified"
+	volumes, err = extender.cache.getPodVolumes(&pod.Spec, pod.Namespace)
+	require.NoError(t, err, "Error getting pod volumes")
+	require.Equal(t, dataNodes, volumes[0].DataNodes, "Cached data nodes were modified")
+	require.NotEqual(t, "modified", volumes[0].VolumeName, "Cached volume was modified")
+
+	resp, err := http.Get("http://localhost:8099/metrics")
+	require.NoError(t, err, "Error getting metrics")
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err, "Error reading metrics")
+	require.Contains(t, string(body), "stork_extender_cache_hits_total", "Cache metrics not exported")
+}
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1, n2 and mark n2 as offline.
+// The explanation for the pod should reject n2 and list n1 with the highest
+// score followed by n3 which is in the same rack as n1
+func explainTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack3", "", ""))
+
+	if err := driver.CreateCluster(4, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("explainTest", []int{0, 1}, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	if err := driver.UpdateNodeStatus(1, volume.NodeOffline); err != nil {
+		t.Fatalf("Error setting node status to Offline: %v", err)
+	}
+
+	for i := range nodes.Items {
+		_, err := fakeKubeClient.CoreV1().Nodes().Create(&nodes.Items[i])
+		require.NoError(t, err, "Error creating node")
+	}
+	pod := newPod("explainTest", []string{"explainTest"})
+	pod.Namespace = defaultNamespace
+	_, err := fakeKubeClient.CoreV1().Pods(defaultNamespace).Create(pod)
+	require.NoError(t, err, "Error creating pod")
+	defer func() {
+		for _, node := range nodes.Items {
+			err := fakeKubeClient.CoreV1().Nodes().Delete(node.Name, &metav1.DeleteOptions{})
+			require.NoError(t, err, "Error deleting node")
+		}
+		err := fakeKubeClient.CoreV1().Pods(defaultNamespace).Delete(pod.Name, &metav1.DeleteOptions{})
+		require.NoError(t, err, "Error deleting pod")
+	}()
+
+	resp, err := http.Get("http://localhost:8099/explain?pod=" + defaultNamespace + "/explainTest")
+	require.NoError(t, err, "Error sending explain request")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "Unexpected status for explain request")
+
+	var result explanation
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err, "Error decoding explain response")
+	require.Equal(t, []string{"node1", "node3", "node4"}, result.FilteredNodes, "Unexpected filtered nodes")
+	require.Equal(t, fmt.Sprintf(reasonDriverOffline, volume.NodeOffline), result.FailedNodes["node2"],
+		"Unexpected reason for offline node")
+	require.Len(t, result.Scores, 3, "Expected scores for all filtered nodes")
+	require.Equal(t, "node1", result.Scores[0].Node, "Expected node with replica to have highest score")
+	require.Equal(t, nodePriorityScore, result.Scores[0].VolumeScores["explainTest"], "Unexpected volume score")
+	require.Equal(t, "node3", result.Scores[1].Node, "Expected node in same rack to have second highest score")
+	require.Equal(t, rackPriorityScore, result.Scores[1].Score, "Unexpected score")
+	require.True(t, result.Scores[2].DefaultScore, "Expected default score for node without locality")
+
+	resp, err = http.Get("http://localhost:8099/explain?pod=" + defaultNamespace + "/missingPod")
+	require.NoError(t, err, "Error sending explain request")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode, "Expected not found for missing pod")
+
+	resp, err = http.Get("http://localhost:8099/explain?pod=explainTest")
+	require.NoError(t, err, "Error sending explain request")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected bad request for invalid pod name")
+}
+
+// Create a storage cluster with 4 nodes which don't report their zone.
+// Label n1, n3 in zone a and n2, n4 in zone b. n4 is only matched through
+// the CSI node ID annotation.
+// Create a volume without replica information which can only be accessed
+// from zone a. The filter response should return n1, n3.
+// Create a volume with data on n1. The prioritize response should assign the
+// highest score to n1 followed by n3 since it is in the same zone.
+func csiTopologyTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("csi-node", "csi-node", "10.0.0.4", "", "", ""))
+	nodes.Items[3].Annotations = map[string]string{
+		volume.CSINodeIDAnnotation: `{"mock.csi.driver":"node4"}`,
+	}
+	for i, zone := range []string{"a", "b", "a", "b"} {
+		nodes.Items[i].Labels[volume.TopologyZoneLabel] = zone
+	}
+
+	if err := driver.CreateCluster(4, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("csiTopologyTest", nil, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	err := driver.UpdateVolumeNodeAffinity("csiTopologyTest", &v1.VolumeNodeAffinity{
+		Required: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchExpressions: []v1.NodeSelectorRequirement{{
+					Key:      volume.TopologyZoneLabel,
+					Operator: v1.NodeSelectorOpIn,
+					Values:   []string{"a"},
+				}},
+			}},
+		},
+	})
+	require.NoError(t, err, "Error updating node affinity for volume")
+
+	pod := newPod("csiTopologyTest", []string{"csiTopologyTest"})
+	filterResponse, err := sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{0, 2}, filterResponse)
+	require.Equal(t, fmt.Sprintf(reasonNodeAffinity, "csiTopologyTest"), filterResponse.FailedNodes["node2"],
+		"Unexpected reason for node in other zone")
+	require.Equal(t, fmt.Sprintf(reasonNodeAffinity, "csiTopologyTest"), filterResponse.FailedNodes["csi-node"],
+		"Unexpected reason for node in other zone")
+
+	if err := driver.ProvisionVolume("csiTopologyTest2", []int{0}, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	pod = newPod("csiTopologyTest2", []string{"csiTopologyTest2"})
+	filterResponse, err = sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{0, 1, 2, 3}, filterResponse)
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{nodePriorityScore, defaultScore, zonePriorityScore, defaultScore},
+		prioritizeResponse)
+}
+
+// Create a volume without replica information or node affinity, like most
+// CSI volumes without topology. The filter response should return all the
+// nodes and the prioritize response should assign them the default score
+func csiNoTopologyTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+
+	if err := driver.CreateCluster(3, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("csiNoTopologyTest", nil, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+
+	pod := newPod("csiNoTopologyTest", []string{"csiNoTopologyTest"})
+	filterResponse, err := sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{0, 1, 2}, filterResponse)
+	require.Empty(t, filterResponse.FailedNodes, "Expected no nodes to be rejected")
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{defaultScore, defaultScore, defaultScore},
+		prioritizeResponse)
+}
+
+// Create a pod with a pending PVC using the mock storage class.
+// The filter request should fail while the storage class doesn't use delayed
+// binding.
+// Update the storage class to wait for the first consumer and let the driver
+// provision volumes on n2, n3. The filter response should return n2, n3 and
+// the prioritize response should assign them the highest score.
+func waitForFirstConsumerTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack3", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack4", "", ""))
+
+	if err := driver.CreateCluster(4, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+
+	pvc := driver.NewPendingPVC("waitForFirstConsumerTest")
+	pvc.Namespace = defaultNamespace
+	_, err := fakeKubeClient.CoreV1().PersistentVolumeClaims(defaultNamespace).Create(pvc)
+	require.NoError(t, err, "Error creating PVC")
+	pod := newPod("waitForFirstConsumerTest", nil)
+	pod.Namespace = defaultNamespace
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvc.Name,
+			},
+		},
+	})
+
+	_, err = sendFilterRequest(pod, nodes)
+	require.Error(t, err, "Expected error for pending PVC with immediate binding")
+
+	bindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: driver.GetStorageClassName()},
+		VolumeBindingMode: &bindingMode,
+	}
+	_, err = fakeKubeClient.StorageV1().StorageClasses().Create(storageClass)
+	require.NoError(t, err, "Error creating storage class")
+	defer func() {
+		err := fakeKubeClient.StorageV1().StorageClasses().Delete(storageClass.Name, &metav1.DeleteOptions{})
+		require.NoError(t, err, "Error deleting storage class")
+		err = fakeKubeClient.CoreV1().PersistentVolumeClaims(defaultNamespace).Delete(pvc.Name, &metav1.DeleteOptions{})
+		require.NoError(t, err, "Error deleting PVC")
+	}()
+
+	err = driver.SetProvisionCandidates([]int{1, 2})
+	require.NoError(t, err, "Error setting provision candidates")
+
+	filterResponse, err := sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{1, 2}, filterResponse)
+	require.Equal(t, fmt.Sprintf(reasonCannotProvision, pvc.Name), filterResponse.FailedNodes["node1"],
+		"Unexpected reason for node without provision candidate")
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]int{defaultScore, nodePriorityScore, nodePriorityScore, defaultScore},
+		prioritizeResponse)
+}
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1, n2. Send a preemption request with victims on
+// n1, n2, n3, n4
+// The preemption response should only return the victims on n1, n2. After
+// marking n2 offline only the victims on n1 should be returned. A pod without
+// any volumes should be able to preempt pods on all the nodes
+func preemptionTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack2", "", ""))
+
+	if err := driver.CreateCluster(4, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("preemptionTest", []int{0, 1}, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	for _, node := range nodes.Items {
+		_, err := fakeKubeClient.CoreV1().Nodes().Create(node.DeepCopy())
+		require.NoError(t, err, "Error creating node")
+		waitForNode(t, node.Name, true)
+	}
+	defer func() {
+		for _, node := range nodes.Items {
+			err := fakeKubeClient.CoreV1().Nodes().Delete(node.Name, &metav1.DeleteOptions{})
+			require.NoError(t, err, "Error deleting node")
+			waitForNode(t, node.Name, false)
+		}
+	}()
+
+	victims := make(map[string]*schedulerapi.Victims)
+	for _, node := range nodes.Items {
+		victim := newPod("victim-"+node.Name, nil)
+		victim.UID = types.UID("victim-" + node.Name)
+		victims[node.Name] = &schedulerapi.Victims{
+			Pods: []*v1.Pod{victim},
+		}
+	}
+
+	pod := newPod("preemptionTest", []string{"preemptionTest"})
+	preemptionResponse, err := sendPreemptionRequest(pod, victims)
+	require.NoError(t, err, "Error sending preemption request")
+	require.Len(t, preemptionResponse.NodeNameToMetaVictims, 2, "Unexpected number of nodes in response")
+	for _, nodeName := range []string{"node1", "node2"} {
+		metaVictims, ok := preemptionResponse.NodeNameToMetaVictims[nodeName]
+		require.True(t, ok, "Expected victims on node %v", nodeName)
+		require.Len(t, metaVictims.Pods, 1, "Unexpected number of victims on node %v", nodeName)
+		require.Equal(t, "victim-"+nodeName, metaVictims.Pods[0].UID, "Unexpected victim on node %v", nodeName)
+	}
+
+	if err := driver.UpdateNodeStatus(1, volume.NodeOffline); err != nil {
+		t.Fatalf("Error setting node status to Offline: %v", err)
+	}
+	preemptionResponse, err = sendPreemptionRequest(pod, victims)
+	require.NoError(t, err, "Error sending preemption request")
+	require.Len(t, preemptionResponse.NodeNameToMetaVictims, 1, "Unexpected number of nodes in response")
+	require.Contains(t, preemptionResponse.NodeNameToMetaVictims, "node1", "Expected victims on node1")
+
+	pod = newPod("preemptionNoVolumeTest", nil)
+	preemptionResponse, err = sendPreemptionRequest(pod, victims)
+	require.NoError(t, err, "Error sending preemption request")
+	require.Len(t, preemptionResponse.NodeNameToMetaVictims, 4, "Expected victims on all nodes")
+}
+
+// secondaryDriver Driver which runs on the nodes but doesn't own any volumes.
+// Used to test filtering with multiple drivers
+type secondaryDriver struct {
+	*mock.Driver
+	nodes []*volume.NodeInfo
+}
+
+func (s *secondaryDriver) String() string {
+	return "SecondaryDriver"
+}
+
+func (s *secondaryDriver) GetNodes() ([]*volume.NodeInfo, error) {
+	return s.nodes, nil
+}
+
+func (s *secondaryDriver) GetPodVolumes(*v1.PodSpec, string) ([]*volume.Info, error) {
+	return nil, nil
+}
+
+func (s *secondaryDriver) OwnsPVC(*v1.PersistentVolumeClaim) bool {
+	return false
+}
+
+// Use a composite driver where the driver owning the volume is offline on a
+// node on which the other driver is online. The node should be filtered out
+// since the driver for the volume isn't online on it
+func multipleDriversTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+
+	if err := driver.CreateCluster(3, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	pod := newPod("multipleDriversPod", []string{"multipleDriversVolume"})
+	if err := driver.ProvisionVolume("multipleDriversVolume", []int{0, 1}, 1); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	if err := driver.UpdateNodeStatus(1, volume.NodeOffline); err != nil {
+		t.Fatalf("Error setting node status to Offline: %v", err)
+	}
+
+	secondary := &secondaryDriver{Driver: driver}
+	for i := 1; i <= 3; i++ {
+		secondary.nodes = append(secondary.nodes, &volume.NodeInfo{
+			ID:       fmt.Sprintf("secondary%v", i),
+			Hostname: fmt.Sprintf("node%v", i),
+			Status:   volume.NodeOnline,
+		})
+	}
+	composite := volume.NewCompositeDriver([]volume.Driver{secondary, driver})
+	compositeExtender := &Extender{
+		Driver: composite,
+		cache:  newTopologyCache(composite, 0),
+	}
+
+	driverVolumes, err := composite.GetPodVolumes(&pod.Spec, pod.Namespace)
+	require.NoError(t, err, "Error getting pod volumes")
+	require.Len(t, driverVolumes, 1, "Unexpected number of volumes")
+	require.Equal(t, mockDriverName, driverVolumes[0].Driver, "Unexpected driver for volume")
+
+	filteredNodes, failedNodes, err := compositeExtender.filterNodes(pod, nodes.Items)
+	require.NoError(t, err, "Error filtering nodes")
+	require.Len(t, filteredNodes, 2, "Unexpected number of filtered nodes")
+	require.Equal(t, "node1", filteredNodes[0].Name)
+	require.Equal(t, "node3", filteredNodes[1].Name)
+	require.Equal(t, fmt.Sprintf(reasonDriverOffline, volume.NodeOffline), failedNodes["node2"],
+		"Unexpected reason for node with offline driver")
 }
@@ -0,0 +1,78 @@
+package extender
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// startListers Starts informers for the objects that are looked up while
+// handling requests, so that they are read from a local cache instead of
+// being fetched from the API server for every request. Lookups before the
+// caches have synced don't find the objects
+func (e *Extender) startListers() {
+	e.namespaceLister = corelisters.NewNamespaceLister(e.startInformer(
+		&v1.Namespace{},
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return e.KubeClient.CoreV1().Namespaces().List(options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return e.KubeClient.CoreV1().Namespaces().Watch(options)
+		},
+	))
+	e.nodeLister = corelisters.NewNodeLister(e.startInformer(
+		&v1.Node{},
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return e.KubeClient.CoreV1().Nodes().List(options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return e.KubeClient.CoreV1().Nodes().Watch(options)
+		},
+	))
+	e.replicaSetLister = appslisters.NewReplicaSetLister(e.startInformer(
+		&appsv1.ReplicaSet{},
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return e.KubeClient.AppsV1().ReplicaSets(v1.NamespaceAll).List(options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return e.KubeClient.AppsV1().ReplicaSets(v1.NamespaceAll).Watch(options)
+		},
+	))
+	e.podLister = corelisters.NewPodLister(e.startInformer(
+		&v1.Pod{},
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return e.KubeClient.CoreV1().Pods(v1.NamespaceAll).List(options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return e.KubeClient.CoreV1().Pods(v1.NamespaceAll).Watch(options)
+		},
+	))
+}
+
+// startInformer Starts an informer for the objects returned by the list and
+// watch functions and returns its indexer, which is indexed by namespace
+func (e *Extender) startInformer(
+	objType runtime.Object,
+	listFunc cache.ListFunc,
+	watchFunc cache.WatchFunc,
+) cache.Indexer {
+	indexer, informer := cache.NewIndexerInformer(
+		&cache.ListWatch{
+			ListFunc:  listFunc,
+			WatchFunc: watchFunc,
+		},
+		objType,
+		// The informers are only used for lookups, so they don't need to
+		// be resynced
+		0,
+		cache.ResourceEventHandlerFuncs{},
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+	go informer.Run(e.stopChannel)
+	return indexer
+}
@@ -0,0 +1,154 @@
+package extender
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+)
+
+func (e *Extender) processPreemptionRequest(w http.ResponseWriter, req *http.Request) {
+	decoder := json.NewDecoder(req.Body)
+	defer func() {
+		if err := req.Body.Close(); err != nil {
+			log.Warnf("Error closing decoder")
+		}
+	}()
+	encoder := json.NewEncoder(w)
+
+	var args schedulerapi.ExtenderPreemptionArgs
+	if err := decoder.Decode(&args); err != nil {
+		log.Errorf("Error decoding preemption request: %v", err)
+		http.Error(w, "Decode error", http.StatusBadRequest)
+		return
+	}
+
+	// The victims are only sent with the pod UIDs if the scheduler caches
+	// the nodes, otherwise they are sent with the full pod spec
+	victims := args.NodeNameToMetaVictims
+	if victims == nil {
+		victims = make(map[string]*schedulerapi.MetaVictims)
+		for nodeName, nodeVictims := range args.NodeNameToVictims {
+			metaVictims := &schedulerapi.MetaVictims{
+				NumPDBViolations: nodeVictims.NumPDBViolations,
+			}
+			for _, pod := range nodeVictims.Pods {
+				metaVictims.Pods = append(metaVictims.Pods, &schedulerapi.MetaPod{UID: string(pod.UID)})
+			}
+			victims[nodeName] = metaVictims
+		}
+	}
+
+	pod := args.Pod
+	nodeNames := make([]string, 0, len(victims))
+	for nodeName := range victims {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	storklog.PodLog(pod).Debugf("Nodes in preemption request: %v", nodeNames)
+
+	allowedNodes := e.getPreemptionNodes(pod, nodeNames)
+	response := &schedulerapi.ExtenderPreemptionResult{
+		NodeNameToMetaVictims: make(map[string]*schedulerapi.MetaVictims),
+	}
+	for nodeName, nodeVictims := range victims {
+		if allowedNodes[nodeName] {
+			response.NodeNameToMetaVictims[nodeName] = nodeVictims
+		} else {
+			storklog.PodLog(pod).Debugf("Not preempting pods on node %v", nodeName)
+		}
+	}
+
+	if err := encoder.Encode(response); err != nil {
+		storklog.PodLog(pod).Errorf("Error encoding preemption response: %+v : %v", response, err)
+	}
+}
+
+// getPreemptionNodes Returns the nodes on which pods can be preempted to make
+// room for the pod. If the pod uses volumes from the driver, only nodes that
+// pass the filter and have data for all the volumes are returned so that the
+// pod doesn't preempt other pods on a node where it won't be hyperconverged.
+// All the nodes are returned if the pod doesn't use any volumes from the
+// driver or the nodes can't be checked
+func (e *Extender) getPreemptionNodes(pod *v1.Pod, nodeNames []string) map[string]bool {
+	allowedNodes := make(map[string]bool)
+	for _, nodeName := range nodeNames {
+		allowedNodes[nodeName] = true
+	}
+	if e.KubeClient == nil {
+		return allowedNodes
+	}
+
+	driverVolumes, _, err := e.getPodVolumes(pod)
+	if err != nil {
+		storklog.PodLog(pod).Warnf("Error getting volumes for Pod for driver, allowing preemption on all nodes: %v", err)
+		return allowedNodes
+	}
+	if len(driverVolumes) == 0 {
+		return allowedNodes
+	}
+
+	var nodes []v1.Node
+	for _, nodeName := range nodeNames {
+		node, err := e.nodeLister.Get(nodeName)
+		if err != nil {
+			storklog.PodLog(pod).Warnf("Error getting node %v, allowing preemption on all nodes: %v", nodeName, err)
+			return allowedNodes
+		}
+		nodes = append(nodes, *node)
+	}
+
+	filteredNodes, _, err := e.filterNodes(pod, nodes)
+	if err != nil {
+		storklog.PodLog(pod).Warnf("Error filtering nodes, allowing preemption on all nodes: %v", err)
+		return allowedNodes
+	}
+	driverNodes, err := e.cache.getNodes()
+	if err != nil {
+		storklog.PodLog(pod).Warnf("Error getting driver nodes, allowing preemption on all nodes: %v", err)
+		return allowedNodes
+	}
+	idMap := make(map[string]*volume.NodeInfo)
+	for _, dnode := range driverNodes {
+		idMap[dnode.ID] = dnode
+	}
+
+	allowedNodes = make(map[string]bool)
+	for _, node := range filteredNodes {
+		if hasAllVolumes(&node, driverVolumes, idMap) {
+			allowedNodes[node.Name] = true
+		}
+	}
+	if len(allowedNodes) == 0 {
+		storklog.PodLog(pod).Infof("No nodes with data for all volumes found, not preempting any pods")
+	}
+	return allowedNodes
+}
+
+// hasAllVolumes Checks if the node has data for all the volumes. Volumes for
+// which the driver doesn't report where the data is are ignored
+func hasAllVolumes(
+	node *v1.Node,
+	driverVolumes []*volume.Info,
+	idMap map[string]*volume.NodeInfo,
+) bool {
+	for _, volumeInfo := range driverVolumes {
+		if len(volumeInfo.DataNodes) == 0 {
+			continue
+		}
+		found := false
+		for _, dataNode := range volumeInfo.DataNodes {
+			if volume.IsNodeMatch(node, idMap[dataNode]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package extender
+
+import (
+	"github.com/libopenstorage/stork/drivers/volume"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8shelper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+// pendingClaim PVC using a StorageClass with delayed binding which will only
+// be provisioned once the pod using it has been scheduled
+type pendingClaim struct {
+	pvc          *v1.PersistentVolumeClaim
+	storageClass *storagev1.StorageClass
+}
+
+// getPodVolumes Returns the driver volumes used by the pod along with the
+// PVCs that are waiting for the pod to be scheduled before being provisioned.
+// Returns ErrPVCPending if any of the pending PVCs doesn't use delayed
+// binding
+func (e *Extender) getPodVolumes(pod *v1.Pod) ([]*volume.Info, []*pendingClaim, error) {
+	driverVolumes, err := e.cache.getPodVolumes(&pod.Spec, pod.Namespace)
+	if err == nil {
+		return driverVolumes, nil, nil
+	}
+	if _, ok := err.(*volume.ErrPVCPending); !ok || e.KubeClient == nil {
+		return nil, nil, err
+	}
+
+	// Get the volumes for the rest of the PVCs without the ones that will be
+	// provisioned after the pod is scheduled
+	var pendingClaims []*pendingClaim
+	podSpec := pod.Spec.DeepCopy()
+	podSpec.Volumes = nil
+	for _, podVolume := range pod.Spec.Volumes {
+		if podVolume.PersistentVolumeClaim != nil {
+			pvc, pvcErr := e.KubeClient.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(
+				podVolume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+			if pvcErr != nil {
+				storklog.PodLog(pod).Warnf("Error getting PVC %v: %v", podVolume.PersistentVolumeClaim.ClaimName, pvcErr)
+				return nil, nil, err
+			}
+			if pvc.Status.Phase == v1.ClaimPending && e.Driver.OwnsPVC(pvc) {
+				storageClass := e.getDelayedBindingStorageClass(pod, pvc)
+				if storageClass == nil {
+					return nil, nil, err
+				}
+				pendingClaims = append(pendingClaims, &pendingClaim{
+					pvc:          pvc,
+					storageClass: storageClass,
+				})
+				continue
+			}
+		}
+		podSpec.Volumes = append(podSpec.Volumes, podVolume)
+	}
+	if len(pendingClaims) == 0 {
+		return nil, nil, err
+	}
+
+	storklog.PodLog(pod).Debugf("Found %v PVCs waiting for the pod to be scheduled", len(pendingClaims))
+	driverVolumes, err = e.cache.getPodVolumes(podSpec, pod.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	return driverVolumes, pendingClaims, nil
+}
+
+// getDelayedBindingStorageClass Returns the StorageClass for the PVC if it
+// waits for the first consumer to be scheduled before provisioning volumes.
+// Returns nil otherwise
+func (e *Extender) getDelayedBindingStorageClass(
+	pod *v1.Pod,
+	pvc *v1.PersistentVolumeClaim,
+) *storagev1.StorageClass {
+	storageClassName := k8shelper.GetPersistentVolumeClaimClass(pvc)
+	if storageClassName == "" {
+		return nil
+	}
+	storageClass, err := e.KubeClient.StorageV1().StorageClasses().Get(storageClassName, metav1.GetOptions{})
+	if err != nil {
+		storklog.PodLog(pod).Warnf("Error getting StorageClass %v for PVC %v: %v", storageClassName, pvc.Name, err)
+		return nil
+	}
+	if storageClass.VolumeBindingMode == nil ||
+		*storageClass.VolumeBindingMode != storagev1.VolumeBindingWaitForFirstConsumer {
+		return nil
+	}
+	return storageClass
+}
+
+// getProvisionCandidates Returns the IDs of the driver nodes on which each of
+// the pending PVCs can be provisioned, keyed by the PVC name. PVCs are
+// skipped if the driver can't report where they can be provisioned
+func (e *Extender) getProvisionCandidates(
+	pod *v1.Pod,
+	pendingClaims []*pendingClaim,
+) map[string]map[string]bool {
+	if len(pendingClaims) == 0 {
+		return nil
+	}
+	plugin, ok := e.Driver.(volume.ProvisionTopologyPluginInterface)
+	if !ok {
+		return nil
+	}
+
+	candidates := make(map[string]map[string]bool)
+	for _, claim := range pendingClaims {
+		provisionCandidates, err := plugin.GetProvisionCandidates(claim.pvc, claim.storageClass)
+		if err != nil {
+			storklog.PodLog(pod).Warnf("Error getting nodes where PVC %v can be provisioned: %v", claim.pvc.Name, err)
+			continue
+		}
+		nodeIDs := make(map[string]bool)
+		for _, candidate := range provisionCandidates {
+			storklog.PodLog(pod).Debugf("PVC %v can be provisioned on node %v using pools %v",
+				claim.pvc.Name, candidate.NodeID, candidate.Pools)
+			nodeIDs[candidate.NodeID] = true
+		}
+		candidates[claim.pvc.Name] = nodeIDs
+	}
+	return candidates
+}
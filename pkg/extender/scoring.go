@@ -0,0 +1,258 @@
+package extender
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ScoringWeightsAnnotation Annotation on a pod or namespace to override the
+	// weights used to score nodes. Specified as a comma separated list of
+	// key=value pairs, for example "node=200,rack=0"
+	ScoringWeightsAnnotation = "stork.libopenstorage.org/scoring-weights"
+	// DefaultScoringConfigMapName Name of the ConfigMap with the cluster wide
+	// scoring weights
+	DefaultScoringConfigMapName = "stork-scoring-policy"
+	// DefaultScoringConfigMapNamespace Namespace of the ConfigMap with the
+	// cluster wide scoring weights
+	DefaultScoringConfigMapNamespace = "kube-system"
+
+	nodeWeightKey    = "node"
+	rackWeightKey    = "rack"
+	zoneWeightKey    = "zone"
+	regionWeightKey  = "region"
+	defaultWeightKey = "default"
+
+	scoringPolicyRefreshInterval = 30 * time.Second
+
+	// tieBreakerMaxScore Maximum score by which a node is bumped over other
+	// nodes with the same score because of lower utilization
+	tieBreakerMaxScore = 10
+)
+
+// scoringWeights Scores by which nodes are bumped depending on their locality
+// to the data for a volume
+type scoringWeights struct {
+	node       int
+	rack       int
+	zone       int
+	region     int
+	defaultVal int
+}
+
+func defaultScoringWeights() scoringWeights {
+	return scoringWeights{
+		node:       nodePriorityScore,
+		rack:       rackPriorityScore,
+		zone:       zonePriorityScore,
+		region:     regionPriorityScore,
+		defaultVal: defaultScore,
+	}
+}
+
+// update Overrides the weights with the ones present in the map
+func (w *scoringWeights) update(values map[string]string) error {
+	for key, value := range values {
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("Invalid weight %v for %v: %v", value, key, err)
+		}
+		if weight < 0 {
+			return fmt.Errorf("Weight for %v can't be negative: %v", key, weight)
+		}
+		switch strings.TrimSpace(key) {
+		case nodeWeightKey:
+			w.node = weight
+		case rackWeightKey:
+			w.rack = weight
+		case zoneWeightKey:
+			w.zone = weight
+		case regionWeightKey:
+			w.region = weight
+		case defaultWeightKey:
+			w.defaultVal = weight
+		default:
+			return fmt.Errorf("Invalid weight key %v", key)
+		}
+	}
+	return nil
+}
+
+// values Returns the weights keyed by the names used in the scoring policy
+func (w *scoringWeights) values() map[string]int {
+	return map[string]int{
+		nodeWeightKey:    w.node,
+		rackWeightKey:    w.rack,
+		zoneWeightKey:    w.zone,
+		regionWeightKey:  w.region,
+		defaultWeightKey: w.defaultVal,
+	}
+}
+
+// parseWeightsAnnotation Parses "key=value" pairs separated by commas
+func parseWeightsAnnotation(annotation string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(annotation, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		keyValue := strings.SplitN(pair, "=", 2)
+		if len(keyValue) != 2 {
+			return nil, fmt.Errorf("Invalid weight %v, should be of the form key=value", pair)
+		}
+		values[strings.TrimSpace(keyValue[0])] = keyValue[1]
+	}
+	return values, nil
+}
+
+func (e *Extender) startScoringPolicyRefresh() {
+	e.loadScoringPolicy()
+	go func() {
+		ticker := time.NewTicker(scoringPolicyRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.loadScoringPolicy()
+			case <-e.stopChannel:
+				return
+			}
+		}
+	}()
+}
+
+// loadScoringPolicy Loads the cluster wide weights from the scoring
+// ConfigMap. The defaults are used if the ConfigMap doesn't exist or is
+// invalid
+func (e *Extender) loadScoringPolicy() {
+	weights := defaultScoringWeights()
+	configMap, err := e.KubeClient.CoreV1().ConfigMaps(e.ScoringConfigMapNamespace).Get(
+		e.ScoringConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Warnf("Error getting scoring policy %v/%v, using defaults: %v",
+				e.ScoringConfigMapNamespace, e.ScoringConfigMapName, err)
+		}
+	} else if err := weights.update(configMap.Data); err != nil {
+		log.Errorf("Error parsing scoring policy %v/%v, using defaults: %v",
+			e.ScoringConfigMapNamespace, e.ScoringConfigMapName, err)
+		weights = defaultScoringWeights()
+	}
+
+	e.weightsLock.Lock()
+	defer e.weightsLock.Unlock()
+	e.weights = weights
+}
+
+// getScoringWeights Returns the weights to be used for the pod. Annotations
+// on the pod take precedence over annotations on its namespace, which take
+// precedence over the cluster wide policy. The namespace is read from the
+// lister
+func (e *Extender) getScoringWeights(pod *v1.Pod) scoringWeights {
+	e.weightsLock.RLock()
+	weights := e.weights
+	e.weightsLock.RUnlock()
+
+	if e.namespaceLister != nil {
+		namespace, err := e.namespaceLister.Get(pod.Namespace)
+		if err != nil {
+			storklog.PodLog(pod).Debugf("Error getting namespace to check for scoring weights: %v", err)
+		} else {
+			weights = applyWeightsAnnotation(pod, weights, namespace.Annotations)
+		}
+	}
+	return applyWeightsAnnotation(pod, weights, pod.Annotations)
+}
+
+func applyWeightsAnnotation(
+	pod *v1.Pod,
+	weights scoringWeights,
+	annotations map[string]string,
+) scoringWeights {
+	annotation, ok := annotations[ScoringWeightsAnnotation]
+	if !ok {
+		return weights
+	}
+	values, err := parseWeightsAnnotation(annotation)
+	if err == nil {
+		updated := weights
+		if err = updated.update(values); err == nil {
+			return updated
+		}
+	}
+	storklog.PodLog(pod).Warnf("Ignoring invalid scoring weights %v: %v", annotation, err)
+	return weights
+}
+
+// compareUtilization Returns a positive number if node a is preferred over
+// node b, a negative number if b is preferred over a and 0 if they are
+// equivalent. Nodes with more free capacity are preferred, followed by nodes
+// with fewer attached volumes
+func compareUtilization(a, b *volume.NodeUtilization) int {
+	if a.FreeCapacity() != b.FreeCapacity() {
+		if a.FreeCapacity() > b.FreeCapacity() {
+			return 1
+		}
+		return -1
+	}
+	return b.AttachedVolumes - a.AttachedVolumes
+}
+
+// breakTies Bumps the scores of nodes which have the same score based on
+// their utilization. The bump is always lower than the difference to the
+// next higher score, so nodes never overtake nodes with better locality.
+// Nodes with a score of 0 will be assigned the default score later, so they
+// are treated as having the default score when calculating the difference.
+func breakTies(
+	priorityMap map[string]int,
+	utilizationMap map[string]*volume.NodeUtilization,
+	weights *scoringWeights,
+) {
+	scoreGroups := make(map[int][]string)
+	var scores []int
+	for nodeName, score := range priorityMap {
+		if score == 0 {
+			scores = append(scores, weights.defaultVal)
+			continue
+		}
+		scores = append(scores, score)
+		if _, ok := utilizationMap[nodeName]; ok {
+			scoreGroups[score] = append(scoreGroups[score], nodeName)
+		}
+	}
+
+	for score, nodeNames := range scoreGroups {
+		if len(nodeNames) < 2 {
+			continue
+		}
+
+		maxBonus := tieBreakerMaxScore
+		for _, otherScore := range scores {
+			if otherScore > score && otherScore-score-1 < maxBonus {
+				maxBonus = otherScore - score - 1
+			}
+		}
+		if maxBonus <= 0 {
+			continue
+		}
+
+		for _, nodeName := range nodeNames {
+			worseNodes := 0
+			for _, otherNodeName := range nodeNames {
+				if compareUtilization(utilizationMap[nodeName], utilizationMap[otherNodeName]) > 0 {
+					worseNodes++
+				}
+			}
+			priorityMap[nodeName] += maxBonus * worseNodes / (len(nodeNames) - 1)
+		}
+	}
+}
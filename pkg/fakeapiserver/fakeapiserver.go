@@ -0,0 +1,657 @@
+package fakeapiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/ghodss/yaml"
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/version"
+	restclient "k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+)
+
+// Resource Type of object served by the fake API server
+type Resource struct {
+	Group      string
+	Version    string
+	Kind       string
+	Name       string
+	Namespaced bool
+}
+
+// DefaultResources Resources served by a server created with New. Includes
+// the core resources used by stork, CRDs, the stork CRDs and the snapshot
+// CRDs
+var DefaultResources = []Resource{
+	{Version: "v1", Kind: "Namespace", Name: "namespaces"},
+	{Version: "v1", Kind: "Node", Name: "nodes"},
+	{Version: "v1", Kind: "PersistentVolume", Name: "persistentvolumes"},
+	{Version: "v1", Kind: "PersistentVolumeClaim", Name: "persistentvolumeclaims", Namespaced: true},
+	{Version: "v1", Kind: "Pod", Name: "pods", Namespaced: true},
+	{Version: "v1", Kind: "Service", Name: "services", Namespaced: true},
+	{Version: "v1", Kind: "Secret", Name: "secrets", Namespaced: true},
+	{Version: "v1", Kind: "ConfigMap", Name: "configmaps", Namespaced: true},
+	{Version: "v1", Kind: "ServiceAccount", Name: "serviceaccounts", Namespaced: true},
+	{Version: "v1", Kind: "Event", Name: "events", Namespaced: true},
+	{Group: "apps", Version: "v1", Kind: "Deployment", Name: "deployments", Namespaced: true},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet", Name: "statefulsets", Namespaced: true},
+	{Group: "storage.k8s.io", Version: "v1", Kind: "StorageClass", Name: "storageclasses"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding", Name: "rolebindings", Namespaced: true},
+	{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition",
+		Name: "customresourcedefinitions"},
+	{Group: stork.GroupName, Version: stork_api.SchemeGroupVersion.Version, Kind: "Rule",
+		Name: "rules", Namespaced: true},
+	{Group: stork.GroupName, Version: stork_api.SchemeGroupVersion.Version, Kind: "ClusterPair",
+		Name: stork_api.ClusterPairResourcePlural, Namespaced: true},
+	{Group: stork.GroupName, Version: stork_api.SchemeGroupVersion.Version, Kind: "Migration",
+		Name: stork_api.MigrationResourcePlural, Namespaced: true},
+	{Group: stork.GroupName, Version: stork_api.SchemeGroupVersion.Version, Kind: "MigrationSchedule",
+		Name: stork_api.MigrationScheduleResourcePlural, Namespaced: true},
+	{Group: stork.GroupName, Version: stork_api.SchemeGroupVersion.Version, Kind: "GroupVolumeSnapshot",
+		Name: stork_api.GroupVolumeSnapshotResourcePlural, Namespaced: true},
+	{Group: stork.GroupName, Version: stork_api.SchemeGroupVersion.Version, Kind: "ResourceTransformation",
+		Name: stork_api.ResourceTransformationResourcePlural, Namespaced: true},
+	{Group: stork.GroupName, Version: stork_api.SchemeGroupVersion.Version, Kind: "SchedulePolicy",
+		Name: stork_api.SchedulePolicyResourcePlural},
+	{Group: stork.GroupName, Version: stork_api.SchemeGroupVersion.Version, Kind: "VolumeSnapshotSchedule",
+		Name: stork_api.VolumeSnapshotScheduleResourcePlural, Namespaced: true},
+	{Group: snapv1.GroupName, Version: snapv1.SchemeGroupVersion.Version, Kind: "VolumeSnapshot",
+		Name: snapv1.VolumeSnapshotResourcePlural, Namespaced: true},
+	{Group: snapv1.GroupName, Version: snapv1.SchemeGroupVersion.Version, Kind: "VolumeSnapshotData",
+		Name: snapv1.VolumeSnapshotDataResourcePlural},
+}
+
+var serverVersion = version.Info{
+	Major:      "1",
+	Minor:      "11",
+	GitVersion: "v1.11.0",
+}
+
+// Server In-memory API server for tests. Serves discovery and create, get,
+// list, update, patch and delete for a fixed set of resources. Watches,
+// validation, defaulting and resourceVersion conflicts aren't supported
+type Server struct {
+	server          *httptest.Server
+	lock            sync.Mutex
+	resources       []Resource
+	objects         map[Resource]map[string]map[string]interface{}
+	resourceVersion int64
+}
+
+// New Starts a server serving the DefaultResources
+func New() *Server {
+	return NewWithResources(DefaultResources)
+}
+
+// NewWithResources Starts a server serving the given resources
+func NewWithResources(resources []Resource) *Server {
+	s := &Server{
+		resources: resources,
+		objects:   make(map[Resource]map[string]map[string]interface{}),
+	}
+	for _, resource := range resources {
+		s.objects[resource] = make(map[string]map[string]interface{})
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close Stops the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL Returns the URL of the server
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Config Returns the config to be used by clients of the server
+func (s *Server) Config() *restclient.Config {
+	return &restclient.Config{
+		Host: s.server.URL,
+	}
+}
+
+// KubeConfig Returns a kubeconfig with a single context, with the given
+// name, for the server
+func (s *Server) KubeConfig(name string) clientcmdapi.Config {
+	return clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			name: {
+				Server: s.server.URL,
+			},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			name: {},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			name: {
+				Cluster:  name,
+				AuthInfo: name,
+			},
+		},
+		CurrentContext: name,
+	}
+}
+
+// WriteKubeConfig Writes a kubeconfig with a single context, with the given
+// name, for the server to the given path. Used to point clients that only
+// load their config from a file, like the operator-sdk, to the server
+func (s *Server) WriteKubeConfig(name string, path string) error {
+	config := clientcmdapiv1.Config{
+		Kind:       "Config",
+		APIVersion: "v1",
+		Clusters: []clientcmdapiv1.NamedCluster{
+			{
+				Name: name,
+				Cluster: clientcmdapiv1.Cluster{
+					Server: s.server.URL,
+				},
+			},
+		},
+		AuthInfos: []clientcmdapiv1.NamedAuthInfo{
+			{
+				Name: name,
+			},
+		},
+		Contexts: []clientcmdapiv1.NamedContext{
+			{
+				Name: name,
+				Context: clientcmdapiv1.Context{
+					Cluster:  name,
+					AuthInfo: name,
+				},
+			},
+		},
+		CurrentContext: name,
+	}
+	encoded, err := yaml.Marshal(&config)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, encoded, 0600)
+}
+
+// Reset Deletes all the objects from the server
+func (s *Server) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for resource := range s.objects {
+		s.objects[resource] = make(map[string]map[string]interface{})
+	}
+}
+
+// GetObject Returns the object with the given name from the server. Returns
+// a NotFound error if it doesn't exist
+func (s *Server) GetObject(
+	gvr schema.GroupVersionResource,
+	namespace string,
+	name string,
+) (*unstructured.Unstructured, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	resource, ok := s.getResource(gvr.Group, gvr.Version, gvr.Resource)
+	if !ok {
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+	object, ok := s.objects[resource][objectKey(namespace, name)]
+	if !ok {
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+	return &unstructured.Unstructured{Object: deepCopy(object)}, nil
+}
+
+// ListObjects Returns all the objects of the given resource in the
+// namespace, sorted by namespace and name. Objects in all namespaces are
+// returned if the namespace is empty
+func (s *Server) ListObjects(gvr schema.GroupVersionResource, namespace string) []*unstructured.Unstructured {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	resource, ok := s.getResource(gvr.Group, gvr.Version, gvr.Resource)
+	if !ok {
+		return nil
+	}
+	objects := make([]*unstructured.Unstructured, 0)
+	for _, object := range s.listObjects(resource, namespace, labels.Everything(), fields.Everything()) {
+		objects = append(objects, &unstructured.Unstructured{Object: deepCopy(object)})
+	}
+	return objects
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/version":
+		writeResponse(w, http.StatusOK, serverVersion)
+	case r.URL.Path == "/api":
+		writeResponse(w, http.StatusOK, &metav1.APIVersions{
+			TypeMeta: metav1.TypeMeta{Kind: "APIVersions"},
+			Versions: []string{"v1"},
+		})
+	case r.URL.Path == "/apis":
+		writeResponse(w, http.StatusOK, s.getGroupList())
+	case parts[0] == "api" && len(parts) >= 2:
+		s.serveGroupVersion(w, r, "", parts[1], parts[2:])
+	case parts[0] == "apis" && len(parts) >= 3:
+		s.serveGroupVersion(w, r, parts[1], parts[2], parts[3:])
+	default:
+		writeError(w, apierrors.NewNotFound(schema.GroupResource{}, r.URL.Path))
+	}
+}
+
+func (s *Server) getGroupList() *metav1.APIGroupList {
+	groupList := &metav1.APIGroupList{
+		TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"},
+	}
+	seen := make(map[string]bool)
+	for _, resource := range s.resources {
+		gv := schema.GroupVersion{Group: resource.Group, Version: resource.Version}
+		if gv.Group == "" || seen[gv.String()] {
+			continue
+		}
+		seen[gv.String()] = true
+		groupVersion := metav1.GroupVersionForDiscovery{
+			GroupVersion: gv.String(),
+			Version:      gv.Version,
+		}
+		found := false
+		for i := range groupList.Groups {
+			if groupList.Groups[i].Name == gv.Group {
+				groupList.Groups[i].Versions = append(groupList.Groups[i].Versions, groupVersion)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groupList.Groups = append(groupList.Groups, metav1.APIGroup{
+				Name:             gv.Group,
+				Versions:         []metav1.GroupVersionForDiscovery{groupVersion},
+				PreferredVersion: groupVersion,
+			})
+		}
+	}
+	return groupList
+}
+
+func (s *Server) getResourceList(group string, version string) (*metav1.APIResourceList, bool) {
+	gv := schema.GroupVersion{Group: group, Version: version}
+	resourceList := &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: gv.String(),
+	}
+	for _, resource := range s.resources {
+		if resource.Group != group || resource.Version != version {
+			continue
+		}
+		resourceList.APIResources = append(resourceList.APIResources, metav1.APIResource{
+			Name:       resource.Name,
+			Namespaced: resource.Namespaced,
+			Kind:       resource.Kind,
+			Verbs:      metav1.Verbs{"create", "delete", "get", "list", "patch", "update"},
+		})
+	}
+	return resourceList, len(resourceList.APIResources) > 0
+}
+
+func (s *Server) getResource(group string, version string, name string) (Resource, bool) {
+	for _, resource := range s.resources {
+		if resource.Group == group && resource.Version == version && resource.Name == name {
+			return resource, true
+		}
+	}
+	return Resource{}, false
+}
+
+// serveGroupVersion Serves the requests for a group version. The path is
+// split into the namespace, resource, name and subresource
+func (s *Server) serveGroupVersion(
+	w http.ResponseWriter,
+	r *http.Request,
+	group string,
+	version string,
+	path []string,
+) {
+	if len(path) == 0 {
+		resourceList, ok := s.getResourceList(group, version)
+		if !ok {
+			writeError(w, apierrors.NewNotFound(schema.GroupResource{Group: group}, version))
+			return
+		}
+		writeResponse(w, http.StatusOK, resourceList)
+		return
+	}
+
+	var namespace, name, subresource string
+	if len(path) >= 3 && path[0] == "namespaces" {
+		if _, ok := s.getResource(group, version, path[2]); ok {
+			namespace = path[1]
+			path = path[2:]
+		}
+	}
+	resource, ok := s.getResource(group, version, path[0])
+	if !ok {
+		writeError(w, apierrors.NewNotFound(schema.GroupResource{Group: group, Resource: path[0]}, ""))
+		return
+	}
+	if len(path) > 1 {
+		name = path[1]
+	}
+	if len(path) > 2 {
+		subresource = path[2]
+	}
+	if subresource != "" && subresource != "status" {
+		writeError(w, apierrors.NewNotFound(schema.GroupResource{Group: group, Resource: resource.Name}, subresource))
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		if r.URL.Query().Get("watch") == "true" {
+			writeError(w, apierrors.NewMethodNotSupported(
+				schema.GroupResource{Group: group, Resource: resource.Name}, "watch"))
+			return
+		}
+		s.list(w, r, resource, namespace)
+	case r.Method == http.MethodGet:
+		s.get(w, resource, namespace, name)
+	case r.Method == http.MethodPost && subresource == "":
+		// Creating with the name in the path is also accepted, like
+		// older API servers do
+		s.create(w, r, resource, namespace, name)
+	case r.Method == http.MethodPut && name != "":
+		s.update(w, r, resource, namespace, name)
+	case r.Method == http.MethodPatch && name != "":
+		s.patch(w, r, resource, namespace, name)
+	case r.Method == http.MethodDelete && name != "":
+		s.delete(w, resource, namespace, name)
+	default:
+		writeError(w, apierrors.NewMethodNotSupported(
+			schema.GroupResource{Group: group, Resource: resource.Name}, r.Method))
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, resource Resource, namespace string) {
+	selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	fieldSelector, err := fields.ParseSelector(r.URL.Query().Get("fieldSelector"))
+	if err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	items := make([]interface{}, 0)
+	for _, object := range s.listObjects(resource, namespace, selector, fieldSelector) {
+		items = append(items, object)
+	}
+	writeResponse(w, http.StatusOK, map[string]interface{}{
+		"kind":       resource.Kind + "List",
+		"apiVersion": groupVersion(resource),
+		"metadata": map[string]interface{}{
+			"resourceVersion": strconv.FormatInt(s.resourceVersion, 10),
+		},
+		"items": items,
+	})
+}
+
+func (s *Server) listObjects(
+	resource Resource,
+	namespace string,
+	selector labels.Selector,
+	fieldSelector fields.Selector,
+) []map[string]interface{} {
+	keys := make([]string, 0)
+	for key, object := range s.objects[resource] {
+		u := &unstructured.Unstructured{Object: object}
+		if namespace != "" && u.GetNamespace() != namespace {
+			continue
+		}
+		if !selector.Matches(labels.Set(u.GetLabels())) {
+			continue
+		}
+		if !fieldSelector.Matches(getFields(object, fieldSelector)) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	objects := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		objects = append(objects, s.objects[resource][key])
+	}
+	return objects
+}
+
+// getFields Returns the values of the fields used by the selector, for
+// example spec.nodeName, from the object
+func getFields(object map[string]interface{}, selector fields.Selector) fields.Set {
+	set := make(fields.Set)
+	for _, requirement := range selector.Requirements() {
+		value, found, err := unstructured.NestedFieldNoCopy(object, strings.Split(requirement.Field, ".")...)
+		if err != nil || !found {
+			continue
+		}
+		set[requirement.Field] = fmt.Sprintf("%v", value)
+	}
+	return set
+}
+
+func (s *Server) get(w http.ResponseWriter, resource Resource, namespace string, name string) {
+	object, ok := s.objects[resource][objectKey(namespace, name)]
+	if !ok {
+		writeError(w, notFound(resource, name))
+		return
+	}
+	writeResponse(w, http.StatusOK, object)
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, resource Resource, namespace string, name string) {
+	object, err := readObject(r)
+	if err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	u := &unstructured.Unstructured{Object: object}
+	if u.GetName() == "" {
+		u.SetName(name)
+	}
+	if u.GetName() == "" && u.GetGenerateName() != "" {
+		u.SetName(u.GetGenerateName() + string(uuid.NewUUID())[:5])
+	}
+	if u.GetName() == "" {
+		writeError(w, apierrors.NewBadRequest("name is required"))
+		return
+	}
+	if resource.Namespaced {
+		if namespace == "" {
+			namespace = u.GetNamespace()
+		}
+		u.SetNamespace(namespace)
+	}
+	key := objectKey(u.GetNamespace(), u.GetName())
+	if _, ok := s.objects[resource][key]; ok {
+		writeError(w, apierrors.NewAlreadyExists(
+			schema.GroupResource{Group: resource.Group, Resource: resource.Name}, u.GetName()))
+		return
+	}
+	u.SetAPIVersion(groupVersion(resource))
+	u.SetKind(resource.Kind)
+	u.SetUID(uuid.NewUUID())
+	u.SetCreationTimestamp(metav1.NewTime(time.Now()))
+	s.setResourceVersion(u)
+	s.objects[resource][key] = u.Object
+	writeResponse(w, http.StatusCreated, u.Object)
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, resource Resource, namespace string, name string) {
+	existing, ok := s.objects[resource][objectKey(namespace, name)]
+	if !ok {
+		writeError(w, notFound(resource, name))
+		return
+	}
+	object, err := readObject(r)
+	if err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	s.replace(w, resource, existing, object)
+}
+
+func (s *Server) patch(w http.ResponseWriter, r *http.Request, resource Resource, namespace string, name string) {
+	existing, ok := s.objects[resource][objectKey(namespace, name)]
+	if !ok {
+		writeError(w, notFound(resource, name))
+		return
+	}
+	patchBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	existingBytes, err := json.Marshal(existing)
+	if err != nil {
+		writeError(w, apierrors.NewInternalError(err))
+		return
+	}
+
+	var patchedBytes []byte
+	if r.Header.Get("Content-Type") == string(types.JSONPatchType) {
+		patch, err := jsonpatch.DecodePatch(patchBytes)
+		if err != nil {
+			writeError(w, apierrors.NewBadRequest(err.Error()))
+			return
+		}
+		patchedBytes, err = patch.Apply(existingBytes)
+		if err != nil {
+			writeError(w, apierrors.NewBadRequest(err.Error()))
+			return
+		}
+	} else {
+		// Strategic merge patches are applied as merge patches, which is
+		// enough for the patches that don't replace lists
+		patchedBytes, err = jsonpatch.MergePatch(existingBytes, patchBytes)
+		if err != nil {
+			writeError(w, apierrors.NewBadRequest(err.Error()))
+			return
+		}
+	}
+	object := make(map[string]interface{})
+	if err := json.Unmarshal(patchedBytes, &object); err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	s.replace(w, resource, existing, object)
+}
+
+// replace Replaces the existing object with the updated one, keeping the
+// fields set by the server
+func (s *Server) replace(
+	w http.ResponseWriter,
+	resource Resource,
+	existing map[string]interface{},
+	object map[string]interface{},
+) {
+	current := &unstructured.Unstructured{Object: existing}
+	u := &unstructured.Unstructured{Object: object}
+	u.SetAPIVersion(groupVersion(resource))
+	u.SetKind(resource.Kind)
+	u.SetName(current.GetName())
+	u.SetNamespace(current.GetNamespace())
+	u.SetUID(current.GetUID())
+	u.SetCreationTimestamp(current.GetCreationTimestamp())
+	s.setResourceVersion(u)
+	s.objects[resource][objectKey(u.GetNamespace(), u.GetName())] = u.Object
+	writeResponse(w, http.StatusOK, u.Object)
+}
+
+func (s *Server) delete(w http.ResponseWriter, resource Resource, namespace string, name string) {
+	key := objectKey(namespace, name)
+	if _, ok := s.objects[resource][key]; !ok {
+		writeError(w, notFound(resource, name))
+		return
+	}
+	delete(s.objects[resource], key)
+	writeResponse(w, http.StatusOK, &metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusSuccess,
+	})
+}
+
+func (s *Server) setResourceVersion(u *unstructured.Unstructured) {
+	s.resourceVersion++
+	u.SetResourceVersion(strconv.FormatInt(s.resourceVersion, 10))
+}
+
+func readObject(r *http.Request) (map[string]interface{}, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	object := make(map[string]interface{})
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, fmt.Errorf("Error parsing object: %v", err)
+	}
+	return object, nil
+}
+
+func writeResponse(w http.ResponseWriter, code int, response interface{}) {
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(encoded)
+}
+
+func writeError(w http.ResponseWriter, err *apierrors.StatusError) {
+	status := err.ErrStatus
+	status.Kind = "Status"
+	status.APIVersion = "v1"
+	writeResponse(w, int(status.Code), &status)
+}
+
+func notFound(resource Resource, name string) *apierrors.StatusError {
+	return apierrors.NewNotFound(schema.GroupResource{Group: resource.Group, Resource: resource.Name}, name)
+}
+
+func groupVersion(resource Resource) string {
+	return schema.GroupVersion{Group: resource.Group, Version: resource.Version}.String()
+}
+
+func objectKey(namespace string, name string) string {
+	return namespace + "/" + name
+}
+
+// deepCopy Returns a copy of the object that can be modified without
+// changing the stored object
+func deepCopy(object map[string]interface{}) map[string]interface{} {
+	return (&unstructured.Unstructured{Object: object}).DeepCopy().Object
+}
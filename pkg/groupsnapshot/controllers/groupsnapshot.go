@@ -217,6 +217,10 @@ func (m *GroupSnapshotController) handleInitial(groupSnap *stork_api.GroupVolume
 		err = fmt.Errorf("matchLabels are required for group snapshots. Refer to spec examples")
 	}
 
+	if !m.Driver.Capabilities().GroupSnapshots {
+		err = fmt.Errorf("group snapshots are not supported by driver %v", m.Driver.String())
+	}
+
 	if err != nil {
 		groupSnap.Status.Status = stork_api.GroupSnapshotFailed
 		groupSnap.Status.Stage = stork_api.GroupSnapshotStageFinal
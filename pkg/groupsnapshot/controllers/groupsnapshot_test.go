@@ -0,0 +1,332 @@
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	crdv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/fakeapiserver"
+	snapshotcontrollers "github.com/libopenstorage/stork/pkg/snapshot/controllers"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	"github.com/operator-framework/operator-sdk/pkg/util/k8sutil"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	mockDriverName = "MockDriver"
+	testNamespace  = "groupsnapshottest"
+	numEvents      = 100
+)
+
+var apiServer *fakeapiserver.Server
+var kubeConfigFile string
+var mockDriver *mock.Driver
+var recorder *record.FakeRecorder
+var groupSnapshotController *GroupSnapshotController
+
+func TestGroupSnapshotController(t *testing.T) {
+	t.Run("setup", setup)
+	t.Run("groupSnapshotTest", groupSnapshotTest)
+	t.Run("groupSnapshotRetryTest", groupSnapshotRetryTest)
+	t.Run("groupSnapshotFailureTest", groupSnapshotFailureTest)
+	t.Run("groupSnapshotPendingPVCTest", groupSnapshotPendingPVCTest)
+	t.Run("groupSnapshotNotSupportedTest", groupSnapshotNotSupportedTest)
+	t.Run("teardown", teardown)
+}
+
+// Start a fake API server and create the controller with the mock driver.
+// The operator-sdk only reads its config from a kubeconfig file, so one is
+// written for the server
+func setup(t *testing.T) {
+	apiServer = fakeapiserver.New()
+	file, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err, "Error creating kubeconfig file")
+	require.NoError(t, file.Close(), "Error closing kubeconfig file")
+	kubeConfigFile = file.Name()
+	require.NoError(t, apiServer.WriteKubeConfig("local", kubeConfigFile), "Error writing kubeconfig")
+	require.NoError(t, os.Setenv(k8sutil.KubeConfigEnvVar, kubeConfigFile), "Error setting kubeconfig")
+	k8s.Instance().SetConfig(apiServer.Config())
+
+	d, err := storkvolume.Get(mockDriverName)
+	require.NoError(t, err, "Error getting mock driver")
+	var ok bool
+	mockDriver, ok = d.(*mock.Driver)
+	require.True(t, ok, "Error casting mock driver")
+
+	recorder = record.NewFakeRecorder(numEvents)
+	groupSnapshotController = &GroupSnapshotController{
+		Driver:              mockDriver,
+		Recorder:            recorder,
+		bgChannelsForRules:  make(map[string]chan bool),
+		minResourceVersions: make(map[string]string),
+	}
+}
+
+func teardown(t *testing.T) {
+	apiServer.Close()
+	require.NoError(t, os.Remove(kubeConfigFile), "Error removing kubeconfig file")
+}
+
+// resetTest Clears the API server and the mock driver, enables group
+// snapshots in the mock driver and creates two PVCs owned by it
+func resetTest(t *testing.T) {
+	apiServer.Reset()
+	require.NoError(t, mockDriver.CreateCluster(3, &v1.NodeList{}), "Error creating cluster")
+	mockDriver.SetCapabilities(&storkvolume.Capabilities{
+		Snapshots:      true,
+		GroupSnapshots: true,
+	})
+	getEvents()
+
+	client, err := kubernetes.NewForConfig(apiServer.Config())
+	require.NoError(t, err, "Error creating client")
+	storageClassName := mockDriver.GetStorageClassName()
+	for _, name := range []string{"mysql-data", "mysql-logs"} {
+		volumeName := name + "-volume"
+		require.NoError(t, mockDriver.ProvisionVolume(volumeName, []int{0}, 1), "Error provisioning volume")
+		_, err = client.CoreV1().PersistentVolumes().Create(&v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: volumeName,
+			},
+			Spec: v1.PersistentVolumeSpec{
+				ClaimRef: &v1.ObjectReference{
+					Name:      name,
+					Namespace: testNamespace,
+				},
+			},
+		})
+		require.NoError(t, err, "Error creating PV")
+		_, err = client.CoreV1().PersistentVolumeClaims(testNamespace).Create(&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app": "mysql"},
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: &storageClassName,
+				VolumeName:       volumeName,
+			},
+			Status: v1.PersistentVolumeClaimStatus{
+				Phase: v1.ClaimBound,
+			},
+		})
+		require.NoError(t, err, "Error creating PVC")
+	}
+}
+
+// getEvents Returns the events recorded since the last call
+func getEvents() []string {
+	events := make([]string, 0)
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func requireEvent(t *testing.T, events []string, substring string) {
+	for _, event := range events {
+		if strings.Contains(event, substring) {
+			return
+		}
+	}
+	require.Fail(t, "Event not found", "Expected event containing %q in %v", substring, events)
+}
+
+func createGroupSnapshot(t *testing.T, name string, maxRetries int) {
+	_, err := k8s.Instance().CreateGroupSnapshot(&stork_api.GroupVolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+		Spec: stork_api.GroupVolumeSnapshotSpec{
+			PVCSelector: stork_api.PVCSelectorSpec{
+				LabelSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "mysql"},
+				},
+			},
+			MaxRetries: maxRetries,
+		},
+	})
+	require.NoError(t, err, "Error creating group snapshot")
+}
+
+// handleGroupSnapshot Runs the controller for the latest version of the
+// group snapshot, like the operator-sdk would, and returns the updated
+// group snapshot
+func handleGroupSnapshot(t *testing.T, name string, deleted bool) *stork_api.GroupVolumeSnapshot {
+	groupSnapshot, err := k8s.Instance().GetGroupSnapshot(name, testNamespace)
+	require.NoError(t, err, "Error getting group snapshot")
+	SetKind(groupSnapshot)
+	err = groupSnapshotController.Handle(context.TODO(), sdk.Event{Object: groupSnapshot, Deleted: deleted})
+	require.NoError(t, err, "Error handling group snapshot")
+	if deleted {
+		return nil
+	}
+	groupSnapshot, err = k8s.Instance().GetGroupSnapshot(name, testNamespace)
+	require.NoError(t, err, "Error getting group snapshot")
+	return groupSnapshot
+}
+
+func requireStage(
+	t *testing.T,
+	groupSnapshot *stork_api.GroupVolumeSnapshot,
+	stage stork_api.GroupVolumeSnapshotStageType,
+	status stork_api.GroupVolumeSnapshotStatusType,
+) {
+	require.Equal(t, stage, groupSnapshot.Status.Stage, "Unexpected stage")
+	require.Equal(t, status, groupSnapshot.Status.Status, "Unexpected status")
+}
+
+func groupSnapshotTest(t *testing.T) {
+	resetTest(t)
+	mockDriver.SetGroupSnapshotSteps(2)
+	createGroupSnapshot(t, "mysql-snapshot", 0)
+
+	groupSnapshot := handleGroupSnapshot(t, "mysql-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageSnapshot, stork_api.GroupSnapshotInProgress)
+
+	// Start the snapshots
+	groupSnapshot = handleGroupSnapshot(t, "mysql-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageSnapshot, stork_api.GroupSnapshotInProgress)
+	require.Len(t, groupSnapshot.Status.VolumeSnapshots, 2, "Unexpected number of snapshots")
+	for _, snapshot := range groupSnapshot.Status.VolumeSnapshots {
+		require.Equal(t, crdv1.VolumeSnapshotConditionPending, snapshot.Conditions[0].Type, "Snapshot should be pending")
+	}
+
+	// First status check, still in progress
+	groupSnapshot = handleGroupSnapshot(t, "mysql-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageSnapshot, stork_api.GroupSnapshotInProgress)
+	for _, snapshot := range groupSnapshot.Status.VolumeSnapshots {
+		require.Equal(t, "Snapshot in progress: 50%", snapshot.Conditions[0].Message, "Unexpected progress")
+	}
+
+	// Snapshots are done, the VolumeSnapshots should be created
+	groupSnapshot = handleGroupSnapshot(t, "mysql-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStagePostSnapshot, stork_api.GroupSnapshotInProgress)
+	for _, snapshot := range groupSnapshot.Status.VolumeSnapshots {
+		require.Equal(t, crdv1.VolumeSnapshotConditionReady, snapshot.Conditions[0].Type, "Snapshot should be ready")
+		volumeSnapshot, err := k8s.Instance().GetSnapshot(snapshot.VolumeSnapshotName, testNamespace)
+		require.NoError(t, err, "Error getting VolumeSnapshot %v", snapshot.VolumeSnapshotName)
+		require.True(t, strings.HasPrefix(snapshot.ParentVolumeID, volumeSnapshot.Spec.PersistentVolumeClaimName),
+			"VolumeSnapshot should be for the PVC of volume %v", snapshot.ParentVolumeID)
+		snapshotData, err := k8s.Instance().GetSnapshotData(volumeSnapshot.Spec.SnapshotDataName)
+		require.NoError(t, err, "Error getting VolumeSnapshotData %v", volumeSnapshot.Spec.SnapshotDataName)
+		require.Equal(t, snapshot.TaskID, snapshotData.Spec.HostPath.Path, "Unexpected data source")
+	}
+
+	groupSnapshot = handleGroupSnapshot(t, "mysql-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageFinal, stork_api.GroupSnapshotSuccessful)
+
+	// Updating the restore namespaces should update the VolumeSnapshots
+	groupSnapshot.Spec.RestoreNamespaces = []string{"restore"}
+	_, err := k8s.Instance().UpdateGroupSnapshot(groupSnapshot)
+	require.NoError(t, err, "Error updating group snapshot")
+	handleGroupSnapshot(t, "mysql-snapshot", false)
+	for _, snapshot := range groupSnapshot.Status.VolumeSnapshots {
+		volumeSnapshot, err := k8s.Instance().GetSnapshot(snapshot.VolumeSnapshotName, testNamespace)
+		require.NoError(t, err, "Error getting VolumeSnapshot %v", snapshot.VolumeSnapshotName)
+		require.Equal(t, "restore", volumeSnapshot.Metadata.Annotations[snapshotcontrollers.StorkSnapshotRestoreNamespacesAnnotation],
+			"Restore namespaces should have been updated")
+	}
+
+	require.True(t, mockDriver.HasGroupSnapshot(groupSnapshot), "Driver should have the group snapshot")
+	handleGroupSnapshot(t, "mysql-snapshot", true)
+	require.False(t, mockDriver.HasGroupSnapshot(groupSnapshot), "Group snapshot should have been deleted from the driver")
+}
+
+func groupSnapshotRetryTest(t *testing.T) {
+	resetTest(t)
+	mockDriver.SetGroupSnapshotFailure("mysql-logs-volume", 1)
+	createGroupSnapshot(t, "retry-snapshot", 1)
+
+	handleGroupSnapshot(t, "retry-snapshot", false)
+	handleGroupSnapshot(t, "retry-snapshot", false)
+
+	// The failed snapshot should reset the group snapshot for a retry
+	groupSnapshot := handleGroupSnapshot(t, "retry-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageSnapshot, stork_api.GroupSnapshotPending)
+	require.Equal(t, 1, groupSnapshot.Status.NumRetries, "Unexpected number of retries")
+	require.Len(t, groupSnapshot.Status.VolumeSnapshots, 0, "Snapshots should have been reset")
+	requireEvent(t, getEvents(), "Resetting group snapshot for retry: 1")
+
+	// The retry should succeed
+	handleGroupSnapshot(t, "retry-snapshot", false)
+	groupSnapshot = handleGroupSnapshot(t, "retry-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStagePostSnapshot, stork_api.GroupSnapshotInProgress)
+	groupSnapshot = handleGroupSnapshot(t, "retry-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageFinal, stork_api.GroupSnapshotSuccessful)
+}
+
+func groupSnapshotFailureTest(t *testing.T) {
+	resetTest(t)
+	mockDriver.SetGroupSnapshotFailure("mysql-data-volume", 1)
+	createGroupSnapshot(t, "failed-snapshot", 0)
+
+	handleGroupSnapshot(t, "failed-snapshot", false)
+	handleGroupSnapshot(t, "failed-snapshot", false)
+	groupSnapshot := handleGroupSnapshot(t, "failed-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStagePostSnapshot, stork_api.GroupSnapshotFailed)
+	for _, snapshot := range groupSnapshot.Status.VolumeSnapshots {
+		expectedCondition := crdv1.VolumeSnapshotConditionReady
+		if snapshot.ParentVolumeID == "mysql-data-volume" {
+			expectedCondition = crdv1.VolumeSnapshotConditionError
+		}
+		require.Equal(t, expectedCondition, snapshot.Conditions[0].Type,
+			"Unexpected condition for volume %v", snapshot.ParentVolumeID)
+	}
+	requireEvent(t, getEvents(), "Failing the groupsnapshot as retries are not enabled")
+
+	groupSnapshot = handleGroupSnapshot(t, "failed-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageFinal, stork_api.GroupSnapshotFailed)
+	snapshots, err := k8s.Instance().ListSnapshots(testNamespace)
+	require.NoError(t, err, "Error listing VolumeSnapshots")
+	require.Len(t, snapshots.Items, 0, "VolumeSnapshots shouldn't be created for failed group snapshots")
+}
+
+func groupSnapshotPendingPVCTest(t *testing.T) {
+	resetTest(t)
+	pvc, err := k8s.Instance().GetPersistentVolumeClaim("mysql-logs", testNamespace)
+	require.NoError(t, err, "Error getting PVC")
+	pvc.Status.Phase = v1.ClaimPending
+	_, err = k8s.Instance().UpdatePersistentVolumeClaim(pvc)
+	require.NoError(t, err, "Error updating PVC")
+	createGroupSnapshot(t, "pending-snapshot", 0)
+
+	groupSnapshot := handleGroupSnapshot(t, "pending-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStagePreChecks, stork_api.GroupSnapshotPending)
+	groupSnapshot = handleGroupSnapshot(t, "pending-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStagePreChecks, stork_api.GroupSnapshotPending)
+	requireEvent(t, getEvents(), "Group snapshot will trigger after all PVCs are bound")
+
+	pvc.Status.Phase = v1.ClaimBound
+	_, err = k8s.Instance().UpdatePersistentVolumeClaim(pvc)
+	require.NoError(t, err, "Error updating PVC")
+	groupSnapshot = handleGroupSnapshot(t, "pending-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageSnapshot, stork_api.GroupSnapshotInProgress)
+}
+
+func groupSnapshotNotSupportedTest(t *testing.T) {
+	resetTest(t)
+	mockDriver.SetCapabilities(&storkvolume.Capabilities{Snapshots: true})
+	createGroupSnapshot(t, "unsupported-snapshot", 0)
+
+	groupSnapshot := handleGroupSnapshot(t, "unsupported-snapshot", false)
+	requireStage(t, groupSnapshot, stork_api.GroupSnapshotStageFinal, stork_api.GroupSnapshotFailed)
+	requireEvent(t, getEvents(), "group snapshots are not supported by driver MockDriver")
+}
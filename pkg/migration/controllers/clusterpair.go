@@ -73,6 +73,19 @@ func (c *ClusterPairController) Handle(ctx context.Context, event sdk.Event) err
 			if err != nil {
 				return err
 			}
+		} else if !c.Driver.Capabilities().ClusterPair {
+			if clusterPair.Status.StorageStatus != stork_api.ClusterPairStatusError {
+				clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusError
+				c.Recorder.Event(clusterPair,
+					v1.EventTypeWarning,
+					string(clusterPair.Status.StorageStatus),
+					fmt.Sprintf("Storage pairing is not supported by driver %v, "+
+						"remove the storage options to only pair the scheduler", c.Driver.String()))
+				err := sdk.Update(clusterPair)
+				if err != nil {
+					return err
+				}
+			}
 		} else {
 			if clusterPair.Status.StorageStatus != stork_api.ClusterPairStatusReady {
 				remoteID, err := c.Driver.CreatePair(clusterPair)
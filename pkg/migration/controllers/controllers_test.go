@@ -0,0 +1,1247 @@
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heptio/ark/pkg/discovery"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
+	"github.com/libopenstorage/stork/pkg/fakeapiserver"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	"github.com/operator-framework/operator-sdk/pkg/util/k8sutil"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	mockDriverName  = "MockDriver"
+	testNamespace   = "migrationtest"
+	clusterPairName = "remotecluster"
+	numEvents       = 100
+)
+
+// mysqlClusterResource Custom resource served by both clusters in the tests.
+// Its CRD is only created on the local cluster
+var mysqlClusterResource = fakeapiserver.Resource{
+	Group:      "example.com",
+	Version:    "v1",
+	Kind:       "MySQLCluster",
+	Name:       "mysqlclusters",
+	Namespaced: true,
+}
+
+var localServer *fakeapiserver.Server
+var remoteServer *fakeapiserver.Server
+var kubeConfigFile string
+var mockDriver *mock.Driver
+var recorder *record.FakeRecorder
+var clusterPairController *ClusterPairController
+var migrationController *MigrationController
+var stopBinder chan struct{}
+
+func TestMigrationControllers(t *testing.T) {
+	t.Run("setup", setup)
+	t.Run("clusterPairTest", clusterPairTest)
+	t.Run("clusterPairErrorTest", clusterPairErrorTest)
+	t.Run("clusterPairNotSupportedTest", clusterPairNotSupportedTest)
+	t.Run("migrationTest", migrationTest)
+	t.Run("migrationVolumeFailureTest", migrationVolumeFailureTest)
+	t.Run("migrationCancelTest", migrationCancelTest)
+	t.Run("migrationNotSupportedTest", migrationNotSupportedTest)
+	t.Run("migrationCustomResourceTest", migrationCustomResourceTest)
+	t.Run("migrationResourceTypesTest", migrationResourceTypesTest)
+	t.Run("migrationOrderTest", migrationOrderTest)
+	t.Run("migrationPVCBindTimeoutTest", migrationPVCBindTimeoutTest)
+	t.Run("migrationApplyStrategyTest", migrationApplyStrategyTest)
+	t.Run("migrationMergeLargeResourceTest", migrationMergeLargeResourceTest)
+	t.Run("migrationTransformationTest", migrationTransformationTest)
+	t.Run("migrationNamespaceMappingTest", migrationNamespaceMappingTest)
+	t.Run("migrationNamespaceMappingCompositeDriverTest", migrationNamespaceMappingCompositeDriverTest)
+	t.Run("teardown", teardown)
+}
+
+// Start fake API servers for the local and remote clusters and create the
+// controllers with the mock driver. The operator-sdk only reads its config
+// from a kubeconfig file, so one is written for the local server
+func setup(t *testing.T) {
+	resources := append([]fakeapiserver.Resource{mysqlClusterResource}, fakeapiserver.DefaultResources...)
+	localServer = fakeapiserver.NewWithResources(resources)
+	remoteServer = fakeapiserver.NewWithResources(resources)
+
+	file, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err, "Error creating kubeconfig file")
+	require.NoError(t, file.Close(), "Error closing kubeconfig file")
+	kubeConfigFile = file.Name()
+	require.NoError(t, localServer.WriteKubeConfig("local", kubeConfigFile), "Error writing kubeconfig")
+	require.NoError(t, os.Setenv(k8sutil.KubeConfigEnvVar, kubeConfigFile), "Error setting kubeconfig")
+	k8s.Instance().SetConfig(localServer.Config())
+
+	d, err := storkvolume.Get(mockDriverName)
+	require.NoError(t, err, "Error getting mock driver")
+	var ok bool
+	mockDriver, ok = d.(*mock.Driver)
+	require.True(t, ok, "Error casting mock driver")
+
+	recorder = record.NewFakeRecorder(numEvents)
+	clusterPairController = &ClusterPairController{
+		Driver:   mockDriver,
+		Recorder: recorder,
+	}
+
+	client, err := kubernetes.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating client")
+	discoveryHelper, err := discovery.NewHelper(client.Discovery(), logrus.New())
+	require.NoError(t, err, "Error creating discovery helper")
+	dynamicInterface, err := dynamic.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating dynamic client")
+	storkClient, err := storkclient.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating stork client")
+	migrationController = &MigrationController{
+		Driver:           mockDriver,
+		Recorder:         recorder,
+		discoveryHelper:  discoveryHelper,
+		dynamicInterface: dynamicInterface,
+		storkClient:      storkClient,
+	}
+
+	pvcBindTimeout = 2 * time.Second
+	remoteClient, err := kubernetes.NewForConfig(remoteServer.Config())
+	require.NoError(t, err, "Error creating remote client")
+	stopBinder = make(chan struct{})
+	go wait.Until(func() { bindPVCs(remoteClient) }, 50*time.Millisecond, stopBinder)
+}
+
+// bindPVCs Binds the PVCs in the remote cluster whose volume exists, like the
+// PV controller would
+func bindPVCs(client kubernetes.Interface) {
+	pvcs, err := client.CoreV1().PersistentVolumeClaims("").List(metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, pvc := range pvcs.Items {
+		if pvc.Status.Phase == v1.ClaimBound || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		if _, err := client.CoreV1().PersistentVolumes().Get(pvc.Spec.VolumeName, metav1.GetOptions{}); err != nil {
+			continue
+		}
+		pvc.Status.Phase = v1.ClaimBound
+		_, _ = client.CoreV1().PersistentVolumeClaims(pvc.Namespace).UpdateStatus(&pvc)
+	}
+}
+
+func teardown(t *testing.T) {
+	close(stopBinder)
+	localServer.Close()
+	remoteServer.Close()
+	require.NoError(t, os.Remove(kubeConfigFile), "Error removing kubeconfig file")
+}
+
+// resetTest Clears both clusters and the mock driver and enables pairing and
+// migration in the mock driver
+func resetTest(t *testing.T) {
+	localServer.Reset()
+	remoteServer.Reset()
+	require.NoError(t, mockDriver.CreateCluster(3, &v1.NodeList{}), "Error creating cluster")
+	mockDriver.SetCapabilities(&storkvolume.Capabilities{
+		ClusterPair: true,
+		Migration:   true,
+	})
+	getEvents()
+}
+
+// getEvents Returns the events recorded since the last call
+func getEvents() []string {
+	events := make([]string, 0)
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func requireEvent(t *testing.T, events []string, substring string) {
+	for _, event := range events {
+		if strings.Contains(event, substring) {
+			return
+		}
+	}
+	require.Fail(t, "Event not found", "Expected event containing %q in %v", substring, events)
+}
+
+func createClusterPair(t *testing.T, options map[string]string) *stork_api.ClusterPair {
+	clusterPair, err := k8s.Instance().CreateClusterPair(&stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterPairName,
+			Namespace: testNamespace,
+		},
+		Spec: stork_api.ClusterPairSpec{
+			Config:  remoteServer.KubeConfig("remote"),
+			Options: options,
+		},
+	})
+	require.NoError(t, err, "Error creating cluster pair")
+	return clusterPair
+}
+
+// handleClusterPair Runs the controller for the latest version of the
+// cluster pair, like the operator-sdk would, and returns the updated pair
+func handleClusterPair(t *testing.T, deleted bool) *stork_api.ClusterPair {
+	clusterPair, err := k8s.Instance().GetClusterPair(clusterPairName, testNamespace)
+	require.NoError(t, err, "Error getting cluster pair")
+	clusterPair.Kind = "ClusterPair"
+	clusterPair.APIVersion = stork_api.SchemeGroupVersion.String()
+	err = clusterPairController.Handle(context.TODO(), sdk.Event{Object: clusterPair, Deleted: deleted})
+	require.NoError(t, err, "Error handling cluster pair")
+	clusterPair, err = k8s.Instance().GetClusterPair(clusterPairName, testNamespace)
+	require.NoError(t, err, "Error getting cluster pair")
+	return clusterPair
+}
+
+func pairClusters(t *testing.T) {
+	createClusterPair(t, map[string]string{"ip": "192.168.1.1"})
+	clusterPair := handleClusterPair(t, false)
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.StorageStatus, "Storage should be paired")
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.SchedulerStatus, "Scheduler should be paired")
+}
+
+func clusterPairTest(t *testing.T) {
+	resetTest(t)
+	pairClusters(t)
+	clusterPair, err := k8s.Instance().GetClusterPair(clusterPairName, testNamespace)
+	require.NoError(t, err, "Error getting cluster pair")
+	require.Equal(t, "mock-remote-"+clusterPairName, clusterPair.Status.RemoteStorageID, "Unexpected remote storage ID")
+	require.Len(t, mockDriver.GetClusterPairs(), 1, "Driver should have one pair")
+	events := getEvents()
+	requireEvent(t, events, "Storage successfully paired")
+	requireEvent(t, events, "Scheduler successfully paired")
+
+	handleClusterPair(t, true)
+	require.Len(t, mockDriver.GetClusterPairs(), 0, "Pair should have been deleted from the driver")
+
+	// Pairing only the scheduler shouldn't call the driver
+	localServer.Reset()
+	createClusterPair(t, nil)
+	clusterPair = handleClusterPair(t, false)
+	require.Equal(t, stork_api.ClusterPairStatusNotProvided, clusterPair.Status.StorageStatus, "Unexpected storage status")
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.SchedulerStatus, "Scheduler should be paired")
+	require.Len(t, mockDriver.GetClusterPairs(), 0, "Driver shouldn't have any pairs")
+}
+
+func clusterPairErrorTest(t *testing.T) {
+	resetTest(t)
+	mockDriver.SetClusterPairError(&storkErrorForTest{"remote cluster unreachable"})
+	createClusterPair(t, map[string]string{"ip": "192.168.1.1"})
+	clusterPair := handleClusterPair(t, false)
+	require.Equal(t, stork_api.ClusterPairStatusError, clusterPair.Status.StorageStatus, "Storage pairing should have failed")
+	requireEvent(t, getEvents(), "remote cluster unreachable")
+
+	// The pairing should be retried once the driver recovers
+	mockDriver.SetClusterPairError(nil)
+	clusterPair = handleClusterPair(t, false)
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.StorageStatus, "Storage should be paired")
+}
+
+func clusterPairNotSupportedTest(t *testing.T) {
+	resetTest(t)
+	mockDriver.SetCapabilities(&storkvolume.Capabilities{})
+	createClusterPair(t, map[string]string{"ip": "192.168.1.1"})
+	clusterPair := handleClusterPair(t, false)
+	require.Equal(t, stork_api.ClusterPairStatusError, clusterPair.Status.StorageStatus, "Storage pairing should have failed")
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.SchedulerStatus, "Scheduler should be paired")
+	requireEvent(t, getEvents(), "Storage pairing is not supported by driver MockDriver")
+	require.Len(t, mockDriver.GetClusterPairs(), 0, "Driver shouldn't have any pairs")
+}
+
+// storkErrorForTest Error injected into the mock driver
+type storkErrorForTest struct {
+	message string
+}
+
+func (e *storkErrorForTest) Error() string {
+	return e.message
+}
+
+// createApplication Creates a namespace with a deployment using a PVC
+// owned by the mock driver, along with a config map, secret and service
+func createApplication(t *testing.T) {
+	client, err := kubernetes.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating client")
+
+	_, err = client.CoreV1().Namespaces().Create(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNamespace,
+			Labels: map[string]string{"app": "mysql"},
+		},
+	})
+	require.NoError(t, err, "Error creating namespace")
+
+	storageClassName := mockDriver.GetStorageClassName()
+	_, err = client.CoreV1().PersistentVolumes().Create(&v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mysql-volume",
+		},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity: v1.ResourceList{
+				v1.ResourceStorage: resource.MustParse("1Gi"),
+			},
+			ClaimRef: &v1.ObjectReference{
+				Name:      "mysql-data",
+				Namespace: testNamespace,
+				UID:       "mysql-data-uid",
+			},
+			StorageClassName: storageClassName,
+		},
+		Status: v1.PersistentVolumeStatus{
+			Phase: v1.VolumeBound,
+		},
+	})
+	require.NoError(t, err, "Error creating PV")
+
+	_, err = client.CoreV1().PersistentVolumeClaims(testNamespace).Create(&v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "mysql-data",
+			Labels: map[string]string{"app": "mysql"},
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClassName,
+			VolumeName:       "mysql-volume",
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Phase: v1.ClaimBound,
+		},
+	})
+	require.NoError(t, err, "Error creating PVC")
+
+	replicas := int32(2)
+	_, err = client.AppsV1().Deployments(testNamespace).Create(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mysql",
+			Labels:      map[string]string{"app": "mysql"},
+			Annotations: map[string]string{"owner": "test"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": "mysql"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "mysql",
+							Image: "mysql",
+						},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating deployment")
+
+	_, err = client.CoreV1().ConfigMaps(testNamespace).Create(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mysql-config",
+		},
+		Data: map[string]string{"key": "value"},
+	})
+	require.NoError(t, err, "Error creating config map")
+
+	_, err = client.CoreV1().Secrets(testNamespace).Create(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mysql-password",
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{"password": []byte("password")},
+	})
+	require.NoError(t, err, "Error creating secret")
+
+	_, err = client.CoreV1().Services(testNamespace).Create(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mysql",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "10.0.0.10",
+			Ports: []v1.ServicePort{
+				{
+					Port: 3306,
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating service")
+}
+
+func createMigration(t *testing.T, name string) *stork_api.Migration {
+	migration, err := k8s.Instance().CreateMigration(&stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair: clusterPairName,
+			Namespaces:  []string{testNamespace},
+		},
+	})
+	require.NoError(t, err, "Error creating migration")
+	return migration
+}
+
+// handleMigration Runs the controller for the latest version of the
+// migration, like the operator-sdk would, and returns the updated migration
+func handleMigration(t *testing.T, name string, deleted bool) *stork_api.Migration {
+	migration, err := k8s.Instance().GetMigration(name, testNamespace)
+	require.NoError(t, err, "Error getting migration")
+	setKind(migration)
+	err = migrationController.Handle(context.TODO(), sdk.Event{Object: migration, Deleted: deleted})
+	require.NoError(t, err, "Error handling migration")
+	migration, err = k8s.Instance().GetMigration(name, testNamespace)
+	require.NoError(t, err, "Error getting migration")
+	return migration
+}
+
+// handleMigrationUntilFinal Handles the migration until it reaches the final
+// stage, like the operator-sdk would on each resync, and returns the updated
+// migration
+func handleMigrationUntilFinal(t *testing.T, name string) *stork_api.Migration {
+	var migration *stork_api.Migration
+	err := wait.PollImmediate(100*time.Millisecond, pvcBindTimeout+5*time.Second, func() (bool, error) {
+		migration = handleMigration(t, name, false)
+		return migration.Status.Stage == stork_api.MigrationStageFinal, nil
+	})
+	require.NoError(t, err, "Migration didn't reach the final stage")
+	return migration
+}
+
+func getRemoteObject(t *testing.T, gvr schema.GroupVersionResource, namespace string, name string) map[string]interface{} {
+	object, err := remoteServer.GetObject(gvr, namespace, name)
+	require.NoError(t, err, "Error getting %v %v from remote cluster", gvr.Resource, name)
+	return object.Object
+}
+
+func migrationTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+	mockDriver.SetMigrationSteps(3)
+	createMigration(t, "mysql-migration")
+
+	migration := handleMigration(t, "mysql-migration", false)
+	require.Equal(t, stork_api.MigrationStageVolumes, migration.Status.Stage, "Unexpected stage")
+	require.Equal(t, stork_api.MigrationStatusInProgress, migration.Status.Status, "Unexpected status")
+	require.Len(t, migration.Status.Volumes, 1, "Unexpected number of volumes")
+	require.Equal(t, "mysql-data", migration.Status.Volumes[0].PersistentVolumeClaim, "Unexpected PVC")
+	require.Equal(t, "mysql-volume", migration.Status.Volumes[0].Volume, "Unexpected volume")
+	require.Equal(t, stork_api.MigrationStatusInProgress, migration.Status.Volumes[0].Status, "Unexpected volume status")
+	require.Equal(t, "Volume migration in progress: 33%", migration.Status.Volumes[0].Reason, "Unexpected progress")
+
+	migration = handleMigration(t, "mysql-migration", false)
+	require.Equal(t, "Volume migration in progress: 66%", migration.Status.Volumes[0].Reason, "Unexpected progress")
+
+	// The workloads are applied once the PVCs are bound on the remote
+	// cluster
+	migration = handleMigration(t, "mysql-migration", false)
+	require.Equal(t, stork_api.MigrationStageApplications, migration.Status.Stage, "Unexpected stage")
+	migration = handleMigrationUntilFinal(t, "mysql-migration")
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Volumes[0].Status, "Unexpected volume status")
+	requireEvent(t, getEvents(), "Volume mysql-volume migrated successfully")
+
+	kinds := make(map[string]bool)
+	for _, resource := range migration.Status.Resources {
+		require.Equal(t, stork_api.MigrationStatusSuccessful, resource.Status,
+			"Unexpected status for %v %v: %v", resource.Kind, resource.Name, resource.Reason)
+		kinds[resource.Kind] = true
+	}
+	for _, kind := range []string{"PersistentVolume", "PersistentVolumeClaim", "Deployment", "ConfigMap", "Secret", "Service"} {
+		require.True(t, kinds[kind], "%v should have been migrated", kind)
+	}
+
+	// Check the resources in the remote cluster
+	namespace := getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, "", testNamespace)
+	require.Equal(t, map[string]interface{}{"app": "mysql"}, namespace["metadata"].(map[string]interface{})["labels"],
+		"Namespace labels should have been migrated")
+
+	deployment := getRemoteObject(t, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		testNamespace, "mysql")
+	require.Equal(t, float64(0), deployment["spec"].(map[string]interface{})["replicas"],
+		"Deployment shouldn't be started on the remote cluster")
+	require.Equal(t, "2", deployment["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})[StorkMigrationReplicasAnnotation],
+		"Replicas should be saved in an annotation")
+
+	service := getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "services"}, testNamespace, "mysql")
+	_, ok := service["spec"].(map[string]interface{})["clusterIP"]
+	require.False(t, ok, "Cluster IP shouldn't be migrated")
+
+	pv := getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}, "", "mysql-volume")
+	claimRef, ok := pv["spec"].(map[string]interface{})["claimRef"].(map[string]interface{})
+	require.True(t, ok, "Claim ref should be migrated")
+	require.Equal(t, map[string]interface{}{"name": "mysql-data", "namespace": testNamespace}, claimRef,
+		"Claim ref should only refer to the PVC by name")
+	_, ok = pv["status"]
+	require.False(t, ok, "Status shouldn't be migrated")
+
+	getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, testNamespace, "mysql-data")
+	getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, testNamespace, "mysql-config")
+	getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, testNamespace, "mysql-password")
+
+	// Nothing should change for a migration in the final stage
+	migration = handleMigration(t, "mysql-migration", false)
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+}
+
+func migrationVolumeFailureTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+	mockDriver.SetMigrationFailure("mysql-volume", "remote pool is full")
+	createMigration(t, "failed-migration")
+
+	migration := handleMigration(t, "failed-migration", false)
+	require.Equal(t, stork_api.MigrationStageFinal, migration.Status.Stage, "Unexpected stage")
+	require.Equal(t, stork_api.MigrationStatusFailed, migration.Status.Status, "Unexpected status")
+	require.Equal(t, stork_api.MigrationStatusFailed, migration.Status.Volumes[0].Status, "Unexpected volume status")
+	requireEvent(t, getEvents(), "Error migrating volume mysql-volume: Migration failed for volume: remote pool is full")
+	require.Len(t, remoteServer.ListObjects(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, ""), 0,
+		"Resources shouldn't be migrated if the volumes failed")
+}
+
+func migrationCancelTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+	mockDriver.SetMigrationSteps(5)
+	createMigration(t, "cancelled-migration")
+
+	migration := handleMigration(t, "cancelled-migration", false)
+	require.Equal(t, stork_api.MigrationStatusInProgress, migration.Status.Status, "Unexpected status")
+	handleMigration(t, "cancelled-migration", true)
+	require.True(t, mockDriver.IsMigrationCancelled(migration), "Migration should have been cancelled")
+
+	// Migrations need a paired cluster
+	resetTest(t)
+	createApplication(t)
+	createClusterPair(t, nil)
+	handleClusterPair(t, false)
+	createMigration(t, "unpaired-migration")
+	migration = handleMigration(t, "unpaired-migration", false)
+	require.Nil(t, migration.Status.Volumes, "Migration shouldn't have started")
+	requireEvent(t, getEvents(), "Cluster pair storage status is not ready")
+}
+
+func migrationNotSupportedTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+	mockDriver.SetCapabilities(&storkvolume.Capabilities{ClusterPair: true})
+	createMigration(t, "unsupported-migration")
+
+	migration := handleMigration(t, "unsupported-migration", false)
+	require.Equal(t, stork_api.MigrationStageFinal, migration.Status.Stage, "Unexpected stage")
+	require.Equal(t, stork_api.MigrationStatusFailed, migration.Status.Status, "Unexpected status")
+	requireEvent(t, getEvents(), "Migration of volumes is not supported by driver MockDriver")
+
+	// Resources can still be migrated without the volumes
+	migration, err := k8s.Instance().CreateMigration(&stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "resource-migration",
+			Namespace: testNamespace,
+		},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair:    clusterPairName,
+			Namespaces:     []string{testNamespace},
+			IncludeVolumes: new(bool),
+		},
+	})
+	require.NoError(t, err, "Error creating migration")
+	migration = handleMigration(t, "resource-migration", false)
+	require.Equal(t, stork_api.MigrationStageApplications, migration.Status.Stage, "Unexpected stage")
+	migration = handleMigrationUntilFinal(t, "resource-migration")
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	getRemoteObject(t, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		testNamespace, "mysql")
+}
+
+// createResourceMigration Creates a migration of the resources in the test
+// namespace, without the volumes, and runs it until it's completed
+func createResourceMigration(t *testing.T, name string, spec stork_api.MigrationSpec) *stork_api.Migration {
+	spec.ClusterPair = clusterPairName
+	spec.Namespaces = []string{testNamespace}
+	spec.IncludeVolumes = new(bool)
+	_, err := k8s.Instance().CreateMigration(&stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+		Spec: spec,
+	})
+	require.NoError(t, err, "Error creating migration")
+	handleMigration(t, name, false)
+	return handleMigrationUntilFinal(t, name)
+}
+
+func migrationCustomResourceTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+
+	dynamicInterface, err := dynamic.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating dynamic client")
+	_, err = dynamicInterface.Resource(crdGVR).Create(&unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1beta1",
+			"kind":       "CustomResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": "mysqlclusters.example.com",
+			},
+			"spec": map[string]interface{}{
+				"group":   "example.com",
+				"version": "v1",
+				"scope":   "Namespaced",
+				"names": map[string]interface{}{
+					"kind":   "MySQLCluster",
+					"plural": "mysqlclusters",
+				},
+			},
+			"status": map[string]interface{}{
+				"acceptedNames": map[string]interface{}{
+					"kind": "MySQLCluster",
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating CRD")
+	_, err = dynamicInterface.Resource(schema.GroupVersionResource{
+		Group:    mysqlClusterResource.Group,
+		Version:  mysqlClusterResource.Version,
+		Resource: mysqlClusterResource.Name,
+	}).Namespace(testNamespace).Create(&unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "MySQLCluster",
+			"metadata": map[string]interface{}{
+				"name": "mysql-cluster",
+			},
+			"spec": map[string]interface{}{
+				"size": int64(3),
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating custom resource")
+
+	client, err := kubernetes.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating client")
+	for _, name := range []string{"default", "mysql"} {
+		_, err = client.CoreV1().ServiceAccounts(testNamespace).Create(&v1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Secrets: []v1.ObjectReference{
+				{
+					Name: name + "-token",
+				},
+			},
+		})
+		require.NoError(t, err, "Error creating service account")
+	}
+	controller := true
+	_, err = client.CoreV1().ConfigMaps(testNamespace).Create(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mysql-cluster-config",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "example.com/v1",
+					Kind:       "MySQLCluster",
+					Name:       "mysql-cluster",
+					Controller: &controller,
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating config map")
+
+	migration := createResourceMigration(t, "custom-resource-migration", stork_api.MigrationSpec{})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+
+	crd := getRemoteObject(t, crdGVR, "", "mysqlclusters.example.com")
+	_, ok := crd["status"]
+	require.False(t, ok, "CRD status shouldn't be migrated")
+	cluster := getRemoteObject(t, schema.GroupVersionResource{
+		Group:    mysqlClusterResource.Group,
+		Version:  mysqlClusterResource.Version,
+		Resource: mysqlClusterResource.Name,
+	}, testNamespace, "mysql-cluster")
+	require.Equal(t, float64(3), cluster["spec"].(map[string]interface{})["size"], "Unexpected custom resource spec")
+
+	serviceAccount := getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"},
+		testNamespace, "mysql")
+	_, ok = serviceAccount["secrets"]
+	require.False(t, ok, "Service account secrets shouldn't be migrated")
+	_, err = remoteServer.GetObject(schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"},
+		testNamespace, "default")
+	require.Error(t, err, "Default service account shouldn't be migrated")
+	_, err = remoteServer.GetObject(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		testNamespace, "mysql-cluster-config")
+	require.Error(t, err, "Objects managed by a controller shouldn't be migrated")
+	_, err = remoteServer.GetObject(schema.GroupVersionResource{Group: stork_api.SchemeGroupVersion.Group,
+		Version: stork_api.SchemeGroupVersion.Version, Resource: stork_api.MigrationResourcePlural},
+		testNamespace, "custom-resource-migration")
+	require.Error(t, err, "Migrations shouldn't be migrated")
+}
+
+func migrationResourceTypesTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+
+	migration := createResourceMigration(t, "included-migration", stork_api.MigrationSpec{
+		IncludeResourceTypes: []string{"deployments.apps", "ConfigMap"},
+		ExcludeResourceTypes: []string{"configmap"},
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	require.Len(t, remoteServer.ListObjects(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, ""), 1,
+		"Deployment should have been migrated")
+	for _, resource := range []string{"configmaps", "services", "secrets", "persistentvolumeclaims"} {
+		require.Len(t, remoteServer.ListObjects(schema.GroupVersionResource{Version: "v1", Resource: resource}, ""), 0,
+			"%v shouldn't have been migrated", resource)
+	}
+
+	migration = createResourceMigration(t, "excluded-migration", stork_api.MigrationSpec{
+		ExcludeResourceTypes: []string{"Service", "secrets.core"},
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	require.Len(t, remoteServer.ListObjects(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, ""), 1,
+		"Config map should have been migrated")
+	for _, resource := range []string{"services", "secrets"} {
+		require.Len(t, remoteServer.ListObjects(schema.GroupVersionResource{Version: "v1", Resource: resource}, ""), 0,
+			"%v shouldn't have been migrated", resource)
+	}
+}
+
+// getRemoteResourceVersion Returns the resource version of an object in the
+// remote cluster. The fake API server increments it for every change, so it
+// gives the order in which objects were created
+func getRemoteResourceVersion(t *testing.T, gvr schema.GroupVersionResource, namespace string, name string) int {
+	object := getRemoteObject(t, gvr, namespace, name)
+	resourceVersion, err := strconv.Atoi(object["metadata"].(map[string]interface{})["resourceVersion"].(string))
+	require.NoError(t, err, "Error parsing resource version")
+	return resourceVersion
+}
+
+func migrationOrderTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+	migration := createResourceMigration(t, "ordered-migration", stork_api.MigrationSpec{})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	stages := make(map[string]stork_api.MigrationResourceStageType)
+	for _, resource := range migration.Status.Resources {
+		stages[resource.Kind] = resource.Stage
+	}
+	require.Equal(t, stork_api.MigrationResourceStageConfiguration, stages["Secret"], "Unexpected stage")
+	require.Equal(t, stork_api.MigrationResourceStagePersistentVolumeClaims, stages["PersistentVolumeClaim"], "Unexpected stage")
+	require.Equal(t, stork_api.MigrationResourceStageWorkloads, stages["Deployment"], "Unexpected stage")
+
+	order := []int{
+		getRemoteResourceVersion(t, schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, testNamespace, "mysql-password"),
+		getRemoteResourceVersion(t, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}, "", "mysql-volume"),
+		getRemoteResourceVersion(t, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, testNamespace, "mysql-data"),
+		getRemoteResourceVersion(t, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, testNamespace, "mysql"),
+	}
+	for i := 1; i < len(order); i++ {
+		require.True(t, order[i-1] < order[i], "Resources weren't applied in order: %v", order)
+	}
+	pvc := getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, testNamespace, "mysql-data")
+	require.Equal(t, string(v1.ClaimBound), pvc["status"].(map[string]interface{})["phase"],
+		"PVC should have been bound before the deployment was created")
+}
+
+func migrationPVCBindTimeoutTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+
+	// The PVC isn't bound on the remote cluster without its volume
+	_, err := k8s.Instance().CreateMigration(&stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "unbound-migration",
+			Namespace: testNamespace,
+		},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair:          clusterPairName,
+			Namespaces:           []string{testNamespace},
+			IncludeVolumes:       new(bool),
+			ExcludeResourceTypes: []string{"PersistentVolume"},
+		},
+	})
+	require.NoError(t, err, "Error creating migration")
+	handleMigration(t, "unbound-migration", false)
+
+	// The controller doesn't wait for the PVCs to be bound while handling
+	// the migration, the stage is recorded and checked again on the next
+	// resync
+	migration := handleMigration(t, "unbound-migration", false)
+	require.Equal(t, stork_api.MigrationStageApplications, migration.Status.Stage, "Unexpected stage")
+	require.Equal(t, stork_api.MigrationResourceStageWorkloads, migration.Status.ResourceStage, "Unexpected resource stage")
+	_, err = remoteServer.GetObject(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		testNamespace, "mysql")
+	require.Error(t, err, "Deployment shouldn't be applied before the PVCs are bound")
+
+	migration = handleMigrationUntilFinal(t, "unbound-migration")
+	require.Equal(t, stork_api.MigrationStatusPartialSuccess, migration.Status.Status, "Unexpected status")
+	for _, resource := range migration.Status.Resources {
+		if resource.Kind == "PersistentVolumeClaim" {
+			require.Equal(t, stork_api.MigrationStatusFailed, resource.Status, "Unexpected PVC status")
+		} else {
+			require.Equal(t, stork_api.MigrationStatusSuccessful, resource.Status,
+				"Unexpected status for %v %v: %v", resource.Kind, resource.Name, resource.Reason)
+		}
+	}
+	requireEvent(t, getEvents(), "Timed out waiting for PVC to be bound on the destination cluster")
+	getRemoteObject(t, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		testNamespace, "mysql")
+}
+
+func migrationApplyStrategyTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+
+	migration := createResourceMigration(t, "merge-migration", stork_api.MigrationSpec{
+		ApplyStrategy: stork_api.MigrationApplyStrategyMerge,
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+
+	// Change the deployment and config map on both clusters
+	localClient, err := kubernetes.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating client")
+	remoteClient, err := kubernetes.NewForConfig(remoteServer.Config())
+	require.NoError(t, err, "Error creating remote client")
+	deployment, err := localClient.AppsV1().Deployments(testNamespace).Get("mysql", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting deployment")
+	deployment.Annotations["owner"] = "local"
+	_, err = localClient.AppsV1().Deployments(testNamespace).Update(deployment)
+	require.NoError(t, err, "Error updating deployment")
+	remoteDeployment, err := remoteClient.AppsV1().Deployments(testNamespace).Get("mysql", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting remote deployment")
+	remoteDeployment.Annotations["remote"] = "true"
+	_, err = remoteClient.AppsV1().Deployments(testNamespace).Update(remoteDeployment)
+	require.NoError(t, err, "Error updating remote deployment")
+
+	configMap, err := localClient.CoreV1().ConfigMaps(testNamespace).Get("mysql-config", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting config map")
+	configMap.Data["key"] = "local"
+	_, err = localClient.CoreV1().ConfigMaps(testNamespace).Update(configMap)
+	require.NoError(t, err, "Error updating config map")
+	remoteConfigMap, err := remoteClient.CoreV1().ConfigMaps(testNamespace).Get("mysql-config", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting remote config map")
+	remoteConfigMap.Data["key"] = "remote"
+	_, err = remoteClient.CoreV1().ConfigMaps(testNamespace).Update(remoteConfigMap)
+	require.NoError(t, err, "Error updating remote config map")
+
+	migration = createResourceMigration(t, "second-merge-migration", stork_api.MigrationSpec{
+		ApplyStrategy: stork_api.MigrationApplyStrategyMerge,
+	})
+	require.Equal(t, stork_api.MigrationStatusPartialSuccess, migration.Status.Status, "Unexpected status")
+	for _, resource := range migration.Status.Resources {
+		if resource.Kind == "ConfigMap" {
+			require.Equal(t, stork_api.MigrationStatusFailed, resource.Status, "Unexpected config map status")
+			require.Contains(t, resource.Reason, "Fields were changed on both clusters: data.key", "Unexpected reason")
+		} else {
+			require.Equal(t, stork_api.MigrationStatusSuccessful, resource.Status,
+				"Unexpected status for %v %v: %v", resource.Kind, resource.Name, resource.Reason)
+		}
+	}
+
+	mergedDeployment, err := remoteClient.AppsV1().Deployments(testNamespace).Get("mysql", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting remote deployment")
+	require.Equal(t, remoteDeployment.UID, mergedDeployment.UID, "Deployment shouldn't have been recreated")
+	require.Equal(t, "local", mergedDeployment.Annotations["owner"], "Changes on the source should be merged")
+	require.Equal(t, "true", mergedDeployment.Annotations["remote"], "Changes on the destination should be kept")
+	remoteConfigMap, err = remoteClient.CoreV1().ConfigMaps(testNamespace).Get("mysql-config", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting remote config map")
+	require.Equal(t, "remote", remoteConfigMap.Data["key"], "Config map with conflicts shouldn't be changed")
+
+	// Existing resources are left alone when skipping them
+	migration = createResourceMigration(t, "skip-migration", stork_api.MigrationSpec{
+		ApplyStrategy: stork_api.MigrationApplyStrategySkipIfExists,
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	requireEvent(t, getEvents(), "Resource already exists on the destination cluster")
+	remoteConfigMap, err = remoteClient.CoreV1().ConfigMaps(testNamespace).Get("mysql-config", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting remote config map")
+	require.Equal(t, "remote", remoteConfigMap.Data["key"], "Existing config map shouldn't be changed")
+
+	_, err = k8s.Instance().CreateMigration(&stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid-migration",
+			Namespace: testNamespace,
+		},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair:   clusterPairName,
+			Namespaces:    []string{testNamespace},
+			ApplyStrategy: "Replace",
+		},
+	})
+	require.NoError(t, err, "Error creating migration")
+	migration = handleMigration(t, "invalid-migration", false)
+	require.Nil(t, migration.Status.Resources, "Migration shouldn't have started")
+	requireEvent(t, getEvents(), "Invalid applyStrategy Replace")
+}
+
+func migrationMergeLargeResourceTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+
+	// The config map is too large for its configuration to be stored in an
+	// annotation
+	localClient, err := kubernetes.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating client")
+	remoteClient, err := kubernetes.NewForConfig(remoteServer.Config())
+	require.NoError(t, err, "Error creating remote client")
+	_, err = localClient.CoreV1().ConfigMaps(testNamespace).Create(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "large-config",
+			Namespace: testNamespace,
+		},
+		Data: map[string]string{
+			"large": strings.Repeat("a", maxAnnotationsSize),
+			"key":   "value",
+		},
+	})
+	require.NoError(t, err, "Error creating config map")
+
+	migration := createResourceMigration(t, "large-merge-migration", stork_api.MigrationSpec{
+		ApplyStrategy: stork_api.MigrationApplyStrategyMerge,
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	remoteConfigMap, err := remoteClient.CoreV1().ConfigMaps(testNamespace).Get("large-config", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting remote config map")
+	require.NotContains(t, remoteConfigMap.Annotations, LastAppliedConfigAnnotation,
+		"Last applied configuration shouldn't be stored for large resources")
+	require.Equal(t, "value", remoteConfigMap.Data["key"], "Unexpected config map data")
+
+	// Changes on the source should be merged without the last applied
+	// configuration, keeping the fields only set on the destination
+	configMap, err := localClient.CoreV1().ConfigMaps(testNamespace).Get("large-config", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting config map")
+	configMap.Data["key"] = "local"
+	_, err = localClient.CoreV1().ConfigMaps(testNamespace).Update(configMap)
+	require.NoError(t, err, "Error updating config map")
+	remoteConfigMap.Data["remote"] = "true"
+	_, err = remoteClient.CoreV1().ConfigMaps(testNamespace).Update(remoteConfigMap)
+	require.NoError(t, err, "Error updating remote config map")
+
+	migration = createResourceMigration(t, "second-large-merge-migration", stork_api.MigrationSpec{
+		ApplyStrategy: stork_api.MigrationApplyStrategyMerge,
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	found := false
+	for _, resource := range migration.Status.Resources {
+		if resource.Kind == "ConfigMap" && resource.Name == "large-config" {
+			require.Contains(t, resource.Reason, "without removing fields", "Unexpected reason")
+			found = true
+		}
+	}
+	require.True(t, found, "Config map not found in migrated resources")
+	mergedConfigMap, err := remoteClient.CoreV1().ConfigMaps(testNamespace).Get("large-config", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting remote config map")
+	require.Equal(t, remoteConfigMap.UID, mergedConfigMap.UID, "Config map shouldn't have been recreated")
+	require.Equal(t, "local", mergedConfigMap.Data["key"], "Changes on the source should be merged")
+	require.Equal(t, "true", mergedConfigMap.Data["remote"], "Changes on the destination should be kept")
+	require.Len(t, mergedConfigMap.Data["large"], maxAnnotationsSize, "Unexpected config map data")
+	require.NotContains(t, mergedConfigMap.Annotations, LastAppliedConfigAnnotation,
+		"Last applied configuration shouldn't be stored for large resources")
+}
+
+func createTransformation(t *testing.T, name string, rules []stork_api.TransformationRule) {
+	_, err := migrationController.storkClient.StorkV1alpha1().ResourceTransformations(testNamespace).Create(
+		&stork_api.ResourceTransformation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: testNamespace,
+			},
+			Spec: stork_api.ResourceTransformationSpec{
+				Rules: rules,
+			},
+		})
+	require.NoError(t, err, "Error creating resource transformation")
+}
+
+func migrationTransformationTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+
+	createTransformation(t, "dr-transformation", []stork_api.TransformationRule{
+		{
+			Resources: stork_api.ResourceMatcher{
+				Types: []string{"deployments.apps"},
+			},
+			Fields: []stork_api.FieldOperation{
+				{
+					Path:      "spec.template.spec.containers.image",
+					Operation: stork_api.FieldOperationReplacePrefix,
+					From:      "mysql",
+					Value:     "registry.example.com/mysql",
+				},
+				{
+					Path:      "metadata.labels[example.com/migrated]",
+					Operation: stork_api.FieldOperationSet,
+					Value:     "true",
+				},
+				{
+					Path:      "metadata.annotations[own*]",
+					Operation: stork_api.FieldOperationDelete,
+				},
+			},
+		},
+		{
+			Resources: stork_api.ResourceMatcher{
+				Types: []string{"persistentvolumeclaims"},
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "mysql"},
+				},
+			},
+			Fields: []stork_api.FieldOperation{
+				{
+					Path:      "spec.storageClassName",
+					Operation: stork_api.FieldOperationReplace,
+					From:      mockDriver.GetStorageClassName(),
+					Value:     "remote-storage",
+				},
+			},
+		},
+		{
+			Resources: stork_api.ResourceMatcher{
+				Types: []string{"Service"},
+			},
+			JSONPatch: []stork_api.JSONPatchOperation{
+				{
+					Op:    "add",
+					Path:  "/spec/type",
+					Value: &runtime.RawExtension{Raw: []byte(`"NodePort"`)},
+				},
+			},
+		},
+	})
+	migration := createResourceMigration(t, "transformed-migration", stork_api.MigrationSpec{
+		ResourceTransformation: "dr-transformation",
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+
+	deployment := getRemoteObject(t, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		testNamespace, "mysql")
+	metadata := deployment["metadata"].(map[string]interface{})
+	require.Equal(t, "true", metadata["labels"].(map[string]interface{})["example.com/migrated"], "Label should have been added")
+	_, ok := metadata["annotations"].(map[string]interface{})["owner"]
+	require.False(t, ok, "Annotation should have been deleted")
+	containers := deployment["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+	require.Equal(t, "registry.example.com/mysql", containers[0].(map[string]interface{})["image"], "Image should have been rewritten")
+
+	pvc := getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}, testNamespace, "mysql-data")
+	require.Equal(t, "remote-storage", pvc["spec"].(map[string]interface{})["storageClassName"], "Storage class should have been mapped")
+	service := getRemoteObject(t, schema.GroupVersionResource{Version: "v1", Resource: "services"}, testNamespace, "mysql")
+	require.Equal(t, "NodePort", service["spec"].(map[string]interface{})["type"], "JSON patch should have been applied")
+
+	// Resources that fail to be transformed aren't migrated
+	createTransformation(t, "rename-transformation", []stork_api.TransformationRule{
+		{
+			Resources: stork_api.ResourceMatcher{
+				Types: []string{"ConfigMap"},
+			},
+			Fields: []stork_api.FieldOperation{
+				{
+					Path:      "metadata.name",
+					Operation: stork_api.FieldOperationSet,
+					Value:     "renamed",
+				},
+			},
+		},
+	})
+	migration = createResourceMigration(t, "renamed-migration", stork_api.MigrationSpec{
+		ResourceTransformation: "rename-transformation",
+	})
+	require.Equal(t, stork_api.MigrationStatusPartialSuccess, migration.Status.Status, "Unexpected status")
+	requireEvent(t, getEvents(), "Transformations can't change the kind, name or namespace of resources")
+	_, err := remoteServer.GetObject(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, testNamespace, "renamed")
+	require.Error(t, err, "Renamed config map shouldn't be migrated")
+
+	// Invalid transformations are reported on the migration
+	createTransformation(t, "invalid-transformation", []stork_api.TransformationRule{
+		{
+			Fields: []stork_api.FieldOperation{
+				{
+					Path:      "spec.replicas",
+					Operation: "Rename",
+				},
+			},
+		},
+	})
+	_, err = k8s.Instance().CreateMigration(&stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid-transformation-migration",
+			Namespace: testNamespace,
+		},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair:            clusterPairName,
+			Namespaces:             []string{testNamespace},
+			IncludeVolumes:         new(bool),
+			ResourceTransformation: "invalid-transformation",
+		},
+	})
+	require.NoError(t, err, "Error creating migration")
+	handleMigration(t, "invalid-transformation-migration", false)
+	migration = handleMigration(t, "invalid-transformation-migration", false)
+	require.NotEqual(t, stork_api.MigrationStageFinal, migration.Status.Stage, "Unexpected stage")
+	requireEvent(t, getEvents(), "Invalid operation Rename on spec.replicas in rule 0")
+}
+
+func migrationNamespaceMappingTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+
+	client, err := kubernetes.NewForConfig(localServer.Config())
+	require.NoError(t, err, "Error creating client")
+	_, err = client.RbacV1().RoleBindings(testNamespace).Create(&rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mysql",
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      "mysql",
+				Namespace: testNamespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     "view",
+		},
+	})
+	require.NoError(t, err, "Error creating role binding")
+
+	destinationNamespace := testNamespace + "-dr"
+	migration := createResourceMigration(t, "mapped-migration", stork_api.MigrationSpec{
+		NamespaceMapping: map[string]string{testNamespace: destinationNamespace},
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+	for _, resource := range migration.Status.Resources {
+		if resource.Kind != "PersistentVolume" {
+			require.Equal(t, testNamespace, resource.Namespace, "Status should have the source namespace")
+		}
+	}
+
+	// The objects are created in the mapped namespace
+	remoteClient, err := kubernetes.NewForConfig(remoteServer.Config())
+	require.NoError(t, err, "Error creating remote client")
+	_, err = remoteClient.CoreV1().Namespaces().Get(destinationNamespace, metav1.GetOptions{})
+	require.NoError(t, err, "Mapped namespace should be created")
+	_, err = remoteClient.CoreV1().Namespaces().Get(testNamespace, metav1.GetOptions{})
+	require.Error(t, err, "Source namespace shouldn't be created")
+	_, err = remoteClient.AppsV1().Deployments(destinationNamespace).Get("mysql", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting deployment from mapped namespace")
+	pvc, err := remoteClient.CoreV1().PersistentVolumeClaims(destinationNamespace).Get("mysql-data", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting PVC from mapped namespace")
+	require.Equal(t, v1.ClaimBound, pvc.Status.Phase, "PVC should be bound")
+	pv, err := remoteClient.CoreV1().PersistentVolumes().Get("mysql-volume", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting PV")
+	require.NotNil(t, pv.Spec.ClaimRef, "PV should keep its claimRef")
+	require.Equal(t, destinationNamespace, pv.Spec.ClaimRef.Namespace, "Unexpected claimRef namespace")
+	require.Equal(t, "mysql-data", pv.Spec.ClaimRef.Name, "Unexpected claimRef name")
+	require.Empty(t, pv.Spec.ClaimRef.UID, "claimRef shouldn't have the UID of the source PVC")
+	roleBinding, err := remoteClient.RbacV1().RoleBindings(destinationNamespace).Get("mysql", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting role binding from mapped namespace")
+	require.Equal(t, destinationNamespace, roleBinding.Subjects[0].Namespace, "Unexpected subject namespace")
+
+	// Namespaces that aren't being migrated can't be mapped
+	_, err = k8s.Instance().CreateMigration(&stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid-mapping-migration",
+			Namespace: testNamespace,
+		},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair:      clusterPairName,
+			Namespaces:       []string{testNamespace},
+			IncludeVolumes:   new(bool),
+			NamespaceMapping: map[string]string{"other": destinationNamespace},
+		},
+	})
+	require.NoError(t, err, "Error creating migration")
+	migration = handleMigration(t, "invalid-mapping-migration", false)
+	require.Equal(t, stork_api.MigrationStageInitial, migration.Status.Stage, "Unexpected stage")
+	requireEvent(t, getEvents(), "Namespace other in namespaceMapping isn't being migrated")
+}
+
+// secondaryDriver Driver that doesn't own any PVCs. Used to test migrations
+// with multiple drivers
+type secondaryDriver struct {
+	*mock.Driver
+}
+
+func (s *secondaryDriver) String() string {
+	return "SecondaryDriver"
+}
+
+func (s *secondaryDriver) OwnsPVC(*v1.PersistentVolumeClaim) bool {
+	return false
+}
+
+// migrationNamespaceMappingCompositeDriverTest Checks that the driver that
+// owns a PV is found when its PVC is migrated to a different namespace
+func migrationNamespaceMappingCompositeDriverTest(t *testing.T) {
+	resetTest(t)
+	createApplication(t)
+	pairClusters(t)
+
+	migrationController.Driver = storkvolume.NewCompositeDriver(
+		[]storkvolume.Driver{&secondaryDriver{Driver: mockDriver}, mockDriver})
+	defer func() {
+		migrationController.Driver = mockDriver
+	}()
+
+	destinationNamespace := testNamespace + "-dr"
+	migration := createResourceMigration(t, "composite-mapped-migration", stork_api.MigrationSpec{
+		NamespaceMapping: map[string]string{testNamespace: destinationNamespace},
+	})
+	require.Equal(t, stork_api.MigrationStatusSuccessful, migration.Status.Status, "Unexpected status")
+
+	remoteClient, err := kubernetes.NewForConfig(remoteServer.Config())
+	require.NoError(t, err, "Error creating remote client")
+	pv, err := remoteClient.CoreV1().PersistentVolumes().Get("mysql-volume", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting PV")
+	require.NotNil(t, pv.Spec.ClaimRef, "PV should keep its claimRef")
+	require.Equal(t, destinationNamespace, pv.Spec.ClaimRef.Namespace, "Unexpected claimRef namespace")
+	pvc, err := remoteClient.CoreV1().PersistentVolumeClaims(destinationNamespace).Get("mysql-data", metav1.GetOptions{})
+	require.NoError(t, err, "Error getting PVC from mapped namespace")
+	require.Equal(t, v1.ClaimBound, pvc.Status.Phase, "PVC should be bound")
+}
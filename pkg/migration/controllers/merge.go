@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// LastAppliedConfigAnnotation Annotation with the configuration of a resource
+// when it was last migrated with the Merge apply strategy. It is used to find
+// the changes made to the resource on the source and destination clusters
+const LastAppliedConfigAnnotation = "stork.libopenstorage.org/last-applied-configuration"
+
+// maxAnnotationsSize Maximum total size of the keys and values of the
+// annotations of an object allowed by the API server
+const maxAnnotationsSize = 256 * 1024
+
+// mergeConflictError Error returned when fields of a resource were changed
+// differently on the source and destination clusters
+type mergeConflictError struct {
+	fields []string
+}
+
+func (e *mergeConflictError) Error() string {
+	return fmt.Sprintf("Fields were changed on both clusters: %v", strings.Join(e.fields, ", "))
+}
+
+// setLastAppliedConfig Stores the configuration of the object in the last
+// applied annotation of the object. Returns false without setting the
+// annotation if the annotations would then be larger than the API server
+// allows
+func setLastAppliedConfig(object *unstructured.Unstructured) (bool, error) {
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	delete(annotations, LastAppliedConfigAnnotation)
+	object.SetAnnotations(annotations)
+	config, err := json.Marshal(object.Object)
+	if err != nil {
+		return false, err
+	}
+	size := len(LastAppliedConfigAnnotation) + len(config)
+	for k, v := range annotations {
+		size += len(k) + len(v)
+	}
+	if size > maxAnnotationsSize {
+		return false, nil
+	}
+	annotations[LastAppliedConfigAnnotation] = string(config)
+	object.SetAnnotations(annotations)
+	return true, nil
+}
+
+// mergeObject Patches the object that exists on the destination cluster with
+// the object being migrated. Fields that were only set on the destination
+// cluster are kept, and fields that were removed since the last migration
+// are removed. The object isn't patched if fields were changed on both
+// clusters since the last migration.
+//
+// Server-side apply can't be used since the vendored client-go and the API
+// servers it supports predate it: there is no apply patch type and no field
+// manager to record who owns a field. Strategic merge patches aren't used
+// either since they need the Go types of the object, which aren't available
+// for the unstructured objects and custom resources being migrated. So this
+// does a client-side three-way merge like kubectl apply. Unlike kubectl, the
+// last migrated configuration is kept in its own annotation so that running
+// kubectl apply on the destination cluster doesn't change what is considered
+// to have been changed there. The patch is a JSON merge patch, so lists are
+// replaced as a whole instead of being merged by key. And fields that were
+// changed differently on both clusters are reported as a conflict and the
+// object is left as it is, since there is no way to force the change or to
+// find the manager that made the other change like with server-side apply.
+//
+// Objects that are too large to have their configuration stored in the last
+// applied annotation don't have it set by setLastAppliedConfig. For those the
+// annotation is removed from the destination object, so the next migration
+// only does a two-way merge: fields set on the source are added or updated
+// but fields removed from the source are kept, and changes made on the
+// destination cluster are overwritten instead of being reported as conflicts
+func mergeObject(dynamicClient dynamic.ResourceInterface, object *unstructured.Unstructured) error {
+	current, err := dynamicClient.Get(object.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	var lastApplied interface{}
+	modifiedConfig, ok := object.GetAnnotations()[LastAppliedConfigAnnotation]
+	if ok {
+		lastApplied = modifiedConfig
+	} else {
+		config, err := json.Marshal(object.Object)
+		if err != nil {
+			return err
+		}
+		modifiedConfig = string(config)
+	}
+	var lastAppliedConfig []byte
+	if config, ok := current.GetAnnotations()[LastAppliedConfigAnnotation]; ok {
+		lastAppliedConfig = []byte(config)
+	}
+
+	current = current.DeepCopy()
+	annotations := current.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	delete(annotations, LastAppliedConfigAnnotation)
+	current.SetAnnotations(annotations)
+	currentConfig, err := json.Marshal(current.Object)
+	if err != nil {
+		return err
+	}
+
+	patch, err := createThreeWayMergePatch(lastAppliedConfig, []byte(modifiedConfig), currentConfig)
+	if err != nil {
+		return err
+	}
+	mergeMaps(patch, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				LastAppliedConfigAnnotation: lastApplied,
+			},
+		},
+	})
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = dynamicClient.Patch(object.GetName(), types.MergePatchType, patchBytes)
+	return err
+}
+
+// createThreeWayMergePatch Returns a JSON merge patch that updates current
+// with the fields set in modified and removes the fields that were removed
+// from original. Original can be nil if the object wasn't migrated before,
+// in which case fields are only added or updated. Returns a
+// mergeConflictError if fields were changed differently from original in
+// modified and current
+func createThreeWayMergePatch(original, modified, current []byte) (map[string]interface{}, error) {
+	additions, err := createMergePatch(current, modified)
+	if err != nil {
+		return nil, err
+	}
+	additions = filterMergePatch(additions, false)
+	if original == nil {
+		return additions, nil
+	}
+
+	ours, err := createMergePatch(original, modified)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := createMergePatch(original, current)
+	if err != nil {
+		return nil, err
+	}
+	if conflicts := mergePatchConflicts(ours, theirs, ""); len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, &mergeConflictError{fields: conflicts}
+	}
+	mergeMaps(additions, filterMergePatch(ours, true))
+	return additions, nil
+}
+
+// createMergePatch Returns the JSON merge patch from original to modified
+func createMergePatch(original, modified []byte) (map[string]interface{}, error) {
+	patchBytes, err := jsonpatch.CreateMergePatch(original, modified)
+	if err != nil {
+		return nil, err
+	}
+	patch := make(map[string]interface{})
+	if err := json.Unmarshal(patchBytes, &patch); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// filterMergePatch Returns only the fields removed by the patch if deletions
+// is true, or only the fields added or updated by the patch otherwise
+func filterMergePatch(patch map[string]interface{}, deletions bool) map[string]interface{} {
+	filtered := make(map[string]interface{})
+	for key, value := range patch {
+		if valueMap, ok := value.(map[string]interface{}); ok {
+			if filteredMap := filterMergePatch(valueMap, deletions); len(filteredMap) > 0 {
+				filtered[key] = filteredMap
+			}
+			continue
+		}
+		if (value == nil) == deletions {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+// mergePatchConflicts Returns the paths of the fields that are set to
+// different values by both patches
+func mergePatchConflicts(ours, theirs map[string]interface{}, prefix string) []string {
+	conflicts := make([]string, 0)
+	for key, ourValue := range ours {
+		theirValue, ok := theirs[key]
+		if !ok {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		ourMap, ourIsMap := ourValue.(map[string]interface{})
+		theirMap, theirIsMap := theirValue.(map[string]interface{})
+		if ourIsMap && theirIsMap {
+			conflicts = append(conflicts, mergePatchConflicts(ourMap, theirMap, path)...)
+		} else if !reflect.DeepEqual(ourValue, theirValue) {
+			conflicts = append(conflicts, path)
+		}
+	}
+	return conflicts
+}
+
+// mergeMaps Recursively merges the fields from src into dst
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok {
+				mergeMaps(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
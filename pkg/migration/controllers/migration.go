@@ -13,6 +13,7 @@ import (
 	"github.com/libopenstorage/stork/drivers/volume"
 	"github.com/libopenstorage/stork/pkg/apis/stork"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
 	"github.com/libopenstorage/stork/pkg/controller"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/rule"
@@ -31,6 +32,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -45,12 +48,97 @@ const (
 	StorkMigrationReplicasAnnotation = "stork.libopenstorage.org/migrationReplicas"
 )
 
+// pvcBindTimeout Time to wait for the migrated PVCs to be bound on the
+// destination cluster before the workloads are applied. The PVCs are checked
+// each time the migration is handled
+var pvcBindTimeout = 5 * time.Minute
+
+// applyStages Stages in which resources are applied on the destination
+// cluster, in order
+var applyStages = []stork_api.MigrationResourceStageType{
+	stork_api.MigrationResourceStageAccessControl,
+	stork_api.MigrationResourceStageConfiguration,
+	stork_api.MigrationResourceStagePersistentVolumes,
+	stork_api.MigrationResourceStagePersistentVolumeClaims,
+	stork_api.MigrationResourceStageServices,
+	stork_api.MigrationResourceStageWorkloads,
+	stork_api.MigrationResourceStageOther,
+}
+
+// resourceStages Stage in which each kind is applied. Kinds that aren't
+// listed are applied in the last stage
+var resourceStages = map[string]stork_api.MigrationResourceStageType{
+	"ServiceAccount":        stork_api.MigrationResourceStageAccessControl,
+	"Role":                  stork_api.MigrationResourceStageAccessControl,
+	"RoleBinding":           stork_api.MigrationResourceStageAccessControl,
+	"Secret":                stork_api.MigrationResourceStageConfiguration,
+	"ConfigMap":             stork_api.MigrationResourceStageConfiguration,
+	"LimitRange":            stork_api.MigrationResourceStageConfiguration,
+	"ResourceQuota":         stork_api.MigrationResourceStageConfiguration,
+	"PersistentVolume":      stork_api.MigrationResourceStagePersistentVolumes,
+	"PersistentVolumeClaim": stork_api.MigrationResourceStagePersistentVolumeClaims,
+	"Service":               stork_api.MigrationResourceStageServices,
+	"Deployment":            stork_api.MigrationResourceStageWorkloads,
+	"StatefulSet":           stork_api.MigrationResourceStageWorkloads,
+	"DaemonSet":             stork_api.MigrationResourceStageWorkloads,
+	"ReplicaSet":            stork_api.MigrationResourceStageWorkloads,
+	"ReplicationController": stork_api.MigrationResourceStageWorkloads,
+	"Job":                   stork_api.MigrationResourceStageWorkloads,
+	"CronJob":               stork_api.MigrationResourceStageWorkloads,
+	"Pod":                   stork_api.MigrationResourceStageWorkloads,
+}
+
+// resourceStage Returns the stage in which objects of the kind are applied
+func resourceStage(kind string) stork_api.MigrationResourceStageType {
+	if stage, ok := resourceStages[kind]; ok {
+		return stage
+	}
+	return stork_api.MigrationResourceStageOther
+}
+
+// applyStrategyForKind Returns the strategy used to apply objects of the
+// kind if they already exist on the destination cluster. Volumes are never
+// changed since they would lose their data
+func applyStrategyForKind(
+	migration *stork_api.Migration,
+	kind string,
+) stork_api.MigrationApplyStrategyType {
+	switch kind {
+	case "PersistentVolumeClaim", "PersistentVolume":
+		return stork_api.MigrationApplyStrategySkipIfExists
+	default:
+		return migration.Spec.ApplyStrategy
+	}
+}
+
+// crdGVR Resource used to get and create CRDs on the clusters
+var crdGVR = apiextensionsv1beta1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+
+// defaultExcludedKinds Kinds that are only migrated if they are listed in
+// includeResourceTypes. They are either created by controllers on the
+// destination cluster or are only valid on the cluster they were created on
+var defaultExcludedKinds = map[string]bool{
+	"Event":                  true,
+	"Pod":                    true,
+	"ReplicaSet":             true,
+	"ControllerRevision":     true,
+	"Endpoints":              true,
+	"Lease":                  true,
+	"ClusterPair":            true,
+	"Migration":              true,
+	"MigrationSchedule":      true,
+	"GroupVolumeSnapshot":    true,
+	"VolumeSnapshotSchedule": true,
+	"VolumeSnapshot":         true,
+}
+
 // MigrationController reconciles migration objects
 type MigrationController struct {
 	Driver                  volume.Driver
 	Recorder                record.EventRecorder
 	discoveryHelper         discovery.Helper
 	dynamicInterface        dynamic.Interface
+	storkClient             storkclient.Interface
 	migrationAdminNamespace string
 }
 
@@ -84,6 +172,10 @@ func (m *MigrationController) Init(migrationAdminNamespace string) error {
 	if err != nil {
 		return err
 	}
+	m.storkClient, err = storkclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
 
 	m.migrationAdminNamespace = migrationAdminNamespace
 	if err := m.performRuleRecovery(); err != nil {
@@ -144,6 +236,9 @@ func setDefaults(migration *stork_api.Migration) *stork_api.Migration {
 		defaultBool := false
 		migration.Spec.StartApplications = &defaultBool
 	}
+	if migration.Spec.ApplyStrategy == "" {
+		migration.Spec.ApplyStrategy = stork_api.MigrationApplyStrategyRecreate
+	}
 	return migration
 }
 
@@ -153,6 +248,9 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 	case *stork_api.Migration:
 		migration := o
 		if event.Deleted {
+			if !m.Driver.Capabilities().Migration {
+				return nil
+			}
 			return m.Driver.CancelMigration(migration)
 		}
 		migration = setDefaults(migration)
@@ -167,6 +265,33 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 			return nil
 		}
 
+		switch migration.Spec.ApplyStrategy {
+		case stork_api.MigrationApplyStrategyRecreate,
+			stork_api.MigrationApplyStrategyMerge,
+			stork_api.MigrationApplyStrategySkipIfExists:
+		default:
+			err := fmt.Errorf("Invalid applyStrategy %v, should be one of %v, %v or %v",
+				migration.Spec.ApplyStrategy,
+				stork_api.MigrationApplyStrategyRecreate,
+				stork_api.MigrationApplyStrategyMerge,
+				stork_api.MigrationApplyStrategySkipIfExists)
+			log.MigrationLog(migration).Errorf(err.Error())
+			m.Recorder.Event(migration,
+				v1.EventTypeWarning,
+				string(stork_api.MigrationStatusFailed),
+				err.Error())
+			return nil
+		}
+
+		if err := validateNamespaceMapping(migration); err != nil {
+			log.MigrationLog(migration).Errorf(err.Error())
+			m.Recorder.Event(migration,
+				v1.EventTypeWarning,
+				string(stork_api.MigrationStatusFailed),
+				err.Error())
+			return nil
+		}
+
 		// Check whether namespace is allowed to be migrated before each stage
 		// Restrict migration to only the namespace that the object belongs
 		// except for the namespace designated by the admin
@@ -185,6 +310,23 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 
 		switch migration.Status.Stage {
 		case stork_api.MigrationStageInitial:
+			// Fail early if the driver can't migrate the volumes
+			if *migration.Spec.IncludeVolumes && !m.Driver.Capabilities().Migration {
+				migration.Status.Status = stork_api.MigrationStatusFailed
+				migration.Status.Stage = stork_api.MigrationStageFinal
+				err := fmt.Errorf("Migration of volumes is not supported by driver %v, "+
+					"set includeVolumes to false to only migrate resources", m.Driver.String())
+				log.MigrationLog(migration).Errorf(err.Error())
+				m.Recorder.Event(migration,
+					v1.EventTypeWarning,
+					string(stork_api.MigrationStatusFailed),
+					err.Error())
+				err = sdk.Update(migration)
+				if err != nil {
+					log.MigrationLog(migration).Errorf("Error updating")
+				}
+				return nil
+			}
 			// Make sure the namespaces exist
 			for _, ns := range migration.Spec.Namespaces {
 				_, err := k8s.Instance().GetNamespace(ns)
@@ -290,6 +432,42 @@ func (m *MigrationController) Handle(ctx context.Context, event sdk.Event) error
 	return nil
 }
 
+// validateNamespaceMapping Returns an error if the namespace mapping has
+// namespaces that aren't being migrated, or maps more than one namespace to
+// the same namespace on the destination cluster
+func validateNamespaceMapping(migration *stork_api.Migration) error {
+	migrated := make(map[string]bool)
+	for _, ns := range migration.Spec.Namespaces {
+		migrated[ns] = true
+	}
+	for source, destination := range migration.Spec.NamespaceMapping {
+		if !migrated[source] {
+			return fmt.Errorf("Namespace %v in namespaceMapping isn't being migrated", source)
+		}
+		if errs := validation.IsDNS1123Label(destination); len(errs) > 0 {
+			return fmt.Errorf("Invalid namespace %v in namespaceMapping: %v", destination, strings.Join(errs, ", "))
+		}
+	}
+	sources := make(map[string]string)
+	for _, ns := range migration.Spec.Namespaces {
+		destination := getDestinationNamespace(migration, ns)
+		if source, ok := sources[destination]; ok && source != ns {
+			return fmt.Errorf("Namespaces %v and %v can't both be migrated to namespace %v", source, ns, destination)
+		}
+		sources[destination] = ns
+	}
+	return nil
+}
+
+// getDestinationNamespace Returns the namespace on the destination cluster to
+// which objects from the namespace are migrated
+func getDestinationNamespace(migration *stork_api.Migration, namespace string) string {
+	if destination, ok := migration.Spec.NamespaceMapping[namespace]; ok {
+		return destination
+	}
+	return namespace
+}
+
 func (m *MigrationController) namespaceMigrationAllowed(migration *stork_api.Migration) bool {
 	// Restrict migration to only the namespace that the object belongs
 	// except for the namespace designated by the admin
@@ -520,25 +698,48 @@ func (m *MigrationController) runPostExecRule(migration *stork_api.Migration) er
 	return nil
 }
 
-func resourceToBeMigrated(migration *stork_api.Migration, resource metav1.APIResource) bool {
-	// Deployment is present in "apps" and "extensions" group, so ignore
-	// "extensions"
-	if resource.Group == "extensions" && resource.Kind == "Deployment" {
+// resourceToBeMigrated Returns true if objects of the resource type should be
+// migrated. Only namespaced types and PersistentVolumes are migrated
+func resourceToBeMigrated(
+	migration *stork_api.Migration,
+	groupVersion schema.GroupVersion,
+	resource metav1.APIResource,
+) bool {
+	if !resource.Namespaced && resource.Kind != "PersistentVolume" {
 		return false
 	}
-
-	switch resource.Kind {
-	case "PersistentVolumeClaim",
-		"PersistentVolume",
-		"Deployment",
-		"StatefulSet",
-		"ConfigMap",
-		"Service",
-		"Secret":
-		return true
-	default:
+	if resourceTypeMatches(migration.Spec.ExcludeResourceTypes, groupVersion, resource) {
 		return false
 	}
+	if len(migration.Spec.IncludeResourceTypes) > 0 {
+		return resourceTypeMatches(migration.Spec.IncludeResourceTypes, groupVersion, resource)
+	}
+	return !defaultExcludedKinds[resource.Kind]
+}
+
+// resourceTypeMatches Returns true if the resource is one of the types. A
+// type is the kind or plural name of the resource, optionally followed by
+// the group, and is matched case insensitively
+func resourceTypeMatches(
+	resourceTypes []string,
+	groupVersion schema.GroupVersion,
+	resource metav1.APIResource,
+) bool {
+	for _, resourceType := range resourceTypes {
+		name := resourceType
+		if i := strings.Index(resourceType, "."); i >= 0 {
+			name = resourceType[:i]
+			group := resourceType[i+1:]
+			if !strings.EqualFold(group, groupVersion.Group) &&
+				!(group == "core" && groupVersion.Group == "") {
+				continue
+			}
+		}
+		if strings.EqualFold(name, resource.Kind) || strings.EqualFold(name, resource.Name) {
+			return true
+		}
+	}
+	return false
 }
 
 func (m *MigrationController) objectToBeMigrated(
@@ -557,6 +758,12 @@ func (m *MigrationController) objectToBeMigrated(
 		return false, nil
 	}
 
+	// Objects managed by a controller, like the Jobs of a CronJob, are
+	// created by the controller on the destination cluster
+	if metav1.GetControllerOf(metadata) != nil {
+		return false, nil
+	}
+
 	objectType, err := meta.TypeAccessor(object)
 	if err != nil {
 		return false, err
@@ -639,6 +846,11 @@ func (m *MigrationController) objectToBeMigrated(
 		if secretType == string(v1.SecretTypeServiceAccountToken) {
 			return false, nil
 		}
+	case "ServiceAccount":
+		// The default service account is created in every namespace
+		if metadata.GetName() == "default" {
+			return false, nil
+		}
 	}
 
 	return true, nil
@@ -654,13 +866,29 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 		return fmt.Errorf("Scheduler Cluster pair is not ready. Status: %v", schedulerStatus)
 	}
 
-	allObjects, err := m.getResources(migration)
+	allObjects, resources, err := m.getResources(migration)
 	if err != nil {
 		log.MigrationLog(migration).Errorf("Error getting resources: %v", err)
 		return err
 	}
 
-	err = m.prepareResources(migration, allObjects)
+	var transformation *stork_api.ResourceTransformation
+	if migration.Spec.ResourceTransformation != "" {
+		transformation, err = m.storkClient.StorkV1alpha1().ResourceTransformations(migration.Namespace).Get(
+			migration.Spec.ResourceTransformation, metav1.GetOptions{})
+		if err == nil {
+			err = validateTransformation(transformation)
+		}
+		if err != nil {
+			m.Recorder.Event(migration,
+				v1.EventTypeWarning,
+				string(stork_api.MigrationStatusFailed),
+				fmt.Sprintf("Error getting resource transformation %v: %v", migration.Spec.ResourceTransformation, err))
+			return err
+		}
+	}
+
+	err = m.prepareResources(migration, allObjects, resources, transformation)
 	if err != nil {
 		m.Recorder.Event(migration,
 			v1.EventTypeWarning,
@@ -669,7 +897,7 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 		log.MigrationLog(migration).Errorf("Error preparing resources: %v", err)
 		return err
 	}
-	err = m.applyResources(migration, allObjects)
+	done, err := m.applyResources(migration, allObjects, resources)
 	if err != nil {
 		m.Recorder.Event(migration,
 			v1.EventTypeWarning,
@@ -679,6 +907,12 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 		return err
 	}
 
+	if !done {
+		// The remaining stages are applied when the migration is handled
+		// again
+		return nil
+	}
+
 	migration.Status.Stage = stork_api.MigrationStageFinal
 	migration.Status.Status = stork_api.MigrationStatusSuccessful
 	for _, resource := range migration.Status.Resources {
@@ -694,28 +928,42 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration) e
 	return nil
 }
 
+// getResources Returns the objects to be migrated and the resource types
+// of the objects keyed by their GroupVersionKind
 func (m *MigrationController) getResources(
 	migration *stork_api.Migration,
-) ([]runtime.Unstructured, error) {
+) ([]runtime.Unstructured, map[schema.GroupVersionKind]metav1.APIResource, error) {
 	err := m.discoveryHelper.Refresh()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	allObjects := make([]runtime.Unstructured, 0)
 	resourceInfos := make([]*stork_api.ResourceInfo, 0)
+	// The status of the resources that were already applied is kept when
+	// the migration is resumed
+	var previousResourceInfos []*stork_api.ResourceInfo
+	if migration.Status.ResourceStage != "" {
+		previousResourceInfos = migration.Status.Resources
+	}
+	resources := make(map[schema.GroupVersionKind]metav1.APIResource)
+	// Kinds served by the groups processed so far. The extensions group is
+	// sorted last by the discovery helper, so kinds that have moved from
+	// it to other groups, like Deployment, are only migrated once
+	kinds := make(map[string]bool)
 
 	for _, group := range m.discoveryHelper.Resources() {
 		groupVersion, err := schema.ParseGroupVersion(group.GroupVersion)
 		if err != nil {
-			return nil, err
-		}
-		if groupVersion.Group == "extensions" {
-			continue
+			return nil, nil, err
 		}
 
 		resourceMap := make(map[types.UID]bool)
 		for _, resource := range group.APIResources {
-			if !resourceToBeMigrated(migration, resource) {
+			if groupVersion.Group == "extensions" && kinds[resource.Kind] {
+				continue
+			}
+			kinds[resource.Kind] = true
+			if !resourceToBeMigrated(migration, groupVersion, resource) {
 				continue
 			}
 
@@ -737,28 +985,28 @@ func (m *MigrationController) getResources(
 					LabelSelector: selectors,
 				})
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				objects, err := meta.ExtractList(objectsList)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				for _, o := range objects {
 					runtimeObject, ok := o.(runtime.Unstructured)
 					if !ok {
-						return nil, fmt.Errorf("Error casting object: %v", o)
+						return nil, nil, fmt.Errorf("Error casting object: %v", o)
 					}
 
 					migrate, err := m.objectToBeMigrated(migration, resourceMap, runtimeObject, ns)
 					if err != nil {
-						return nil, fmt.Errorf("Error processing object %v: %v", runtimeObject, err)
+						return nil, nil, fmt.Errorf("Error processing object %v: %v", runtimeObject, err)
 					}
 					if !migrate {
 						continue
 					}
 					metadata, err := meta.Accessor(runtimeObject)
 					if err != nil {
-						return nil, err
+						return nil, nil, err
 					}
 					resourceInfo := &stork_api.ResourceInfo{
 						Name:      metadata.GetName(),
@@ -772,8 +1020,15 @@ func (m *MigrationController) getResources(
 						resourceInfo.Group = "core"
 					}
 					resourceInfo.Version = groupVersion.Version
+					resourceInfo.Stage = resourceStage(resource.Kind)
+					if previous := findResourceInfo(previousResourceInfos, resourceInfo); previous != nil {
+						resourceInfo = previous
+					}
 					resourceInfos = append(resourceInfos, resourceInfo)
 					allObjects = append(allObjects, runtimeObject)
+					resource.Group = groupVersion.Group
+					resource.Version = groupVersion.Version
+					resources[groupVersion.WithKind(resource.Kind)] = resource
 					resourceMap[metadata.GetUID()] = true
 				}
 			}
@@ -781,16 +1036,18 @@ func (m *MigrationController) getResources(
 		migration.Status.Resources = resourceInfos
 		err = sdk.Update(migration)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	return allObjects, nil
+	return allObjects, resources, nil
 }
 
 func (m *MigrationController) prepareResources(
 	migration *stork_api.Migration,
 	objects []runtime.Unstructured,
+	resources map[schema.GroupVersionKind]metav1.APIResource,
+	transformation *stork_api.ResourceTransformation,
 ) error {
 	for _, o := range objects {
 		content := o.UnstructuredContent()
@@ -831,9 +1088,23 @@ func (m *MigrationController) prepareResources(
 				continue
 			}
 			o = updatedObject
+		case "Job":
+			updatedObject, err := m.prepareJobResource(migration, o)
+			if err != nil {
+				m.updateResourceStatus(
+					migration,
+					o,
+					stork_api.MigrationStatusFailed,
+					fmt.Sprintf("Error preparing Job resource: %v", err))
+				continue
+			}
+			o = updatedObject
+		case "ServiceAccount":
+			// The token secrets aren't migrated, new ones are created for
+			// the service account on the destination cluster
+			delete(content, "secrets")
 		}
-		metadata, err := collections.GetMap(content, "metadata")
-		if err != nil {
+		if err := removeClusterMetadata(content); err != nil {
 			m.updateResourceStatus(
 				migration,
 				o,
@@ -841,49 +1112,99 @@ func (m *MigrationController) prepareResources(
 				fmt.Sprintf("Error getting metadata for resource: %v", err))
 			continue
 		}
-		for key := range metadata {
-			switch key {
-			case "name", "namespace", "labels", "annotations":
-			default:
-				delete(metadata, key)
+		if transformation != nil {
+			resource := resources[o.GetObjectKind().GroupVersionKind()]
+			if err := transformResource(transformation, o, resource); err != nil {
+				m.updateResourceStatus(
+					migration,
+					o,
+					stork_api.MigrationStatusFailed,
+					fmt.Sprintf("Error transforming resource: %v", err))
+				continue
 			}
 		}
 	}
 	return nil
 }
 
-func (m *MigrationController) updateResourceStatus(
+// removeClusterMetadata Removes the metadata that is set by the cluster for
+// an object, keeping only the name, namespace, labels and annotations
+func removeClusterMetadata(content map[string]interface{}) error {
+	metadata, err := collections.GetMap(content, "metadata")
+	if err != nil {
+		return err
+	}
+	for key := range metadata {
+		switch key {
+		case "name", "namespace", "labels", "annotations":
+		default:
+			delete(metadata, key)
+		}
+	}
+	return nil
+}
+
+// getResourceInfo Returns the status of the object in the migration, or nil
+// if it isn't being migrated
+func getResourceInfo(
 	migration *stork_api.Migration,
 	object runtime.Unstructured,
-	status stork_api.MigrationStatusType,
-	reason string,
-) {
+) *stork_api.ResourceInfo {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return nil
+	}
+	gkv := object.GetObjectKind().GroupVersionKind()
 	for _, resource := range migration.Status.Resources {
-		metadata, err := meta.Accessor(object)
-		if err != nil {
-			continue
-		}
-		gkv := object.GetObjectKind().GroupVersionKind()
 		if resource.Name == metadata.GetName() &&
 			resource.Namespace == metadata.GetNamespace() &&
 			(resource.Group == gkv.Group || (resource.Group == "core" && gkv.Group == "")) &&
 			resource.Version == gkv.Version &&
 			resource.Kind == gkv.Kind {
-			resource.Status = status
-			resource.Reason = reason
-			eventType := v1.EventTypeNormal
-			if status == stork_api.MigrationStatusFailed {
-				eventType = v1.EventTypeWarning
-			}
-			eventMessage := fmt.Sprintf("%v %v/%v: %v",
-				gkv,
-				resource.Namespace,
-				resource.Name,
-				reason)
-			m.Recorder.Event(migration, eventType, string(status), eventMessage)
-			return
+			return resource
+		}
+	}
+	return nil
+}
+
+// findResourceInfo Returns the status in the list for the same resource as
+// the given status, or nil if it isn't in the list
+func findResourceInfo(
+	resourceInfos []*stork_api.ResourceInfo,
+	resourceInfo *stork_api.ResourceInfo,
+) *stork_api.ResourceInfo {
+	for _, r := range resourceInfos {
+		if r.Name == resourceInfo.Name &&
+			r.Namespace == resourceInfo.Namespace &&
+			r.GroupVersionKind == resourceInfo.GroupVersionKind {
+			return r
 		}
 	}
+	return nil
+}
+
+func (m *MigrationController) updateResourceStatus(
+	migration *stork_api.Migration,
+	object runtime.Unstructured,
+	status stork_api.MigrationStatusType,
+	reason string,
+) {
+	resource := getResourceInfo(migration, object)
+	if resource == nil {
+		return
+	}
+	resource.Status = status
+	resource.Reason = reason
+	eventType := v1.EventTypeNormal
+	if status == stork_api.MigrationStatusFailed {
+		eventType = v1.EventTypeWarning
+	}
+	eventMessage := fmt.Sprintf("%v %v/%v: %v",
+		object.GetObjectKind().GroupVersionKind(),
+		resource.Namespace,
+		resource.Name,
+		reason)
+	m.Recorder.Event(migration, eventType, string(status), eventMessage)
 }
 
 func (m *MigrationController) prepareServiceResource(
@@ -902,6 +1223,29 @@ func (m *MigrationController) prepareServiceResource(
 	return object, nil
 }
 
+// prepareJobResource Removes the selector and labels generated for a Job,
+// since they have the UID of the Job and new ones are generated when it is
+// created on the destination cluster
+func (m *MigrationController) prepareJobResource(
+	migration *stork_api.Migration,
+	object runtime.Unstructured,
+) (runtime.Unstructured, error) {
+	spec, err := collections.GetMap(object.UnstructuredContent(), "spec")
+	if err != nil {
+		return nil, err
+	}
+	if manualSelector, ok := spec["manualSelector"].(bool); ok && manualSelector {
+		return object, nil
+	}
+	delete(spec, "selector")
+	if templateLabels, err := collections.GetMap(spec, "template.metadata.labels"); err == nil {
+		delete(templateLabels, "controller-uid")
+		delete(templateLabels, "job-name")
+	}
+
+	return object, nil
+}
+
 func (m *MigrationController) preparePVResource(
 	migration *stork_api.Migration,
 	object runtime.Unstructured,
@@ -910,10 +1254,28 @@ func (m *MigrationController) preparePVResource(
 	if err != nil {
 		return nil, err
 	}
-	delete(spec, "claimRef")
 	delete(spec, "storageClassName")
 
-	return m.Driver.UpdateMigratedPersistentVolumeSpec(object)
+	// The driver is called while the claimRef still refers to the PVC on the
+	// source cluster, which is used to find the driver that owns the PV
+	object, err = m.Driver.UpdateMigratedPersistentVolumeSpec(object)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the PV reserved for its PVC, which might be migrated to a
+	// different namespace
+	if claimRef, err := collections.GetMap(object.UnstructuredContent(), "spec.claimRef"); err == nil {
+		for key := range claimRef {
+			if key != "kind" && key != "apiVersion" && key != "name" && key != "namespace" {
+				delete(claimRef, key)
+			}
+		}
+		if namespace, ok := claimRef["namespace"].(string); ok {
+			claimRef["namespace"] = getDestinationNamespace(migration, namespace)
+		}
+	}
+	return object, nil
 }
 
 func (m *MigrationController) prepareApplicationResource(
@@ -937,22 +1299,31 @@ func (m *MigrationController) prepareApplicationResource(
 	}
 
 	annotations[StorkMigrationReplicasAnnotation] = strconv.FormatInt(replicas, 10)
-	spec["replicas"] = 0
+	spec["replicas"] = int64(0)
 	return object, nil
 }
 
+// applyResources Applies the objects on the destination cluster in stages.
+// Returns false if the objects in a stage can't be applied yet, in which case
+// the stage is recorded in the status and the remaining stages are applied
+// the next time the migration is handled
 func (m *MigrationController) applyResources(
 	migration *stork_api.Migration,
 	objects []runtime.Unstructured,
-) error {
+	resources map[schema.GroupVersionKind]metav1.APIResource,
+) (bool, error) {
 	remoteConfig, err := getClusterPairSchedulerConfig(migration.Spec.ClusterPair, migration.Namespace)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	if err := m.applyCRDs(migration, remoteConfig, resources); err != nil {
+		return false, err
 	}
 
 	client, err := kubernetes.NewForConfig(remoteConfig)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// First make sure all the namespaces are created on the
@@ -960,82 +1331,318 @@ func (m *MigrationController) applyResources(
 	for _, ns := range migration.Spec.Namespaces {
 		namespace, err := k8s.Instance().GetNamespace(ns)
 		if err != nil {
-			return err
+			return false, err
 		}
+		destination := getDestinationNamespace(migration, namespace.Name)
 
 		// Don't create if the namespace already exists on the remote cluster
-		_, err = client.CoreV1().Namespaces().Get(namespace.Name, metav1.GetOptions{})
+		_, err = client.CoreV1().Namespaces().Get(destination, metav1.GetOptions{})
 		if err == nil {
 			continue
 		}
 
 		_, err = client.CoreV1().Namespaces().Create(&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:        namespace.Name,
+				Name:        destination,
 				Labels:      namespace.Labels,
 				Annotations: namespace.Annotations,
 			},
 		})
 		if err != nil && !apierrors.IsAlreadyExists(err) {
-			return err
+			return false, err
 		}
 	}
 
 	remoteDynamicInterface, err := dynamic.NewForConfig(remoteConfig)
 	if err != nil {
-		return nil
+		return false, err
 	}
+
+	stageObjects := make(map[stork_api.MigrationResourceStageType][]runtime.Unstructured)
 	for _, o := range objects {
-		metadata, err := meta.Accessor(o)
+		// Skip objects that couldn't be prepared
+		if resourceInfo := getResourceInfo(migration, o); resourceInfo == nil ||
+			resourceInfo.Status == stork_api.MigrationStatusFailed {
+			continue
+		}
+		stage := resourceStage(o.GetObjectKind().GroupVersionKind().Kind)
+		stageObjects[stage] = append(stageObjects[stage], o)
+	}
+	for _, stage := range applyStages {
+		// Skip the stages that were applied before the migration was
+		// resumed
+		if resourceStageIndex(stage) < resourceStageIndex(migration.Status.ResourceStage) {
+			continue
+		}
+		if migration.Status.ResourceStage != stage {
+			migration.Status.ResourceStage = stage
+			migration.Status.ResourceStageTimestamp = metav1.Now()
+		}
+		if stage == stork_api.MigrationResourceStageWorkloads &&
+			!m.checkPVCsBound(
+				migration,
+				client,
+				stageObjects[stork_api.MigrationResourceStagePersistentVolumeClaims]) {
+			// Store the stage so that the PVCs are checked again when the
+			// migration is handled next
+			return false, sdk.Update(migration)
+		}
+		if len(stageObjects[stage]) == 0 {
+			continue
+		}
+		log.MigrationLog(migration).Infof("Applying %v resources", stage)
+		for _, o := range stageObjects[stage] {
+			reason, err := m.applyObject(migration, remoteDynamicInterface, o, resources)
+			if err != nil {
+				m.updateResourceStatus(
+					migration,
+					o,
+					stork_api.MigrationStatusFailed,
+					fmt.Sprintf("Error applying resource: %v", err))
+			} else {
+				m.updateResourceStatus(
+					migration,
+					o,
+					stork_api.MigrationStatusSuccessful,
+					reason)
+			}
+		}
+		// Store the status of the resources after each stage
+		if err := sdk.Update(migration); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// resourceStageIndex Returns the position of the stage in the order in
+// which the stages are applied, -1 if it isn't a stage
+func resourceStageIndex(stage stork_api.MigrationResourceStageType) int {
+	for i, s := range applyStages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyObject Creates the object on the destination cluster. If it already
+// exists it is handled based on the apply strategy for its kind. Returns
+// the reason to record in the status of the resource
+func (m *MigrationController) applyObject(
+	migration *stork_api.Migration,
+	remoteDynamicInterface dynamic.Interface,
+	o runtime.Unstructured,
+	resources map[schema.GroupVersionKind]metav1.APIResource,
+) (string, error) {
+	metadata, err := meta.Accessor(o)
+	if err != nil {
+		return "", err
+	}
+	gvk := o.GetObjectKind().GroupVersionKind()
+	resource, ok := resources[gvk]
+	if !ok {
+		return "", fmt.Errorf("Resource type not found for %v", gvk)
+	}
+	log.MigrationLog(migration).Infof("Applying %v %v", gvk.Kind, metadata.GetName())
+	unstructured, ok := o.(*unstructured.Unstructured)
+	if !ok {
+		return "", fmt.Errorf("Unable to cast object to unstructured: %v", o)
+	}
+	var dynamicClient dynamic.ResourceInterface
+	if !resource.Namespaced {
+		dynamicClient = remoteDynamicInterface.Resource(gvk.GroupVersion().WithResource(resource.Name))
+	} else {
+		destination := getDestinationNamespace(migration, metadata.GetNamespace())
+		if destination != metadata.GetNamespace() {
+			// Update a copy so that the object can still be matched
+			// with its status, which has the source namespace
+			unstructured = unstructured.DeepCopy()
+			if err := updateNamespaceReferences(migration, unstructured); err != nil {
+				return "", err
+			}
+			unstructured.SetNamespace(destination)
+		}
+		dynamicClient = remoteDynamicInterface.Resource(
+			gvk.GroupVersion().WithResource(resource.Name)).Namespace(destination)
+	}
+
+	strategy := applyStrategyForKind(migration, gvk.Kind)
+	lastAppliedStored := false
+	if strategy == stork_api.MigrationApplyStrategyMerge {
+		if lastAppliedStored, err = setLastAppliedConfig(unstructured); err != nil {
+			return "", err
+		}
+		if !lastAppliedStored {
+			log.MigrationLog(migration).Warnf("%v %v is too large to store its last applied configuration, "+
+				"it will be merged without removing fields", gvk.Kind, metadata.GetName())
+		}
+	}
+	_, err = dynamicClient.Create(unstructured)
+	if err == nil {
+		return "Resource migrated successfully", nil
+	} else if !(apierrors.IsAlreadyExists(err) ||
+		strings.Contains(err.Error(), portallocator.ErrAllocated.Error())) {
+		return "", err
+	}
+
+	switch strategy {
+	case stork_api.MigrationApplyStrategySkipIfExists:
+		return "Resource already exists on the destination cluster", nil
+	case stork_api.MigrationApplyStrategyMerge:
+		if err := mergeObject(dynamicClient, unstructured); err != nil {
+			return "", err
+		}
+		if !lastAppliedStored {
+			return "Resource merged with the existing resource without removing fields since it is too large " +
+				"to store its last applied configuration", nil
+		}
+		return "Resource merged with the existing resource", nil
+	default:
+		// Delete the resource if it already exists on the destination
+		// cluster and try creating again
+		err = dynamicClient.Delete(metadata.GetName(), &metav1.DeleteOptions{})
 		if err != nil {
-			return err
+			log.MigrationLog(migration).Errorf("Error deleting %v %v during migrate: %v", gvk.Kind, metadata.GetName(), err)
+			return "", err
+		}
+		if _, err = dynamicClient.Create(unstructured); err != nil {
+			return "", err
 		}
-		objectType, err := meta.TypeAccessor(o)
+		return "Resource migrated successfully", nil
+	}
+}
+
+// updateNamespaceReferences Updates the namespaces of the objects referred to
+// by the object to the namespaces they are migrated to. Only the subjects of
+// RoleBindings refer to objects in other namespaces
+func updateNamespaceReferences(migration *stork_api.Migration, object *unstructured.Unstructured) error {
+	if object.GetKind() != "RoleBinding" {
+		return nil
+	}
+	subjects, found, err := unstructured.NestedSlice(object.Object, "subjects")
+	if err != nil || !found {
+		return err
+	}
+	for _, s := range subjects {
+		subject, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if namespace, ok := subject["namespace"].(string); ok {
+			subject["namespace"] = getDestinationNamespace(migration, namespace)
+		}
+	}
+	return unstructured.SetNestedSlice(object.Object, subjects, "subjects")
+}
+
+// checkPVCsBound Returns true if the migrated PVCs are bound on the
+// destination cluster so that the pods using them can be started. Returns
+// false if some of them aren't bound yet, in which case they are checked
+// again the next time the migration is handled. PVCs that aren't bound
+// within pvcBindTimeout of the start of the stage are marked as failed
+func (m *MigrationController) checkPVCsBound(
+	migration *stork_api.Migration,
+	client kubernetes.Interface,
+	pvcs []runtime.Unstructured,
+) bool {
+	pending := make([]runtime.Unstructured, 0)
+	for _, o := range pvcs {
+		if resourceInfo := getResourceInfo(migration, o); resourceInfo == nil ||
+			resourceInfo.Status != stork_api.MigrationStatusSuccessful {
+			continue
+		}
+		metadata, err := meta.Accessor(o)
 		if err != nil {
-			return err
+			continue
 		}
-		resource := &metav1.APIResource{
-			Name:       strings.ToLower(objectType.GetKind()) + "s",
-			Namespaced: len(metadata.GetNamespace()) > 0,
+		namespace := getDestinationNamespace(migration, metadata.GetNamespace())
+		pvc, err := client.CoreV1().PersistentVolumeClaims(namespace).Get(metadata.GetName(), metav1.GetOptions{})
+		if err != nil || pvc.Status.Phase != v1.ClaimBound {
+			pending = append(pending, o)
 		}
-		dynamicClient := remoteDynamicInterface.Resource(
-			o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name)).Namespace(metadata.GetNamespace())
+	}
+	if len(pending) == 0 {
+		return true
+	}
+
+	if time.Since(migration.Status.ResourceStageTimestamp.Time) < pvcBindTimeout {
+		log.MigrationLog(migration).Infof("Waiting for %v PVCs to be bound", len(pending))
+		return false
+	}
+	for _, o := range pending {
+		m.updateResourceStatus(
+			migration,
+			o,
+			stork_api.MigrationStatusFailed,
+			"Timed out waiting for PVC to be bound on the destination cluster")
+	}
+	return true
+}
+
+// applyCRDs Creates the CRDs for the custom resources being migrated on the
+// remote cluster if they don't exist there, and waits for the resources to
+// be served by the remote cluster
+func (m *MigrationController) applyCRDs(
+	migration *stork_api.Migration,
+	remoteConfig *rest.Config,
+	resources map[schema.GroupVersionKind]metav1.APIResource,
+) error {
+	crdList, err := m.dynamicInterface.Resource(crdGVR).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("Error getting CRDs: %v", err)
+	}
+	crds := make(map[string]*unstructured.Unstructured)
+	for i := range crdList.Items {
+		crds[crdList.Items[i].GetName()] = &crdList.Items[i]
+	}
 
-		log.MigrationLog(migration).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
-		unstructured, ok := o.(*unstructured.Unstructured)
+	remoteDynamicInterface, err := dynamic.NewForConfig(remoteConfig)
+	if err != nil {
+		return err
+	}
+	remoteClient, err := kubernetes.NewForConfig(remoteConfig)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		crd, ok := crds[resource.Name+"."+resource.Group]
 		if !ok {
-			return fmt.Errorf("Unable to cast object to unstructured: %v", o)
-		}
-		_, err = dynamicClient.Create(unstructured)
-		if err != nil && (apierrors.IsAlreadyExists(err) || strings.Contains(err.Error(), portallocator.ErrAllocated.Error())) {
-			switch objectType.GetKind() {
-			// Don't want to delete the Volume resources
-			case "PersistentVolumeClaim", "PersistentVolume":
-				err = nil
-			default:
-				// Delete the resource if it already exists on the destination
-				// cluster and try creating again
-				err = dynamicClient.Delete(metadata.GetName(), &metav1.DeleteOptions{})
-				if err == nil {
-					_, err = dynamicClient.Create(unstructured)
-				} else {
-					log.MigrationLog(migration).Errorf("Error deleting %v %v during migrate: %v", objectType.GetKind(), metadata.GetName(), err)
-				}
-			}
+			continue
+		}
+		_, err := remoteDynamicInterface.Resource(crdGVR).Get(crd.GetName(), metav1.GetOptions{})
+		if err == nil {
+			continue
+		} else if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("Error getting CRD %v from remote cluster: %v", crd.GetName(), err)
+		}
 
+		log.MigrationLog(migration).Infof("Creating CRD %v", crd.GetName())
+		crd = crd.DeepCopy()
+		delete(crd.Object, "status")
+		if err := removeClusterMetadata(crd.Object); err != nil {
+			return err
+		}
+		_, err = remoteDynamicInterface.Resource(crdGVR).Create(crd)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("Error creating CRD %v on remote cluster: %v", crd.GetName(), err)
 		}
+
+		groupVersion := schema.GroupVersion{Group: resource.Group, Version: resource.Version}
+		err = wait.PollImmediate(validateCRDInterval, validateCRDTimeout, func() (bool, error) {
+			resourceList, err := remoteClient.Discovery().ServerResourcesForGroupVersion(groupVersion.String())
+			if err != nil {
+				return false, nil
+			}
+			for _, r := range resourceList.APIResources {
+				if r.Name == resource.Name {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
 		if err != nil {
-			m.updateResourceStatus(
-				migration,
-				o,
-				stork_api.MigrationStatusFailed,
-				fmt.Sprintf("Error applying resource: %v", err))
-		} else {
-			m.updateResourceStatus(
-				migration,
-				o,
-				stork_api.MigrationStatusSuccessful,
-				"Resource migrated successfully")
+			return fmt.Errorf("Error waiting for CRD %v on remote cluster: %v", crd.GetName(), err)
 		}
 	}
 	return nil
@@ -1055,5 +1662,23 @@ func (m *MigrationController) createCRD() error {
 		return err
 	}
 
+	err = k8s.Instance().ValidateCRD(resource, validateCRDTimeout, validateCRDInterval)
+	if err != nil {
+		return err
+	}
+
+	resource = k8s.CustomResource{
+		Name:    stork_api.ResourceTransformationResourceName,
+		Plural:  stork_api.ResourceTransformationResourcePlural,
+		Group:   stork.GroupName,
+		Version: stork_api.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(stork_api.ResourceTransformation{}).Name(),
+	}
+	err = k8s.Instance().CreateCRD(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
 	return k8s.Instance().ValidateCRD(resource, validateCRDTimeout, validateCRDInterval)
 }
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// validateTransformation Returns an error if any of the rules in the
+// transformation are invalid
+func validateTransformation(transformation *stork_api.ResourceTransformation) error {
+	for i, rule := range transformation.Spec.Rules {
+		if _, err := metav1.LabelSelectorAsSelector(rule.Resources.Selector); err != nil {
+			return fmt.Errorf("Invalid selector in rule %v: %v", i, err)
+		}
+		if _, err := decodeJSONPatch(rule.JSONPatch); err != nil {
+			return fmt.Errorf("Invalid JSON patch in rule %v: %v", i, err)
+		}
+		for _, field := range rule.Fields {
+			if _, err := parseFieldPath(field.Path); err != nil {
+				return fmt.Errorf("Invalid path %v in rule %v: %v", field.Path, i, err)
+			}
+			switch field.Operation {
+			case stork_api.FieldOperationSet, stork_api.FieldOperationDelete:
+			case stork_api.FieldOperationReplace, stork_api.FieldOperationReplacePrefix:
+				if field.From == "" {
+					return fmt.Errorf("From needs to be set for operation %v on %v in rule %v",
+						field.Operation, field.Path, i)
+				}
+			default:
+				return fmt.Errorf("Invalid operation %v on %v in rule %v", field.Operation, field.Path, i)
+			}
+		}
+	}
+	return nil
+}
+
+// transformResource Applies the rules in the transformation that match the
+// object. The kind, name and namespace of the object can't be changed. The
+// object is left unchanged if an error is returned
+func transformResource(
+	transformation *stork_api.ResourceTransformation,
+	object runtime.Unstructured,
+	resource metav1.APIResource,
+) error {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return err
+	}
+	gvk := object.GetObjectKind().GroupVersionKind()
+	name := metadata.GetName()
+	namespace := metadata.GetNamespace()
+	groupVersion := schema.GroupVersion{Group: resource.Group, Version: resource.Version}
+	// Transform a copy so that the object isn't changed if there is an
+	// error
+	content, err := json.Marshal(object.UnstructuredContent())
+	if err != nil {
+		return err
+	}
+	transformed := &unstructured.Unstructured{}
+	if err := json.Unmarshal(content, &transformed.Object); err != nil {
+		return err
+	}
+
+	for i, rule := range transformation.Spec.Rules {
+		if len(rule.Resources.Types) > 0 &&
+			!resourceTypeMatches(rule.Resources.Types, groupVersion, resource) {
+			continue
+		}
+		if rule.Resources.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(rule.Resources.Selector)
+			if err != nil {
+				return err
+			}
+			if !selector.Matches(labels.Set(transformed.GetLabels())) {
+				continue
+			}
+		}
+
+		if len(rule.JSONPatch) > 0 {
+			patch, err := decodeJSONPatch(rule.JSONPatch)
+			if err != nil {
+				return err
+			}
+			content, err := json.Marshal(transformed.Object)
+			if err != nil {
+				return err
+			}
+			content, err = patch.Apply(content)
+			if err != nil {
+				return fmt.Errorf("Error applying JSON patch in rule %v: %v", i, err)
+			}
+			patched := make(map[string]interface{})
+			if err := json.Unmarshal(content, &patched); err != nil {
+				return err
+			}
+			transformed.Object = patched
+		}
+		for _, field := range rule.Fields {
+			keys, err := parseFieldPath(field.Path)
+			if err != nil {
+				return err
+			}
+			applyFieldOperation(transformed.Object, keys, field)
+		}
+	}
+
+	if transformed.GroupVersionKind() != gvk ||
+		transformed.GetName() != name ||
+		transformed.GetNamespace() != namespace {
+		return fmt.Errorf("Transformations can't change the kind, name or namespace of resources")
+	}
+	object.SetUnstructuredContent(transformed.Object)
+	return nil
+}
+
+// decodeJSONPatch Returns the JSON patch with the operations
+func decodeJSONPatch(operations []stork_api.JSONPatchOperation) (jsonpatch.Patch, error) {
+	patchBytes, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.DecodePatch(patchBytes)
+}
+
+// parseFieldPath Returns the keys in a field path. Keys are separated by dots
+// or put in brackets
+func parseFieldPath(path string) ([]string, error) {
+	keys := make([]string, 0)
+	for len(path) > 0 {
+		var key string
+		if path[0] == '[' {
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("Missing ] in path")
+			}
+			key = path[1:end]
+			path = path[end+1:]
+		} else {
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			key = path[:end]
+			path = path[end:]
+		}
+		if key == "" {
+			return nil, fmt.Errorf("Empty key in path")
+		}
+		keys = append(keys, key)
+		if strings.HasPrefix(path, ".") {
+			path = path[1:]
+			if path == "" {
+				return nil, fmt.Errorf("Path can't end with .")
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("Path can't be empty")
+	}
+	return keys, nil
+}
+
+// applyFieldOperation Applies the operation to the fields at the path under
+// the node. The operation is applied to every item of the lists in the path
+func applyFieldOperation(node interface{}, keys []string, field stork_api.FieldOperation) {
+	switch n := node.(type) {
+	case []interface{}:
+		for _, item := range n {
+			applyFieldOperation(item, keys, field)
+		}
+	case map[string]interface{}:
+		key := keys[0]
+		matchingKeys := []string{key}
+		prefix := strings.TrimSuffix(key, "*")
+		wildcard := prefix != key
+		if wildcard {
+			matchingKeys = make([]string, 0)
+			for k := range n {
+				if strings.HasPrefix(k, prefix) {
+					matchingKeys = append(matchingKeys, k)
+				}
+			}
+		}
+
+		for _, k := range matchingKeys {
+			if len(keys) > 1 {
+				child, ok := n[k]
+				if !ok || child == nil {
+					// Only create the fields above the one being set
+					if field.Operation != stork_api.FieldOperationSet || wildcard {
+						continue
+					}
+					child = make(map[string]interface{})
+					n[k] = child
+				}
+				applyFieldOperation(child, keys[1:], field)
+				continue
+			}
+
+			switch field.Operation {
+			case stork_api.FieldOperationSet:
+				n[k] = field.Value
+			case stork_api.FieldOperationDelete:
+				delete(n, k)
+			case stork_api.FieldOperationReplace:
+				if value, ok := n[k].(string); ok && value == field.From {
+					n[k] = field.Value
+				}
+			case stork_api.FieldOperationReplacePrefix:
+				if value, ok := n[k].(string); ok && strings.HasPrefix(value, field.From) {
+					n[k] = field.Value + strings.TrimPrefix(value, field.From)
+				}
+			}
+		}
+	}
+}
@@ -15,16 +15,31 @@ import (
 const (
 	defaultIntervalSec = 120
 	minimumIntervalSec = 30
+	// defaultIOErrorRestartIntervalSec Default interval after which pods are
+	// restarted again if IO to their volume is still failing
+	defaultIOErrorRestartIntervalSec = 600
 )
 
 // Monitor Storage driver monitor
 type Monitor struct {
 	Driver      volume.Driver
 	IntervalSec int64
-	lock        sync.Mutex
-	started     bool
-	stopChannel chan int
-	done        chan int
+	// NodeStatusChangeHandlers Functions to be called when the status of a
+	// driver node changes between two runs of the monitor
+	NodeStatusChangeHandlers []func(node *volume.NodeInfo)
+	// IOErrorRestartIntervalSec Interval in seconds after which the pods
+	// using a volume are restarted again if IO to the volume is still
+	// failing. Pods are restarted right away when IO starts failing
+	IOErrorRestartIntervalSec int64
+	lock                      sync.Mutex
+	started                   bool
+	stopChannel               chan int
+	done                      chan int
+	nodeStatus                map[string]volume.NodeStatus
+	// ioErrorRestarts Last time the pods using each PVC were restarted
+	// because IO to the volume was failing, keyed by namespace/name. PVCs
+	// are removed once IO to their volume isn't failing
+	ioErrorRestarts map[string]time.Time
 }
 
 // Start Starts the monitor
@@ -41,9 +56,16 @@ func (m *Monitor) Start() error {
 	} else if m.IntervalSec < minimumIntervalSec {
 		return fmt.Errorf("Minimum interval for health monitor is %v seconds", minimumIntervalSec)
 	}
+	if m.IOErrorRestartIntervalSec == 0 {
+		m.IOErrorRestartIntervalSec = defaultIOErrorRestartIntervalSec
+	} else if m.IOErrorRestartIntervalSec < 0 {
+		return fmt.Errorf("IO error restart interval for health monitor can't be negative")
+	}
 
 	m.stopChannel = make(chan int)
 	m.done = make(chan int)
+	m.nodeStatus = make(map[string]volume.NodeStatus)
+	m.ioErrorRestarts = make(map[string]time.Time)
 
 	go m.driverMonitor()
 
@@ -80,6 +102,22 @@ func (m *Monitor) isSameNode(k8sNodeName string, driverNode *volume.NodeInfo) bo
 	return volume.IsNodeMatch(node, driverNode)
 }
 
+// notifyStatusChanges Calls the registered handlers for nodes whose status
+// has changed since the last time they were checked
+func (m *Monitor) notifyStatusChanges(nodes []*volume.NodeInfo) {
+	for _, node := range nodes {
+		previousStatus, ok := m.nodeStatus[node.ID]
+		m.nodeStatus[node.ID] = node.Status
+		if !ok || previousStatus == node.Status {
+			continue
+		}
+		log.Infof("Status of node %v changed from %v to %v", node.ID, previousStatus, node.Status)
+		for _, handler := range m.NodeStatusChangeHandlers {
+			handler(node)
+		}
+	}
+}
+
 func (m *Monitor) driverMonitor() {
 	defer close(m.done)
 	for {
@@ -91,6 +129,7 @@ func (m *Monitor) driverMonitor() {
 				log.Errorf("Error getting nodes: %v", err)
 				time.Sleep(2 * time.Second)
 			}
+			m.notifyStatusChanges(nodes)
 			for _, node := range nodes {
 				// Check if nodes are reported online by the storage driver
 				// If not online, look at all the pods on that node
@@ -109,7 +148,7 @@ func (m *Monitor) driverMonitor() {
 							continue
 						}
 
-						if len(volumes) == 0 {
+						if !hasDriverVolumes(volumes, node) {
 							storklog.PodLog(&pod).Debugf("Pod doesn't have any volumes by driver, skipping")
 							continue
 						}
@@ -126,9 +165,130 @@ func (m *Monitor) driverMonitor() {
 					}
 				}
 			}
+			if err == nil {
+				m.checkVolumes(nodes)
+			}
 			time.Sleep(time.Duration(m.IntervalSec) * time.Second)
 		case <-m.stopChannel:
 			return
 		}
 	}
 }
+
+// checkVolumes Publishes the health of the volumes owned by the driver on
+// their PVCs and restarts pods using volumes for which IO is failing. Pods
+// are restarted when IO starts failing, and then only once every
+// IOErrorRestartIntervalSec while it keeps failing so that they aren't
+// deleted on every run of the monitor. The volumes of all the PVCs are
+// inspected together so that the driver isn't called once for each PVC
+func (m *Monitor) checkVolumes(nodes []*volume.NodeInfo) {
+	pvcList, err := k8s.Instance().GetPersistentVolumeClaims("", nil)
+	if err != nil {
+		log.Errorf("Error getting PVCs: %v", err)
+		return
+	}
+	pvcs := make([]*v1.PersistentVolumeClaim, 0)
+	volumeIDs := make([]string, 0)
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if pvc.Status.Phase != v1.ClaimBound || !m.Driver.OwnsPVC(pvc) {
+			continue
+		}
+		pvcs = append(pvcs, pvc)
+		volumeIDs = append(volumeIDs, pvc.Spec.VolumeName)
+	}
+	if len(pvcs) == 0 {
+		m.ioErrorRestarts = make(map[string]time.Time)
+		return
+	}
+	volumes, err := volume.InspectVolumes(m.Driver, volumeIDs)
+	if err != nil {
+		log.Errorf("Error inspecting volumes: %v", err)
+		return
+	}
+
+	// Keep the last restart of PVCs whose volumes are still failing or
+	// couldn't be inspected
+	keepRestarts := make(map[string]bool)
+	defer func() {
+		for key := range m.ioErrorRestarts {
+			if !keepRestarts[key] {
+				delete(m.ioErrorRestarts, key)
+			}
+		}
+	}()
+	for _, pvc := range pvcs {
+		key := pvc.Namespace + "/" + pvc.Name
+		info, ok := volumes[pvc.Spec.VolumeName]
+		if !ok {
+			storklog.PVCLog(pvc).Errorf("Error inspecting volume %v", pvc.Spec.VolumeName)
+			keepRestarts[key] = true
+			continue
+		}
+		if volume.UpdateHealthAnnotations(pvc, volume.GetHealthAnnotations(info)) {
+			if _, err := k8s.Instance().UpdatePersistentVolumeClaim(pvc); err != nil {
+				storklog.PVCLog(pvc).Errorf("Error updating volume health: %v", err)
+			}
+		}
+		if info.IOState != volume.IOStateError {
+			continue
+		}
+		keepRestarts[key] = true
+		restartInterval := time.Duration(m.IOErrorRestartIntervalSec) * time.Second
+		if lastRestart, ok := m.ioErrorRestarts[key]; ok && time.Since(lastRestart) < restartInterval {
+			storklog.PVCLog(pvc).Debugf("IO to volume is still failing, pods were restarted at %v", lastRestart)
+			continue
+		}
+		m.restartPodsOnAttachedNode(pvc, info, nodes)
+		m.ioErrorRestarts[key] = time.Now()
+	}
+}
+
+// restartPodsOnAttachedNode Deletes the running pods using the PVC on the
+// node where its volume is attached, so that the volume is attached again
+// when they are restarted. Pods aren't restarted if the volume is down since
+// that wouldn't help them
+func (m *Monitor) restartPodsOnAttachedNode(
+	pvc *v1.PersistentVolumeClaim,
+	info *volume.Info,
+	nodes []*volume.NodeInfo,
+) {
+	var attachedNode *volume.NodeInfo
+	for _, node := range nodes {
+		if info.IsAttachedOn(node) {
+			attachedNode = node
+			break
+		}
+	}
+	if attachedNode == nil {
+		storklog.PVCLog(pvc).Warnf("IO to volume is failing but node %v where it is attached wasn't found", info.AttachedOn)
+		return
+	}
+
+	pods, err := k8s.Instance().GetPodsUsingPVC(pvc.Name, pvc.Namespace)
+	if err != nil {
+		storklog.PVCLog(pvc).Errorf("Error getting pods using PVC: %v", err)
+		return
+	}
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning || !m.isSameNode(pod.Spec.NodeName, attachedNode) {
+			continue
+		}
+		storklog.PodLog(&pod).Infof("Deleting Pod since IO to volume %v is failing on Node: %v",
+			info.VolumeName, pod.Spec.NodeName)
+		if err := k8s.Instance().DeletePods([]v1.Pod{pod}, true); err != nil {
+			storklog.PodLog(&pod).Errorf("Error deleting pod: %v", err)
+		}
+	}
+}
+
+// hasDriverVolumes Returns true if any of the volumes are owned by the driver
+// running on the node
+func hasDriverVolumes(volumes []*volume.Info, node *volume.NodeInfo) bool {
+	for _, volumeInfo := range volumes {
+		if volumeInfo.Driver == node.Driver {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+// +build unittest
+
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	"github.com/libopenstorage/stork/pkg/fakeapiserver"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const mockDriverName = "MockDriver"
+
+// bulkInspectDriver Mock driver that counts the calls to inspect volumes
+type bulkInspectDriver struct {
+	*mock.Driver
+	inspects     int
+	bulkInspects int
+}
+
+func (d *bulkInspectDriver) InspectVolume(volumeID string) (*volume.Info, error) {
+	d.inspects++
+	return d.Driver.InspectVolume(volumeID)
+}
+
+func (d *bulkInspectDriver) InspectVolumes(volumeIDs []string) (map[string]*volume.Info, error) {
+	d.bulkInspects++
+	return volume.InspectVolumes(d.Driver, volumeIDs)
+}
+
+func TestNodeFlaps(t *testing.T) {
+	d, err := volume.Get(mockDriverName)
+	require.NoError(t, err, "Error getting mock driver")
+	mockDriver, ok := d.(*mock.Driver)
+	require.True(t, ok, "Error casting mock driver")
+	require.NoError(t, mockDriver.CreateCluster(3, &v1.NodeList{}), "Error creating cluster")
+
+	now := time.Now()
+	mockDriver.SetClock(func() time.Time { return now })
+	err = mockDriver.SetNodeTimeline([]mock.NodeStatusChange{
+		{NodeIndex: 1, After: 10 * time.Second, Status: volume.NodeOffline},
+		{NodeIndex: 1, After: 20 * time.Second, Status: volume.NodeOnline},
+		{NodeIndex: 2, After: 20 * time.Second, Status: volume.NodeDegraded},
+		{NodeIndex: 1, After: 40 * time.Second, Status: volume.NodeOffline},
+	})
+	require.NoError(t, err, "Error setting node timeline")
+	err = mockDriver.SetNodeTimeline([]mock.NodeStatusChange{{NodeIndex: 3}})
+	require.Error(t, err, "Expected error for missing node")
+
+	var changes []string
+	monitor := &Monitor{
+		Driver: mockDriver,
+		NodeStatusChangeHandlers: []func(node *volume.NodeInfo){
+			func(node *volume.NodeInfo) {
+				changes = append(changes, node.ID+"="+string(node.Status))
+			},
+		},
+		nodeStatus: make(map[string]volume.NodeStatus),
+	}
+	checkNodes := func(after time.Duration, expected ...string) {
+		now = now.Add(after)
+		nodes, err := mockDriver.GetNodes()
+		require.NoError(t, err, "Error getting nodes")
+		changes = nil
+		monitor.notifyStatusChanges(nodes)
+		require.Equal(t, expected, changes, "Unexpected status changes after %v", after)
+	}
+
+	checkNodes(0)
+	checkNodes(5 * time.Second)
+	checkNodes(5*time.Second, "node2="+string(volume.NodeOffline))
+	checkNodes(25*time.Second, "node2="+string(volume.NodeOnline), "node3="+string(volume.NodeDegraded))
+	checkNodes(5*time.Second, "node2="+string(volume.NodeOffline))
+	checkNodes(time.Minute)
+}
+
+func TestVolumeHealth(t *testing.T) {
+	server := fakeapiserver.New()
+	defer server.Close()
+	k8s.Instance().SetConfig(server.Config())
+
+	d, err := volume.Get(mockDriverName)
+	require.NoError(t, err, "Error getting mock driver")
+	mockDriver, ok := d.(*mock.Driver)
+	require.True(t, ok, "Error casting mock driver")
+	require.NoError(t, mockDriver.CreateCluster(3, &v1.NodeList{}), "Error creating cluster")
+
+	volumeName := "healthvolume"
+	namespace := "healthtest"
+	require.NoError(t, mockDriver.ProvisionVolume(volumeName, []int{0, 1}, 1), "Error provisioning volume")
+	pvc := mockDriver.NewPVC(volumeName)
+	pvc.Namespace = namespace
+	pvc.Status.Phase = v1.ClaimBound
+	_, err = k8s.Instance().CreatePersistentVolumeClaim(pvc)
+	require.NoError(t, err, "Error creating PVC")
+
+	createPod := func(nodeName string) {
+		_, err := k8s.Instance().CreatePod(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod-" + nodeName,
+				Namespace: namespace,
+			},
+			Spec: v1.PodSpec{
+				NodeName: nodeName,
+				Volumes: []v1.Volume{{
+					Name: "data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+							ClaimName: volumeName,
+						},
+					},
+				}},
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+			},
+		})
+		require.NoError(t, err, "Error creating pod")
+	}
+	createPod("node1")
+	createPod("node2")
+
+	driver := &bulkInspectDriver{Driver: mockDriver}
+	monitor := &Monitor{
+		Driver:                    driver,
+		IOErrorRestartIntervalSec: 3600,
+		nodeStatus:                make(map[string]volume.NodeStatus),
+		ioErrorRestarts:           make(map[string]time.Time),
+	}
+	nodes, err := mockDriver.GetNodes()
+	require.NoError(t, err, "Error getting nodes")
+
+	checkPods := func(expected ...string) {
+		pods, err := k8s.Instance().GetPods(namespace, nil)
+		require.NoError(t, err, "Error getting pods")
+		names := make([]string, 0)
+		for _, pod := range pods.Items {
+			names = append(names, pod.Name)
+		}
+		require.ElementsMatch(t, expected, names, "Unexpected pods")
+	}
+
+	// Healthy volumes should only have their state published
+	require.NoError(t, mockDriver.AttachVolume(volumeName, 0), "Error attaching volume")
+	require.NoError(t, mockDriver.UpdateVolumeHealth(volumeName, volume.ReplicationDegraded, []int{1}, volume.IOStateOnline),
+		"Error updating volume health")
+	monitor.checkVolumes(nodes)
+	pvc, err = k8s.Instance().GetPersistentVolumeClaim(volumeName, namespace)
+	require.NoError(t, err, "Error getting PVC")
+	require.Equal(t, string(volume.ReplicationDegraded), pvc.Annotations[volume.ReplicationStatusAnnotation])
+	require.Equal(t, nodes[1].ID, pvc.Annotations[volume.DegradedNodesAnnotation])
+	require.Equal(t, nodes[0].ID, pvc.Annotations[volume.AttachedOnAnnotation])
+	require.Equal(t, string(volume.IOStateOnline), pvc.Annotations[volume.IOStateAnnotation])
+	checkPods("pod-node1", "pod-node2")
+
+	// The volumes should be inspected with one call to the driver
+	require.Equal(t, 1, driver.bulkInspects, "Unexpected number of bulk inspects")
+	require.Zero(t, driver.inspects, "Volumes shouldn't be inspected one at a time")
+
+	// Pods shouldn't be restarted if the volume is offline
+	require.NoError(t, mockDriver.UpdateVolumeHealth(volumeName, volume.ReplicationInSync, nil, volume.IOStateOffline),
+		"Error updating volume health")
+	monitor.checkVolumes(nodes)
+	pvc, err = k8s.Instance().GetPersistentVolumeClaim(volumeName, namespace)
+	require.NoError(t, err, "Error getting PVC")
+	require.Equal(t, string(volume.ReplicationInSync), pvc.Annotations[volume.ReplicationStatusAnnotation])
+	require.NotContains(t, pvc.Annotations, volume.DegradedNodesAnnotation)
+	require.Equal(t, string(volume.IOStateOffline), pvc.Annotations[volume.IOStateAnnotation])
+	checkPods("pod-node1", "pod-node2")
+
+	// Only the pod on the node where the volume is attached should be
+	// restarted when IO to the volume fails
+	require.NoError(t, mockDriver.UpdateVolumeHealth(volumeName, volume.ReplicationInSync, nil, volume.IOStateError),
+		"Error updating volume health")
+	monitor.checkVolumes(nodes)
+	checkPods("pod-node2")
+
+	// Pods shouldn't be restarted again while IO keeps failing until the
+	// restart interval has passed
+	createPod("node1")
+	monitor.checkVolumes(nodes)
+	checkPods("pod-node1", "pod-node2")
+	restartKey := namespace + "/" + volumeName
+	monitor.ioErrorRestarts[restartKey] = time.Now().Add(-2 * time.Hour)
+	monitor.checkVolumes(nodes)
+	checkPods("pod-node2")
+
+	// Pods should be restarted right away if IO fails again after the volume
+	// recovered
+	createPod("node1")
+	require.NoError(t, mockDriver.UpdateVolumeHealth(volumeName, volume.ReplicationInSync, nil, volume.IOStateOnline),
+		"Error updating volume health")
+	monitor.checkVolumes(nodes)
+	require.NotContains(t, monitor.ioErrorRestarts, restartKey, "Expected restart to be cleared after recovery")
+	checkPods("pod-node1", "pod-node2")
+	require.NoError(t, mockDriver.UpdateVolumeHealth(volumeName, volume.ReplicationInSync, nil, volume.IOStateError),
+		"Error updating volume health")
+	monitor.checkVolumes(nodes)
+	checkPods("pod-node2")
+}
@@ -0,0 +1,257 @@
+package nodewatcher
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/pkg/controller"
+	"github.com/libopenstorage/stork/pkg/errors"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	"github.com/portworx/sched-ops/k8s"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// MaintenanceLabel Label added to a node when stork puts the driver on it
+	// into maintenance. Only nodes with this label are taken out of
+	// maintenance when they are uncordoned
+	MaintenanceLabel = "stork.libopenstorage.org/driver-maintenance"
+
+	// resyncPeriod Interval at which cordoned nodes are checked again to
+	// find out if they have been drained
+	resyncPeriod = 1 * time.Minute
+
+	defaultMaxNodesInMaintenance = 1
+)
+
+// NodeWatcher Watches for nodes being cordoned and drained and puts the
+// driver on them into maintenance. The driver is taken out of maintenance
+// once the node is uncordoned
+type NodeWatcher struct {
+	Driver   volume.Driver
+	Recorder record.EventRecorder
+	// MaxNodesInMaintenance Maximum number of driver nodes that can be in
+	// maintenance at the same time, including the ones that were put into
+	// maintenance manually. Defaults to 1
+	MaxNodesInMaintenance int
+}
+
+// Start Starts the controller to watch updates on nodes
+func (n *NodeWatcher) Start() error {
+	if n.MaxNodesInMaintenance == 0 {
+		n.MaxNodesInMaintenance = defaultMaxNodesInMaintenance
+	} else if n.MaxNodesInMaintenance < 0 {
+		return fmt.Errorf("Maximum number of nodes in maintenance should be greater than 0")
+	}
+
+	return controller.Register(
+		&schema.GroupVersionKind{
+			Group:   v1.GroupName,
+			Version: v1.SchemeGroupVersion.Version,
+			Kind:    reflect.TypeOf(v1.Node{}).Name(),
+		},
+		"",
+		resyncPeriod,
+		n)
+}
+
+// Handle updates for nodes
+func (n *NodeWatcher) Handle(ctx context.Context, event sdk.Event) error {
+	node, ok := event.Object.(*v1.Node)
+	if !ok || event.Deleted {
+		return nil
+	}
+	// Only nodes that are cordoned or were put into maintenance by stork
+	// need to be checked with the driver
+	if !node.Spec.Unschedulable && node.Labels[MaintenanceLabel] != "true" {
+		return nil
+	}
+
+	driverNodes, err := n.Driver.GetNodes()
+	if err != nil {
+		return fmt.Errorf("Error getting driver nodes: %v", err)
+	}
+	var matchingNodes []*volume.NodeInfo
+	for _, driverNode := range driverNodes {
+		if volume.IsNodeMatch(node, driverNode) {
+			matchingNodes = append(matchingNodes, driverNode)
+		}
+	}
+	if len(matchingNodes) == 0 {
+		return nil
+	}
+
+	if node.Spec.Unschedulable {
+		return n.enterMaintenance(node, matchingNodes, driverNodes)
+	}
+	if node.Labels[MaintenanceLabel] == "true" {
+		return n.exitMaintenance(node, matchingNodes)
+	}
+	return nil
+}
+
+// enterMaintenance Puts the driver nodes into maintenance once there aren't
+// any pods using volumes from the driver on the cordoned node and fewer than
+// the maximum number of other driver nodes are in maintenance
+func (n *NodeWatcher) enterMaintenance(
+	node *v1.Node,
+	driverNodes []*volume.NodeInfo,
+	allDriverNodes []*volume.NodeInfo,
+) error {
+	var pendingNodes []*volume.NodeInfo
+	for _, driverNode := range driverNodes {
+		if !driverNode.Maintenance {
+			pendingNodes = append(pendingNodes, driverNode)
+		}
+	}
+	if len(pendingNodes) == 0 || !n.Driver.Capabilities().Maintenance {
+		return nil
+	}
+
+	// Nodes that stork has labeled are also counted since the driver might
+	// not report them as being in maintenance yet, or failed to put them
+	// into maintenance
+	labeledNodes, err := n.getLabeledNodes(node)
+	if err != nil {
+		return err
+	}
+	inMaintenance := len(driverNodes) - len(pendingNodes)
+	for _, driverNode := range allDriverNodes {
+		if volume.IsNodeMatch(node, driverNode) {
+			continue
+		}
+		if driverNode.Maintenance || isLabeledNode(driverNode, labeledNodes) {
+			inMaintenance++
+		}
+	}
+	if inMaintenance+len(pendingNodes) > n.MaxNodesInMaintenance {
+		log.Debugf("Waiting for driver nodes to exit maintenance before putting node %v into maintenance, %v of %v are in maintenance",
+			node.Name, inMaintenance, n.MaxNodesInMaintenance)
+		return nil
+	}
+
+	pods, err := n.getPodsUsingDriver(node)
+	if err != nil {
+		return err
+	}
+	if len(pods) > 0 {
+		log.Debugf("Waiting for %v pods using volumes to be drained from node %v before entering maintenance",
+			len(pods), node.Name)
+		return nil
+	}
+
+	// Add the label first so that the node is taken out of maintenance
+	// even if stork restarts after the driver has entered maintenance
+	if err := k8s.Instance().AddLabelOnNode(node.Name, MaintenanceLabel, "true"); err != nil {
+		return fmt.Errorf("Error adding maintenance label to node %v: %v", node.Name, err)
+	}
+	for _, driverNode := range pendingNodes {
+		if err := n.Driver.EnterMaintenance(driverNode); err != nil {
+			if _, ok := err.(*errors.ErrNotSupported); ok {
+				continue
+			}
+			n.Recorder.Event(node,
+				v1.EventTypeWarning,
+				"MaintenanceFailed",
+				fmt.Sprintf("Error putting driver node %v into maintenance: %v", driverNode.ID, err))
+			continue
+		}
+		n.Recorder.Event(node,
+			v1.EventTypeNormal,
+			"EnteredMaintenance",
+			fmt.Sprintf("Put driver node %v into maintenance since the node was drained", driverNode.ID))
+	}
+	return nil
+}
+
+// getLabeledNodes Returns the nodes, other than the given node, that have
+// the maintenance label
+func (n *NodeWatcher) getLabeledNodes(node *v1.Node) ([]*v1.Node, error) {
+	nodes, err := k8s.Instance().GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("Error getting nodes: %v", err)
+	}
+	var labeledNodes []*v1.Node
+	for i := range nodes.Items {
+		if nodes.Items[i].Name != node.Name && nodes.Items[i].Labels[MaintenanceLabel] == "true" {
+			labeledNodes = append(labeledNodes, &nodes.Items[i])
+		}
+	}
+	return labeledNodes, nil
+}
+
+// isLabeledNode Returns true if the driver node runs on one of the labeled
+// nodes
+func isLabeledNode(driverNode *volume.NodeInfo, labeledNodes []*v1.Node) bool {
+	for _, labeledNode := range labeledNodes {
+		if volume.IsNodeMatch(labeledNode, driverNode) {
+			return true
+		}
+	}
+	return false
+}
+
+// exitMaintenance Takes the driver nodes out of maintenance after the node
+// has been uncordoned. The label is only removed once all of them have
+// exited maintenance so that failures are retried
+func (n *NodeWatcher) exitMaintenance(node *v1.Node, driverNodes []*volume.NodeInfo) error {
+	failed := false
+	for _, driverNode := range driverNodes {
+		if !driverNode.Maintenance {
+			continue
+		}
+		if err := n.Driver.ExitMaintenance(driverNode); err != nil {
+			failed = true
+			n.Recorder.Event(node,
+				v1.EventTypeWarning,
+				"MaintenanceFailed",
+				fmt.Sprintf("Error taking driver node %v out of maintenance: %v", driverNode.ID, err))
+			continue
+		}
+		n.Recorder.Event(node,
+			v1.EventTypeNormal,
+			"ExitedMaintenance",
+			fmt.Sprintf("Took driver node %v out of maintenance since the node was uncordoned", driverNode.ID))
+	}
+	if failed {
+		return nil
+	}
+	if err := k8s.Instance().RemoveLabelOnNode(node.Name, MaintenanceLabel); err != nil {
+		return fmt.Errorf("Error removing maintenance label from node %v: %v", node.Name, err)
+	}
+	return nil
+}
+
+// getPodsUsingDriver Returns the pods on the node that haven't completed and
+// are using volumes from the driver. Pods whose volumes can't be checked are
+// also returned so that maintenance isn't entered while they might be
+// running
+func (n *NodeWatcher) getPodsUsingDriver(node *v1.Node) ([]v1.Pod, error) {
+	pods, err := k8s.Instance().GetPodsByNode(node.Name, "")
+	if err != nil {
+		return nil, fmt.Errorf("Error getting pods on node %v: %v", node.Name, err)
+	}
+	var driverPods []v1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		volumes, err := n.Driver.GetPodVolumes(&pod.Spec, pod.Namespace)
+		if err != nil {
+			storklog.PodLog(&pod).Warnf("Error getting volumes for pod: %v", err)
+			driverPods = append(driverPods, pod)
+			continue
+		}
+		if len(volumes) > 0 {
+			driverPods = append(driverPods, pod)
+		}
+	}
+	return driverPods, nil
+}
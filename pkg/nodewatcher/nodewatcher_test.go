@@ -0,0 +1,246 @@
+// +build unittest
+
+package nodewatcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	"github.com/libopenstorage/stork/pkg/fakeapiserver"
+	"github.com/operator-framework/operator-sdk/pkg/sdk"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	mockDriverName = "MockDriver"
+	testNamespace  = "maintenancetest"
+	nodeName       = "node1"
+)
+
+var server *fakeapiserver.Server
+var client kubernetes.Interface
+var mockDriver *mock.Driver
+var recorder *record.FakeRecorder
+var watcher *NodeWatcher
+
+func TestNodeWatcher(t *testing.T) {
+	t.Run("setup", setup)
+	t.Run("drainTest", drainTest)
+	t.Run("manualMaintenanceTest", manualMaintenanceTest)
+	t.Run("maintenanceNotSupportedTest", maintenanceNotSupportedTest)
+	t.Run("maintenanceLimitTest", maintenanceLimitTest)
+	t.Run("maintenanceLimitLabeledTest", maintenanceLimitLabeledTest)
+	t.Run("teardown", teardown)
+}
+
+func setup(t *testing.T) {
+	server = fakeapiserver.New()
+	k8s.Instance().SetConfig(server.Config())
+	var err error
+	client, err = kubernetes.NewForConfig(server.Config())
+	require.NoError(t, err, "Error creating client")
+
+	d, err := volume.Get(mockDriverName)
+	require.NoError(t, err, "Error getting mock driver")
+	var ok bool
+	mockDriver, ok = d.(*mock.Driver)
+	require.True(t, ok, "Error casting mock driver")
+
+	recorder = record.NewFakeRecorder(100)
+	watcher = &NodeWatcher{
+		Driver:                mockDriver,
+		Recorder:              recorder,
+		MaxNodesInMaintenance: defaultMaxNodesInMaintenance,
+	}
+}
+
+func teardown(t *testing.T) {
+	server.Close()
+}
+
+// resetTest Creates a cluster with one node and a pod on it using a volume
+// from the driver. The driver has a second node which doesn't run pods
+func resetTest(t *testing.T, capabilities *volume.Capabilities) {
+	server.Reset()
+	require.NoError(t, mockDriver.CreateCluster(2, &v1.NodeList{}), "Error creating cluster")
+	mockDriver.SetCapabilities(capabilities)
+	getEvents()
+
+	_, err := client.CoreV1().Nodes().Create(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   nodeName,
+			Labels: map[string]string{"kubernetes.io/hostname": nodeName},
+		},
+	})
+	require.NoError(t, err, "Error creating node")
+
+	require.NoError(t, mockDriver.ProvisionVolume("maintenancevolume", []int{0}, 1), "Error provisioning volume")
+	pvc := mockDriver.NewPVC("maintenancevolume")
+	_, err = k8s.Instance().CreatePod(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: testNamespace,
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Volumes: []v1.Volume{{
+				Name: "data",
+				VolumeSource: v1.VolumeSource{
+					PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+						ClaimName: pvc.Name,
+					},
+				},
+			}},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+		},
+	})
+	require.NoError(t, err, "Error creating pod")
+}
+
+// setCordoned Cordons or uncordons the node and sends the update to the
+// watcher
+func setCordoned(t *testing.T, cordoned bool) {
+	node, err := k8s.Instance().GetNodeByName(nodeName)
+	require.NoError(t, err, "Error getting node")
+	node.Spec.Unschedulable = cordoned
+	node, err = client.CoreV1().Nodes().Update(node)
+	require.NoError(t, err, "Error updating node")
+	require.NoError(t, watcher.Handle(context.TODO(), sdk.Event{Object: node}), "Error handling node update")
+}
+
+// requireMaintenance Checks whether the driver node is in maintenance and
+// whether the node has the maintenance label
+func requireMaintenance(t *testing.T, maintenance bool, labeled bool) {
+	driverNodes, err := mockDriver.GetNodes()
+	require.NoError(t, err, "Error getting driver nodes")
+	require.Equal(t, maintenance, driverNodes[0].Maintenance, "Unexpected maintenance state for driver node")
+
+	node, err := k8s.Instance().GetNodeByName(nodeName)
+	require.NoError(t, err, "Error getting node")
+	_, ok := node.Labels[MaintenanceLabel]
+	require.Equal(t, labeled, ok, "Unexpected maintenance label on node")
+}
+
+// getEvents Returns the events recorded since the last call
+func getEvents() []string {
+	events := make([]string, 0)
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func requireEvent(t *testing.T, substring string) {
+	events := getEvents()
+	for _, event := range events {
+		if strings.Contains(event, substring) {
+			return
+		}
+	}
+	require.Fail(t, "Event not found", "Expected event containing %q in %v", substring, events)
+}
+
+func drainTest(t *testing.T) {
+	resetTest(t, &volume.Capabilities{Maintenance: true})
+
+	// The driver shouldn't enter maintenance until the pod using the volume
+	// has been drained
+	setCordoned(t, true)
+	requireMaintenance(t, false, false)
+
+	require.NoError(t, k8s.Instance().DeletePod("pod1", testNamespace, true), "Error deleting pod")
+	setCordoned(t, true)
+	requireMaintenance(t, true, true)
+	requireEvent(t, "EnteredMaintenance")
+
+	setCordoned(t, false)
+	requireMaintenance(t, false, false)
+	requireEvent(t, "ExitedMaintenance")
+}
+
+func manualMaintenanceTest(t *testing.T) {
+	resetTest(t, &volume.Capabilities{Maintenance: true})
+	driverNodes, err := mockDriver.GetNodes()
+	require.NoError(t, err, "Error getting driver nodes")
+	require.NoError(t, mockDriver.EnterMaintenance(driverNodes[0]), "Error entering maintenance")
+
+	// Nodes that weren't put into maintenance by stork shouldn't be taken out
+	// of it
+	setCordoned(t, false)
+	requireMaintenance(t, true, false)
+	require.Empty(t, getEvents(), "Unexpected events")
+}
+
+func maintenanceNotSupportedTest(t *testing.T) {
+	resetTest(t, nil)
+	require.NoError(t, k8s.Instance().DeletePod("pod1", testNamespace, true), "Error deleting pod")
+
+	setCordoned(t, true)
+	requireMaintenance(t, false, false)
+	require.Empty(t, getEvents(), "Unexpected events")
+}
+
+func maintenanceLimitTest(t *testing.T) {
+	resetTest(t, &volume.Capabilities{Maintenance: true})
+	driverNodes, err := mockDriver.GetNodes()
+	require.NoError(t, err, "Error getting driver nodes")
+	require.NoError(t, mockDriver.EnterMaintenance(driverNodes[1]), "Error entering maintenance")
+	require.NoError(t, k8s.Instance().DeletePod("pod1", testNamespace, true), "Error deleting pod")
+
+	// The drained node shouldn't enter maintenance while another driver node
+	// is in maintenance
+	setCordoned(t, true)
+	requireMaintenance(t, false, false)
+	require.Empty(t, getEvents(), "Unexpected events")
+
+	watcher.MaxNodesInMaintenance = 2
+	defer func() {
+		watcher.MaxNodesInMaintenance = defaultMaxNodesInMaintenance
+	}()
+	setCordoned(t, true)
+	requireMaintenance(t, true, true)
+	requireEvent(t, "EnteredMaintenance")
+}
+
+func maintenanceLimitLabeledTest(t *testing.T) {
+	resetTest(t, &volume.Capabilities{Maintenance: true})
+	require.NoError(t, k8s.Instance().DeletePod("pod1", testNamespace, true), "Error deleting pod")
+
+	// The other node was labeled by stork but its driver node isn't reported
+	// as being in maintenance, so it should still count towards the limit
+	_, err := client.CoreV1().Nodes().Create(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node2",
+			Labels: map[string]string{
+				"kubernetes.io/hostname": "node2",
+				MaintenanceLabel:         "true",
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating node")
+	setCordoned(t, true)
+	requireMaintenance(t, false, false)
+	require.Empty(t, getEvents(), "Unexpected events")
+
+	watcher.MaxNodesInMaintenance = 2
+	defer func() {
+		watcher.MaxNodesInMaintenance = defaultMaxNodesInMaintenance
+	}()
+	setCordoned(t, true)
+	requireMaintenance(t, true, true)
+	requireEvent(t, "EnteredMaintenance")
+}
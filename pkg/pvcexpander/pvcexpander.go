@@ -0,0 +1,292 @@
+package pvcexpander
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	"github.com/portworx/sched-ops/k8s"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	k8shelper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+const (
+	annotationPrefix = "stork.libopenstorage.org/"
+	// ExpandThresholdAnnotation Annotation on a PVC or StorageClass with the
+	// percentage of a volume that needs to be used before it is expanded.
+	// Volumes aren't expanded if it isn't set
+	ExpandThresholdAnnotation = annotationPrefix + "expand-threshold-percent"
+	// ExpandStepAnnotation Annotation on a PVC or StorageClass with the
+	// percentage by which a volume is expanded
+	ExpandStepAnnotation = annotationPrefix + "expand-step-percent"
+	// ExpandMaxSizeAnnotation Annotation on a PVC or StorageClass with the
+	// maximum size to which a volume can be expanded, for example 100Gi
+	ExpandMaxSizeAnnotation = annotationPrefix + "expand-max-size"
+
+	defaultStepPercent = 50
+	defaultIntervalSec = 60
+	minimumIntervalSec = 10
+)
+
+// PVCExpander Expands volumes through the driver when the space used in them
+// crosses the threshold set for their PVC or StorageClass
+type PVCExpander struct {
+	Driver   volume.Driver
+	Recorder record.EventRecorder
+	// KubeClient Client used to update the capacity of PVs, which isn't
+	// supported by sched-ops
+	KubeClient  kubernetes.Interface
+	IntervalSec int64
+	lock        sync.Mutex
+	started     bool
+	stopChannel chan int
+	done        chan int
+}
+
+// expansionPolicy Policy read from the annotations on a PVC and its
+// StorageClass
+type expansionPolicy struct {
+	thresholdPercent uint64
+	stepPercent      uint64
+	// maxSize Upper bound for the size of the volume in bytes, 0 if there
+	// isn't one
+	maxSize uint64
+}
+
+// Start Starts checking the usage of volumes periodically
+func (p *PVCExpander) Start() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.started {
+		return fmt.Errorf("PVC expander has already been started")
+	}
+
+	if p.IntervalSec == 0 {
+		p.IntervalSec = defaultIntervalSec
+	} else if p.IntervalSec < minimumIntervalSec {
+		return fmt.Errorf("Minimum interval for PVC expander is %v seconds", minimumIntervalSec)
+	}
+
+	p.stopChannel = make(chan int)
+	p.done = make(chan int)
+
+	go p.expander()
+
+	p.started = true
+	return nil
+}
+
+// Stop Stops the PVC expander
+func (p *PVCExpander) Stop() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.started {
+		return fmt.Errorf("PVC expander has not been started")
+	}
+
+	close(p.stopChannel)
+	<-p.done
+
+	p.started = false
+	return nil
+}
+
+func (p *PVCExpander) expander() {
+	defer close(p.done)
+	for {
+		select {
+		case <-time.After(time.Duration(p.IntervalSec) * time.Second):
+			if err := p.expandVolumes(); err != nil {
+				log.Errorf("Error expanding volumes: %v", err)
+			}
+		case <-p.stopChannel:
+			return
+		}
+	}
+}
+
+// expandVolumes Checks the usage of the volumes for all the bound PVCs owned
+// by the driver and expands the ones which have crossed their threshold
+func (p *PVCExpander) expandVolumes() error {
+	pvcs, err := k8s.Instance().GetPersistentVolumeClaims("", nil)
+	if err != nil {
+		return fmt.Errorf("Error getting PVCs: %v", err)
+	}
+	storageClasses := make(map[string]*storagev1.StorageClass)
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if pvc.Status.Phase != v1.ClaimBound || !p.Driver.OwnsPVC(pvc) {
+			continue
+		}
+
+		storageClassName := k8shelper.GetPersistentVolumeClaimClass(pvc)
+		storageClass, ok := storageClasses[storageClassName]
+		if !ok && storageClassName != "" {
+			storageClass, err = k8s.Instance().GetStorageClass(storageClassName)
+			if err != nil {
+				if !errors.IsNotFound(err) {
+					storklog.PVCLog(pvc).Errorf("Error getting StorageClass: %v", err)
+					continue
+				}
+				// The annotations on the PVC can still be used
+				storageClass = nil
+			}
+			storageClasses[storageClassName] = storageClass
+		}
+
+		policy, err := getExpansionPolicy(pvc, storageClass)
+		if err != nil {
+			p.Recorder.Event(pvc,
+				v1.EventTypeWarning,
+				"InvalidExpansionPolicy",
+				err.Error())
+			continue
+		}
+		if policy == nil {
+			continue
+		}
+		if err := p.expandVolume(pvc, policy); err != nil {
+			storklog.PVCLog(pvc).Errorf("Error expanding volume: %v", err)
+		}
+	}
+	return nil
+}
+
+// getExpansionPolicy Returns the expansion policy for the PVC. Annotations
+// on the PVC override the ones on its StorageClass. Returns nil if a
+// threshold hasn't been set
+func getExpansionPolicy(
+	pvc *v1.PersistentVolumeClaim,
+	storageClass *storagev1.StorageClass,
+) (*expansionPolicy, error) {
+	annotations := make(map[string]string)
+	if storageClass != nil {
+		for k, v := range storageClass.Annotations {
+			annotations[k] = v
+		}
+	}
+	for k, v := range pvc.Annotations {
+		annotations[k] = v
+	}
+
+	threshold, ok := annotations[ExpandThresholdAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	policy := &expansionPolicy{
+		stepPercent: defaultStepPercent,
+	}
+	var err error
+	policy.thresholdPercent, err = strconv.ParseUint(threshold, 10, 64)
+	if err != nil || policy.thresholdPercent == 0 || policy.thresholdPercent > 100 {
+		return nil, fmt.Errorf("Invalid value %q for %v, should be a percentage between 1 and 100",
+			threshold, ExpandThresholdAnnotation)
+	}
+	if step, ok := annotations[ExpandStepAnnotation]; ok {
+		policy.stepPercent, err = strconv.ParseUint(step, 10, 64)
+		if err != nil || policy.stepPercent == 0 {
+			return nil, fmt.Errorf("Invalid value %q for %v, should be a positive percentage",
+				step, ExpandStepAnnotation)
+		}
+	}
+	if maxSize, ok := annotations[ExpandMaxSizeAnnotation]; ok {
+		quantity, err := resource.ParseQuantity(maxSize)
+		if err != nil || quantity.Sign() <= 0 {
+			return nil, fmt.Errorf("Invalid value %q for %v, should be a size like 100Gi",
+				maxSize, ExpandMaxSizeAnnotation)
+		}
+		policy.maxSize = uint64(quantity.Value())
+	}
+	return policy, nil
+}
+
+// expandVolume Expands the volume for the PVC if its usage has crossed the
+// threshold in the policy. The capacity of the PV is used as the current size
+// since it is updated once the volume has been expanded
+func (p *PVCExpander) expandVolume(pvc *v1.PersistentVolumeClaim, policy *expansionPolicy) error {
+	pv, err := p.KubeClient.CoreV1().PersistentVolumes().Get(pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("Error getting PV %v: %v", pvc.Spec.VolumeName, err)
+	}
+	capacity, ok := pv.Spec.Capacity[v1.ResourceStorage]
+	if !ok || capacity.Sign() <= 0 {
+		return nil
+	}
+	currentSize := uint64(capacity.Value())
+
+	info, err := p.Driver.InspectVolume(pvc.Spec.VolumeName)
+	if err != nil {
+		return err
+	}
+	usedPercent := info.UsedBytes * 100 / currentSize
+	if usedPercent < policy.thresholdPercent {
+		return nil
+	}
+
+	if policy.maxSize != 0 && currentSize >= policy.maxSize {
+		p.Recorder.Event(pvc,
+			v1.EventTypeWarning,
+			"ExpansionLimitReached",
+			fmt.Sprintf("%v%% of the volume is used but it has already been expanded to the maximum size of %v",
+				usedPercent, formatSize(policy.maxSize)))
+		return nil
+	}
+	newSize := currentSize + currentSize*policy.stepPercent/100
+	if policy.maxSize != 0 && newSize > policy.maxSize {
+		newSize = policy.maxSize
+	}
+
+	storklog.PVCLog(pvc).Infof("Expanding volume from %v to %v since %v%% of it is used",
+		formatSize(currentSize), formatSize(newSize), usedPercent)
+	if _, err := p.Driver.ResizeVolume(pvc.Spec.VolumeName, newSize); err != nil {
+		p.Recorder.Event(pvc,
+			v1.EventTypeWarning,
+			"ExpansionFailed",
+			fmt.Sprintf("Error expanding volume to %v: %v", formatSize(newSize), err))
+		return err
+	}
+
+	newCapacity := *resource.NewQuantity(int64(newSize), resource.BinarySI)
+	pv.Spec.Capacity[v1.ResourceStorage] = newCapacity
+	if _, err := p.KubeClient.CoreV1().PersistentVolumes().Update(pv); err != nil {
+		p.Recorder.Event(pvc,
+			v1.EventTypeWarning,
+			"ExpansionFailed",
+			fmt.Sprintf("Volume was expanded to %v but error updating PV: %v", formatSize(newSize), err))
+		return err
+	}
+
+	// Also update the request so that the PVC reflects the new size. This is
+	// rejected if the StorageClass doesn't allow expansion, in which case
+	// only the PV is updated
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = make(v1.ResourceList)
+	}
+	pvc.Spec.Resources.Requests[v1.ResourceStorage] = newCapacity
+	if _, err := k8s.Instance().UpdatePersistentVolumeClaim(pvc); err != nil {
+		storklog.PVCLog(pvc).Warnf("Error updating requested size for PVC: %v", err)
+	}
+
+	p.Recorder.Event(pvc,
+		v1.EventTypeNormal,
+		"Expanded",
+		fmt.Sprintf("Expanded volume from %v to %v since %v%% of it was used",
+			formatSize(currentSize), formatSize(newSize), usedPercent))
+	return nil
+}
+
+func formatSize(size uint64) string {
+	return resource.NewQuantity(int64(size), resource.BinarySI).String()
+}
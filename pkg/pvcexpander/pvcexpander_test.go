@@ -0,0 +1,227 @@
+// +build unittest
+
+package pvcexpander
+
+import (
+	"strings"
+	"testing"
+
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	"github.com/libopenstorage/stork/pkg/fakeapiserver"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	mockDriverName = "MockDriver"
+	testNamespace  = "expandtest"
+	volumeName     = "expandvolume"
+	gb             = 1024 * 1024 * 1024
+)
+
+var server *fakeapiserver.Server
+var mockDriver *mock.Driver
+var recorder *record.FakeRecorder
+var expander *PVCExpander
+
+func TestPVCExpander(t *testing.T) {
+	t.Run("setup", setup)
+	t.Run("expandTest", expandTest)
+	t.Run("pvcOverrideTest", pvcOverrideTest)
+	t.Run("maxSizeTest", maxSizeTest)
+	t.Run("noPolicyTest", noPolicyTest)
+	t.Run("invalidPolicyTest", invalidPolicyTest)
+	t.Run("resizeNotSupportedTest", resizeNotSupportedTest)
+	t.Run("teardown", teardown)
+}
+
+func setup(t *testing.T) {
+	server = fakeapiserver.New()
+	k8s.Instance().SetConfig(server.Config())
+	client, err := kubernetes.NewForConfig(server.Config())
+	require.NoError(t, err, "Error creating client")
+
+	d, err := storkvolume.Get(mockDriverName)
+	require.NoError(t, err, "Error getting mock driver")
+	var ok bool
+	mockDriver, ok = d.(*mock.Driver)
+	require.True(t, ok, "Error casting mock driver")
+
+	recorder = record.NewFakeRecorder(100)
+	expander = &PVCExpander{
+		Driver:     mockDriver,
+		Recorder:   recorder,
+		KubeClient: client,
+	}
+}
+
+func teardown(t *testing.T) {
+	server.Close()
+}
+
+// resetTest Creates a 10GB volume with the given usage and a bound PVC for it
+// using a StorageClass with the given annotations
+func resetTest(
+	t *testing.T,
+	usedBytes uint64,
+	storageClassAnnotations map[string]string,
+	pvcAnnotations map[string]string,
+) {
+	server.Reset()
+	require.NoError(t, mockDriver.CreateCluster(3, &v1.NodeList{}), "Error creating cluster")
+	mockDriver.SetCapabilities(&storkvolume.Capabilities{Resize: true})
+	require.NoError(t, mockDriver.ProvisionVolume(volumeName, []int{0}, 10), "Error provisioning volume")
+	require.NoError(t, mockDriver.UpdateVolumeUsage(volumeName, usedBytes), "Error updating volume usage")
+	getEvents()
+
+	_, err := k8s.Instance().CreateStorageClass(&storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        mockDriver.GetStorageClassName(),
+			Annotations: storageClassAnnotations,
+		},
+		Provisioner: "kubernetes.io/mock-volume",
+	})
+	require.NoError(t, err, "Error creating storage class")
+
+	size := resource.MustParse("10Gi")
+	_, err = expander.KubeClient.CoreV1().PersistentVolumes().Create(&v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: volumeName,
+		},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity: v1.ResourceList{
+				v1.ResourceStorage: size,
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating PV")
+
+	pvc := mockDriver.NewPVC(volumeName)
+	pvc.Namespace = testNamespace
+	pvc.Annotations = pvcAnnotations
+	pvc.Spec.Resources.Requests = v1.ResourceList{
+		v1.ResourceStorage: size,
+	}
+	pvc.Status.Phase = v1.ClaimBound
+	_, err = k8s.Instance().CreatePersistentVolumeClaim(pvc)
+	require.NoError(t, err, "Error creating PVC")
+}
+
+// getEvents Returns the events recorded since the last call
+func getEvents() []string {
+	events := make([]string, 0)
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func requireEvent(t *testing.T, substring string) {
+	events := getEvents()
+	for _, event := range events {
+		if strings.Contains(event, substring) {
+			return
+		}
+	}
+	require.Fail(t, "Event not found", "Expected event containing %q in %v", substring, events)
+}
+
+// requireSize Checks the size of the volume in the driver, the capacity of
+// the PV and the requested size of the PVC
+func requireSize(t *testing.T, expected string) {
+	quantity := resource.MustParse(expected)
+	info, err := mockDriver.InspectVolume(volumeName)
+	require.NoError(t, err, "Error inspecting volume")
+	require.Equal(t, uint64(quantity.Value()/gb), info.Size, "Unexpected size for volume")
+
+	pv, err := k8s.Instance().GetPersistentVolume(volumeName)
+	require.NoError(t, err, "Error getting PV")
+	capacity := pv.Spec.Capacity[v1.ResourceStorage]
+	require.Equal(t, expected, capacity.String(), "Unexpected capacity for PV")
+
+	pvc, err := k8s.Instance().GetPersistentVolumeClaim(volumeName, testNamespace)
+	require.NoError(t, err, "Error getting PVC")
+	request := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	require.Equal(t, expected, request.String(), "Unexpected requested size for PVC")
+}
+
+func expandTest(t *testing.T) {
+	resetTest(t, 9*gb, map[string]string{ExpandThresholdAnnotation: "80"}, nil)
+
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "15Gi")
+	requireEvent(t, "Expanded volume from 10Gi to 15Gi since 90% of it was used")
+
+	// 60% is used after the expansion, so the volume shouldn't be expanded
+	// again
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "15Gi")
+	require.Empty(t, getEvents(), "Unexpected events")
+}
+
+func pvcOverrideTest(t *testing.T) {
+	resetTest(t, 9*gb,
+		map[string]string{ExpandThresholdAnnotation: "80"},
+		map[string]string{ExpandThresholdAnnotation: "95", ExpandStepAnnotation: "100"})
+
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "10Gi")
+
+	require.NoError(t, mockDriver.UpdateVolumeUsage(volumeName, 10*gb), "Error updating volume usage")
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "20Gi")
+	requireEvent(t, "Expanded volume from 10Gi to 20Gi")
+}
+
+func maxSizeTest(t *testing.T) {
+	resetTest(t, 9*gb, map[string]string{
+		ExpandThresholdAnnotation: "80",
+		ExpandMaxSizeAnnotation:   "12Gi",
+	}, nil)
+
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "12Gi")
+	requireEvent(t, "Expanded volume from 10Gi to 12Gi")
+
+	require.NoError(t, mockDriver.UpdateVolumeUsage(volumeName, 11*gb), "Error updating volume usage")
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "12Gi")
+	requireEvent(t, "already been expanded to the maximum size of 12Gi")
+}
+
+func noPolicyTest(t *testing.T) {
+	resetTest(t, 10*gb, nil, nil)
+
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "10Gi")
+	require.Empty(t, getEvents(), "Unexpected events")
+}
+
+func invalidPolicyTest(t *testing.T) {
+	resetTest(t, 9*gb, map[string]string{ExpandThresholdAnnotation: "80"},
+		map[string]string{ExpandMaxSizeAnnotation: "large"})
+
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "10Gi")
+	requireEvent(t, "Invalid value \"large\" for "+ExpandMaxSizeAnnotation)
+}
+
+func resizeNotSupportedTest(t *testing.T) {
+	resetTest(t, 9*gb, map[string]string{ExpandThresholdAnnotation: "80"}, nil)
+	mockDriver.SetCapabilities(nil)
+
+	require.NoError(t, expander.expandVolumes(), "Error expanding volumes")
+	requireSize(t, "10Gi")
+	requireEvent(t, "Error expanding volume to 15Gi")
+}
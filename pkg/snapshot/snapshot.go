@@ -6,7 +6,6 @@ import (
 
 	"github.com/kubernetes-incubator/external-storage/lib/controller"
 	"github.com/kubernetes-incubator/external-storage/snapshot/pkg/client"
-	snapshotvolume "github.com/kubernetes-incubator/external-storage/snapshot/pkg/volume"
 	"github.com/libopenstorage/stork/drivers/volume"
 	"github.com/libopenstorage/stork/pkg/snapshot/controllers"
 	"github.com/portworx/sched-ops/k8s"
@@ -86,8 +85,7 @@ func (s *Snapshot) Start() error {
 		return err
 	}
 
-	plugins := make(map[string]snapshotvolume.Plugin)
-	plugins[s.Driver.String()] = s.Driver.GetSnapshotPlugin()
+	plugins := volume.GetSnapshotPlugins(s.Driver)
 
 	snapProvisioner := controllers.NewSnapshotProvisioner(clientset, snapshotClient, plugins, snapshotProvisionerID)
 
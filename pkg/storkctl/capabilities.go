@@ -0,0 +1,125 @@
+package storkctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/spf13/cobra"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/util"
+	"k8s.io/kubernetes/pkg/kubectl/genericclioptions"
+	"k8s.io/kubernetes/pkg/printers"
+)
+
+const (
+	capabilitiesSubcommand = "capabilities"
+	storkNamespaceFlag     = "stork-namespace"
+)
+
+var capabilitiesColumns = []string{"DRIVER", "SNAPSHOTS", "CLOUD-SNAPSHOTS", "GROUP-SNAPSHOTS", "CLUSTER-PAIR", "MIGRATION", "RESIZE", "MAINTENANCE", "TOPOLOGY"}
+
+// storkCapabilities Capabilities published by stork, used for the json and
+// yaml output
+type storkCapabilities struct {
+	Version string                          `json:"version"`
+	Drivers map[string]*volume.Capabilities `json:"drivers"`
+}
+
+func newGetCapabilitiesCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var storkNamespace string
+	getCapabilitiesCommand := &cobra.Command{
+		Use:     capabilitiesSubcommand,
+		Aliases: []string{"caps"},
+		Short:   "Get the capabilities of the storage drivers used by stork",
+		Run: func(c *cobra.Command, args []string) {
+			version, capabilities, err := volume.GetPublishedCapabilities(storkNamespace)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			outputFormat, err := cmdFactory.GetOutputFormat()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			if err := printCapabilities(version, capabilities, outputFormat, ioStreams.Out); err != nil {
+				util.CheckErr(err)
+				return
+			}
+		},
+	}
+	getCapabilitiesCommand.Flags().StringVar(&storkNamespace, storkNamespaceFlag, volume.DefaultCapabilitiesConfigMapNamespace,
+		"Namespace in which stork publishes the capabilities of its drivers")
+
+	return getCapabilitiesCommand
+}
+
+func printCapabilities(
+	version string,
+	capabilities map[string]*volume.Capabilities,
+	outputFormat string,
+	out io.Writer,
+) error {
+	switch outputFormat {
+	case outputFormatJSON:
+		encoded, err := json.MarshalIndent(&storkCapabilities{Version: version, Drivers: capabilities}, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", encoded)
+		return err
+	case outputFormatYaml:
+		encoded, err := yaml.Marshal(&storkCapabilities{Version: version, Drivers: capabilities})
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(encoded)
+		return err
+	}
+
+	if len(capabilities) == 0 {
+		handleEmptyList(out)
+		return nil
+	}
+
+	writer := printers.GetNewTabWriter(out)
+	if _, err := fmt.Fprintf(writer, "%v\n", strings.Join(capabilitiesColumns, "\t")); err != nil {
+		return err
+	}
+	for _, driver := range getDriverNames(capabilities) {
+		driverCapabilities := capabilities[driver]
+		var levels []string
+		for _, level := range driverCapabilities.TopologyLevels {
+			levels = append(levels, string(level))
+		}
+		if _, err := fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			driver,
+			strconv.FormatBool(driverCapabilities.Snapshots),
+			strconv.FormatBool(driverCapabilities.CloudSnapshots),
+			strconv.FormatBool(driverCapabilities.GroupSnapshots),
+			strconv.FormatBool(driverCapabilities.ClusterPair),
+			strconv.FormatBool(driverCapabilities.Migration),
+			strconv.FormatBool(driverCapabilities.Resize),
+			strconv.FormatBool(driverCapabilities.Maintenance),
+			strings.Join(levels, ",")); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// getDriverNames Returns the sorted names of the drivers
+func getDriverNames(capabilities map[string]*volume.Capabilities) []string {
+	drivers := make([]string, 0, len(capabilities))
+	for driver := range capabilities {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
+	return drivers
+}
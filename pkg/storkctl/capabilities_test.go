@@ -0,0 +1,87 @@
+// +build unittest
+
+package storkctl
+
+import (
+	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/drivers/volume/mock"
+	"github.com/libopenstorage/stork/pkg/version"
+	"github.com/stretchr/testify/require"
+)
+
+func publishCapabilities(t *testing.T, namespace string) {
+	driver := &mock.Driver{}
+	driver.SetCapabilities(&volume.Capabilities{
+		Snapshots:      true,
+		GroupSnapshots: true,
+		TopologyLevels: []volume.TopologyLevel{volume.TopologyLevelZone, volume.TopologyLevelRegion},
+	})
+	err := volume.PublishCapabilities(driver, namespace, "1.2.3")
+	require.NoError(t, err, "Error publishing capabilities")
+}
+
+func TestGetCapabilitiesNotPublished(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"get", "capabilities"}
+
+	expected := `Error from server (NotFound): configmaps "stork-capabilities" not found`
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestGetCapabilities(t *testing.T) {
+	defer resetTest()
+	publishCapabilities(t, volume.DefaultCapabilitiesConfigMapNamespace)
+
+	cmdArgs := []string{"get", "capabilities"}
+	expected := "DRIVER       SNAPSHOTS   CLOUD-SNAPSHOTS   GROUP-SNAPSHOTS   CLUSTER-PAIR   MIGRATION   RESIZE    MAINTENANCE   TOPOLOGY\n" +
+		"MockDriver   true        false             true              false          false       false     false         zone,region\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	// Publishing again should update the existing ConfigMap
+	publishCapabilities(t, volume.DefaultCapabilitiesConfigMapNamespace)
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestGetCapabilitiesNamespace(t *testing.T) {
+	defer resetTest()
+	publishCapabilities(t, "stork")
+
+	cmdArgs := []string{"get", "capabilities"}
+	expected := `Error from server (NotFound): configmaps "stork-capabilities" not found`
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"get", "capabilities", "--stork-namespace", "stork", "-o", "json"}
+	expected = `{
+    "version": "1.2.3",
+    "drivers": {
+        "MockDriver": {
+            "snapshots": true,
+            "cloudSnapshots": false,
+            "groupSnapshots": true,
+            "clusterPair": false,
+            "migration": false,
+            "resize": false,
+            "maintenance": false,
+            "topologyLevels": [
+                "zone",
+                "region"
+            ]
+        }
+    }
+}
+`
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestVersionWithCapabilities(t *testing.T) {
+	defer resetTest()
+	publishCapabilities(t, volume.DefaultCapabilitiesConfigMapNamespace)
+
+	cmdArgs := []string{"version"}
+	expected := "Version: " + version.Version + "\n" +
+		"Stork Version: 1.2.3\n" +
+		"Drivers: MockDriver\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
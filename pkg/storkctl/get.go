@@ -23,6 +23,8 @@ func newGetCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *c
 		newGetSchedulePolicyCommand(cmdFactory, ioStreams),
 		newGetMigrationScheduleCommand(cmdFactory, ioStreams),
 		newGetSnapshotScheduleCommand(cmdFactory, ioStreams),
+		newGetCapabilitiesCommand(cmdFactory, ioStreams),
+		newGetPVCCommand(cmdFactory, ioStreams),
 	)
 
 	return getCommands
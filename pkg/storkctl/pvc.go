@@ -2,8 +2,10 @@ package storkctl
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/kubernetes-incubator/external-storage/snapshot/pkg/client"
+	"github.com/libopenstorage/stork/drivers/volume"
 	snapshotcontrollers "github.com/libopenstorage/stork/pkg/snapshot/controllers"
 	"github.com/portworx/sched-ops/k8s"
 	"github.com/spf13/cobra"
@@ -12,11 +14,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/util"
 	"k8s.io/kubernetes/pkg/kubectl/genericclioptions"
+	"k8s.io/kubernetes/pkg/printers"
 )
 
 var defaultStrokSnapshotStorageClass = "stork-snapshot-sc"
 var pvcSubcommand = "persistentvolumeclaims"
 var pvcAliases = []string{"persistentvolumeclaim", "volume", "pvc"}
+var pvcColumns = []string{"NAME", "VOLUME", "REPLICATION", "IO-STATE", "ATTACHED-ON", "DEGRADED-NODES"}
 
 func newCreatePVCCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var snapName string
@@ -87,3 +91,82 @@ func newCreatePVCCommand(cmdFactory Factory, ioStreams genericclioptions.IOStrea
 
 	return createPVCCommand
 }
+
+func newGetPVCCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	getPVCCommand := &cobra.Command{
+		Use:     pvcSubcommand,
+		Aliases: pvcAliases,
+		Short:   "Get persistent volume claims (PVCs) with the health of their volumes",
+		Run: func(c *cobra.Command, args []string) {
+			namespaces, err := cmdFactory.GetAllNamespaces()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			pvcs := new(v1.PersistentVolumeClaimList)
+			if len(args) > 0 {
+				for _, pvcName := range args {
+					for _, ns := range namespaces {
+						pvc, err := k8s.Instance().GetPersistentVolumeClaim(pvcName, ns)
+						if err != nil {
+							util.CheckErr(err)
+							return
+						}
+						pvcs.Items = append(pvcs.Items, *pvc)
+					}
+				}
+			} else {
+				for _, ns := range namespaces {
+					pvcList, err := k8s.Instance().GetPersistentVolumeClaims(ns, nil)
+					if err != nil {
+						util.CheckErr(err)
+						return
+					}
+					pvcs.Items = append(pvcs.Items, pvcList.Items...)
+				}
+			}
+
+			if len(pvcs.Items) == 0 {
+				handleEmptyList(ioStreams.Out)
+				return
+			}
+
+			if err := printObjects(c, pvcs, cmdFactory, pvcColumns, pvcPrinter, ioStreams.Out); err != nil {
+				util.CheckErr(err)
+				return
+			}
+		},
+	}
+	cmdFactory.BindGetFlags(getPVCCommand.Flags())
+
+	return getPVCCommand
+}
+
+// pvcPrinter Prints the health of the volumes for the PVCs from the
+// annotations published by stork
+func pvcPrinter(pvcList *v1.PersistentVolumeClaimList, writer io.Writer, options printers.PrintOptions) error {
+	if pvcList == nil {
+		return nil
+	}
+	for _, pvc := range pvcList.Items {
+		name := printers.FormatResourceName(options.Kind, pvc.Name, options.WithKind)
+
+		if options.WithNamespace {
+			if _, err := fmt.Fprintf(writer, "%v\t", pvc.Namespace); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\n",
+			name,
+			pvc.Spec.VolumeName,
+			pvc.Annotations[volume.ReplicationStatusAnnotation],
+			pvc.Annotations[volume.IOStateAnnotation],
+			pvc.Annotations[volume.AttachedOnAnnotation],
+			pvc.Annotations[volume.DegradedNodesAnnotation]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,6 +4,12 @@ package storkctl
 
 import (
 	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/portworx/sched-ops/k8s"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestCreatePVCNoName(t *testing.T) {
@@ -40,3 +46,51 @@ func TestCreatePVCSourceNamespace(t *testing.T) {
 	expected := "PersistentVolumeClaim pvc2 created successfully\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
+
+func TestGetPVCsNoPVC(t *testing.T) {
+	cmdArgs := []string{"get", "pvc", "-n", "pvchealth"}
+
+	expected := "No resources found.\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestGetPVCsHealth(t *testing.T) {
+	defer resetTest()
+	_, err := k8s.Instance().CreatePersistentVolumeClaim(&v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pvc1",
+			Namespace: "pvchealth",
+			Annotations: map[string]string{
+				volume.ReplicationStatusAnnotation: string(volume.ReplicationDegraded),
+				volume.DegradedNodesAnnotation:     "node2,node3",
+				volume.AttachedOnAnnotation:        "node1",
+				volume.IOStateAnnotation:           string(volume.IOStateOnline),
+			},
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			VolumeName: "volume1",
+		},
+	})
+	require.NoError(t, err, "Error creating PVC")
+	_, err = k8s.Instance().CreatePersistentVolumeClaim(&v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pvc2",
+			Namespace: "pvchealth",
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			VolumeName: "volume2",
+		},
+	})
+	require.NoError(t, err, "Error creating PVC")
+
+	expected := "NAME      VOLUME    REPLICATION   IO-STATE   ATTACHED-ON   DEGRADED-NODES\n" +
+		"pvc1      volume1   Degraded      Online     node1         node2,node3\n" +
+		"pvc2      volume2                                          \n"
+	cmdArgs := []string{"get", "pvc", "-n", "pvchealth"}
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	expected = "NAME      VOLUME    REPLICATION   IO-STATE   ATTACHED-ON   DEGRADED-NODES\n" +
+		"pvc1      volume1   Degraded      Online     node1         node2,node3\n"
+	cmdArgs = []string{"get", "pvc", "pvc1", "-n", "pvchealth"}
+	testCommon(t, cmdArgs, nil, expected, false)
+}
@@ -2,13 +2,16 @@ package storkctl
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/libopenstorage/stork/drivers/volume"
 	"github.com/libopenstorage/stork/pkg/version"
 	"github.com/spf13/cobra"
 	"k8s.io/kubernetes/pkg/kubectl/genericclioptions"
 )
 
 func newVersionCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var storkNamespace string
 	versionCommands := &cobra.Command{
 		Use:   "version",
 		Short: "Print the version of storkctl",
@@ -17,7 +20,20 @@ func newVersionCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams
 			if err != nil {
 				panic("Failed to print: " + err.Error())
 			}
+			// Also print the version and drivers of stork if it has
+			// published them
+			storkVersion, capabilities, err := volume.GetPublishedCapabilities(storkNamespace)
+			if err != nil {
+				return
+			}
+			_, err = fmt.Fprintf(ioStreams.Out, "Stork Version: %v\nDrivers: %v\n",
+				storkVersion, strings.Join(getDriverNames(capabilities), ","))
+			if err != nil {
+				panic("Failed to print: " + err.Error())
+			}
 		},
 	}
+	versionCommands.Flags().StringVar(&storkNamespace, storkNamespaceFlag, volume.DefaultCapabilitiesConfigMapNamespace,
+		"Namespace in which stork publishes its version and the capabilities of its drivers")
 	return versionCommands
 }